@@ -0,0 +1,122 @@
+package lzsnmp
+
+import (
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// AccessLogEntry 访问日志中的一条请求记录
+type AccessLogEntry struct {
+	Time         time.Time `json:"time"`
+	Source       string    `json:"source"`
+	Version      string    `json:"version,omitempty"`
+	SecurityName string    `json:"securityName,omitempty"`
+	PDUType      string    `json:"pduType,omitempty"`
+	OIDs         []string  `json:"oids,omitempty"`
+	Result       string    `json:"result"` // 响应的错误状态，如 NoError、NoSuchName；未回复时为 dropped
+	LatencyMs    float64   `json:"latencyMs"`
+}
+
+// AccessLogWriter 访问日志输出
+type AccessLogWriter interface {
+	WriteAccessLog(entry *AccessLogEntry) error
+}
+
+// AccessLogConfig 访问日志配置
+type AccessLogConfig struct {
+	Writer AccessLogWriter
+	// SampleRate 成功请求的采样比例（0~1），0 表示全部记录；失败和未回复的请求总是记录
+	SampleRate float64
+}
+
+// 访问日志中未回复请求的结果
+const accessLogDropped = "dropped"
+
+// jsonAccessLog 以 JSON Lines 格式写入 io.Writer
+type jsonAccessLog struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONAccessLog 返回以 JSON Lines 格式写入 w 的 AccessLogWriter，可以安全并发使用
+func NewJSONAccessLog(w io.Writer) AccessLogWriter {
+	return &jsonAccessLog{enc: json.NewEncoder(w)}
+}
+
+func (l *jsonAccessLog) WriteAccessLog(entry *AccessLogEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.enc.Encode(entry)
+}
+
+// SetAccessLog 启用访问日志，cfg.Writer 为 nil 时关闭
+func (a *Agent) SetAccessLog(cfg AccessLogConfig) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if cfg.Writer == nil {
+		a.accessLog = nil
+		a.logger.Info("Access log disabled")
+		return
+	}
+	a.accessLog = &cfg
+	a.logger.Info("Access log enabled", "sampleRate", cfg.SampleRate)
+}
+
+// writeAccessLog 记录一个已处理的请求，response 为空表示未回复
+func (a *Agent) writeAccessLog(start time.Time, source net.Addr, request *gosnmp.SnmpPacket, response []byte) {
+	a.mu.RLock()
+	cfg := a.accessLog
+	a.mu.RUnlock()
+	if cfg == nil {
+		return
+	}
+
+	entry := &AccessLogEntry{
+		Time:      start,
+		Result:    accessLogDropped,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if source != nil {
+		entry.Source = source.String()
+	}
+	if len(response) > 0 {
+		entry.Result = responseStatus(response)
+	}
+
+	if entry.Result == gosnmp.NoError.String() && cfg.SampleRate > 0 && rand.Float64() >= cfg.SampleRate {
+		return
+	}
+
+	if request != nil {
+		info := requestInfoOf(source, request)
+		entry.Version = request.Version.String()
+		entry.SecurityName = info.SecurityName
+		if request.PDUType != 0 {
+			entry.PDUType = request.PDUType.String()
+		}
+		for _, v := range request.Variables {
+			entry.OIDs = append(entry.OIDs, v.Name)
+		}
+	}
+
+	if err := cfg.Writer.WriteAccessLog(entry); err != nil {
+		a.logger.Warn("Failed to write access log", "error", err)
+	}
+}
+
+// responseStatus 解码响应的错误状态，SNMPv3 加密响应无法解码时返回 NoError
+func responseStatus(response []byte) string {
+	decoder := gosnmp.GoSNMP{SecurityParameters: &gosnmp.UsmSecurityParameters{}}
+	pkt, err := decoder.SnmpDecodePacket(response)
+	if err != nil {
+		return gosnmp.NoError.String()
+	}
+	return pkt.Error.String()
+}
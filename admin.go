@@ -0,0 +1,285 @@
+//go:build !lzsnmp_noadmin
+
+package lzsnmp
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxAdminBody POST 请求体的大小上限
+const maxAdminBody = 1 << 20
+
+// AdminServer 由 StartAdmin 启动的管理接口
+type AdminServer struct {
+	server   *http.Server
+	listener net.Listener
+}
+
+// Addr 返回管理接口实际监听的地址，addr 端口为 0 时用于获取分配的端口
+func (s *AdminServer) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Close 立即关闭管理接口
+func (s *AdminServer) Close() error {
+	return s.server.Close()
+}
+
+// AdminOptions StartAdminWithOptions 的选项
+type AdminOptions struct {
+	// Token 不为空时所有请求必须带有 "Authorization: Bearer <Token>" 头，否则返回 401
+	Token string
+}
+
+// StartAdmin 在回环地址 addr（如 "127.0.0.1:8161"）上启动没有认证的管理 HTTP 接口，见 StartAdminWithOptions
+func (a *Agent) StartAdmin(addr string) (*AdminServer, error) {
+	return a.StartAdminWithOptions(addr, AdminOptions{})
+}
+
+// StartAdminWithOptions 在回环地址 addr 上启动管理 HTTP 接口，接口说明见 AdminHandler
+//
+// 只允许监听回环地址，Host 头不是回环地址或 localhost 的请求返回 403，防止 DNS 重绑定；
+// 本机的其他用户也能访问回环地址时应设置 Token。需要远程访问时请将 AdminHandler 挂到带认证的 HTTP 服务上。
+func (a *Agent) StartAdminWithOptions(addr string, opts AdminOptions) (*AdminServer, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid admin address %s: %w", addr, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("admin address must be a loopback address, got %s", addr)
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start admin server: %w", err)
+	}
+	s := &AdminServer{
+		server:   &http.Server{Handler: guardAdmin(a.AdminHandler(), opts.Token), ReadHeaderTimeout: 5 * time.Second},
+		listener: ln,
+	}
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			a.logger.Error("Admin server stopped", "error", err)
+		}
+	}()
+
+	a.logger.Info("Admin server started", "addr", ln.Addr())
+	return s, nil
+}
+
+// AdminHandler 返回管理接口的 http.Handler，请求和响应均为 JSON：
+//   - GET /oids: 列出所有 OID，格式同 SubtreeEntry；动态 OID 不调用处理函数，value 为空
+//   - GET /oids/{oid}: 返回一个 OID 的信息（名称、说明、单位等），格式同上
+//   - POST /oids: 注册静态 OID，请求体为 SubtreeEntry（oid 为绝对 OID，type、value、hex、name、description、units）
+//   - DELETE /oids/{oid}: 注销 OID
+//   - GET /modules: 列出所有模块的状态，格式同 ModuleStatus
+//   - POST /modules/{name}/enable、POST /modules/{name}/disable: 启用或停用模块（见 EnableModule、DisableModule），返回模块状态
+//   - GET /stats: 返回 Stats
+//
+// 所有 POST 请求的 Content-Type 必须为 application/json，否则返回 415，防止跨站表单提交。
+func (a *Agent) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /oids", a.adminListOIDs)
+	mux.HandleFunc("GET /oids/{oid}", a.adminDescribeOID)
+	mux.HandleFunc("POST /oids", a.adminRegisterOID)
+	mux.HandleFunc("DELETE /oids/{oid}", a.adminUnregisterOID)
+	mux.HandleFunc("GET /modules", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, a.Modules())
+	})
+	mux.HandleFunc("POST /modules/{name}/enable", a.adminModuleAction(a.EnableModule, "Enabled module via admin API"))
+	mux.HandleFunc("POST /modules/{name}/disable", a.adminModuleAction(a.DisableModule, "Disabled module via admin API"))
+	mux.HandleFunc("GET /stats", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, a.Stats())
+	})
+	return mux
+}
+
+func (a *Agent) adminListOIDs(w http.ResponseWriter, r *http.Request) {
+	infos := a.ListOIDs()
+	entries := make([]SubtreeEntry, 0, len(infos))
+	for _, info := range infos {
+		entries = append(entries, adminEntry(info))
+	}
+	writeAdminJSON(w, http.StatusOK, entries)
+}
+
+func (a *Agent) adminDescribeOID(w http.ResponseWriter, r *http.Request) {
+	info, err := a.Describe(r.PathValue("oid"))
+	if err != nil {
+		writeAdminError(w, http.StatusNotFound, err)
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, adminEntry(info))
+}
+
+// adminEntry 将 OIDInfo 转换为管理接口返回的 SubtreeEntry，动态 OID 的 value 为空
+func adminEntry(info OIDInfo) SubtreeEntry {
+	entry := SubtreeEntry{
+		OID:         info.OID,
+		Type:        info.Type.String(),
+		Writable:    info.Writable,
+		Name:        info.Name,
+		Description: info.Description,
+		Units:       info.Units,
+	}
+	if info.Kind == OIDDynamic {
+		entry.Dynamic = true
+	} else if text, isHex, err := formatValueText(info.Type, info.LastValue); err == nil {
+		entry.Value, entry.Hex = text, isHex
+	}
+	return entry
+}
+
+func (a *Agent) adminRegisterOID(w http.ResponseWriter, r *http.Request) {
+	if !requireAdminJSON(w, r) {
+		return
+	}
+
+	var entry SubtreeEntry
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAdminBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&entry); err != nil {
+		writeAdminError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	oidType, err := ParseType(entry.Type)
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err)
+		return
+	}
+	value, err := parseValueText(oidType, entry.Value, entry.Hex)
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err)
+		return
+	}
+	oid, err := normalizeOID(entry.OID)
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := a.RegisterStaticAbsolute(oid, oidType, value); err != nil {
+		status := http.StatusBadRequest
+		var overlap *OverlapError
+		if errors.As(err, &overlap) {
+			status = http.StatusConflict
+		}
+		writeAdminError(w, status, err)
+		return
+	}
+	if entry.Name != "" || entry.Description != "" || entry.Units != "" {
+		if err := a.AnnotateAbsolute(oid, OIDMeta{Name: entry.Name, Description: entry.Description, Units: entry.Units}); err != nil {
+			writeAdminError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	a.logger.Info("Registered OID via admin API", "oid", oid, "from", r.RemoteAddr)
+	entry.OID, entry.Type, entry.Dynamic, entry.Writable = oid, oidType.String(), false, false
+	writeAdminJSON(w, http.StatusCreated, entry)
+}
+
+func (a *Agent) adminUnregisterOID(w http.ResponseWriter, r *http.Request) {
+	oid := strings.TrimPrefix(r.PathValue("oid"), ".")
+	if err := a.UnregisterAbsolute(oid); err != nil {
+		writeAdminError(w, http.StatusNotFound, err)
+		return
+	}
+	a.logger.Info("Unregistered OID via admin API", "oid", oid, "from", r.RemoteAddr)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// requireAdminJSON 检查 POST 请求的 Content-Type 为 application/json，否则返回 415
+//
+// 浏览器的跨站表单只能以 application/x-www-form-urlencoded、multipart/form-data 或 text/plain 提交，
+// 其他类型需要 CORS 预检，因此可以防止跨站请求伪造。
+func requireAdminJSON(w http.ResponseWriter, r *http.Request) bool {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		writeAdminError(w, http.StatusUnsupportedMediaType, fmt.Errorf("content type must be application/json"))
+		return false
+	}
+	return true
+}
+
+// guardAdmin 拒绝 Host 头不是回环地址的请求，token 不为空时检查 Bearer 令牌
+func guardAdmin(next http.Handler, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopbackHost(r.Host) {
+			writeAdminError(w, http.StatusForbidden, fmt.Errorf("host %q is not allowed", r.Host))
+			return
+		}
+		if token != "" {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeAdminError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid bearer token"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackHost 判断 Host 头（可带端口）是否为 localhost 或回环地址
+func isLoopbackHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = strings.Trim(hostport, "[]")
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// adminModuleAction 返回启用或停用模块的处理函数，成功时返回模块的新状态
+func (a *Agent) adminModuleAction(action func(name string) error, logMsg string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !requireAdminJSON(w, r) {
+			return
+		}
+		name := r.PathValue("name")
+		if err := action(name); err != nil {
+			status := http.StatusConflict
+			if errors.Is(err, ErrModuleNotFound) {
+				status = http.StatusNotFound
+			}
+			writeAdminError(w, status, err)
+			return
+		}
+		a.logger.Info(logMsg, "name", name, "from", r.RemoteAddr)
+		statuses := a.Modules()
+		i := slices.IndexFunc(statuses, func(s ModuleStatus) bool { return s.Name == name })
+		if i < 0 {
+			// 模块在操作完成后被并发移除
+			writeAdminError(w, http.StatusNotFound, fmt.Errorf("%w: %s", ErrModuleNotFound, name))
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, statuses[i])
+	}
+}
+
+// writeAdminJSON 以 JSON 写出响应
+func writeAdminJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// writeAdminError 以 {"error": "..."} 写出错误
+func writeAdminError(w http.ResponseWriter, status int, err error) {
+	writeAdminJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -1,135 +1,478 @@
 package lzsnmp
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/gosnmp/gosnmp"
 	"github.com/slayercat/GoSNMPServer"
 )
 
+// maxResponseJitter 响应延迟上限，常见管理端的默认超时为 1 秒
+const maxResponseJitter = time.Second
+
 // ValueHandler 动态值处理函数类型
 type ValueHandler func() (interface{}, error)
 
+// ContextHandler 接收请求 context 的动态值处理函数，ctx 包含请求 ID 和追踪 span
+type ContextHandler func(ctx context.Context) (interface{}, error)
+
+// SetHandler SET 请求处理函数类型
+type SetHandler func(value interface{}) error
+
 // Config SNMP Agent 配置
 type Config struct {
-	PEN        uint32 // Private Enterprise Number
-	ListenAddr string // 监听地址，如 "0.0.0.0:161"
+	PEN       uint32 // Private Enterprise Number，未设置 OIDPrefix 时必需
+	OIDPrefix string // 相对 OID 的前缀，如实验性分支 "1.3.6.1.3.9999"；为空时为 1.3.6.1.4.1.<PEN>
+
+	// Prefixes 除默认前缀外的命名前缀，如 {"acme": "1.3.6.1.4.1.4242"}，用于在一个 Agent 中服务多个企业 OID 树；
+	// 通过 RegisterIn、ListOIDsIn 等按前缀 ID 注册和列出，前缀之间不能重叠
+	Prefixes   map[string]string
+	ListenAddr string // 监听地址，如 "0.0.0.0:161"；端口为 0 时由系统分配，启动后通过 Addr 获取；"systemd" 使用 socket 激活传入的 socket
 	Community  string // Community string，默认 "public"
-	LogLevel   log.Level
-	Logger     *log.Logger
+	SourceAddr string // 响应源地址，为空时使用请求到达的地址（仅监听通配地址时生效）
+
+	// ListenAddrs 除 ListenAddr 外同时监听的地址，如 IPv4 和 IPv6 分别监听 "0.0.0.0:161" 与 "[::]:161"；
+	// 监听多个地址时 IPv6 地址的 socket 只接收 IPv6 请求。只设置 ListenAddrs 时 ListenAddr 不使用默认值
+	ListenAddrs []string
+	// ListenInterface 将所有监听 socket 绑定到该网卡（SO_BINDTODEVICE，仅 Linux），只接收从该网卡到达的请求
+	ListenInterface string
+	// ReusePortSockets 每个监听地址打开的 socket 数（SO_REUSEPORT，仅 Linux），默认 1；
+	// 每个 socket 由独立的协程接收，内核按来源地址把报文分配到各 socket，应同时调大 MaxConcurrentRequests
+	ReusePortSockets int
+
+	Communities []string // 除 Community 外额外接受的 community
+	Users       []User   // SNMPv3 USM 用户
+
+	// Modules 按模块名覆盖 Module.Disabled：true 启用，false 禁用，未列出的模块保持原设置
+	Modules map[string]bool
+
+	MaxConcurrentRequests int  // 同时处理的请求数上限，默认 1（按到达顺序逐个处理）
+	RequestQueueSize      int  // 等待处理的请求数上限，默认与 MaxConcurrentRequests 相同
+	DropWhenBusy          bool // 队列已满时丢弃新请求；默认暂停读取，由内核接收缓冲区排队
+
+	// ResponseJitter 每个响应随机延迟 [0, ResponseJitter) 后发送，错开大量 Agent 同时回复同一轮询周期的峰值；
+	// 最大 1 秒，建议不超过管理端超时的十分之一
+	ResponseJitter time.Duration
+
+	// EngineID SNMPv3 engineID 的管理员指定部分（1..27 字节），为空时由主机 ID 生成，
+	// 也可以使用 GenerateEngineID 由 MAC/IP 地址生成；配置了 Cluster 时使用 Cluster.EngineID
+	EngineID string
+
+	// Cluster 多个实例在 anycast/VIP 地址后共同应答时共享的引擎参数，单实例部署为 nil
+	Cluster *ClusterConfig
+
+	// DisableStartTraps 不在 Start 时发送 coldStart/warmStart 通知
+	DisableStartTraps bool
+	// EnableAuthenTraps 收到未知 community 的请求时发送 authenticationFailure 通知，默认关闭
+	EnableAuthenTraps bool
+
+	// LogSampling 每个请求输出的日志的采样和限速，默认全部输出
+	LogSampling LogSampling
+
+	// Tracer 为每个请求创建追踪 span，为 nil 时不追踪；otelsnmp.NewTracer 提供 OpenTelemetry 实现
+	Tracer Tracer
+
+	// Persist 保存静态值和 SET 修改的值，重启后在注册时恢复，为 nil 时不持久化；
+	// 同时保存 SNMPv3 engineBoots，每次启动递增，engineTime 从启动时重新计时
+	Persist *PersistConfig
+
+	// OnStart 开始监听后调用，addrs 与 Addrs 相同
+	OnStart func(addrs []net.Addr)
+	// OnStop Stop 关闭监听后调用
+	OnStop func()
+	// OnError 服务循环因 socket 错误退出或重启失败时调用，为 nil 时只记录日志
+	OnError func(err error)
+	// Restart socket 出错后重启服务循环的策略，为 nil 时不重启，通过 Err 和 Done 获取退出的原因
+	Restart *RestartPolicy
+
+	LogLevel log.Level // 默认日志的级别
+	Logger   Logger    // 自定义日志，为 nil 时使用 charmbracelet/log 输出到 stderr
 }
 
 // Agent SNMP Agent 封装
 type Agent struct {
-	config     Config
-	server     *GoSNMPServer.MasterAgent
-	snmpServer *GoSNMPServer.SNMPServer
-	logger     *log.Logger
-	oidPrefix  string
-	handlers   map[string]ValueHandler
-	staticVals map[string]interface{}
-	mu         sync.RWMutex
+	config        Config
+	workers       []*worker
+	conns         []transport
+	run           *serveRun // 当前的服务循环，未启动时为 nil
+	sourceIP      net.IP
+	logger        Logger
+	logLimits     logLimits
+	tracer        Tracer
+	tracing       bool // 配置了 Tracer，为 false 时不计算 span 属性
+	oidPrefix     string
+	prefixes      map[string]string // Config.Prefixes，NewAgent 后不变
+	store         atomic.Pointer[oidStore]
+	access        atomic.Pointer[accessConfig]
+	accessMu      sync.Mutex // 串行化对 access 的修改
+	meta          map[string]OIDMeta
+	docGroups     map[string]bool
+	stats         agentStats
+	middleware    atomic.Pointer[[]Middleware]
+	coalesceRules atomic.Pointer[map[string]time.Duration] // 开启请求合并的子树 → 窗口
+	coalesceMu    sync.Mutex                               // 串行化对 coalesceRules 的修改
+	coalesced     sync.Map                                 // OID → *coalescedCall
+	pollers       map[string]*poller                       // RegisterPolled 的采集任务
+	pollMu        sync.Mutex                               // 保护 pollers
+	accessLog     *AccessLogConfig
+	modules       moduleRegistry
+	sysOR         sysORRegistry
+	notifications notificationHub
+	events        eventHub
+	watches       watchHub
+	targets       TargetManager
+	authenTraps   atomic.Bool
+	authTrapBusy  atomic.Bool
+	restarted     atomic.Bool
+	shadow        *shadowRunner
+	packetDump    atomic.Pointer[packetDumper]
+	persist       *persister
+	engine        atomic.Pointer[engineState]
+	mu            sync.RWMutex
+	syncMu        sync.Mutex
+	createdAt     time.Time
 }
 
-// OIDEntry OID 注册项
+// OIDEntry OID 注册项，用于 RegisterBatch
 type OIDEntry struct {
 	OID     string
 	Type    gosnmp.Asn1BER
-	Handler ValueHandler
-	Static  interface{}
+	Handler ValueHandler // 动态值处理函数，与 Static 二选一
+	Static  interface{}  // 静态值
 }
 
 // NewAgent 创建新的 SNMP Agent
 func NewAgent(cfg Config) (*Agent, error) {
-	if cfg.PEN == 0 {
-		return nil, fmt.Errorf("PEN (Private Enterprise Number) is required")
+	// 生成企业 OID 前缀
+	oidPrefix := fmt.Sprintf("1.3.6.1.4.1.%d", cfg.PEN)
+	if cfg.OIDPrefix != "" {
+		prefix, err := normalizeOID(cfg.OIDPrefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OIDPrefix: %w", err)
+		}
+		oidPrefix = prefix
+	} else if cfg.PEN == 0 {
+		return nil, fmt.Errorf("PEN (Private Enterprise Number) or OIDPrefix is required")
+	}
+	prefixes, err := newPrefixes(cfg.Prefixes, oidPrefix)
+	if err != nil {
+		return nil, err
 	}
 
-	if cfg.ListenAddr == "" {
+	if cfg.ListenAddr == "" && len(cfg.ListenAddrs) == 0 {
 		cfg.ListenAddr = "0.0.0.0:161"
 	}
+	seen := make(map[string]bool)
+	for _, addr := range listenAddrsOf(&cfg) {
+		if seen[addr] {
+			return nil, fmt.Errorf("duplicate listen address: %s", addr)
+		}
+		seen[addr] = true
+	}
+
+	if cfg.ReusePortSockets < 0 {
+		return nil, fmt.Errorf("ReusePortSockets must not be negative")
+	}
+	if cfg.ReusePortSockets == 0 {
+		cfg.ReusePortSockets = 1
+	}
+
+	if cfg.MaxConcurrentRequests < 0 || cfg.RequestQueueSize < 0 {
+		return nil, fmt.Errorf("MaxConcurrentRequests and RequestQueueSize must not be negative")
+	}
+	if cfg.MaxConcurrentRequests == 0 {
+		cfg.MaxConcurrentRequests = 1
+	}
+	if cfg.RequestQueueSize == 0 {
+		cfg.RequestQueueSize = cfg.MaxConcurrentRequests
+	}
+
+	if cfg.ResponseJitter < 0 || cfg.ResponseJitter > maxResponseJitter {
+		return nil, fmt.Errorf("ResponseJitter must be between 0 and %s", maxResponseJitter)
+	}
+
+	access, err := newAccessConfig(&cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	if cfg.Community == "" {
-		cfg.Community = "public"
+	if cfg.Cluster != nil {
+		cluster := *cfg.Cluster
+		if err := cluster.validate(); err != nil {
+			return nil, err
+		}
+		cfg.Cluster = &cluster
+	}
+	engine, err := newEngineState(&cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := newLogLimits(cfg.LogSampling)
+	if err != nil {
+		return nil, err
+	}
+
+	var sourceIP net.IP
+	if cfg.SourceAddr != "" {
+		if sourceIP = net.ParseIP(cfg.SourceAddr); sourceIP == nil {
+			return nil, fmt.Errorf("invalid source address: %s", cfg.SourceAddr)
+		}
 	}
 
 	// 初始化日志
 	logger := cfg.Logger
 	if logger == nil {
-		logger = log.NewWithOptions(os.Stderr, log.Options{
-			ReportTimestamp: true,
-			ReportCaller:    cfg.LogLevel == log.DebugLevel,
-			Level:           cfg.LogLevel,
-			Prefix:          "lzsnmp",
-		})
+		logger = newDefaultLogger(cfg.LogLevel, cfg.LogLevel == log.DebugLevel)
 	}
 
-	// 生成企业 OID 前缀
-	oidPrefix := fmt.Sprintf("1.3.6.1.4.1.%d", cfg.PEN)
+	tracer := cfg.Tracer
+	if tracer == nil {
+		tracer = noopTracer{}
+	}
 
 	agent := &Agent{
-		config:     cfg,
-		logger:     logger,
-		oidPrefix:  oidPrefix,
-		handlers:   make(map[string]ValueHandler),
-		staticVals: make(map[string]interface{}),
+		config:    cfg,
+		logger:    logger,
+		logLimits: limits,
+		tracer:    tracer,
+		tracing:   cfg.Tracer != nil,
+		oidPrefix: oidPrefix,
+		prefixes:  prefixes,
+		sourceIP:  sourceIP,
+		meta:      make(map[string]OIDMeta),
+		docGroups: make(map[string]bool),
+		createdAt: time.Now(),
+	}
+	agent.targets.agent = agent
+	agent.authenTraps.Store(cfg.EnableAuthenTraps)
+	agent.store.Store(newOIDStore())
+	agent.access.Store(access)
+	agent.engine.Store(engine)
+	if cfg.Persist != nil {
+		if agent.persist, err = newPersister(agent, *cfg.Persist); err != nil {
+			return nil, err
+		}
 	}
 
 	logger.Info("SNMP Agent initialized",
 		"pen", cfg.PEN,
 		"prefix", oidPrefix,
-		"listen", cfg.ListenAddr)
+		"listen", strings.Join(listenAddrsOf(&cfg), ","))
 
 	return agent, nil
 }
 
+// listenAddrsOf 返回 Start 监听的所有地址
+func listenAddrsOf(cfg *Config) []string {
+	var addrs []string
+	if cfg.ListenAddr != "" {
+		addrs = append(addrs, cfg.ListenAddr)
+	}
+	return append(addrs, cfg.ListenAddrs...)
+}
+
 // Start 启动 SNMP Agent
 func (a *Agent) Start() error {
-	a.logger.Info("Starting SNMP Agent", "addr", a.config.ListenAddr)
-
-	master := GoSNMPServer.MasterAgent{
-		SecurityConfig: GoSNMPServer.SecurityConfig{
-			AuthoritativeEngineBoots: 1,
-			Users:                    []gosnmp.UsmSecurityParameters{},
-		},
-		SubAgents: []*GoSNMPServer.SubAgent{
-			{
-				CommunityIDs: []string{a.config.Community},
-				OIDs:         []*GoSNMPServer.PDUValueControlItem{},
-			},
-		},
+	addrs := listenAddrsOf(&a.config)
+	a.logger.Info("Starting SNMP Agent", "addr", strings.Join(addrs, ","))
+	return a.start(func() ([]transport, error) {
+		opts := listenOptions{
+			iface:     a.config.ListenInterface,
+			v6only:    len(addrs) > 1,
+			reusePort: a.config.ReusePortSockets > 1,
+		}
+		var conns []transport
+		for _, addr := range addrs {
+			group, err := a.listenGroup(addr, opts)
+			if err != nil {
+				for _, c := range conns {
+					c.Close()
+				}
+				return nil, err
+			}
+			conns = append(conns, group...)
+		}
+		return conns, nil
+	}, true)
+}
+
+// listenGroup 在 addr 上打开 ReusePortSockets 个 socket，端口为 0 时其余 socket 使用第一个分配到的端口
+func (a *Agent) listenGroup(addr string, opts listenOptions) ([]transport, error) {
+	first, err := listenUDP(addr, opts)
+	if err != nil {
+		return nil, err
+	}
+	if a.sourceIP != nil && first.pkt == nil {
+		a.logger.Warn("Source address ignored, listener is not bound to a wildcard address or PKTINFO is unavailable", "source", a.sourceIP, "listen", first.LocalAddr())
+	}
+	group := []transport{first}
+	if isSystemdAddr(addr) || a.config.ReusePortSockets <= 1 {
+		return group, nil
 	}
 
-	a.server = &master
-	a.snmpServer = GoSNMPServer.NewSNMPServer(master)
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		first.Close()
+		return nil, err
+	}
+	bound := net.JoinHostPort(host, strconv.Itoa(first.LocalAddr().(*net.UDPAddr).Port))
+	for len(group) < a.config.ReusePortSockets {
+		conn, err := listenUDP(bound, opts)
+		if err != nil {
+			for _, c := range group {
+				c.Close()
+			}
+			return nil, err
+		}
+		group = append(group, conn)
+	}
+	return group, nil
+}
 
-	// 注册处理器
-	a.registerHandlers()
+// StartPacketConn 在 conn 上启动 SNMP Agent，不监听 ListenAddr，Stop 时关闭 conn
+//
+// 用于在 testutil.MemNetwork 等自定义的 net.PacketConn 上提供服务，单元测试不需要占用 UDP 端口。
+// 响应总是发往请求的来源地址，SourceAddr 不生效。
+func (a *Agent) StartPacketConn(conn net.PacketConn) error {
+	a.logger.Info("Starting SNMP Agent", "addr", conn.LocalAddr())
+	return a.start(func() ([]transport, error) {
+		return []transport{packetConnTransport{conn}}, nil
+	}, false)
+}
+
+// start 初始化 Agent，通过 listen 获取连接并启动服务循环，restartable 为 true 时出错后可以再次调用 listen 重启
+func (a *Agent) start(listen func() ([]transport, error), restartable bool) error {
+	if err := a.bootEngine(); err != nil {
+		return err
+	}
+	if err := a.prepare(); err != nil {
+		return err
+	}
 
 	// 启动服务器
-	if err := a.snmpServer.ListenUDP("udp", a.config.ListenAddr); err != nil {
+	conns, err := listen()
+	if err != nil {
 		a.logger.Error("Failed to start SNMP server", "error", err)
 		return fmt.Errorf("failed to start SNMP server: %w", err)
 	}
+	r := &serveRun{done: make(chan struct{}), stop: make(chan struct{})}
+	var restart func() ([]transport, error)
+	if restartable {
+		restart = listen
+	}
+	a.mu.Lock()
+	a.conns = conns
+	a.run = r
+	a.mu.Unlock()
 
 	// 启动服务循环
 	go func() {
 		a.logger.Debug("Starting SNMP server loop")
-		a.snmpServer.ServeForever()
+		a.runServe(r, conns, restart)
 	}()
 
-	a.logger.Info("SNMP Agent started successfully")
+	bound := make([]string, len(conns))
+	for i, conn := range conns {
+		bound[i] = conn.LocalAddr().String()
+	}
+	a.persist.start()
+	a.logger.Info("SNMP Agent started successfully", "addr", strings.Join(bound, ","))
+	a.sendStartTrap()
+	a.notifySystemd("READY=1\nSTATUS=Serving SNMP on " + strings.Join(bound, ", "))
+	a.publishEvent(Event{Type: EventStarted})
+	if a.config.OnStart != nil {
+		a.config.OnStart(a.Addrs())
+	}
+	return nil
+}
+
+// Addr 返回实际监听的地址，监听多个地址时为第一个，未启动时为 nil
+//
+// ListenAddr 的端口为 0（如 "127.0.0.1:0"）时由系统分配空闲端口，测试和嵌入使用时通过 Addr 获取，
+// 避免写死端口和端口冲突。通过 Start 启动时为 *net.UDPAddr。
+func (a *Agent) Addr() net.Addr {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if len(a.conns) == 0 {
+		return nil
+	}
+	return a.conns[0].LocalAddr()
+}
+
+// Addrs 返回所有监听的地址，顺序与 ListenAddr、ListenAddrs 相同，ReusePortSockets 大于 1 时每个 socket 一项；
+// 未启动时为 nil
+func (a *Agent) Addrs() []net.Addr {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	var addrs []net.Addr
+	for _, conn := range a.conns {
+		addrs = append(addrs, conn.LocalAddr())
+	}
+	return addrs
+}
+
+// prepare 初始化模块并创建 worker，完成后 Agent 可以处理请求报文
+func (a *Agent) prepare() error {
+	// 按依赖顺序初始化模块
+	if err := a.initModules(); err != nil {
+		return fmt.Errorf("failed to initialize modules: %w", err)
+	}
+	if len(a.access.Load().users) > 0 {
+		a.addSysOR(snmpUsmMIBOID, "The management information definitions for the SNMP User-based Security Model", "")
+	}
+
+	// 每个 worker 使用独立的 MasterAgent，处理函数通过它获取所属请求的上下文
+	workers := make([]*worker, a.config.MaxConcurrentRequests)
+	for i := range workers {
+		w, err := a.newWorker()
+		if err != nil {
+			return fmt.Errorf("invalid SNMP server config: %w", err)
+		}
+		workers[i] = w
+	}
+
+	// 注册处理器
+	a.mu.Lock()
+	a.workers = workers
+	a.mu.Unlock()
+	a.syncWorkers()
 	return nil
 }
 
 // Stop 停止 SNMP Agent
 func (a *Agent) Stop() error {
 	a.logger.Info("Stopping SNMP Agent")
-	if a.snmpServer != nil {
-		a.snmpServer.Shutdown()
+	a.notifySystemd("STOPPING=1")
+	a.mu.RLock()
+	r := a.run
+	a.mu.RUnlock()
+	if r != nil {
+		// 先通知服务循环停止重启，再关闭连接
+		r.stopOnce.Do(func() { close(r.stop) })
+	}
+	a.mu.RLock()
+	conns := a.conns
+	a.mu.RUnlock()
+	for _, conn := range conns {
+		conn.Close()
+	}
+	a.stopModuleTasks()
+	a.stopPollers()
+	a.persist.close()
+	a.publishEvent(Event{Type: EventStopped})
+	if a.config.OnStop != nil {
+		a.config.OnStop()
 	}
 	return nil
 }
@@ -142,24 +485,124 @@ func (a *Agent) Register(relativeOID string, oidType gosnmp.Asn1BER, handler Val
 
 // RegisterAbsolute 注册绝对路径 OID
 func (a *Agent) RegisterAbsolute(oid string, oidType gosnmp.Asn1BER, handler ValueHandler) error {
-	a.mu.Lock()
-	defer a.mu.Unlock()
+	return a.registerDynamic(oid, oidType, handler, nil)
+}
+
+// RegisterContext 注册相对 OID，处理函数接收请求的 context
+func (a *Agent) RegisterContext(relativeOID string, oidType gosnmp.Asn1BER, handler ContextHandler) error {
+	absoluteOID := fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID)
+	return a.RegisterContextAbsolute(absoluteOID, oidType, handler)
+}
 
-	if _, exists := a.handlers[oid]; exists {
-		a.logger.Warn("OID already registered, overwriting", "oid", oid)
+// RegisterContextAbsolute 注册绝对路径 OID，处理函数接收请求的 context
+//
+// 通过 SNMP 请求调用时 ctx 包含请求 ID（RequestIDFromContext）和 Tracer 创建的 snmp.handler span，
+// 处理函数可以据此记录日志或创建子 span；在导出、HTTP 接口等 SNMP 请求之外调用时 ctx 为 context.Background()。
+func (a *Agent) RegisterContextAbsolute(oid string, oidType gosnmp.Asn1BER, handler ContextHandler) error {
+	oid, err := normalizeOID(oid)
+	if err != nil {
+		return err
 	}
+	return a.updateStore(func(s *oidStore) error {
+		if err := s.checkLeaf(a.store.Load(), oid); err != nil {
+			return err
+		}
+		if _, exists := s.types[oid]; exists {
+			a.logger.Warn("OID already registered, overwriting", "oid", oid)
+		}
+		s.putDynamic(oid, oidType, func() (interface{}, error) { return handler(context.Background()) }, nil)
+		s.contexts[oid] = handler
+		a.logger.Info("Registered dynamic OID", "oid", oid, "type", oidType, "writable", false)
+		return nil
+	})
+}
 
-	a.handlers[oid] = handler
-	a.logger.Info("Registered dynamic OID", "oid", oid, "type", oidType)
+// RegisterWritable 注册可写的相对 OID
+func (a *Agent) RegisterWritable(relativeOID string, oidType gosnmp.Asn1BER, handler ValueHandler, setter SetHandler) error {
+	absoluteOID := fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID)
+	return a.RegisterWritableAbsolute(absoluteOID, oidType, handler, setter)
+}
 
-	// 如果服务器已启动，更新处理器
-	if a.server != nil {
-		a.registerHandlers()
+// RegisterWritableAbsolute 注册可写的绝对路径 OID
+func (a *Agent) RegisterWritableAbsolute(oid string, oidType gosnmp.Asn1BER, handler ValueHandler, setter SetHandler) error {
+	if setter == nil {
+		return fmt.Errorf("setter is required for writable OID: %s", oid)
 	}
+	return a.registerDynamic(oid, oidType, handler, setter)
+}
 
+// registerDynamic 注册动态 OID，setter 为 nil 时为只读
+func (a *Agent) registerDynamic(oid string, oidType gosnmp.Asn1BER, handler ValueHandler, setter SetHandler) error {
+	return a.registerDynamicOverride(oid, oidType, handler, setter, false, OIDMeta{})
+}
+
+// registerDynamicOverride 注册动态 OID，override 为 true 时跳过重叠检查并覆盖子树中的同名实例
+func (a *Agent) registerDynamicOverride(oid string, oidType gosnmp.Asn1BER, handler ValueHandler, setter SetHandler, override bool, meta OIDMeta) error {
+	oid, err := normalizeOID(oid)
+	if err != nil {
+		return err
+	}
+	err = a.updateStore(func(s *oidStore) error {
+		if override {
+			s.overrides[oid] = true
+		} else if err := s.checkLeaf(a.store.Load(), oid); err != nil {
+			return err
+		}
+		if _, exists := s.types[oid]; exists {
+			a.logger.Warn("OID already registered, overwriting", "oid", oid)
+		}
+		s.putDynamic(oid, oidType, handler, setter)
+		if meta != (OIDMeta{}) {
+			a.meta[oid] = meta
+		}
+		a.logger.Info("Registered dynamic OID", "oid", oid, "name", a.metaNameLocked(oid), "type", oidType, "writable", setter != nil)
+		return nil
+	})
+	if err != nil || setter == nil {
+		return err
+	}
+	a.restoreWritable(oid, oidType, setter)
 	return nil
 }
 
+// restoreWritable 通过 setter 恢复上次运行时 SET 的值，setter 可能注册 OID，不能在 updateStore 中调用
+func (a *Agent) restoreWritable(oid string, oidType gosnmp.Asn1BER, setter SetHandler) {
+	if value, ok := a.persist.registerWritable(oid, oidType); ok {
+		if err := setter(value); err != nil {
+			a.logger.Warn("Failed to restore persisted value", "oid", oid, "error", err)
+		} else {
+			a.logger.Info("Restored persisted value", "oid", oid, "value", value)
+		}
+	}
+}
+
+// dynamicOID 批量注册时的动态 OID 定义
+type dynamicOID struct {
+	Type    gosnmp.Asn1BER
+	Handler ValueHandler
+	Setter  SetHandler
+}
+
+// replaceDynamic 批量注销 remove 中的 OID 并注册 add 中的 OID，只重建一次处理器列表
+func (a *Agent) replaceDynamic(remove []string, add map[string]dynamicOID) {
+	a.updateStore(func(s *oidStore) error {
+		// 通过 Override 注册的 OID 优先于子树中的同名实例
+		for _, oid := range remove {
+			if !s.overrides[oid] {
+				s.remove(oid)
+				a.forgetCoalesced(oid)
+			}
+		}
+		for oid, def := range add {
+			if !s.overrides[oid] {
+				s.putDynamic(oid, def.Type, def.Handler, def.Setter)
+			}
+		}
+		a.logger.Debug("Replaced dynamic OIDs", "removed", len(remove), "added", len(add))
+		return nil
+	})
+}
+
 // RegisterStatic 注册静态值
 func (a *Agent) RegisterStatic(relativeOID string, oidType gosnmp.Asn1BER, value interface{}) error {
 	absoluteOID := fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID)
@@ -168,22 +611,22 @@ func (a *Agent) RegisterStatic(relativeOID string, oidType gosnmp.Asn1BER, value
 
 // RegisterStaticAbsolute 注册绝对路径静态值
 func (a *Agent) RegisterStaticAbsolute(oid string, oidType gosnmp.Asn1BER, value interface{}) error {
-	a.mu.Lock()
-	defer a.mu.Unlock()
-
-	if _, exists := a.staticVals[oid]; exists {
-		a.logger.Warn("Static OID already registered, overwriting", "oid", oid)
+	oid, err := normalizeOID(oid)
+	if err != nil {
+		return err
 	}
-
-	a.staticVals[oid] = value
-	a.logger.Info("Registered static OID", "oid", oid, "type", oidType, "value", value)
-
-	// 如果服务器已启动，更新处理器
-	if a.server != nil {
-		a.registerHandlers()
-	}
-
-	return nil
+	return a.updateStore(func(s *oidStore) error {
+		if err := s.checkLeaf(a.store.Load(), oid); err != nil {
+			return err
+		}
+		if _, exists := s.types[oid]; exists {
+			a.logger.Warn("Static OID already registered, overwriting", "oid", oid)
+		}
+		value := a.persist.registerStatic(oid, oidType, value)
+		s.putStatic(oid, oidType, value)
+		a.logger.Info("Registered static OID", "oid", oid, "type", oidType, "value", value)
+		return nil
+	})
 }
 
 // Unregister 注销 OID
@@ -194,92 +637,134 @@ func (a *Agent) Unregister(relativeOID string) error {
 
 // UnregisterAbsolute 注销绝对路径 OID
 func (a *Agent) UnregisterAbsolute(oid string) error {
-	a.mu.Lock()
-	defer a.mu.Unlock()
-
-	deletedHandler := false
-	deletedStatic := false
-
-	if _, exists := a.handlers[oid]; exists {
-		delete(a.handlers, oid)
-		deletedHandler = true
-	}
-
-	if _, exists := a.staticVals[oid]; exists {
-		delete(a.staticVals, oid)
-		deletedStatic = true
-	}
-
-	if !deletedHandler && !deletedStatic {
-		a.logger.Warn("OID not found for unregistration", "oid", oid)
-		return fmt.Errorf("OID not found: %s", oid)
-	}
-
-	a.logger.Info("Unregistered OID", "oid", oid)
-
-	// 如果服务器已启动，更新处理器
-	if a.server != nil {
-		a.registerHandlers()
-	}
-
-	return nil
+	oid = strings.TrimPrefix(oid, ".")
+	return a.updateStore(func(s *oidStore) error {
+		if !s.remove(oid) {
+			a.logger.Warn("OID not found for unregistration", "oid", oid)
+			return fmt.Errorf("OID not found: %s", oid)
+		}
+		a.persist.forget(oid)
+		a.forgetCoalesced(oid)
+		a.logger.Info("Unregistered OID", "oid", oid)
+		return nil
+	})
 }
 
-// registerHandlers 将所有注册的 OID 注册到 SNMP 服务器
-func (a *Agent) registerHandlers() {
-	a.mu.RLock()
-	defer a.mu.RUnlock()
-
-	if a.server == nil || len(a.server.SubAgents) == 0 {
+// registerWorkerHandlers 按快照 s 为一个 worker 重建 OID 列表，处理函数从 w 获取请求上下文
+//
+// 新列表由 worker 在处理下一个请求前换入，正在处理的请求继续使用旧列表。
+func (a *Agent) registerWorkerHandlers(w *worker, s *oidStore) {
+	if len(w.server.SubAgents) == 0 {
 		return
 	}
 
-	subAgent := a.server.SubAgents[0]
-	subAgent.OIDs = []*GoSNMPServer.PDUValueControlItem{}
+	items := make([]*GoSNMPServer.PDUValueControlItem, 0, len(s.types))
 
 	// 注册动态处理器
-	for oid, handler := range a.handlers {
+	for oid, handler := range s.handlers {
 		oidCopy := oid
-		handlerCopy := handler
+		typeCopy := s.types[oid]
+		tc := s.tcs[oid]
+		final := func(*RequestInfo) (interface{}, error) {
+			return handler()
+		}
+		if contextHandler, ok := s.contexts[oid]; ok {
+			final = func(req *RequestInfo) (interface{}, error) {
+				return contextHandler(req.Context)
+			}
+		}
+		call := final
+		final = func(req *RequestInfo) (interface{}, error) {
+			return a.coalesce(oidCopy, req, call)
+		}
 
 		pduItem := &GoSNMPServer.PDUValueControlItem{
 			OID:  oidCopy,
-			Type: gosnmp.OctetString,
+			Type: typeCopy,
 			OnGet: func() (interface{}, error) {
-				a.logger.Debug("GET request", "oid", oidCopy)
-				value, err := handlerCopy()
+				if debugEnabled(a.logger) {
+					logSampled(a.logLimits.get, a.logger.Debug, "GET request", "request", w.current.id, "oid", oidCopy)
+				}
+				start := time.Now()
+				value, err := a.guardRequest(w, oidCopy, func() (interface{}, error) {
+					return a.resolve(w, oidCopy, nil, final)
+				})
+				if errors.Is(err, ErrNoSuchInstance) {
+					// 表格中的空洞不算处理函数错误
+					a.stats.observeHandler(oidCopy, time.Since(start), nil)
+					return nil, err
+				}
+				a.stats.observeHandler(oidCopy, time.Since(start), err)
+				if err != nil {
+					a.logger.Error("Handler error", "request", w.current.id, "oid", oidCopy, "name", a.metaName(oidCopy), "error", err)
+					return nil, err
+				}
+				value, err = normalizeValue(typeCopy, value)
 				if err != nil {
-					a.logger.Error("Handler error", "oid", oidCopy, "error", err)
+					a.logger.Error("Invalid handler value", "request", w.current.id, "oid", oidCopy, "name", a.metaName(oidCopy), "error", err)
 					return nil, err
 				}
-				a.logger.Debug("GET response", "oid", oidCopy, "value", value)
+				a.stats.oids.remember(oidCopy, value)
+				if debugEnabled(a.logger) {
+					logSampled(a.logLimits.get, a.logger.Debug, "GET response", "request", w.current.id, "oid", oidCopy, "value", displayValue(tc, value))
+				}
 				return value, nil
 			},
 		}
 
-		subAgent.OIDs = append(subAgent.OIDs, pduItem)
+		if target := s.setTarget(oidCopy); target != nil {
+			pduItem.OnSet = func(value interface{}) error {
+				value, err := a.testSet(w, target, value)
+				if err != nil {
+					return err
+				}
+				step := a.setStep(w, target, value, 0, false)
+				if err := step.commit(); err != nil {
+					return err
+				}
+				a.persist.sync()
+				a.publishChanges(w, step.changes())
+				return nil
+			}
+		}
+
+		items = append(items, pduItem)
 	}
 
 	// 注册静态值
-	for oid, value := range a.staticVals {
+	for oid, value := range s.staticVals {
 		oidCopy := oid
 		valueCopy := value
+		typeCopy := s.types[oid]
+		tc := s.tcs[oid]
 
 		pduItem := &GoSNMPServer.PDUValueControlItem{
 			OID:  oidCopy,
-			Type: gosnmp.OctetString, // 使用类型转换
+			Type: typeCopy,
 			OnGet: func() (interface{}, error) {
-				a.logger.Debug("GET request (static)", "oid", oidCopy, "value", valueCopy)
-				return valueCopy, nil
+				if debugEnabled(a.logger) {
+					logSampled(a.logLimits.get, a.logger.Debug, "GET request (static)", "request", w.current.id, "oid", oidCopy, "value", displayValue(tc, valueCopy))
+				}
+				value, err := a.guardRequest(w, oidCopy, func() (interface{}, error) {
+					return a.resolve(w, oidCopy, nil, func(*RequestInfo) (interface{}, error) {
+						return valueCopy, nil
+					})
+				})
+				if err != nil {
+					return nil, err
+				}
+				return normalizeValue(typeCopy, value)
 			},
 		}
 
-		subAgent.OIDs = append(subAgent.OIDs, pduItem)
+		items = append(items, pduItem)
 	}
 
-	a.logger.Debug("Handlers registered",
-		"dynamic", len(a.handlers),
-		"static", len(a.staticVals))
+	// 排序 OID，GETNEXT/WALK 依赖有序列表
+	if err := sortOIDs(w.server.SubAgents[0], items); err != nil {
+		a.logger.Error("Failed to sync OIDs", "error", err)
+	}
+	w.oids.Store(s.oidViews(items, a.access.Load().subAgents))
 }
 
 // GetPrefix 获取企业 OID 前缀
@@ -287,20 +772,85 @@ func (a *Agent) GetPrefix() string {
 	return a.oidPrefix
 }
 
-// ListOIDs 列出所有已注册的 OID
-func (a *Agent) ListOIDs() map[string]string {
+// OIDKind OID 的注册方式
+type OIDKind string
+
+const (
+	OIDDynamic OIDKind = "dynamic" // 由处理函数提供值，包括表格、代理等子树中的实例
+	OIDStatic  OIDKind = "static"  // 静态值
+)
+
+// OIDInfo ListOIDs 返回的一个 OID
+type OIDInfo struct {
+	OID         string
+	Kind        OIDKind
+	Type        gosnmp.Asn1BER
+	Writable    bool
+	Name        string      // Annotate 设置的名称，表格等子树中的实例为子树的名称
+	Description string      // Annotate 设置的说明
+	Units       string      // Annotate 设置的单位
+	LastValue   interface{} // 静态 OID 为其值；动态 OID 为最近一次 GET 返回或 SET 写入的值，未访问过时为 nil
+	LastAccess  time.Time   // 最近一次调用处理函数的时间，静态 OID 和未访问过的 OID 为零值
+	Calls       uint64      // 处理函数调用次数（GET 和 SET）
+	Errors      uint64      // 处理函数返回错误的次数
+}
+
+// ListOIDs 按 OID 顺序列出已注册的 OID，指定 prefixes 时只列出这些子树中的 OID
+//
+// 动态 OID 不调用处理函数，LastValue 等来自请求处理时的记录。
+func (a *Agent) ListOIDs(prefixes ...string) []OIDInfo {
+	roots := make([]string, len(prefixes))
+	for i, p := range prefixes {
+		roots[i] = strings.Trim(p, ".")
+	}
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 
-	result := make(map[string]string)
+	s := a.store.Load()
+	result := make([]OIDInfo, 0, len(s.types))
+	for oid := range s.types {
+		if len(roots) > 0 && !slices.ContainsFunc(roots, func(root string) bool { return hasOIDPrefix(oid, root) }) {
+			continue
+		}
+		result = append(result, a.oidInfoLocked(s, oid))
+	}
+	slices.SortFunc(result, func(x, y OIDInfo) int { return compareOID(x.OID, y.OID) })
+	return result
+}
 
-	for oid := range a.handlers {
-		result[oid] = "dynamic"
+// Describe 返回已注册的绝对路径 OID 的信息，格式与 ListOIDs 相同
+func (a *Agent) Describe(oid string) (OIDInfo, error) {
+	oid, err := normalizeOID(oid)
+	if err != nil {
+		return OIDInfo{}, err
 	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
 
-	for oid := range a.staticVals {
-		result[oid] = "static"
+	s := a.store.Load()
+	if _, ok := s.types[oid]; !ok {
+		return OIDInfo{}, fmt.Errorf("OID not registered: %s", oid)
 	}
+	return a.oidInfoLocked(s, oid), nil
+}
 
-	return result
+// oidInfoLocked 生成已注册 oid 的 OIDInfo，调用方需持有 a.mu
+func (a *Agent) oidInfoLocked(s *oidStore, oid string) OIDInfo {
+	meta := a.meta[a.metaOwnerLocked(oid)]
+	_, writable := s.setters[oid]
+	info := OIDInfo{
+		OID:         oid,
+		Kind:        OIDDynamic,
+		Type:        s.types[oid],
+		Writable:    writable,
+		Name:        meta.Name,
+		Description: meta.Description,
+		Units:       meta.Units,
+	}
+	if value, ok := s.staticVals[oid]; ok {
+		info.Kind, info.LastValue = OIDStatic, value
+	} else {
+		info.Calls, info.Errors, info.LastAccess, info.LastValue, _ = a.stats.oids.last(oid)
+	}
+	return info
 }
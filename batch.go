@@ -0,0 +1,82 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RegisterBatch 批量注册相对 OID，entries 中的 OID 相对于企业前缀
+//
+// 所有条目先校验，任何一项无效、批内重复或与已注册的 OID 冲突（含 *OverlapError）时返回错误且不注册任何 OID；
+// 校验通过后一次更新注册表，Agent 运行时只重建一次处理器列表。
+func (a *Agent) RegisterBatch(entries []OIDEntry) error {
+	absolute := make([]OIDEntry, len(entries))
+	for i, e := range entries {
+		e.OID = fmt.Sprintf("%s.%s", a.oidPrefix, strings.TrimPrefix(e.OID, "."))
+		absolute[i] = e
+	}
+	return a.RegisterBatchAbsolute(absolute)
+}
+
+// RegisterBatchAbsolute 批量注册绝对路径 OID
+func (a *Agent) RegisterBatchAbsolute(entries []OIDEntry) error {
+	batch := make(map[string]OIDEntry, len(entries))
+	for i, e := range entries {
+		oid, err := normalizeOID(e.OID)
+		if err != nil {
+			return fmt.Errorf("entry %d: %w", i, err)
+		}
+		if _, dup := batch[oid]; dup {
+			return fmt.Errorf("entry %d: duplicate OID in batch: %s", i, oid)
+		}
+
+		switch {
+		case e.Handler == nil && e.Static == nil:
+			return fmt.Errorf("entry %d: OID %s needs a handler or a static value", i, oid)
+		case e.Handler != nil && e.Static != nil:
+			return fmt.Errorf("entry %d: OID %s has both a handler and a static value", i, oid)
+		case e.Static != nil:
+			if _, err := normalizeValue(e.Type, e.Static); err != nil {
+				return fmt.Errorf("entry %d: OID %s: %w", i, oid, err)
+			}
+		}
+		batch[oid] = e
+	}
+
+	// 排序后批内互相重叠的 OID 必然相邻
+	sorted := make([]string, 0, len(batch))
+	for oid := range batch {
+		sorted = append(sorted, oid)
+	}
+	sort.Strings(sorted)
+	for i := 1; i < len(sorted); i++ {
+		if hasOIDPrefix(sorted[i], sorted[i-1]) {
+			return &OverlapError{OID: sorted[i], Existing: sorted[i-1]}
+		}
+	}
+
+	return a.updateStore(func(s *oidStore) error {
+		base := a.store.Load()
+		for _, oid := range sorted {
+			if _, exists := s.types[oid]; exists {
+				return fmt.Errorf("OID already registered: %s", oid)
+			}
+			if err := s.checkLeaf(base, oid); err != nil {
+				return err
+			}
+		}
+
+		dynamic := 0
+		for oid, e := range batch {
+			if e.Handler != nil {
+				s.putDynamic(oid, e.Type, e.Handler, nil)
+				dynamic++
+			} else {
+				s.putStatic(oid, e.Type, a.persist.registerStatic(oid, e.Type, e.Static))
+			}
+		}
+		a.logger.Info("Registered OID batch", "dynamic", dynamic, "static", len(batch)-dynamic)
+		return nil
+	})
+}
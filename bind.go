@@ -0,0 +1,362 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// Refresher 绑定的结构体可实现此接口，每次 GET 前调用以刷新字段值
+type Refresher interface {
+	Refresh() error
+}
+
+// boundField 结构体标签解析结果
+type boundField struct {
+	relativeOID string
+	name        string
+	description string
+	oidType     gosnmp.Asn1BER
+	writable    bool
+	field       reflect.Value
+	atomic      bool
+}
+
+var ipType = reflect.TypeOf(net.IP{})
+
+// Bind 通过结构体标签注册 OID
+//
+// 标签格式为 `snmp:"<相对 OID>[,<类型>][,rw]"`，例如 `snmp:"1.2.0,gauge32"`。
+// 类型省略时根据字段类型推断；rw 表示允许 SET 修改字段。
+// bool 字段按 TruthValue 编码（true=1, false=2）。
+// 字段名和 snmpdesc 标签作为 ExportDocs 中的名称和说明。
+// 若结构体实现 sync.Locker（如嵌入 sync.Mutex），读写字段时会加锁；
+// 若实现 Refresher，每次 GET 前会调用 Refresh。
+// sync/atomic 类型的字段（如 atomic.Int64）通过 Load/Store 访问，无需加锁。
+//
+// 与 RegisterBatch 相同，所有字段先校验，任何一个字段的标签无效、OID 重复或与已注册的 OID 冲突时
+// 返回错误且不注册任何字段；已注册的同名 OID 被覆盖。
+func (a *Agent) Bind(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind target must be a non-nil pointer to struct, got %T", v)
+	}
+
+	locker, _ := v.(sync.Locker)
+	refresher, _ := v.(Refresher)
+
+	fields, err := parseBoundFields(rv.Elem())
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("no snmp tagged fields in %T", v)
+	}
+
+	typeName := rv.Elem().Type().Name()
+	oids := make(map[string]boundField, len(fields))
+	for _, f := range fields {
+		oid, err := normalizeOID(fmt.Sprintf("%s.%s", a.oidPrefix, f.relativeOID))
+		if err != nil {
+			return fmt.Errorf("field %s.%s: %w", typeName, f.name, err)
+		}
+		if other, dup := oids[oid]; dup {
+			return fmt.Errorf("fields %s.%s and %s.%s have the same OID: %s", typeName, other.name, typeName, f.name, oid)
+		}
+		oids[oid] = f
+	}
+	// 排序后互相重叠的 OID 必然相邻
+	sorted := make([]string, 0, len(oids))
+	for oid := range oids {
+		sorted = append(sorted, oid)
+	}
+	sort.Strings(sorted)
+	for i := 1; i < len(sorted); i++ {
+		if hasOIDPrefix(sorted[i], sorted[i-1]) {
+			return &OverlapError{OID: sorted[i], Existing: sorted[i-1]}
+		}
+	}
+
+	setters := make(map[string]SetHandler)
+	err = a.updateStore(func(s *oidStore) error {
+		base := a.store.Load()
+		for _, oid := range sorted {
+			if err := s.checkLeaf(base, oid); err != nil {
+				return err
+			}
+		}
+		for _, oid := range sorted {
+			f := oids[oid]
+			fieldLocker := locker
+			if f.atomic {
+				fieldLocker = nil
+			}
+			var setter SetHandler
+			if f.writable {
+				setter = bindSetter(f, fieldLocker)
+				setters[oid] = setter
+			}
+			if _, exists := s.types[oid]; exists {
+				a.logger.Warn("OID already registered, overwriting", "oid", oid)
+			}
+			s.putDynamic(oid, f.oidType, bindGetter(f, fieldLocker, refresher), setter)
+			a.meta[oid] = OIDMeta{Name: f.name, Description: f.description}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, oid := range sorted {
+		if setter, ok := setters[oid]; ok {
+			a.restoreWritable(oid, oids[oid].oidType, setter)
+		}
+	}
+
+	a.logger.Info("Bound struct", "type", rv.Elem().Type().String(), "fields", len(fields))
+	return nil
+}
+
+// parseBoundFields 解析结构体中带 snmp 标签的字段
+func parseBoundFields(sv reflect.Value) ([]boundField, error) {
+	st := sv.Type()
+	var fields []boundField
+
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		tag, ok := sf.Tag.Lookup("snmp")
+		if !ok || tag == "" || tag == "-" {
+			continue
+		}
+		if !sf.IsExported() {
+			return nil, fmt.Errorf("field %s.%s: snmp tag on unexported field", st.Name(), sf.Name)
+		}
+
+		parts := strings.Split(tag, ",")
+		f := boundField{
+			relativeOID: strings.TrimSpace(parts[0]),
+			name:        sf.Name,
+			description: sf.Tag.Get("snmpdesc"),
+			field:       sv.Field(i),
+		}
+		if f.relativeOID == "" {
+			return nil, fmt.Errorf("field %s.%s: empty OID in snmp tag", st.Name(), sf.Name)
+		}
+
+		valueType := sf.Type
+		if loadType, ok := atomicLoadType(f.field); ok {
+			f.atomic = true
+			valueType = loadType
+		}
+
+		typeSet := false
+		for _, opt := range parts[1:] {
+			opt = strings.TrimSpace(opt)
+			switch opt {
+			case "":
+			case "rw":
+				f.writable = true
+			case "ro":
+				f.writable = false
+			default:
+				t, err := ParseType(opt)
+				if err != nil {
+					return nil, fmt.Errorf("field %s.%s: %w", st.Name(), sf.Name, err)
+				}
+				f.oidType = t
+				typeSet = true
+			}
+		}
+
+		if !typeSet {
+			t, err := inferType(valueType)
+			if err != nil {
+				return nil, fmt.Errorf("field %s.%s: %w", st.Name(), sf.Name, err)
+			}
+			f.oidType = t
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+// atomicLoadType 判断字段是否为 sync/atomic 类型，返回 Load 的返回类型
+func atomicLoadType(fv reflect.Value) (reflect.Type, bool) {
+	if fv.Type().PkgPath() != "sync/atomic" {
+		return nil, false
+	}
+	load := fv.Addr().MethodByName("Load")
+	if !load.IsValid() || load.Type().NumIn() != 0 || load.Type().NumOut() != 1 {
+		return nil, false
+	}
+	return load.Type().Out(0), true
+}
+
+// inferType 根据 Go 类型推断 SNMP 类型
+func inferType(t reflect.Type) (gosnmp.Asn1BER, error) {
+	if t == ipType {
+		return gosnmp.IPAddress, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return gosnmp.Integer, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return gosnmp.Gauge32, nil
+	case reflect.Uint64:
+		return gosnmp.Counter64, nil
+	case reflect.Float32:
+		return gosnmp.OpaqueFloat, nil
+	case reflect.Float64:
+		return gosnmp.OpaqueDouble, nil
+	case reflect.String:
+		return gosnmp.OctetString, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return gosnmp.OctetString, nil
+		}
+	}
+
+	return 0, fmt.Errorf("cannot infer SNMP type for %s", t)
+}
+
+func bindGetter(f boundField, locker sync.Locker, refresher Refresher) ValueHandler {
+	return func() (interface{}, error) {
+		if refresher != nil {
+			if err := refresher.Refresh(); err != nil {
+				return nil, err
+			}
+		}
+
+		var value interface{}
+		if f.atomic {
+			value = f.field.Addr().MethodByName("Load").Call(nil)[0].Interface()
+		} else {
+			if locker != nil {
+				locker.Lock()
+			}
+			value = f.field.Interface()
+			if locker != nil {
+				locker.Unlock()
+			}
+		}
+
+		switch v := value.(type) {
+		case net.IP:
+			return v.String(), nil
+		case bool:
+			return TruthValue(v), nil
+		}
+		return value, nil
+	}
+}
+
+func bindSetter(f boundField, locker sync.Locker) SetHandler {
+	return func(value interface{}) error {
+		if f.atomic {
+			store := f.field.Addr().MethodByName("Store")
+			converted, err := convertToType(value, store.Type().In(0))
+			if err != nil {
+				return err
+			}
+			store.Call([]reflect.Value{converted})
+			return nil
+		}
+
+		converted, err := convertToType(value, f.field.Type())
+		if err != nil {
+			return err
+		}
+
+		if locker != nil {
+			locker.Lock()
+			defer locker.Unlock()
+		}
+		f.field.Set(converted)
+		return nil
+	}
+}
+
+// convertToType 将 SET 请求中的值转换为字段类型
+func convertToType(value interface{}, t reflect.Type) (reflect.Value, error) {
+	if value == nil {
+		return reflect.Value{}, fmt.Errorf("nil value for %s", t)
+	}
+
+	if t == ipType {
+		var s string
+		switch v := value.(type) {
+		case string:
+			s = v
+		case []byte:
+			s = string(v)
+		}
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return reflect.Value{}, fmt.Errorf("invalid IP address: %v", value)
+		}
+		return reflect.ValueOf(ip), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		b, err := ParseTruthValue(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(b).Convert(t), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := toInt64(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		rv := reflect.New(t).Elem()
+		if rv.OverflowInt(n) {
+			return reflect.Value{}, fmt.Errorf("value %d overflows %s", n, t)
+		}
+		rv.SetInt(n)
+		return rv, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := toUint64(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		rv := reflect.New(t).Elem()
+		if rv.OverflowUint(n) {
+			return reflect.Value{}, fmt.Errorf("value %d overflows %s", n, t)
+		}
+		rv.SetUint(n)
+		return rv, nil
+	case reflect.Float32, reflect.Float64:
+		f, err := toFloat64(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(f).Convert(t), nil
+	case reflect.String:
+		switch v := value.(type) {
+		case string:
+			return reflect.ValueOf(v).Convert(t), nil
+		case []byte:
+			return reflect.ValueOf(string(v)).Convert(t), nil
+		}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			switch v := value.(type) {
+			case string:
+				return reflect.ValueOf([]byte(v)).Convert(t), nil
+			case []byte:
+				return reflect.ValueOf(append([]byte(nil), v...)).Convert(t), nil
+			}
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("cannot convert %T to %s", value, t)
+}
@@ -0,0 +1,141 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// Bits SMIv2 BITS 类型，键为位编号，值为 MIB 中的名称
+//
+// BITS 编码为 OctetString：位 0 为第一个字节的最高位，位 8 为第二个字节的最高位，依此类推。
+// 编码长度由定义的最大位编号决定，解码时接受更长或更短的字节串，缺少的位视为 0。
+type Bits map[int]string
+
+// size 编码所需的字节数
+func (b Bits) size() int {
+	maxBit := -1
+	for n := range b {
+		maxBit = max(maxBit, n)
+	}
+	return maxBit/8 + 1
+}
+
+// Encode 将置位的位编号编码为字节串，位编号未定义时返回错误
+func (b Bits) Encode(bits ...int) ([]byte, error) {
+	octets := make([]byte, b.size())
+	for _, n := range bits {
+		if _, ok := b[n]; !ok {
+			return nil, fmt.Errorf("undefined bit: %d", n)
+		}
+		octets[n/8] |= 0x80 >> (n % 8)
+	}
+	return octets, nil
+}
+
+// Parse 将以空白或逗号分隔的位名称编码为字节串，接受 "name"、"name(n)" 和 "n" 三种形式
+func (b Bits) Parse(text string) ([]byte, error) {
+	var bits []int
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		n, err := Enum(b).Parse(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bit: %s", field)
+		}
+		bits = append(bits, n)
+	}
+	return b.Encode(bits...)
+}
+
+// Validate 检查值是否为 OctetString 且只设置了定义的位
+func (b Bits) Validate(value interface{}) error {
+	bits, err := DecodeBits(value)
+	if err != nil {
+		return err
+	}
+	for _, n := range bits {
+		if _, ok := b[n]; !ok {
+			return fmt.Errorf("undefined bit: %d", n)
+		}
+	}
+	return nil
+}
+
+// Format 按 net-snmp 的格式显示置位的位，如 "linkUp(0) linkDown(2)"，未定义的位只显示编号
+func (b Bits) Format(value interface{}) string {
+	bits, err := DecodeBits(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	names := make([]string, len(bits))
+	for i, n := range bits {
+		names[i] = Enum(b).Format(n)
+	}
+	return strings.Join(names, " ")
+}
+
+// DecodeBits 解码 BITS 值，返回按升序排列的置位位编号
+func DecodeBits(value interface{}) ([]int, error) {
+	data, err := octets(value)
+	if err != nil {
+		return nil, err
+	}
+	var bits []int
+	for i, c := range data {
+		for j := 0; j < 8; j++ {
+			if c&(0x80>>j) != 0 {
+				bits = append(bits, i*8+j)
+			}
+		}
+	}
+	return bits, nil
+}
+
+// BitsHandler 返回 BITS OID 当前置位的位编号
+type BitsHandler func() ([]int, error)
+
+// BitsSetter 处理 BITS OID 的 SET 请求，bits 为按升序排列的置位位编号
+type BitsSetter func(bits []int) error
+
+// RegisterBits 注册 BITS 类型的相对 OID，见 RegisterBitsAbsolute
+func (a *Agent) RegisterBits(relativeOID string, bits Bits, handler BitsHandler, setter BitsSetter) error {
+	absoluteOID := fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID)
+	return a.RegisterBitsAbsolute(absoluteOID, bits, handler, setter)
+}
+
+// RegisterBitsAbsolute 注册 BITS 类型的绝对路径 OID，setter 为 nil 时为只读
+//
+// handler 返回的位编号按 bits 编码为 OctetString；SET 的值解码为位编号后交给 setter，
+// 设置了未定义位的值被拒绝。OID 同时关联 bits 作为文本约定，日志中显示位名称。
+func (a *Agent) RegisterBitsAbsolute(oid string, bits Bits, handler BitsHandler, setter BitsSetter) error {
+	if len(bits) == 0 {
+		return fmt.Errorf("BITS OID %s has no named bits", oid)
+	}
+	for n := range bits {
+		if n < 0 {
+			return fmt.Errorf("BITS OID %s has a negative bit number: %d", oid, n)
+		}
+	}
+
+	get := func() (interface{}, error) {
+		set, err := handler()
+		if err != nil {
+			return nil, err
+		}
+		return bits.Encode(set...)
+	}
+	var set SetHandler
+	if setter != nil {
+		set = func(value interface{}) error {
+			if err := bits.Validate(value); err != nil {
+				return err
+			}
+			decoded, _ := DecodeBits(value)
+			return setter(decoded)
+		}
+	}
+	if err := a.registerDynamic(oid, gosnmp.OctetString, get, set); err != nil {
+		return err
+	}
+	return a.SetTextConventionAbsolute(oid, bits)
+}
@@ -0,0 +1,226 @@
+// Package boltstore 基于 bbolt 的 lzsnmp.TrapStore 和 lzsnmp.ValueStore，
+// 将 Receiver 接收到的通知和 Agent 持久化的值保存在本地文件中
+//
+// 单独成包，只使用内存、SQL 或 JSON 存储时不引入 bbolt 依赖。
+package boltstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	lzsnmp "github.com/liuzhen9320/snmp-go"
+	bolt "go.etcd.io/bbolt"
+)
+
+// bucketName 保存通知的 bucket
+var bucketName = []byte("traps")
+
+// valuesBucketName 保存持久化值的 bucket，键为 OID
+var valuesBucketName = []byte("values")
+
+// Store 保存在 bbolt 文件中的通知，键为接收时间（Unix 纳秒，大端）加序号，按时间有序
+type Store struct {
+	db *bolt.DB
+}
+
+var _ lzsnmp.TrapStore = (*Store)(nil)
+
+// Open 打开或创建 path 处的数据库文件，同一文件同时只能被一个进程打开
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open trap store %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(bucketName); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(valuesBucketName)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open trap store %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// timeKey 返回时间 t 对应的键前缀
+func timeKey(t time.Time) []byte {
+	key := make([]byte, 8, 16)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	return key
+}
+
+// Add 保存一条通知
+func (s *Store) Add(t *lzsnmp.ReceivedTrap) error {
+	data, err := t.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketName)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		return b.Put(binary.BigEndian.AppendUint64(timeKey(t.Time), seq), data)
+	})
+}
+
+// Query 返回符合条件的通知，按接收时间从新到旧排列
+func (s *Store) Query(q lzsnmp.TrapQuery) ([]*lzsnmp.ReceivedTrap, error) {
+	var result []*lzsnmp.ReceivedTrap
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketName).Cursor()
+
+		var k, v []byte
+		if q.Until.IsZero() {
+			k, v = c.Last()
+		} else {
+			// 定位到第一个不早于 Until 的键，再向前一条
+			if k, _ = c.Seek(timeKey(q.Until)); k == nil {
+				k, v = c.Last()
+			} else {
+				k, v = c.Prev()
+			}
+		}
+		var since []byte
+		if !q.Since.IsZero() {
+			since = timeKey(q.Since)
+		}
+
+		for ; k != nil; k, v = c.Prev() {
+			if since != nil && bytes.Compare(k[:8], since) < 0 {
+				break
+			}
+			t := &lzsnmp.ReceivedTrap{}
+			if err := t.UnmarshalJSON(v); err != nil {
+				return fmt.Errorf("trap %x: %w", k, err)
+			}
+			if !q.Match(t) {
+				continue
+			}
+			result = append(result, t)
+			if q.Limit > 0 && len(result) >= q.Limit {
+				break
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+// Prune 删除 before 之前接收的通知，maxCount 大于 0 时只保留最新的 maxCount 条
+func (s *Store) Prune(before time.Time, maxCount int) (int, error) {
+	removed := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketName)
+		excess := 0
+		if maxCount > 0 {
+			excess = max(b.Stats().KeyN-maxCount, 0)
+		}
+		var limit []byte
+		if !before.IsZero() {
+			limit = timeKey(before)
+		}
+
+		// 先收集再删除，遍历中删除会让游标跳过下一个键
+		var keys [][]byte
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			if len(keys) >= excess && (limit == nil || bytes.Compare(k[:8], limit) >= 0) {
+				break
+			}
+			keys = append(keys, bytes.Clone(k))
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		removed = len(keys)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// Close 关闭数据库文件
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Values 返回保存在同一文件中的 lzsnmp.ValueStore，用于 Config.Persist，随 Store.Close 关闭
+func (s *Store) Values() lzsnmp.ValueStore {
+	return valueStore{db: s.db}
+}
+
+// valueStore 每个 OID 一个键，值为 SubtreeEntry 的 JSON
+type valueStore struct {
+	db *bolt.DB
+}
+
+func (s valueStore) Load() ([]lzsnmp.SubtreeEntry, error) {
+	var entries []lzsnmp.SubtreeEntry
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(valuesBucketName).ForEach(func(k, v []byte) error {
+			var e lzsnmp.SubtreeEntry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return fmt.Errorf("value %s: %w", k, err)
+			}
+			entries = append(entries, e)
+			return nil
+		})
+	})
+	return entries, err
+}
+
+func (s valueStore) Put(entries []lzsnmp.SubtreeEntry) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putValues(tx.Bucket(valuesBucketName), entries)
+	})
+}
+
+func (s valueStore) Delete(oids []string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(valuesBucketName)
+		for _, oid := range oids {
+			if err := b.Delete([]byte(oid)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s valueStore) Replace(entries []lzsnmp.SubtreeEntry) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(valuesBucketName); err != nil {
+			return err
+		}
+		b, err := tx.CreateBucket(valuesBucketName)
+		if err != nil {
+			return err
+		}
+		return putValues(b, entries)
+	})
+}
+
+func putValues(b *bolt.Bucket, entries []lzsnmp.SubtreeEntry) error {
+	for _, e := range entries {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		if err := b.Put([]byte(e.OID), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package lzsnmp
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 熔断器的默认参数
+const (
+	defaultBreakerFailures = 5
+	defaultBreakerCooldown = 30 * time.Second
+)
+
+var (
+	// ErrHandlerTimeout 处理函数超过 RegisterOpts.Timeout 未返回
+	ErrHandlerTimeout = errors.New("handler timeout")
+	// ErrCircuitOpen 熔断期间不调用处理函数，直接返回该错误
+	ErrCircuitOpen = errors.New("circuit breaker open")
+)
+
+// BreakerConfig 处理函数的熔断配置
+//
+// 连续 Failures 次返回错误或超时后熔断，Cooldown 内的请求不调用处理函数，直接返回 ErrCircuitOpen；
+// 冷却结束后放行一次请求试探，成功则恢复，失败则再熔断一个 Cooldown。
+type BreakerConfig struct {
+	Failures int           // 触发熔断的连续失败次数，默认 5
+	Cooldown time.Duration // 熔断持续时间，默认 30 秒
+
+	// OnStateChange 熔断（open 为 true，err 为最后一次错误）和恢复（open 为 false）时调用，用于告警
+	OnStateChange func(oid string, open bool, err error)
+}
+
+// circuitBreaker 一个 OID 的熔断器
+type circuitBreaker struct {
+	cfg     BreakerConfig
+	oid     string
+	handler ValueHandler
+	agent   *Agent
+
+	mu        sync.Mutex
+	failures  int
+	open      bool
+	openUntil time.Time
+	probing   bool
+	lastErr   error
+}
+
+// newCircuitBreaker 检查配置、补全默认值并创建熔断器
+func (a *Agent) newCircuitBreaker(oid string, cfg BreakerConfig, handler ValueHandler) (*circuitBreaker, error) {
+	if cfg.Failures < 0 || cfg.Cooldown < 0 {
+		return nil, fmt.Errorf("breaker Failures and Cooldown must not be negative for OID: %s", oid)
+	}
+	if cfg.Failures == 0 {
+		cfg.Failures = defaultBreakerFailures
+	}
+	if cfg.Cooldown == 0 {
+		cfg.Cooldown = defaultBreakerCooldown
+	}
+	return &circuitBreaker{cfg: cfg, oid: oid, handler: handler, agent: a}, nil
+}
+
+// get 熔断期间直接返回错误，否则调用处理函数并记录结果
+func (b *circuitBreaker) get() (interface{}, error) {
+	b.mu.Lock()
+	if b.open {
+		if b.probing || time.Now().Before(b.openUntil) {
+			err := b.lastErr
+			b.mu.Unlock()
+			return nil, fmt.Errorf("%w: %v", ErrCircuitOpen, err)
+		}
+		b.probing = true
+	}
+	b.mu.Unlock()
+
+	// panic 也计为失败，否则试探状态无法结束
+	value, err := b.agent.guard(b.oid, b.handler)
+
+	b.mu.Lock()
+	b.probing = false
+	// ErrNoSuchInstance（表格中的空洞）说明后端正常响应，不计为失败
+	if err == nil || errors.Is(err, ErrNoSuchInstance) {
+		recovered := b.open
+		b.failures, b.open = 0, false
+		b.mu.Unlock()
+		if recovered {
+			b.agent.logger.Info("Circuit breaker closed", "oid", b.oid)
+			b.notify(false, nil)
+		}
+		return value, err
+	}
+
+	b.failures++
+	opened := false
+	if b.open || b.failures >= b.cfg.Failures {
+		opened = !b.open
+		b.open, b.openUntil, b.lastErr = true, time.Now().Add(b.cfg.Cooldown), err
+	}
+	b.mu.Unlock()
+	if opened {
+		b.agent.logger.Warn("Circuit breaker opened", "oid", b.oid, "failures", b.cfg.Failures, "cooldown", b.cfg.Cooldown, "error", err)
+		b.notify(true, err)
+	}
+	return nil, err
+}
+
+func (b *circuitBreaker) notify(open bool, err error) {
+	if b.cfg.OnStateChange != nil {
+		b.cfg.OnStateChange(b.oid, open, err)
+	}
+}
+
+// withTimeout 在单独的协程中调用处理函数，超过 timeout 未返回时返回 ErrHandlerTimeout
+//
+// 超时的处理函数在后台继续运行直到返回，其结果被丢弃。
+func (a *Agent) withTimeout(oid string, timeout time.Duration, handler ValueHandler) ValueHandler {
+	type result struct {
+		value interface{}
+		err   error
+	}
+	return func() (interface{}, error) {
+		done := make(chan result, 1)
+		go func() {
+			value, err := a.guard(oid, handler)
+			done <- result{value, err}
+		}()
+
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case r := <-done:
+			return r.value, r.err
+		case <-timer.C:
+			a.logger.Warn("Handler timed out", "oid", oid, "timeout", timeout)
+			return nil, fmt.Errorf("%w after %s", ErrHandlerTimeout, timeout)
+		}
+	}
+}
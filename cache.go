@@ -0,0 +1,114 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// cachedValue 缓存的处理函数结果
+//
+// 第一次请求同步调用处理函数，调用进行中到达的请求等待并共享其结果；缓存过期后的请求先返回旧值并在后台刷新，
+// 同一时间最多只有一个刷新。后台刷新失败时丢弃缓存，下一次请求同步调用并返回错误。
+type cachedValue struct {
+	ttl        time.Duration
+	handler    ValueHandler
+	onError    func(err error)
+	mu         sync.Mutex
+	value      interface{}
+	fetched    time.Time
+	valid      bool
+	refreshing bool
+	inflight   chan struct{} // 缓存为空时正在进行的同步调用，完成时关闭
+	err        error         // 最近一次同步调用的错误，只返回给等待它的请求
+}
+
+func (c *cachedValue) get() (interface{}, error) {
+	c.mu.Lock()
+	if !c.valid {
+		return c.fetchLocked()
+	}
+	value := c.value
+	if time.Since(c.fetched) >= c.ttl && !c.refreshing {
+		c.refreshing = true
+		go c.refresh()
+	}
+	c.mu.Unlock()
+	return value, nil
+}
+
+// fetchLocked 同步调用处理函数，成功时更新缓存；已有调用进行中时等待它完成并共享结果
+//
+// 调用时持有 c.mu，返回前释放。处理函数经过 Agent.guard，panic 时返回错误，不会使等待的请求永远阻塞。
+func (c *cachedValue) fetchLocked() (interface{}, error) {
+	if ch := c.inflight; ch != nil {
+		c.mu.Unlock()
+		<-ch
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.err != nil {
+			return nil, c.err
+		}
+		return c.value, nil
+	}
+	ch := make(chan struct{})
+	c.inflight = ch
+	c.mu.Unlock()
+
+	value, err := c.handler()
+
+	c.mu.Lock()
+	c.err = err
+	if err == nil {
+		c.value, c.fetched, c.valid = value, time.Now(), true
+	}
+	c.inflight = nil
+	c.mu.Unlock()
+	close(ch)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// refresh 后台刷新缓存
+func (c *cachedValue) refresh() {
+	value, err := c.handler()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshing = false
+	if err != nil {
+		c.valid = false
+		c.onError(err)
+		return
+	}
+	c.value, c.fetched = value, time.Now()
+}
+
+// RegisterCached 注册带缓存的相对 OID，处理函数的结果在 ttl 内直接返回
+//
+// 适用于磁盘扫描、API 调用等较重的处理函数，避免每个 NMS 的每次轮询都调用一次。
+// 缓存为空时（首次请求或后台刷新失败后）同时到达的请求只调用一次处理函数，共享其结果或错误。
+func (a *Agent) RegisterCached(relativeOID string, oidType gosnmp.Asn1BER, ttl time.Duration, handler ValueHandler) error {
+	absoluteOID := fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID)
+	return a.RegisterCachedAbsolute(absoluteOID, oidType, ttl, handler)
+}
+
+// RegisterCachedAbsolute 注册带缓存的绝对路径 OID
+func (a *Agent) RegisterCachedAbsolute(oid string, oidType gosnmp.Asn1BER, ttl time.Duration, handler ValueHandler) error {
+	if ttl <= 0 {
+		return fmt.Errorf("cache TTL must be positive for OID: %s", oid)
+	}
+	c := &cachedValue{
+		ttl: ttl,
+		// 后台刷新不经过服务循环，panic 需要单独恢复
+		handler: func() (interface{}, error) { return a.guard(oid, handler) },
+		onError: func(err error) {
+			a.logger.Warn("Background cache refresh failed", "oid", oid, "error", err)
+		},
+	}
+	return a.registerDynamic(oid, oidType, c.get, nil)
+}
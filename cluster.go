@@ -0,0 +1,65 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// sysUpTimeOID SNMPv2-MIB sysUpTime.0
+const sysUpTimeOID = "1.3.6.1.2.1.1.3.0"
+
+// maxEngineIDData engineID 中管理员指定部分的最大长度，RFC 3411 限制 engineID 最长 32 字节，前缀占 5 字节
+const maxEngineIDData = 27
+
+// ClusterConfig 多个实例在 anycast 或 VIP 地址后共同应答时的协调参数
+//
+// SNMPv3 管理端按 engineID、engineBoots 和 engineTime 判断报文是否来自同一个引擎，
+// 请求落到不同实例时这三个值必须一致，否则会被当作重放或引擎重启而丢弃。
+// 所有实例使用相同的 ClusterConfig 即可：engineTime 和 sysUpTime 都从 Epoch 开始计算，
+// 与实例自身的启动时间无关。
+type ClusterConfig struct {
+	EngineID    string    // 所有实例共享的 engineID（管理员指定部分，1..27 字节）
+	EngineBoots uint32    // 所有实例共享的 engineBoots，默认 1；重新部署集群时递增
+	Epoch       time.Time // engineTime 与 sysUpTime 的共同起点，通常为集群首次部署的时间
+
+	// ShouldSendTrap 本实例向通知目标发送前调用，返回 false 时不发送（SubscribeNotifications 的订阅方仍会收到）。
+	// 为 nil 时每个实例都发送，管理端会收到每个实例的 coldStart 和每个通知的多份副本；
+	// 可以只由选举出的领导者发送，或以 Notification.DedupKey 在共享存储中去重
+	ShouldSendTrap func(n Notification) bool
+}
+
+// validate 检查集群配置并填充默认值
+func (c *ClusterConfig) validate() error {
+	if c.EngineID == "" || len(c.EngineID) > maxEngineIDData {
+		return fmt.Errorf("cluster EngineID must be 1 to %d bytes", maxEngineIDData)
+	}
+	if c.EngineBoots == 0 {
+		c.EngineBoots = 1
+	}
+	if c.Epoch.IsZero() {
+		return fmt.Errorf("cluster Epoch is required")
+	}
+	if c.Epoch.After(time.Now()) {
+		return fmt.Errorf("cluster Epoch %s is in the future", c.Epoch.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Uptime 返回 sysUpTime 使用的运行时长：配置了 Cluster 时从 Cluster.Epoch 开始，否则从 NewAgent 开始
+func (a *Agent) Uptime() time.Duration {
+	if a.config.Cluster != nil {
+		return time.Since(a.config.Cluster.Epoch)
+	}
+	return time.Since(a.createdAt)
+}
+
+// RegisterSysUpTime 注册 SNMPv2-MIB sysUpTime.0（TimeTicks，百分之一秒）
+//
+// 配置了 Cluster 时所有实例从同一基准计算，管理端在实例之间切换时不会误判为设备重启。
+func (a *Agent) RegisterSysUpTime() error {
+	return a.RegisterAbsolute(sysUpTimeOID, gosnmp.TimeTicks, func() (interface{}, error) {
+		return uint32(a.Uptime() / (10 * time.Millisecond)), nil
+	})
+}
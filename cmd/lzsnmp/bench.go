@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// benchResult 一个客户端的压测结果
+type benchResult struct {
+	walks     int
+	requests  int
+	varbinds  int
+	errors    int
+	latencies []time.Duration
+}
+
+// benchMain 以多个并发客户端反复 bulkwalk 一棵子树，报告吞吐量和请求延迟
+//
+//	lzsnmp bench -clients 64 -duration 30s 127.0.0.1:161 1.3.6.1.4.1.12345
+//
+// 每个客户端使用独立的 UDP socket（不同的源端口），Agent 开启 ReusePortSockets 时请求会分散到各 socket。
+// -allocs 不访问网络，在进程内统计每个请求的内存分配，见 benchAllocs。
+func benchMain(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: lzsnmp bench [flags] <host[:port]> [oid]")
+		fmt.Fprintln(fs.Output(), "       lzsnmp bench -allocs [-requests n] [-max-allocs n]")
+		fs.PrintDefaults()
+	}
+	var (
+		community      = fs.String("c", "public", "community")
+		clients        = fs.Int("clients", 32, "concurrent clients, each with its own socket")
+		duration       = fs.Duration("duration", 10*time.Second, "test duration")
+		maxRepetitions = fs.Uint("max-repetitions", 25, "GETBULK max-repetitions")
+		timeout        = fs.Duration("timeout", 2*time.Second, "per-request timeout")
+		allocs         = fs.Bool("allocs", false, "measure allocations per request against an in-process agent")
+		requests       = fs.Int("requests", 10000, "requests per type with -allocs")
+		maxAllocs      = fs.Float64("max-allocs", 0, "with -allocs, fail if any request type allocates more than this per request")
+	)
+	fs.Parse(args)
+	if *allocs {
+		if fs.NArg() != 0 || *requests <= 0 {
+			fs.Usage()
+			return 2
+		}
+		return benchAllocs(*requests, *maxAllocs)
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 || *clients <= 0 {
+		fs.Usage()
+		return 2
+	}
+	host, port, err := splitTarget(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "bench:", err)
+		return 2
+	}
+	root := "1.3.6.1"
+	if fs.NArg() == 2 {
+		root = strings.TrimPrefix(fs.Arg(1), ".")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *duration)
+	defer cancel()
+
+	results := make([]benchResult, *clients)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := range results {
+		g := &gosnmp.GoSNMP{
+			Target:         host,
+			Port:           port,
+			Community:      *community,
+			Version:        gosnmp.Version2c,
+			Timeout:        *timeout,
+			MaxRepetitions: uint32(*maxRepetitions),
+		}
+		if err := g.Connect(); err != nil {
+			fmt.Fprintln(os.Stderr, "bench:", err)
+			return 1
+		}
+		defer g.Conn.Close()
+		wg.Add(1)
+		go func(r *benchResult) {
+			defer wg.Done()
+			benchClient(ctx, g, root, r)
+		}(&results[i])
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	var total benchResult
+	for _, r := range results {
+		total.walks += r.walks
+		total.requests += r.requests
+		total.varbinds += r.varbinds
+		total.errors += r.errors
+		total.latencies = append(total.latencies, r.latencies...)
+	}
+	seconds := elapsed.Seconds()
+	fmt.Printf("target      %s:%d %s\n", host, port, root)
+	fmt.Printf("clients     %d\n", *clients)
+	fmt.Printf("duration    %s\n", elapsed.Round(time.Millisecond))
+	fmt.Printf("walks       %d (%.1f/s)\n", total.walks, float64(total.walks)/seconds)
+	fmt.Printf("requests    %d (%.1f/s)\n", total.requests, float64(total.requests)/seconds)
+	fmt.Printf("varbinds    %d (%.1f/s)\n", total.varbinds, float64(total.varbinds)/seconds)
+	fmt.Printf("errors      %d\n", total.errors)
+	if len(total.latencies) > 0 {
+		slices.Sort(total.latencies)
+		q := func(p float64) time.Duration {
+			return total.latencies[int(p*float64(len(total.latencies)-1)+0.5)].Round(10 * time.Microsecond)
+		}
+		fmt.Printf("latency     p50 %s  p90 %s  p99 %s  max %s\n", q(0.5), q(0.9), q(0.99), q(1))
+	}
+	if total.requests == 0 {
+		return 1
+	}
+	return 0
+}
+
+// benchClient 反复 bulkwalk root 直到 ctx 结束
+func benchClient(ctx context.Context, g *gosnmp.GoSNMP, root string, r *benchResult) {
+	prefix := "." + root + "."
+	next := "." + root
+	for ctx.Err() == nil {
+		sent := time.Now()
+		resp, err := g.GetBulk([]string{next}, 0, g.MaxRepetitions)
+		if ctx.Err() != nil {
+			// 结束时被打断的请求不计入
+			return
+		}
+		r.requests++
+		r.latencies = append(r.latencies, time.Since(sent))
+		if err != nil || resp.Error != gosnmp.NoError || len(resp.Variables) == 0 {
+			r.errors++
+			next = "." + root
+			continue
+		}
+
+		done := false
+		for _, v := range resp.Variables {
+			if v.Type == gosnmp.EndOfMibView || !strings.HasPrefix(v.Name, prefix) {
+				done = true
+				break
+			}
+			r.varbinds++
+			next = v.Name
+		}
+		if done {
+			r.walks++
+			next = "." + root
+		}
+	}
+}
+
+// splitTarget 拆分 host[:port]，默认端口 161
+func splitTarget(target string) (string, uint16, error) {
+	host, portText, err := net.SplitHostPort(target)
+	if err != nil {
+		// 不带端口的主机名或 IPv6 地址
+		return strings.Trim(target, "[]"), 161, nil
+	}
+	port, err := strconv.ParseUint(portText, 10, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port %q", portText)
+	}
+	return host, uint16(port), nil
+}
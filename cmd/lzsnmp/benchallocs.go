@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/gosnmp/gosnmp"
+	lzsnmp "github.com/liuzhen9320/snmp-go"
+	"github.com/liuzhen9320/snmp-go/testutil"
+)
+
+// allocsPEN 分配测试使用的企业号
+const allocsPEN = 99999
+
+// allocsWorkload 分配测试的一种请求
+type allocsWorkload struct {
+	name    string
+	request func(id uint32) gosnmp.SnmpPacket
+}
+
+// benchAllocs 在 testutil.MemNetwork 上逐个发送预先编码的请求，统计每个请求的内存分配次数和字节数
+//
+// 结果包含内存网络转发报文的少量分配，用于比较不同版本处理路径的分配，maxAllocs 大于 0 时
+// 任一请求类型超过该值返回 1，可以在 CI 中作为回归检查。
+func benchAllocs(requests int, maxAllocs float64) int {
+	agent, err := lzsnmp.NewAgent(lzsnmp.Config{PEN: allocsPEN, DisableStartTraps: true, LogLevel: log.ErrorLevel})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "bench:", err)
+		return 1
+	}
+	for i := 1; i <= 100; i++ {
+		agent.RegisterStatic(fmt.Sprintf("1.%d.0", i), gosnmp.Integer, i)
+	}
+	agent.RegisterStatic("2.1.0", gosnmp.OctetString, "allocation benchmark")
+
+	network := testutil.NewMemNetwork()
+	server, err := network.Listen("agent")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "bench:", err)
+		return 1
+	}
+	if err := agent.StartPacketConn(server); err != nil {
+		fmt.Fprintln(os.Stderr, "bench:", err)
+		return 1
+	}
+	defer agent.Stop()
+	client, err := network.Listen("")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "bench:", err)
+		return 1
+	}
+	defer client.Close()
+
+	prefix := fmt.Sprintf(".1.3.6.1.4.1.%d", allocsPEN)
+	pdu := func(oid string) []gosnmp.SnmpPDU {
+		return []gosnmp.SnmpPDU{{Name: prefix + oid, Type: gosnmp.Null}}
+	}
+	workloads := []allocsWorkload{
+		{"get", func(id uint32) gosnmp.SnmpPacket {
+			return gosnmp.SnmpPacket{PDUType: gosnmp.GetRequest, RequestID: id, Variables: pdu(".2.1.0")}
+		}},
+		{"getnext", func(id uint32) gosnmp.SnmpPacket {
+			return gosnmp.SnmpPacket{PDUType: gosnmp.GetNextRequest, RequestID: id, Variables: pdu(".1.50.0")}
+		}},
+		{"getbulk", func(id uint32) gosnmp.SnmpPacket {
+			return gosnmp.SnmpPacket{PDUType: gosnmp.GetBulkRequest, RequestID: id, MaxRepetitions: 25, Variables: pdu(".1")}
+		}},
+	}
+
+	failed := false
+	fmt.Printf("%-8s  %10s  %12s  %10s\n", "REQUEST", "ALLOCS/OP", "BYTES/OP", "NS/OP")
+	id := uint32(0)
+	for _, w := range workloads {
+		// 请求 ID 各不相同，避免被当作重传；编码在计数之前完成
+		packets := make([][]byte, requests+requests/10)
+		for i := range packets {
+			id++
+			pkt := w.request(id)
+			pkt.Version = gosnmp.Version2c
+			pkt.Community = "public"
+			b, err := pkt.MarshalMsg()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "bench:", err)
+				return 1
+			}
+			packets[i] = b
+		}
+		warmup, measured := packets[requests:], packets[:requests]
+
+		buf := make([]byte, 65535)
+		roundTrip := func(b []byte) error {
+			if _, err := client.WriteTo(b, server.LocalAddr()); err != nil {
+				return err
+			}
+			client.SetReadDeadline(time.Now().Add(2 * time.Second))
+			_, _, err := client.ReadFrom(buf)
+			return err
+		}
+		for _, b := range warmup {
+			if err := roundTrip(b); err != nil {
+				fmt.Fprintln(os.Stderr, "bench:", w.name, err)
+				return 1
+			}
+		}
+
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		for _, b := range measured {
+			if err := roundTrip(b); err != nil {
+				fmt.Fprintln(os.Stderr, "bench:", w.name, err)
+				return 1
+			}
+		}
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&after)
+
+		n := float64(len(measured))
+		allocs := float64(after.Mallocs-before.Mallocs) / n
+		fmt.Printf("%-8s  %10.1f  %12.0f  %10.0f\n", w.name, allocs, float64(after.TotalAlloc-before.TotalAlloc)/n, float64(elapsed.Nanoseconds())/n)
+		if maxAllocs > 0 && allocs > maxAllocs {
+			failed = true
+		}
+	}
+	if failed {
+		fmt.Fprintf(os.Stderr, "bench: allocations per request exceed %.1f\n", maxAllocs)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/gosnmp/gosnmp"
+	lzsnmp "github.com/liuzhen9320/snmp-go"
+)
+
+// discoverJSON -json 输出的一行
+type discoverJSON struct {
+	Address     string `json:"address"`
+	Version     string `json:"version"`
+	Community   string `json:"community,omitempty"`
+	User        string `json:"user,omitempty"`
+	SysDescr    string `json:"sysDescr"`
+	SysObjectID string `json:"sysObjectID"`
+	SysName     string `json:"sysName"`
+	RTTMillis   int64  `json:"rttMs"`
+}
+
+// discoverMain 探测网段中的 SNMP Agent 并打印 system 组信息
+//
+//	lzsnmp discover -c public,private 10.0.0.0/24 10.0.1.1-10.0.1.20
+func discoverMain(args []string) int {
+	fs := flag.NewFlagSet("discover", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: lzsnmp discover [flags] <IP|CIDR|range>...")
+		fs.PrintDefaults()
+	}
+	var (
+		port        = fs.Int("port", 161, "agent UDP port")
+		versions    = fs.String("versions", "2c,1", "comma-separated SNMP versions to try with communities")
+		communities = fs.String("c", "public", "comma-separated communities to try")
+		user        = fs.String("user", "", "SNMPv3 user to try after the communities")
+		authProto   = fs.String("auth-proto", "", "SNMPv3 auth protocol (md5, sha, sha256, ...)")
+		authPass    = fs.String("auth-pass", "", "SNMPv3 auth passphrase")
+		privProto   = fs.String("priv-proto", "", "SNMPv3 privacy protocol (des, aes, ...)")
+		privPass    = fs.String("priv-pass", "", "SNMPv3 privacy passphrase")
+		timeout     = fs.Duration("timeout", time.Second, "per-request timeout")
+		retries     = fs.Int("retries", 0, "retries per request")
+		concurrency = fs.Int("concurrency", 64, "addresses probed at the same time")
+		rate        = fs.Float64("rate", 0, "maximum probes started per second (0 = unlimited)")
+		asJSON      = fs.Bool("json", false, "print results as JSON lines")
+		verbose     = fs.Bool("v", false, "log failed probes")
+	)
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	cfg := lzsnmp.DiscoverConfig{
+		Targets:     fs.Args(),
+		Port:        *port,
+		Communities: splitList(*communities),
+		Timeout:     *timeout,
+		Retries:     *retries,
+		Concurrency: *concurrency,
+		Rate:        *rate,
+		LogLevel:    log.WarnLevel,
+	}
+	if *verbose {
+		cfg.LogLevel = log.DebugLevel
+	}
+	for _, v := range splitList(*versions) {
+		switch v {
+		case "1":
+			cfg.Versions = append(cfg.Versions, gosnmp.Version1)
+		case "2c":
+			cfg.Versions = append(cfg.Versions, gosnmp.Version2c)
+		default:
+			fmt.Fprintf(os.Stderr, "discover: unknown version %q (use -user for SNMPv3)\n", v)
+			return 2
+		}
+	}
+	if *user != "" {
+		u := lzsnmp.User{Name: *user, AuthPassphrase: *authPass, PrivPassphrase: *privPass}
+		var err error
+		if u.AuthProtocol, err = lzsnmp.ParseAuthProtocol(*authProto); err != nil {
+			fmt.Fprintln(os.Stderr, "discover:", err)
+			return 2
+		}
+		if u.PrivProtocol, err = lzsnmp.ParsePrivProtocol(*privProto); err != nil {
+			fmt.Fprintln(os.Stderr, "discover:", err)
+			return 2
+		}
+		cfg.Users = []lzsnmp.User{u}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	agents, err := lzsnmp.Discover(ctx, cfg)
+	if err != nil && ctx.Err() == nil {
+		fmt.Fprintln(os.Stderr, "discover:", err)
+		return 2
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, a := range agents {
+			enc.Encode(discoverJSON{
+				Address:     a.Address,
+				Version:     a.Version.String(),
+				Community:   a.Community,
+				User:        a.User,
+				SysDescr:    a.SysDescr,
+				SysObjectID: a.SysObjectID,
+				SysName:     a.SysName,
+				RTTMillis:   a.RTT.Milliseconds(),
+			})
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ADDRESS\tVERSION\tCREDENTIAL\tSYSOBJECTID\tSYSNAME\tSYSDESCR")
+		for _, a := range agents {
+			credential := a.Community
+			if a.User != "" {
+				credential = a.User
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", a.Address, a.Version, credential, a.SysObjectID, a.SysName, firstLine(a.SysDescr))
+		}
+		w.Flush()
+	}
+	if ctx.Err() != nil {
+		fmt.Fprintln(os.Stderr, "discover: interrupted, results are incomplete")
+		return 1
+	}
+	return 0
+}
+
+// splitList 拆分逗号分隔的列表并去掉空项
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// firstLine 返回多行 sysDescr 的第一行
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,40 @@
+// lzsnmp 命令行工具
+//
+//	lzsnmp discover [flags] <IP|CIDR|范围>...
+//	lzsnmp bench [flags] <host[:port]> [oid]
+//
+// 子命令的参数见 lzsnmp <子命令> -h。
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// commands 子命令名称到入口函数的映射，入口函数返回进程退出码
+var commands = map[string]func(args []string) int{
+	"discover": discoverMain,
+	"bench":    benchMain,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "lzsnmp: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	os.Exit(run(os.Args[2:]))
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: lzsnmp <command> [flags]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "commands:")
+	fmt.Fprintln(os.Stderr, "  discover   probe IP ranges for responding SNMP agents")
+	fmt.Fprintln(os.Stderr, "  bench      measure bulk-walk throughput of an agent")
+}
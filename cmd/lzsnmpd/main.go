@@ -0,0 +1,147 @@
+// lzsnmpd 按配置文件运行 SNMP Agent，配置文件中的 scripts 由 luascript 包注册，plugins 由 wasmplugin 包加载
+//
+//	lzsnmpd -config /etc/lzsnmp.yaml
+//
+// SIGHUP 重新加载配置文件，SIGINT / SIGTERM 停止。Windows 上可以安装为服务：
+//
+//	lzsnmpd install -config C:\lzsnmp\lzsnmp.yaml [-name lzsnmpd] [-eventlog]
+//	lzsnmpd uninstall [-name lzsnmpd]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/charmbracelet/log"
+	lzsnmp "github.com/liuzhen9320/snmp-go"
+	"github.com/liuzhen9320/snmp-go/luascript"
+	"github.com/liuzhen9320/snmp-go/wasmplugin"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if code, handled := serviceMain(args); handled {
+		return code
+	}
+
+	fs := flag.NewFlagSet("lzsnmpd", flag.ExitOnError)
+	configPath := fs.String("config", "lzsnmp.yaml", "YAML or JSON config file")
+	fs.Parse(args)
+
+	d, err := startDaemon(*configPath, nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lzsnmpd:", err)
+		return 1
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM)
+	for sig := range signals {
+		if sig == syscall.SIGHUP {
+			d.reload()
+			continue
+		}
+		break
+	}
+	d.stop()
+	return 0
+}
+
+// daemon 运行中的 Agent 和它的配置文件
+type daemon struct {
+	path    string
+	agent   *lzsnmp.Agent
+	logger  lzsnmp.Logger
+	plugins []*wasmplugin.Plugin
+}
+
+// startDaemon 按配置文件创建并启动 Agent，logger 为 nil 时使用默认日志
+func startDaemon(path string, logger lzsnmp.Logger) (*daemon, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	fc, err := lzsnmp.LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := fc.Config()
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	if err := cfg.ApplyEnv(); err != nil {
+		return nil, err
+	}
+	cfg.Logger = logger
+	agent, err := lzsnmp.NewAgent(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := fc.RegisterStatics(agent); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	if err := luascript.RegisterScripts(agent, fc, filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	if logger == nil {
+		logger = log.Default()
+	}
+	d := &daemon{path: path, agent: agent, logger: logger}
+	if err := d.loadPlugins(fc); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	if err := agent.Start(); err != nil {
+		d.closePlugins()
+		return nil, err
+	}
+	return d, nil
+}
+
+// reload 重新加载配置文件、其中的脚本和插件，配置文件无效时保留当前配置
+func (d *daemon) reload() {
+	if err := d.agent.ReloadFromFile(d.path); err != nil {
+		d.logger.Error("Failed to reload config", "path", d.path, "error", err)
+		return
+	}
+	fc, err := lzsnmp.LoadConfig(d.path)
+	if err == nil {
+		err = luascript.RegisterScripts(d.agent, fc, filepath.Dir(d.path))
+	}
+	if err != nil {
+		d.logger.Error("Failed to reload scripts", "path", d.path, "error", err)
+		return
+	}
+	// 插件的实例集合可能改变，先关闭旧插件再加载
+	d.closePlugins()
+	if err := d.loadPlugins(fc); err != nil {
+		d.logger.Error("Failed to reload plugins", "path", d.path, "error", err)
+	}
+}
+
+// loadPlugins 加载配置文件中的 WebAssembly 插件，定时刷新失败时记录日志
+func (d *daemon) loadPlugins(fc *lzsnmp.FileConfig) error {
+	plugins, err := wasmplugin.RegisterPlugins(d.agent, fc, filepath.Dir(d.path), func(err error) {
+		d.logger.Warn("Failed to refresh plugin", "error", err)
+	})
+	d.plugins = plugins
+	return err
+}
+
+func (d *daemon) closePlugins() {
+	for _, p := range d.plugins {
+		p.Close()
+	}
+	d.plugins = nil
+}
+
+func (d *daemon) stop() {
+	d.closePlugins()
+	d.agent.Stop()
+}
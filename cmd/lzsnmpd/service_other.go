@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+// serviceMain 只在 Windows 上处理服务子命令
+func serviceMain([]string) (int, bool) {
+	return 0, false
+}
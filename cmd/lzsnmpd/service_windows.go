@@ -0,0 +1,235 @@
+//go:build windows
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+
+	lzsnmp "github.com/liuzhen9320/snmp-go"
+)
+
+// defaultServiceName 服务和事件日志源的默认名称
+const defaultServiceName = "lzsnmpd"
+
+// eventID 写入事件日志的事件 ID
+const eventID = 1
+
+// serviceMain 处理 install / uninstall 子命令，由服务控制管理器启动时以服务方式运行
+func serviceMain(args []string) (int, bool) {
+	if len(args) > 0 {
+		switch args[0] {
+		case "install":
+			return installService(args[1:]), true
+		case "uninstall":
+			return uninstallService(args[1:]), true
+		}
+	}
+
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lzsnmpd:", err)
+		return 1, true
+	}
+	if !isService {
+		return 0, false
+	}
+	return runService(args), true
+}
+
+// installService 将当前可执行文件注册为自动启动的服务，-eventlog 时同时注册事件日志源
+func installService(args []string) int {
+	fs := flag.NewFlagSet("install", flag.ExitOnError)
+	name := fs.String("name", defaultServiceName, "service name")
+	configPath := fs.String("config", "", "YAML or JSON config file")
+	useEventLog := fs.Bool("eventlog", false, "write logs to the Windows event log")
+	fs.Parse(args)
+	if *configPath == "" {
+		fmt.Fprintln(os.Stderr, "lzsnmpd install: -config is required")
+		return 2
+	}
+
+	if err := doInstall(*name, *configPath, *useEventLog); err != nil {
+		fmt.Fprintln(os.Stderr, "lzsnmpd install:", err)
+		return 1
+	}
+	fmt.Printf("service %s installed\n", *name)
+	return 0
+}
+
+func doInstall(name, configPath string, useEventLog bool) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	configPath, err = filepath.Abs(configPath)
+	if err != nil {
+		return err
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	if s, err := m.OpenService(name); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s already exists", name)
+	}
+
+	// 服务启动时以这些参数运行 lzsnmpd
+	serviceArgs := []string{"-name", name, "-config", configPath}
+	if useEventLog {
+		serviceArgs = append(serviceArgs, "-eventlog")
+	}
+	s, err := m.CreateService(name, exe, mgr.Config{
+		DisplayName: "lzsnmp SNMP Agent",
+		Description: "SNMP agent configured by " + configPath,
+		StartType:   mgr.StartAutomatic,
+	}, serviceArgs...)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	if useEventLog {
+		if err := eventlog.InstallAsEventCreate(name, eventlog.Error|eventlog.Warning|eventlog.Info); err != nil {
+			s.Delete()
+			return fmt.Errorf("install event log source: %w", err)
+		}
+	}
+	return nil
+}
+
+// uninstallService 删除服务和事件日志源
+func uninstallService(args []string) int {
+	fs := flag.NewFlagSet("uninstall", flag.ExitOnError)
+	name := fs.String("name", defaultServiceName, "service name")
+	fs.Parse(args)
+
+	m, err := mgr.Connect()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "lzsnmpd uninstall:", err)
+		return 1
+	}
+	defer m.Disconnect()
+	s, err := m.OpenService(*name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "lzsnmpd uninstall: service %s is not installed\n", *name)
+		return 1
+	}
+	defer s.Close()
+	if err := s.Delete(); err != nil {
+		fmt.Fprintln(os.Stderr, "lzsnmpd uninstall:", err)
+		return 1
+	}
+	// 未使用事件日志时没有注册日志源，忽略错误
+	eventlog.Remove(*name)
+	fmt.Printf("service %s removed\n", *name)
+	return 0
+}
+
+// runService 以服务方式运行，参数为 install 时记录的参数
+func runService(args []string) int {
+	fs := flag.NewFlagSet("lzsnmpd", flag.ContinueOnError)
+	name := fs.String("name", defaultServiceName, "service name")
+	configPath := fs.String("config", "", "YAML or JSON config file")
+	useEventLog := fs.Bool("eventlog", false, "write logs to the Windows event log")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	h := &serviceHandler{configPath: *configPath}
+	if *useEventLog {
+		elog, err := eventlog.Open(*name)
+		if err != nil {
+			return 1
+		}
+		defer elog.Close()
+		h.logger = eventLogger{elog}
+	}
+	if err := svc.Run(*name, h); err != nil {
+		if h.logger != nil {
+			h.logger.Error("Service failed", "error", err)
+		}
+		return 1
+	}
+	return 0
+}
+
+// serviceHandler 响应服务控制管理器的请求
+type serviceHandler struct {
+	configPath string
+	logger     lzsnmp.Logger // 为 nil 时使用 Agent 的默认日志
+}
+
+// Execute 启动 Agent 并处理停止、关机和参数变更（重新加载配置文件）请求
+func (h *serviceHandler) Execute(_ []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	changes <- svc.Status{State: svc.StartPending}
+	d, err := startDaemon(h.configPath, h.logger)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("Failed to start SNMP Agent", "config", h.configPath, "error", err)
+		}
+		// 服务特定的退出码，服务控制管理器会记录启动失败
+		return true, 1
+	}
+
+	const accepted = svc.AcceptStop | svc.AcceptShutdown | svc.AcceptParamChange
+	changes <- svc.Status{State: svc.Running, Accepts: accepted}
+	for req := range requests {
+		switch req.Cmd {
+		case svc.Interrogate:
+			changes <- req.CurrentStatus
+		case svc.ParamChange:
+			d.reload()
+		case svc.Stop, svc.Shutdown:
+			changes <- svc.Status{State: svc.StopPending, WaitHint: uint32((10 * time.Second).Milliseconds())}
+			d.stop()
+			return false, 0
+		}
+	}
+	d.stop()
+	return false, 0
+}
+
+// eventLogger 将日志写入 Windows 事件日志，Debug 级别被丢弃
+type eventLogger struct {
+	elog *eventlog.Log
+}
+
+func (l eventLogger) Debug(interface{}, ...interface{}) {}
+
+func (l eventLogger) Info(msg interface{}, keyvals ...interface{}) {
+	l.elog.Info(eventID, formatEvent(msg, keyvals))
+}
+
+func (l eventLogger) Warn(msg interface{}, keyvals ...interface{}) {
+	l.elog.Warning(eventID, formatEvent(msg, keyvals))
+}
+
+func (l eventLogger) Error(msg interface{}, keyvals ...interface{}) {
+	l.elog.Error(eventID, formatEvent(msg, keyvals))
+}
+
+// formatEvent 将消息和键值对格式化为一行文本
+func formatEvent(msg interface{}, keyvals []interface{}) string {
+	var b strings.Builder
+	fmt.Fprint(&b, msg)
+	for i := 0; i < len(keyvals); i += 2 {
+		var value interface{} = "(missing)"
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+		fmt.Fprintf(&b, " %v=%v", keyvals[i], value)
+	}
+	return b.String()
+}
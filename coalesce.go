@@ -0,0 +1,144 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+)
+
+// coalescedCall 一个 OID 最近一次处理函数调用的结果，窗口内的请求共享该结果
+type coalescedCall struct {
+	mu       sync.Mutex
+	value    interface{}
+	at       time.Time     // 最近一次成功调用完成的时间，为零时没有可用结果
+	inflight chan struct{} // 正在进行的调用，完成时关闭
+	err      error         // 正在进行或刚完成的调用的错误，只返回给等待它的请求
+}
+
+// do 在窗口内返回上一次成功的结果；有调用正在进行时等待它完成并共享结果，否则调用 fn
+func (c *coalescedCall) do(window time.Duration, fn func() (interface{}, error)) (interface{}, bool, error) {
+	c.mu.Lock()
+	if ch := c.inflight; ch != nil {
+		c.mu.Unlock()
+		<-ch
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.err != nil {
+			return nil, true, c.err
+		}
+		return c.value, true, nil
+	}
+	if !c.at.IsZero() && time.Since(c.at) < window {
+		defer c.mu.Unlock()
+		return c.value, true, nil
+	}
+	ch := make(chan struct{})
+	c.inflight = ch
+	c.mu.Unlock()
+
+	var value interface{}
+	err := errHandlerPanic
+	defer func() {
+		// fn panic 时等待的请求也收到错误，panic 继续由 guardRequest 处理
+		c.mu.Lock()
+		c.err = err
+		if err == nil {
+			c.value, c.at = value, time.Now()
+		} else {
+			c.at = time.Time{}
+		}
+		c.inflight = nil
+		c.mu.Unlock()
+		close(ch)
+	}()
+	value, err = fn()
+	return value, false, err
+}
+
+// Coalesce 为相对 OID 子树开启请求合并，见 CoalesceAbsolute
+func (a *Agent) Coalesce(relativeOID string, window time.Duration) error {
+	absoluteOID := fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID)
+	return a.CoalesceAbsolute(absoluteOID, window)
+}
+
+// CoalesceAbsolute 合并 oid 及其子树下动态 OID 的处理函数调用，window 为 0 时取消
+//
+// 多个 NMS 轮询同一批 OID 时，同一 OID 的 GET/GETNEXT/GETBULK 在上一次调用完成后 window 内直接使用其结果，
+// 调用进行中到达的请求等待并共享它的结果，处理函数每个窗口只运行一次。处理函数看到的 RequestInfo
+// 来自触发调用的请求；失败的结果只返回给等待它的请求，不在窗口内保留。对该 OID 的 SET 成功后清除结果。
+// 中间件对每个请求仍单独执行。window 应小于轮询间隔，需要较长缓存时使用 RegisterCached。
+func (a *Agent) CoalesceAbsolute(oid string, window time.Duration) error {
+	if window < 0 {
+		return fmt.Errorf("coalescing window must not be negative for OID: %s", oid)
+	}
+	oid, err := normalizeOID(oid)
+	if err != nil {
+		return err
+	}
+
+	a.coalesceMu.Lock()
+	defer a.coalesceMu.Unlock()
+	rules := make(map[string]time.Duration)
+	if p := a.coalesceRules.Load(); p != nil {
+		maps.Copy(rules, *p)
+	}
+	if window == 0 {
+		delete(rules, oid)
+	} else {
+		rules[oid] = window
+	}
+	a.coalesceRules.Store(&rules)
+	// 窗口改变后不再使用按旧窗口保存的结果
+	a.coalesced.Clear()
+	a.logger.Info("Request coalescing updated", "oid", oid, "window", window)
+	return nil
+}
+
+// coalesceWindow 返回 oid 适用的合并窗口，取最长匹配的前缀，未开启时为 0
+func (a *Agent) coalesceWindow(oid string) time.Duration {
+	p := a.coalesceRules.Load()
+	if p == nil || len(*p) == 0 {
+		return 0
+	}
+	for prefix := oid; prefix != ""; prefix = parentOID(prefix) {
+		if window, ok := (*p)[prefix]; ok {
+			return window
+		}
+	}
+	return 0
+}
+
+// coalesce 按 oid 的合并窗口调用 final，未开启合并时直接调用
+func (a *Agent) coalesce(oid string, req *RequestInfo, final HandlerFunc) (interface{}, error) {
+	window := a.coalesceWindow(oid)
+	if window == 0 {
+		return final(req)
+	}
+	v, _ := a.coalesced.LoadOrStore(oid, &coalescedCall{})
+	c := v.(*coalescedCall)
+	value, shared, err := c.do(window, func() (interface{}, error) { return final(req) })
+	if shared {
+		a.stats.coalescedCalls.Add(1)
+	} else if err != nil {
+		a.expireCoalesced(oid, c, window)
+	} else {
+		// 窗口结束后结果不再使用，删除条目，不再被轮询的 OID 不会一直占用内存
+		time.AfterFunc(window, func() { a.expireCoalesced(oid, c, window) })
+	}
+	return value, err
+}
+
+// expireCoalesced 在 c 没有进行中的调用、结果已过期时从 a.coalesced 中删除它
+func (a *Agent) expireCoalesced(oid string, c *coalescedCall, window time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.inflight == nil && (c.at.IsZero() || time.Since(c.at) >= window) {
+		a.coalesced.CompareAndDelete(oid, c)
+	}
+}
+
+// forgetCoalesced 清除 oid 保存的结果，SET 成功和注销 OID 后调用
+func (a *Agent) forgetCoalesced(oid string) {
+	a.coalesced.Delete(oid)
+}
@@ -0,0 +1,278 @@
+package lzsnmp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// FileConfig 配置文件内容，由 LoadConfig 读取
+//
+// 文件扩展名为 .json 时按 JSON 解析，其余按 YAML 解析（YAML 兼容 JSON）。
+type FileConfig struct {
+	PEN         uint32            `yaml:"pen" json:"pen"`
+	OIDPrefix   string            `yaml:"oid_prefix" json:"oid_prefix"` // 见 Config.OIDPrefix
+	Prefixes    map[string]string `yaml:"prefixes" json:"prefixes"`     // 前缀 ID → OID，见 Config.Prefixes
+	Listen      string            `yaml:"listen" json:"listen"`
+	ListenAddrs []string          `yaml:"listen_addrs" json:"listen_addrs"` // 额外监听的地址，见 Config.ListenAddrs
+	Interface   string            `yaml:"listen_interface" json:"listen_interface"`
+	SourceAddr  string            `yaml:"source_addr" json:"source_addr"`
+	Communities []string          `yaml:"communities" json:"communities"` // 第一个为 Config.Community，其余为 Config.Communities
+	Users       []FileUser        `yaml:"users" json:"users"`
+	EngineID    string            `yaml:"engine_id" json:"engine_id"`
+	LogLevel    string            `yaml:"log_level" json:"log_level"` // debug、info、warn、error
+
+	MaxConcurrentRequests int    `yaml:"max_concurrent_requests" json:"max_concurrent_requests"`
+	RequestQueueSize      int    `yaml:"request_queue_size" json:"request_queue_size"`
+	DropWhenBusy          bool   `yaml:"drop_when_busy" json:"drop_when_busy"`
+	ReusePortSockets      int    `yaml:"reuse_port_sockets" json:"reuse_port_sockets"`
+	ResponseJitter        string `yaml:"response_jitter" json:"response_jitter"` // 如 "50ms"
+
+	DisableStartTraps bool `yaml:"disable_start_traps" json:"disable_start_traps"`
+	EnableAuthenTraps bool `yaml:"enable_authen_traps" json:"enable_authen_traps"`
+
+	Cluster *FileCluster    `yaml:"cluster" json:"cluster"`
+	Modules map[string]bool `yaml:"modules" json:"modules"` // 模块名 → 是否启用
+	Static  []FileStatic    `yaml:"static" json:"static"`
+	Scripts []FileScript    `yaml:"scripts" json:"scripts"` // 由 luascript.RegisterScripts 注册
+	Plugins []FilePlugin    `yaml:"plugins" json:"plugins"` // 由 wasmplugin.RegisterPlugins 注册
+}
+
+// FileUser 配置文件中的 SNMPv3 用户，协议名称见 ParseAuthProtocol / ParsePrivProtocol
+type FileUser struct {
+	Name           string `yaml:"name" json:"name"`
+	AuthProtocol   string `yaml:"auth_protocol" json:"auth_protocol"`
+	AuthPassphrase string `yaml:"auth_passphrase" json:"auth_passphrase"`
+	PrivProtocol   string `yaml:"priv_protocol" json:"priv_protocol"`
+	PrivPassphrase string `yaml:"priv_passphrase" json:"priv_passphrase"`
+}
+
+// FileCluster 配置文件中的集群参数，见 ClusterConfig
+type FileCluster struct {
+	EngineID    string `yaml:"engine_id" json:"engine_id"`
+	EngineBoots uint32 `yaml:"engine_boots" json:"engine_boots"`
+	Epoch       string `yaml:"epoch" json:"epoch"` // RFC 3339 时间
+}
+
+// FileStatic 配置文件中的静态 OID
+//
+// OID 以 "." 开头时为绝对 OID，否则相对于 Prefix 指定的前缀（为空时为企业 OID）。类型名称与 ParseType 相同。
+type FileStatic struct {
+	OID         string `yaml:"oid" json:"oid"`
+	Prefix      string `yaml:"prefix" json:"prefix"` // 前缀 ID，见 Config.Prefixes
+	Type        string `yaml:"type" json:"type"`
+	Value       string `yaml:"value" json:"value"`
+	Hex         bool   `yaml:"hex" json:"hex"` // OctetString 的 Value 为十六进制
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+	Units       string `yaml:"units" json:"units"`
+}
+
+// FileScript 配置文件中由 Lua 脚本提供值的 OID，OID 和类型的写法同 FileStatic
+//
+// 脚本来自 Script（内联源码）或 File，需要定义 get() 函数，同时定义了 set(value) 时 OID 可写。
+// 根包不解析脚本，lzsnmpd 或应用程序通过 luascript.RegisterScripts 注册。
+type FileScript struct {
+	OID         string `yaml:"oid" json:"oid"`
+	Prefix      string `yaml:"prefix" json:"prefix"`
+	Type        string `yaml:"type" json:"type"`
+	Script      string `yaml:"script" json:"script"`
+	File        string `yaml:"file" json:"file"`       // 相对路径相对于 RegisterScripts 的 baseDir
+	Timeout     string `yaml:"timeout" json:"timeout"` // 每次调用的超时，如 "2s"，默认 5s
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+	Units       string `yaml:"units" json:"units"`
+}
+
+// FilePlugin 配置文件中由 WebAssembly 插件提供的子树，OID 为子树根，写法同 FileStatic
+//
+// 根包不加载插件，lzsnmpd 或应用程序通过 wasmplugin.RegisterPlugins 注册。
+type FilePlugin struct {
+	OID         string `yaml:"oid" json:"oid"`
+	Prefix      string `yaml:"prefix" json:"prefix"`
+	File        string `yaml:"file" json:"file"`                   // .wasm 文件，相对路径相对于 RegisterPlugins 的 baseDir
+	Timeout     string `yaml:"timeout" json:"timeout"`             // 每次调用的超时，如 "500ms"，默认 1s
+	Refresh     string `yaml:"refresh" json:"refresh"`             // 重新列出实例的间隔，如 "30s"，默认只在加载时列出
+	MaxMemoryMB int    `yaml:"max_memory_mb" json:"max_memory_mb"` // 插件内存上限，默认 64，最大 4096
+}
+
+// LoadConfig 读取 YAML 或 JSON 配置文件，未知字段视为错误；以 lzsnmp_noyaml 标签构建时只接受 JSON 格式的内容
+func LoadConfig(path string) (*FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	fc := &FileConfig{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(fc)
+	} else {
+		if err = decodeYAML(data, fc); err != nil && len(bytes.TrimSpace(data)) == 0 {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return fc, nil
+}
+
+// Config 转换为 NewAgent 使用的 Config，静态 OID 由 NewAgentFromFile 注册
+func (fc *FileConfig) Config() (Config, error) {
+	cfg := Config{
+		PEN:                   fc.PEN,
+		OIDPrefix:             fc.OIDPrefix,
+		Prefixes:              fc.Prefixes,
+		ListenAddr:            fc.Listen,
+		ListenAddrs:           fc.ListenAddrs,
+		ListenInterface:       fc.Interface,
+		SourceAddr:            fc.SourceAddr,
+		EngineID:              fc.EngineID,
+		MaxConcurrentRequests: fc.MaxConcurrentRequests,
+		RequestQueueSize:      fc.RequestQueueSize,
+		DropWhenBusy:          fc.DropWhenBusy,
+		ReusePortSockets:      fc.ReusePortSockets,
+		DisableStartTraps:     fc.DisableStartTraps,
+		EnableAuthenTraps:     fc.EnableAuthenTraps,
+		Modules:               fc.Modules,
+	}
+
+	if len(fc.Communities) > 0 {
+		cfg.Community = fc.Communities[0]
+		cfg.Communities = fc.Communities[1:]
+	}
+
+	if fc.LogLevel != "" {
+		level, err := log.ParseLevel(fc.LogLevel)
+		if err != nil {
+			return Config{}, fmt.Errorf("log_level: %w", err)
+		}
+		cfg.LogLevel = level
+	}
+
+	if fc.ResponseJitter != "" {
+		d, err := time.ParseDuration(fc.ResponseJitter)
+		if err != nil {
+			return Config{}, fmt.Errorf("response_jitter: %w", err)
+		}
+		cfg.ResponseJitter = d
+	}
+
+	for _, fu := range fc.Users {
+		auth, err := ParseAuthProtocol(fu.AuthProtocol)
+		if err != nil {
+			return Config{}, fmt.Errorf("user %s: %w", fu.Name, err)
+		}
+		priv, err := ParsePrivProtocol(fu.PrivProtocol)
+		if err != nil {
+			return Config{}, fmt.Errorf("user %s: %w", fu.Name, err)
+		}
+		cfg.Users = append(cfg.Users, User{
+			Name:           fu.Name,
+			AuthProtocol:   auth,
+			AuthPassphrase: fu.AuthPassphrase,
+			PrivProtocol:   priv,
+			PrivPassphrase: fu.PrivPassphrase,
+		})
+	}
+
+	if fc.Cluster != nil {
+		epoch, err := time.Parse(time.RFC3339, fc.Cluster.Epoch)
+		if err != nil {
+			return Config{}, fmt.Errorf("cluster epoch: %w", err)
+		}
+		cfg.Cluster = &ClusterConfig{
+			EngineID:    fc.Cluster.EngineID,
+			EngineBoots: fc.Cluster.EngineBoots,
+			Epoch:       epoch,
+		}
+	}
+	return cfg, nil
+}
+
+// RegisterStatics 注册配置文件中的静态 OID 及其说明
+func (fc *FileConfig) RegisterStatics(a *Agent) error {
+	for _, st := range fc.Static {
+		oidType, err := ParseType(st.Type)
+		if err != nil {
+			return fmt.Errorf("static %s: %w", st.OID, err)
+		}
+		value, err := parseValueText(oidType, st.Value, st.Hex)
+		if err != nil {
+			return fmt.Errorf("static %s: %w", st.OID, err)
+		}
+
+		oid := st.OID
+		if !strings.HasPrefix(oid, ".") {
+			if oid, err = a.OIDIn(st.Prefix, oid); err != nil {
+				return fmt.Errorf("static %s: %w", st.OID, err)
+			}
+		}
+		if err := a.RegisterStaticAbsolute(oid, oidType, value); err != nil {
+			return fmt.Errorf("static %s: %w", st.OID, err)
+		}
+		if st.Name != "" || st.Description != "" || st.Units != "" {
+			if err := a.AnnotateAbsolute(oid, OIDMeta{Name: st.Name, Description: st.Description, Units: st.Units}); err != nil {
+				return fmt.Errorf("static %s: %w", st.OID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// NewAgentFromFile 按配置文件创建 Agent 并注册其中的静态 OID
+//
+// LZSNMP_* 环境变量优先于配置文件，见 Config.ApplyEnv。配置文件中的 modules 在之后调用 AddModule 时生效。
+func NewAgentFromFile(path string) (*Agent, error) {
+	fc, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := fc.Config()
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	if err := cfg.ApplyEnv(); err != nil {
+		return nil, err
+	}
+	agent, err := NewAgent(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := fc.RegisterStatics(agent); err != nil {
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	return agent, nil
+}
+
+// ReloadFromFile 重新读取配置文件并调用 Reload，用于 SIGHUP 等场景
+//
+// 配置文件中的静态 OID 重新注册以更新其值，已从文件中删除的 OID 不会注销。
+func (a *Agent) ReloadFromFile(path string) error {
+	fc, err := LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	cfg, err := fc.Config()
+	if err != nil {
+		return fmt.Errorf("config %s: %w", path, err)
+	}
+	if err := cfg.ApplyEnv(); err != nil {
+		return err
+	}
+	if err := a.Reload(cfg); err != nil {
+		return err
+	}
+	if err := fc.RegisterStatics(a); err != nil {
+		return fmt.Errorf("config %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,377 @@
+package conformance
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+const (
+	// maxWalk WALK 子树时最多读取的变量数，防止 Agent 的 GETNEXT 循环
+	maxWalk = 10000
+	// maxGets 逐个 GET 检查的变量数上限
+	maxGets = 50
+	// bulkRepetitions GETBULK 截断检查使用的 max-repetitions，足以超过单个 UDP 报文
+	bulkRepetitions = 5000
+	// missingOID GETNEXT 越过 MIB 视图末尾时使用的 OID，位于 iso.org(1.3) 之后
+	missingOID = "1.4.999999"
+)
+
+// session 一次检查中共享的客户端和 WALK 结果
+type session struct {
+	opts Options
+	root string
+	v1   *gosnmp.GoSNMP
+	v2   *gosnmp.GoSNMP
+	walk []gosnmp.SnmpPDU // 子树下按 GETNEXT 顺序读取到的变量
+}
+
+// check 一项检查
+type check struct {
+	name        string
+	description string
+	minObjects  int  // 需要的子树变量数，不足时跳过
+	v3          bool // SNMPv3 检查，可以通过 Options.SkipV3 跳过
+	run         func(s *session) error
+}
+
+// checks 按顺序执行，getnext-order 必须最先执行以填充 session.walk
+var checks = []check{
+	{"getnext-order", "GETNEXT walks the subtree in strictly increasing order", 0, false, checkGetNextOrder},
+	{"get-instances", "GET returns every walked instance with the same type", 1, false, checkGetInstances},
+	{"get-missing-v2c", "GET of a missing instance returns noSuchObject/noSuchInstance", 1, false, checkGetMissingV2},
+	{"get-missing-v1", "SNMPv1 GET of a missing instance returns noSuchName", 1, false, checkGetMissingV1},
+	{"getnext-end-v2c", "GETNEXT past the last object returns endOfMibView", 0, false, checkGetNextEndV2},
+	{"getnext-end-v1", "SNMPv1 GETNEXT past the last object returns noSuchName", 0, false, checkGetNextEndV1},
+	{"getnext-multi", "GETNEXT resolves every varbind of a multi-varbind request", 2, false, checkGetNextMulti},
+	{"getbulk-walk", "GETBULK returns the same successors as GETNEXT", 2, false, checkGetBulkWalk},
+	{"getbulk-nonrepeaters", "GETBULK handles non-repeaters before repeaters", 3, false, checkGetBulkNonRepeaters},
+	{"getbulk-truncation", "GETBULK with large max-repetitions is truncated, not dropped", 1, false, checkGetBulkTruncation},
+	{"set-missing", "SET of a missing instance is rejected and creates nothing", 1, false, checkSetMissing},
+	{"v3-discovery", "SNMPv3 engine discovery returns a Report with the engine ID", 0, true, checkV3Discovery},
+}
+
+// checkGetNextOrder 用单变量 GETNEXT WALK 子树，检查 OID 严格递增
+func checkGetNextOrder(s *session) error {
+	prev := s.root
+	for i := 0; i < maxWalk; i++ {
+		resp, err := s.v2.GetNext([]string{prev})
+		if err != nil {
+			return fmt.Errorf("GETNEXT %s: %w", prev, err)
+		}
+		if resp.Error != gosnmp.NoError {
+			return fmt.Errorf("GETNEXT %s: error-status %s", prev, resp.Error)
+		}
+		if len(resp.Variables) != 1 {
+			return fmt.Errorf("GETNEXT %s: %d varbinds in response, want 1", prev, len(resp.Variables))
+		}
+		pdu := resp.Variables[0]
+		if pdu.Type == gosnmp.EndOfMibView || !inSubtree(pdu.Name, s.root) {
+			if len(s.walk) == 0 {
+				return fmt.Errorf("no objects under %s", s.root)
+			}
+			return nil
+		}
+		if compareOID(pdu.Name, prev) <= 0 {
+			return fmt.Errorf("GETNEXT %s returned %s, which is not after the request", prev, pdu.Name)
+		}
+		if pdu.Type == gosnmp.NoSuchObject || pdu.Type == gosnmp.NoSuchInstance {
+			return fmt.Errorf("GETNEXT %s returned exception %s for %s", prev, pdu.Type, pdu.Name)
+		}
+		s.walk = append(s.walk, pdu)
+		prev = pdu.Name
+	}
+	return fmt.Errorf("subtree has more than %d objects, possible GETNEXT loop", maxWalk)
+}
+
+// checkGetInstances GET 每个 WALK 到的实例，类型必须与 GETNEXT 一致
+func checkGetInstances(s *session) error {
+	for i, pdu := range s.walk {
+		if i >= maxGets {
+			break
+		}
+		resp, err := s.v2.Get([]string{pdu.Name})
+		if err != nil {
+			return fmt.Errorf("GET %s: %w", pdu.Name, err)
+		}
+		if resp.Error != gosnmp.NoError {
+			return fmt.Errorf("GET %s: error-status %s", pdu.Name, resp.Error)
+		}
+		if len(resp.Variables) != 1 || resp.Variables[0].Name != pdu.Name {
+			return fmt.Errorf("GET %s: response does not echo the requested OID", pdu.Name)
+		}
+		if got := resp.Variables[0].Type; got != pdu.Type {
+			return fmt.Errorf("GET %s returned %s, GETNEXT returned %s", pdu.Name, got, pdu.Type)
+		}
+	}
+	return nil
+}
+
+// missingInstance 返回子树中不存在的实例：最后一个实例下的子 OID
+func (s *session) missingInstance() string {
+	return s.walk[len(s.walk)-1].Name + ".1"
+}
+
+func checkGetMissingV2(s *session) error {
+	oid := s.missingInstance()
+	resp, err := s.v2.Get([]string{oid})
+	if err != nil {
+		return fmt.Errorf("GET %s: %w", oid, err)
+	}
+	if resp.Error != gosnmp.NoError {
+		return fmt.Errorf("GET %s: error-status %s, want noError with an exception varbind", oid, resp.Error)
+	}
+	if len(resp.Variables) != 1 {
+		return fmt.Errorf("GET %s: %d varbinds in response, want 1", oid, len(resp.Variables))
+	}
+	if t := resp.Variables[0].Type; t != gosnmp.NoSuchObject && t != gosnmp.NoSuchInstance {
+		return fmt.Errorf("GET %s returned %s, want noSuchObject or noSuchInstance", oid, t)
+	}
+	return nil
+}
+
+func checkGetMissingV1(s *session) error {
+	oid := s.missingInstance()
+	resp, err := s.v1.Get([]string{oid})
+	if err != nil {
+		return fmt.Errorf("GET %s: %w", oid, err)
+	}
+	return expectV1NoSuchName(resp, "GET "+oid)
+}
+
+func checkGetNextEndV2(s *session) error {
+	resp, err := s.v2.GetNext([]string{missingOID})
+	if err != nil {
+		return fmt.Errorf("GETNEXT %s: %w", missingOID, err)
+	}
+	if resp.Error != gosnmp.NoError {
+		return fmt.Errorf("GETNEXT %s: error-status %s, want noError with endOfMibView", missingOID, resp.Error)
+	}
+	if len(resp.Variables) != 1 || resp.Variables[0].Type != gosnmp.EndOfMibView {
+		return fmt.Errorf("GETNEXT %s: response is not a single endOfMibView varbind", missingOID)
+	}
+	return nil
+}
+
+func checkGetNextEndV1(s *session) error {
+	resp, err := s.v1.GetNext([]string{missingOID})
+	if err != nil {
+		return fmt.Errorf("GETNEXT %s: %w", missingOID, err)
+	}
+	return expectV1NoSuchName(resp, "GETNEXT "+missingOID)
+}
+
+// expectV1NoSuchName SNMPv1 没有异常值，缺失的变量必须以 noSuchName 和 error-index 报告
+func expectV1NoSuchName(resp *gosnmp.SnmpPacket, what string) error {
+	if resp.Error != gosnmp.NoSuchName {
+		return fmt.Errorf("%s: error-status %s, want noSuchName", what, resp.Error)
+	}
+	if resp.ErrorIndex != 1 {
+		return fmt.Errorf("%s: error-index %d, want 1", what, resp.ErrorIndex)
+	}
+	return nil
+}
+
+// checkGetNextMulti 一个请求中的每个变量绑定都必须独立求后继
+func checkGetNextMulti(s *session) error {
+	req := []string{s.root, s.walk[0].Name}
+	resp, err := s.v2.GetNext(req)
+	if err != nil {
+		return fmt.Errorf("GETNEXT %v: %w", req, err)
+	}
+	if resp.Error != gosnmp.NoError {
+		return fmt.Errorf("GETNEXT %v: error-status %s", req, resp.Error)
+	}
+	return expectNames(resp.Variables, []string{s.walk[0].Name, s.walk[1].Name}, "GETNEXT")
+}
+
+// checkGetBulkWalk 单变量 GETBULK 的结果必须是 WALK 结果的前缀
+func checkGetBulkWalk(s *session) error {
+	n := min(len(s.walk), 10)
+	resp, err := s.v2.GetBulk([]string{s.root}, 0, uint32(n))
+	if err != nil {
+		return fmt.Errorf("GETBULK %s: %w", s.root, err)
+	}
+	if resp.Error != gosnmp.NoError {
+		return fmt.Errorf("GETBULK %s: error-status %s", s.root, resp.Error)
+	}
+	return expectNames(resp.Variables, walkNames(s.walk[:n]), "GETBULK")
+}
+
+// checkGetBulkNonRepeaters 第一个变量是非重复变量，只返回一次后继，第二个变量重复 3 次
+func checkGetBulkNonRepeaters(s *session) error {
+	req := []string{s.walk[0].Name, s.root}
+	resp, err := s.v2.GetBulk(req, 1, 3)
+	if err != nil {
+		return fmt.Errorf("GETBULK %v: %w", req, err)
+	}
+	if resp.Error != gosnmp.NoError {
+		return fmt.Errorf("GETBULK %v: error-status %s", req, resp.Error)
+	}
+	want := []string{s.walk[1].Name, s.walk[0].Name, s.walk[1].Name, s.walk[2].Name}
+	return expectNames(resp.Variables, want, "GETBULK")
+}
+
+// checkGetBulkTruncation 响应超过报文大小时 Agent 应截断变量列表，而不是丢弃请求
+func checkGetBulkTruncation(s *session) error {
+	resp, err := s.v2.GetBulk([]string{s.root}, 0, bulkRepetitions)
+	if err != nil {
+		return fmt.Errorf("GETBULK max-repetitions %d: %w", bulkRepetitions, err)
+	}
+	if resp.Error != gosnmp.NoError && resp.Error != gosnmp.TooBig {
+		return fmt.Errorf("GETBULK max-repetitions %d: error-status %s", bulkRepetitions, resp.Error)
+	}
+	if len(resp.Variables) > bulkRepetitions {
+		return fmt.Errorf("GETBULK returned %d varbinds, more than max-repetitions %d", len(resp.Variables), bulkRepetitions)
+	}
+	n := min(len(resp.Variables), len(s.walk))
+	return expectNames(resp.Variables[:n], walkNames(s.walk[:n]), "GETBULK")
+}
+
+// checkSetMissing SET 不存在的实例必须返回错误，且之后 GET 仍然不存在
+func checkSetMissing(s *session) error {
+	oid := s.missingInstance()
+	resp, err := s.v2.Set([]gosnmp.SnmpPDU{{Name: oid, Type: gosnmp.Integer, Value: 1}})
+	if err != nil {
+		return fmt.Errorf("SET %s: %w", oid, err)
+	}
+	switch resp.Error {
+	case gosnmp.NoCreation, gosnmp.NotWritable, gosnmp.NoAccess, gosnmp.InconsistentName:
+	default:
+		return fmt.Errorf("SET %s: error-status %s, want noCreation or notWritable", oid, resp.Error)
+	}
+	if resp.ErrorIndex != 1 {
+		return fmt.Errorf("SET %s: error-index %d, want 1", oid, resp.ErrorIndex)
+	}
+
+	get, err := s.v2.Get([]string{oid})
+	if err != nil {
+		return fmt.Errorf("GET %s: %w", oid, err)
+	}
+	if len(get.Variables) == 1 {
+		if t := get.Variables[0].Type; t != gosnmp.NoSuchObject && t != gosnmp.NoSuchInstance {
+			return fmt.Errorf("rejected SET created %s", oid)
+		}
+	}
+	return nil
+}
+
+// checkV3Discovery 发送不带引擎 ID 的 noAuthNoPriv 请求，Agent 必须回复 usmStatsUnknownEngineIDs Report
+func checkV3Discovery(s *session) error {
+	host, port, err := splitTarget(s.opts.Target)
+	if err != nil {
+		return err
+	}
+	conn, err := net.Dial("udp", net.JoinHostPort(host, strconv.Itoa(int(port))))
+	if err != nil {
+		return fmt.Errorf("dial: %w", err)
+	}
+	defer conn.Close()
+
+	msgID := rand.Uint32() & 0x7fffffff
+	probe := &gosnmp.SnmpPacket{
+		Version:            gosnmp.Version3,
+		MsgFlags:           gosnmp.Reportable,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{Logger: gosnmp.NewLogger(nil)},
+		MsgID:              msgID,
+		MsgMaxSize:         65507,
+		PDUType:            gosnmp.GetRequest,
+		RequestID:          rand.Uint32() & 0x7fffffff,
+	}
+	out, err := probe.MarshalMsg()
+	if err != nil {
+		return fmt.Errorf("encode discovery request: %w", err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(s.opts.Timeout)); err != nil {
+		return err
+	}
+	if _, err := conn.Write(out); err != nil {
+		return fmt.Errorf("send discovery request: %w", err)
+	}
+
+	buf := make([]byte, 65535)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return fmt.Errorf("no response to discovery request: %w", err)
+	}
+	decoder := gosnmp.GoSNMP{SecurityParameters: &gosnmp.UsmSecurityParameters{}, Logger: gosnmp.NewLogger(nil)}
+	resp, err := decoder.SnmpDecodePacket(buf[:n])
+	if err != nil {
+		return fmt.Errorf("decode discovery response: %w", err)
+	}
+	if resp.PDUType != gosnmp.Report {
+		return fmt.Errorf("discovery response is %s, want Report", resp.PDUType)
+	}
+	if resp.MsgID != msgID {
+		return fmt.Errorf("discovery response msgID %d, want %d", resp.MsgID, msgID)
+	}
+	usm, ok := resp.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	if !ok || usm.AuthoritativeEngineID == "" {
+		return fmt.Errorf("discovery response has no authoritative engine ID")
+	}
+	return nil
+}
+
+// expectNames 检查响应变量的 OID 与 want 一致
+func expectNames(got []gosnmp.SnmpPDU, want []string, what string) error {
+	if len(got) != len(want) {
+		return fmt.Errorf("%s returned %d varbinds, want %d (%v)", what, len(got), len(want), want)
+	}
+	for i, pdu := range got {
+		if pdu.Name != want[i] {
+			return fmt.Errorf("%s varbind %d is %s, want %s", what, i+1, pdu.Name, want[i])
+		}
+	}
+	return nil
+}
+
+func walkNames(pdus []gosnmp.SnmpPDU) []string {
+	names := make([]string, len(pdus))
+	for i, pdu := range pdus {
+		names[i] = pdu.Name
+	}
+	return names
+}
+
+// inSubtree 判断 oid 是否位于 root 子树下（不含 root 本身）
+func inSubtree(oid, root string) bool {
+	return strings.HasPrefix(strings.TrimPrefix(oid, "."), strings.TrimPrefix(root, ".")+".")
+}
+
+// parseOID 将点分 OID 解析为子标识符
+func parseOID(oid string) ([]uint64, error) {
+	parts := strings.Split(strings.TrimPrefix(oid, "."), ".")
+	out := make([]uint64, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OID %q: %w", oid, err)
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// compareOID 按子标识符逐个比较两个 OID，无法解析时按字符串比较
+func compareOID(a, b string) int {
+	pa, errA := parseOID(a)
+	pb, errB := parseOID(b)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(pa) - len(pb)
+}
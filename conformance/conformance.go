@@ -0,0 +1,159 @@
+// Package conformance 对运行中的 SNMP Agent 执行协议一致性检查
+//
+// 检查项覆盖 GETNEXT 顺序、错误码、GETBULK 截断和 SNMPv3 引擎发现，
+// 用于确认自定义注册没有破坏协议行为。检查只读取数据，SET 检查只写入不存在的 OID。
+package conformance
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// Options 一致性检查配置
+type Options struct {
+	Target    string        // Agent 地址 host:port，端口缺省为 161
+	Community string        // v1/v2c community
+	Root      string        // 检查的子树根 OID，如 1.3.6.1.4.1.12345
+	Timeout   time.Duration // 单个请求超时，默认 2 秒
+	SkipV3    bool          // 跳过 SNMPv3 引擎发现检查
+}
+
+// Status 检查结果
+type Status string
+
+const (
+	Pass Status = "PASS"
+	Fail Status = "FAIL"
+	Skip Status = "SKIP"
+)
+
+// Result 一项检查的结果
+type Result struct {
+	Name        string
+	Description string
+	Status      Status
+	Detail      string // 失败或跳过的原因
+}
+
+// Report 一次检查的全部结果
+type Report struct {
+	Target  string
+	Root    string
+	Objects int // 子树下 WALK 到的变量数
+	Results []Result
+}
+
+// Passed 所有检查都通过或跳过时返回 true
+func (r *Report) Passed() bool {
+	for _, res := range r.Results {
+		if res.Status == Fail {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteText 以文本格式输出报告
+func (r *Report) WriteText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "target %s, root %s, %d objects\n", r.Target, r.Root, r.Objects); err != nil {
+		return err
+	}
+	failed := 0
+	for _, res := range r.Results {
+		line := fmt.Sprintf("%-4s %-22s %s", res.Status, res.Name, res.Description)
+		if res.Detail != "" {
+			line += ": " + res.Detail
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+		if res.Status == Fail {
+			failed++
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d checks, %d failed\n", len(r.Results), failed)
+	return err
+}
+
+// Run 对 opts.Target 执行全部检查
+//
+// 只有连接失败等无法开始检查的情况返回错误，协议行为问题记录在 Report 中。
+func Run(opts Options) (*Report, error) {
+	if opts.Root == "" {
+		return nil, fmt.Errorf("root OID is required")
+	}
+	if _, err := parseOID(opts.Root); err != nil {
+		return nil, err
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = 2 * time.Second
+	}
+
+	host, port, err := splitTarget(opts.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &session{opts: opts, root: opts.Root}
+	if s.v2, err = connect(host, port, opts, gosnmp.Version2c); err != nil {
+		return nil, err
+	}
+	defer s.v2.Conn.Close()
+	if s.v1, err = connect(host, port, opts, gosnmp.Version1); err != nil {
+		return nil, err
+	}
+	defer s.v1.Conn.Close()
+
+	report := &Report{Target: opts.Target, Root: opts.Root}
+	for _, c := range checks {
+		res := Result{Name: c.name, Description: c.description, Status: Pass}
+		if len(s.walk) < c.minObjects {
+			res.Status = Skip
+			res.Detail = fmt.Sprintf("needs at least %d objects under root", c.minObjects)
+		} else if c.v3 && opts.SkipV3 {
+			res.Status = Skip
+			res.Detail = "SNMPv3 checks disabled"
+		} else if err := c.run(s); err != nil {
+			res.Status = Fail
+			res.Detail = err.Error()
+		}
+		report.Results = append(report.Results, res)
+	}
+	report.Objects = len(s.walk)
+	return report, nil
+}
+
+// connect 创建指定版本的客户端，不重试以便准确观察 Agent 行为
+func connect(host string, port uint16, opts Options, version gosnmp.SnmpVersion) (*gosnmp.GoSNMP, error) {
+	client := &gosnmp.GoSNMP{
+		Target:    host,
+		Port:      port,
+		Community: opts.Community,
+		Version:   version,
+		Timeout:   opts.Timeout,
+		Retries:   0,
+		MaxOids:   gosnmp.MaxOids,
+	}
+	if err := client.Connect(); err != nil {
+		return nil, fmt.Errorf("connect %s: %w", opts.Target, err)
+	}
+	return client, nil
+}
+
+// splitTarget 解析 host:port，端口缺省为 161
+func splitTarget(target string) (string, uint16, error) {
+	host, portStr, err := net.SplitHostPort(target)
+	if err != nil {
+		return target, 161, nil
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port in %q: %w", target, err)
+	}
+	return host, uint16(port), nil
+}
@@ -0,0 +1,263 @@
+package lzsnmp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/gosnmp/gosnmp"
+)
+
+// maxDiscoverHosts 一次发现最多探测的地址数，防止误写为 /8 等大网段
+const maxDiscoverHosts = 65536
+
+// SNMPv2-MIB system 组中用于识别设备的对象
+const (
+	sysDescrOID    = "1.3.6.1.2.1.1.1.0"
+	sysObjectIDOID = "1.3.6.1.2.1.1.2.0"
+	sysNameOID     = "1.3.6.1.2.1.1.5.0"
+)
+
+// DiscoverConfig 探测 SNMP Agent 的配置
+type DiscoverConfig struct {
+	// Targets 探测的地址：单个 IP、CIDR（如 "10.0.0.0/24"，IPv4 跳过网络地址和广播地址）
+	// 或范围（如 "10.0.0.1-10.0.0.50"）
+	Targets []string
+	Port    int // 默认 161
+
+	Versions    []gosnmp.SnmpVersion // 使用 community 探测的版本，按顺序尝试，默认 [Version2c, Version1]
+	Communities []string             // 按顺序尝试的 community，默认 ["public"]
+	Users       []User               // 在 community 之后尝试的 SNMPv3 用户
+
+	Timeout     time.Duration // 每个请求的超时，默认 1 秒
+	Retries     int           // 超时后的重发次数
+	Concurrency int           // 同时探测的地址数，默认 64
+	Rate        float64       // 每秒开始探测的地址数上限，0 表示不限制
+
+	LogLevel log.Level
+	Logger   Logger
+}
+
+// DiscoveredAgent 发现的 SNMP Agent，Community 和 User 为第一个得到响应的凭据
+type DiscoveredAgent struct {
+	Address     string // host:port
+	Version     gosnmp.SnmpVersion
+	Community   string // v1/v2c 时为响应的 community
+	User        string // v3 时为响应的用户名
+	SysDescr    string
+	SysObjectID string
+	SysName     string
+	RTT         time.Duration // 成功探测的请求耗时
+}
+
+// discoverCredential 一组探测凭据
+type discoverCredential struct {
+	version   gosnmp.SnmpVersion
+	community string
+	user      *User
+}
+
+// Discover 探测 cfg.Targets 中响应 SNMP 的 Agent，结果按地址排序
+//
+// 每个地址依次尝试各个凭据，直到得到响应为止；每一轮使用一个凭据并发探测所有尚未响应的地址，
+// 因此总耗时约为凭据数乘以 Timeout。ctx 取消时返回已发现的 Agent 和 ctx 的错误。
+func Discover(ctx context.Context, cfg DiscoverConfig) ([]DiscoveredAgent, error) {
+	hosts, err := expandDiscoverTargets(cfg.Targets)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 161
+	}
+	if cfg.Port < 0 || cfg.Port > 65535 {
+		return nil, fmt.Errorf("invalid discovery port: %d", cfg.Port)
+	}
+	if len(cfg.Versions) == 0 {
+		cfg.Versions = []gosnmp.SnmpVersion{gosnmp.Version2c, gosnmp.Version1}
+	}
+	if len(cfg.Communities) == 0 {
+		cfg.Communities = []string{"public"}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = time.Second
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 64
+	}
+
+	var creds []discoverCredential
+	for _, v := range cfg.Versions {
+		if v == gosnmp.Version3 {
+			continue
+		}
+		for _, c := range cfg.Communities {
+			creds = append(creds, discoverCredential{version: v, community: c})
+		}
+	}
+	for i := range cfg.Users {
+		creds = append(creds, discoverCredential{version: gosnmp.Version3, user: &cfg.Users[i]})
+	}
+
+	// v3 先进行引擎发现，每个地址最多两次请求及其重发
+	poller, err := NewPoller(PollerConfig{
+		Concurrency: cfg.Concurrency,
+		Rate:        cfg.Rate,
+		Timeout:     cfg.Timeout * time.Duration(cfg.Retries+1) * 2,
+		LogLevel:    cfg.LogLevel,
+		Logger:      cfg.Logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	address := func(host netip.Addr) string {
+		return net.JoinHostPort(host.String(), strconv.Itoa(cfg.Port))
+	}
+	found := make(map[string]DiscoveredAgent)
+	pending := slices.Clone(hosts)
+	for _, cred := range creds {
+		if len(pending) == 0 || ctx.Err() != nil {
+			break
+		}
+		targets := make([]PollTarget, len(pending))
+		for i, host := range pending {
+			targets[i] = PollTarget{
+				Name: address(host),
+				Config: ManagerConfig{
+					Address:   address(host),
+					Version:   cred.version,
+					Community: cred.community,
+					User:      cred.user,
+					Timeout:   cfg.Timeout,
+					Retries:   cfg.Retries,
+				},
+				Plan: PollPlan{Get: []string{sysDescrOID, sysObjectIDOID, sysNameOID}},
+			}
+		}
+		poller.PollFunc(ctx, targets, func(r *PollResult) {
+			if r.Err == nil {
+				found[r.Target] = discoveredAgent(r, cred)
+			}
+		})
+
+		pending = slices.DeleteFunc(pending, func(host netip.Addr) bool {
+			_, ok := found[address(host)]
+			return ok
+		})
+	}
+
+	agents := make([]DiscoveredAgent, 0, len(found))
+	for _, host := range hosts {
+		if a, ok := found[address(host)]; ok {
+			agents = append(agents, a)
+		}
+	}
+	return agents, ctx.Err()
+}
+
+// discoveredAgent 从探测结果中提取 system 组的值
+func discoveredAgent(r *PollResult, cred discoverCredential) DiscoveredAgent {
+	a := DiscoveredAgent{
+		Address:   r.Target,
+		Version:   cred.version,
+		Community: cred.community,
+		RTT:       r.Duration,
+	}
+	if cred.user != nil {
+		a.User = cred.user.Name
+	}
+	if v, ok := r.Value(sysDescrOID); ok {
+		DecodeValue(v, &a.SysDescr)
+	}
+	if v, ok := r.Value(sysObjectIDOID); ok {
+		DecodeValue(v, &a.SysObjectID)
+	}
+	if v, ok := r.Value(sysNameOID); ok {
+		DecodeValue(v, &a.SysName)
+	}
+	return a
+}
+
+// expandDiscoverTargets 将 IP、CIDR 和范围展开为去重排序的地址列表
+func expandDiscoverTargets(specs []string) ([]netip.Addr, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no discovery targets")
+	}
+
+	seen := make(map[netip.Addr]bool)
+	var hosts []netip.Addr
+	add := func(addr netip.Addr) error {
+		if seen[addr] {
+			return nil
+		}
+		if len(hosts) >= maxDiscoverHosts {
+			return fmt.Errorf("discovery targets exceed %d addresses", maxDiscoverHosts)
+		}
+		seen[addr] = true
+		hosts = append(hosts, addr)
+		return nil
+	}
+
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		switch {
+		case strings.Contains(spec, "/"):
+			prefix, err := netip.ParsePrefix(spec)
+			if err != nil {
+				return nil, fmt.Errorf("invalid discovery target %q: %w", spec, err)
+			}
+			prefix = prefix.Masked()
+			if prefix.Addr().BitLen()-prefix.Bits() > 16 {
+				return nil, fmt.Errorf("discovery target %s exceeds %d addresses", spec, maxDiscoverHosts)
+			}
+			first, last := prefix.Addr(), lastAddr(prefix)
+			if first.Is4() && prefix.Bits() < 31 {
+				first, last = first.Next(), last.Prev()
+			}
+			for addr := first; addr.IsValid() && addr.Compare(last) <= 0; addr = addr.Next() {
+				if err := add(addr); err != nil {
+					return nil, err
+				}
+			}
+		case strings.Contains(spec, "-"):
+			from, to, _ := strings.Cut(spec, "-")
+			first, err1 := netip.ParseAddr(strings.TrimSpace(from))
+			last, err2 := netip.ParseAddr(strings.TrimSpace(to))
+			if err1 != nil || err2 != nil || first.BitLen() != last.BitLen() || first.Compare(last) > 0 {
+				return nil, fmt.Errorf("invalid discovery target range: %s", spec)
+			}
+			for addr := first; addr.IsValid() && addr.Compare(last) <= 0; addr = addr.Next() {
+				if err := add(addr); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			addr, err := netip.ParseAddr(spec)
+			if err != nil {
+				return nil, fmt.Errorf("invalid discovery target %q: %w", spec, err)
+			}
+			if err := add(addr); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	slices.SortFunc(hosts, netip.Addr.Compare)
+	return hosts, nil
+}
+
+// lastAddr 返回网段中的最后一个地址
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	b := prefix.Addr().AsSlice()
+	for i := prefix.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
@@ -0,0 +1,193 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+)
+
+// OIDMeta OID 的文档信息
+type OIDMeta struct {
+	Name        string
+	Description string
+	Units       string // 值的单位，如 "seconds"、"bytes"
+}
+
+// DocFormat 文档输出格式
+type DocFormat string
+
+// 支持的文档格式
+const (
+	DocMarkdown DocFormat = "markdown"
+	DocHTML     DocFormat = "html"
+)
+
+// docEntry 文档中的一个对象，表格列等子树的所有实例合并为一项
+type docEntry struct {
+	OID         string
+	Name        string
+	Description string
+	Units       string
+	Type        string
+	Access      string
+	Instances   int
+}
+
+// Annotate 为相对 OID 添加文档信息
+func (a *Agent) Annotate(relativeOID string, meta OIDMeta) error {
+	absoluteOID := fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID)
+	return a.AnnotateAbsolute(absoluteOID, meta)
+}
+
+// AnnotateAbsolute 为绝对路径 OID 添加文档信息，OID 可以尚未注册
+func (a *Agent) AnnotateAbsolute(oid string, meta OIDMeta) error {
+	oid = strings.Trim(oid, ".")
+	if oid == "" {
+		return fmt.Errorf("OID is required")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.meta[oid] = meta
+	return nil
+}
+
+// annotateGroup 为子树根添加文档信息，子树中的所有实例在文档中合并为一项
+func (a *Agent) annotateGroup(oid string, meta OIDMeta) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.meta[oid] = meta
+	a.docGroups[oid] = true
+}
+
+// ExportDocs 输出所有已注册 OID 的文档（名称、类型、单位、访问模式、说明）
+func (a *Agent) ExportDocs(w io.Writer, format DocFormat) error {
+	entries := a.docEntries()
+
+	switch format {
+	case DocMarkdown, "md", "":
+		return writeMarkdownDocs(w, a.oidPrefix, entries)
+	case DocHTML:
+		return htmlDocsTemplate.Execute(w, struct {
+			Prefix  string
+			Entries []docEntry
+		}{a.oidPrefix, entries})
+	}
+	return fmt.Errorf("unsupported doc format: %s", format)
+}
+
+// docEntries 收集文档条目，分组内的实例合并为一项
+func (a *Agent) docEntries() []docEntry {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	s := a.store.Load()
+	byOID := make(map[string]*docEntry)
+	for oid, oidType := range s.types {
+		object := a.metaOwnerLocked(oid)
+
+		entry, ok := byOID[object]
+		if !ok {
+			meta := a.meta[object]
+			entry = &docEntry{
+				OID:         object,
+				Name:        meta.Name,
+				Description: meta.Description,
+				Units:       meta.Units,
+				Type:        oidType.String(),
+				Access:      "read-only",
+			}
+			byOID[object] = entry
+		}
+
+		entry.Instances++
+		if entry.Type != oidType.String() {
+			entry.Type = "mixed"
+		}
+		if _, writable := s.setters[oid]; writable {
+			entry.Access = "read-write"
+		}
+	}
+
+	entries := make([]docEntry, 0, len(byOID))
+	for _, entry := range byOID {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return compareOID(entries[i].OID, entries[j].OID) < 0 })
+	return entries
+}
+
+// metaOwnerLocked 返回 oid 所属的文档分组，不属于任何分组时返回 oid 本身
+func (a *Agent) metaOwnerLocked(oid string) string {
+	if _, ok := a.meta[oid]; ok {
+		return oid
+	}
+	for candidate := oid; ; {
+		i := strings.LastIndex(candidate, ".")
+		if i < 0 {
+			return oid
+		}
+		candidate = candidate[:i]
+		if a.docGroups[candidate] {
+			return candidate
+		}
+	}
+}
+
+// metaNameLocked 返回 oid 的名称，子树中的实例为子树的名称，调用方需持有 a.mu
+func (a *Agent) metaNameLocked(oid string) string {
+	return a.meta[a.metaOwnerLocked(oid)].Name
+}
+
+// metaName 返回 oid 的名称，用于日志
+func (a *Agent) metaName(oid string) string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.metaNameLocked(oid)
+}
+
+func writeMarkdownDocs(w io.Writer, prefix string, entries []docEntry) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# SNMP OID Reference\n\nEnterprise prefix: `%s`\n\n", prefix)
+	b.WriteString("| OID | Name | Type | Units | Access | Instances | Description |\n")
+	b.WriteString("|-----|------|------|-------|--------|-----------|-------------|\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s | %d | %s |\n",
+			e.OID, markdownCell(e.Name), e.Type, markdownCell(e.Units), e.Access, e.Instances, markdownCell(e.Description))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCell 转义表格单元格中的竖线和换行
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+var htmlDocsTemplate = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>SNMP OID Reference</title>
+</head>
+<body>
+<h1>SNMP OID Reference</h1>
+<p>Enterprise prefix: <code>{{.Prefix}}</code></p>
+<table>
+<thead>
+<tr><th>OID</th><th>Name</th><th>Type</th><th>Units</th><th>Access</th><th>Instances</th><th>Description</th></tr>
+</thead>
+<tbody>
+{{- range .Entries}}
+<tr><td><code>{{.OID}}</code></td><td>{{.Name}}</td><td>{{.Type}}</td><td>{{.Units}}</td><td>{{.Access}}</td><td>{{.Instances}}</td><td>{{.Description}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
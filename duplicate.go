@@ -0,0 +1,82 @@
+package lzsnmp
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+const (
+	// duplicateWindow 同一来源的相同请求 ID 在该时间内再次出现视为重传
+	duplicateWindow = 30 * time.Second
+	// slowResponseThreshold 原请求的处理耗时超过该值时，重传归因于处理慢而不是网络丢包
+	slowResponseThreshold = time.Second
+)
+
+// requestKey 标识一个请求：来源地址 + 请求 ID（v3 为 msgID）
+type requestKey struct {
+	source string
+	id     uint32
+}
+
+// requestRecord 已处理请求的记录
+type requestRecord struct {
+	seen time.Time
+	took time.Duration
+}
+
+// duplicateTracker 检测管理端重传的请求
+type duplicateTracker struct {
+	mu        sync.Mutex
+	requests  map[requestKey]requestRecord
+	lastPrune time.Time
+}
+
+// requestKeyOf 返回请求的标识，无法解码的请求返回 false
+func requestKeyOf(source net.Addr, pkt *gosnmp.SnmpPacket) (requestKey, bool) {
+	if pkt == nil || source == nil {
+		return requestKey{}, false
+	}
+	id := pkt.RequestID
+	if pkt.Version == gosnmp.Version3 {
+		id = pkt.MsgID
+	}
+	return requestKey{source: source.String(), id: id}, true
+}
+
+// observe 记录收到的请求，返回是否为重传，以及原请求是否处理过慢
+func (d *duplicateTracker) observe(key requestKey, now time.Time) (duplicate, slow bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.requests == nil {
+		d.requests = make(map[requestKey]requestRecord)
+	}
+	if now.Sub(d.lastPrune) > duplicateWindow {
+		for k, r := range d.requests {
+			if now.Sub(r.seen) > duplicateWindow {
+				delete(d.requests, k)
+			}
+		}
+		d.lastPrune = now
+	}
+
+	if r, ok := d.requests[key]; ok && now.Sub(r.seen) <= duplicateWindow {
+		return true, r.took >= slowResponseThreshold
+	}
+	d.requests[key] = requestRecord{seen: now}
+	return false, false
+}
+
+// complete 记录请求的处理耗时，重传请求不会覆盖原请求的记录
+func (d *duplicateTracker) complete(key requestKey, seen time.Time, took time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if r, ok := d.requests[key]; ok && r.seen.Equal(seen) {
+		r.took = took
+		d.requests[key] = r
+	}
+}
@@ -0,0 +1,132 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/slayercat/GoSNMPServer"
+)
+
+// snmpEngineBootsOID SNMP-FRAMEWORK-MIB snmpEngineBoots.0，持久化时作为保存 engineBoots 的键
+const snmpEngineBootsOID = "1.3.6.1.6.3.10.2.1.2.0"
+
+// maxEngineBoots snmpEngineBoots 的最大值，RFC 3414 规定达到后保持不变
+const maxEngineBoots = 2147483647
+
+// engineState Agent 的 SNMPv3 引擎参数
+type engineState struct {
+	id    string // engineID 的管理员指定部分
+	boots uint32
+	epoch time.Time // engineTime 的起点，为零时使用主机运行时长
+}
+
+// newEngineState 按配置确定引擎参数，配置了 Persist 时 Start 会再更新 engineBoots 和起点
+func newEngineState(cfg *Config) (*engineState, error) {
+	if c := cfg.Cluster; c != nil {
+		if cfg.EngineID != "" {
+			return nil, fmt.Errorf("EngineID cannot be used with Cluster, set Cluster.EngineID instead")
+		}
+		return &engineState{id: c.EngineID, boots: c.EngineBoots, epoch: c.Epoch}, nil
+	}
+	if len(cfg.EngineID) > maxEngineIDData {
+		return nil, fmt.Errorf("EngineID must be at most %d bytes", maxEngineIDData)
+	}
+	id := cfg.EngineID
+	if id == "" {
+		id = GoSNMPServer.DefaultAuthoritativeEngineID().EngineIDData
+	}
+	return &engineState{id: id, boots: 1}, nil
+}
+
+// applySecurity 将引擎参数写入 MasterAgent 的安全配置
+func (e *engineState) applySecurity(sc *GoSNMPServer.SecurityConfig) {
+	sc.AuthoritativeEngineID = GoSNMPServer.SNMPEngineID{EngineIDData: e.id}
+	sc.AuthoritativeEngineBoots = e.boots
+	sc.OnGetAuthoritativeEngineTime = e.time
+}
+
+// time 返回当前的 snmpEngineTime（秒）
+func (e *engineState) time() uint32 {
+	if e.epoch.IsZero() {
+		return GoSNMPServer.DefaultGetAuthoritativeEngineTime()
+	}
+	return uint32(time.Since(e.epoch) / time.Second)
+}
+
+// bootEngine 第一次 Start 时递增并保存 engineBoots，engineTime 从此时重新计时
+//
+// RFC 3414 要求 engineTime 归零时 engineBoots 必须增大，否则管理端会把之后的报文当作重放丢弃。
+// 未配置 Persist 或配置了 Cluster 时保持原有参数。
+func (a *Agent) bootEngine() error {
+	e := a.engine.Load()
+	if a.persist == nil || a.config.Cluster != nil || !e.epoch.IsZero() {
+		return nil
+	}
+	boots, err := a.persist.nextEngineBoots()
+	if err != nil {
+		return fmt.Errorf("failed to persist engineBoots: %w", err)
+	}
+	a.engine.Store(&engineState{id: e.id, boots: boots, epoch: time.Now()})
+	a.logger.Info("SNMPv3 engine booted", "engineBoots", boots)
+	return nil
+}
+
+// nextEngineBoots 返回递增后的 engineBoots，并在返回前写入存储，保证重启后不会重复使用
+func (p *persister) nextEngineBoots() (uint32, error) {
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+
+	p.mu.Lock()
+	boots := 1
+	if value, ok := p.restore(snmpEngineBootsOID, gosnmp.Integer, ""); ok {
+		boots = min(max(value.(int), 0)+1, maxEngineBoots)
+	}
+	p.recordLocked(snmpEngineBootsOID, gosnmp.Integer, boots)
+	delete(p.pendingPut, snmpEngineBootsOID)
+	entry := p.values[snmpEngineBootsOID]
+	p.mu.Unlock()
+
+	if err := p.config.Store.Put([]SubtreeEntry{entry}); err != nil {
+		return 0, err
+	}
+	return uint32(boots), nil
+}
+
+// GenerateEngineID 按 RFC 3411 的格式由本机第一个可用网卡的 MAC 地址生成 engineID，没有 MAC 地址时使用 IP 地址
+//
+// GoSNMPServer 固定 engineID 的前 5 字节（enterprise 20408，格式 5），返回值用作 Config.EngineID，
+// 即其后的管理员指定部分：RFC 3411 的格式字节（3 为 MAC，1 为 IPv4，2 为 IPv6）加地址。
+func GenerateEngineID() (string, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", fmt.Errorf("failed to list network interfaces: %w", err)
+	}
+	usable := func(iface net.Interface) bool {
+		return iface.Flags&net.FlagUp != 0 && iface.Flags&net.FlagLoopback == 0
+	}
+
+	for _, iface := range ifaces {
+		if usable(iface) && len(iface.HardwareAddr) == 6 {
+			return string(append([]byte{3}, iface.HardwareAddr...)), nil
+		}
+	}
+	for _, iface := range ifaces {
+		if !usable(iface) {
+			continue
+		}
+		addrs, _ := iface.Addrs()
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.IsLinkLocalUnicast() {
+				continue
+			}
+			if ip4 := ipNet.IP.To4(); ip4 != nil {
+				return string(append([]byte{1}, ip4...)), nil
+			}
+			return string(append([]byte{2}, ipNet.IP.To16()...)), nil
+		}
+	}
+	return "", fmt.Errorf("no network interface with a MAC or IP address")
+}
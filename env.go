@@ -0,0 +1,47 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/charmbracelet/log"
+)
+
+// 环境变量名称，值为空时视为未设置
+const (
+	EnvPEN        = "LZSNMP_PEN"
+	EnvListenAddr = "LZSNMP_LISTEN_ADDR"
+	EnvCommunity  = "LZSNMP_COMMUNITY"
+	EnvLogLevel   = "LZSNMP_LOG_LEVEL"
+)
+
+// ApplyEnv 用 LZSNMP_* 环境变量覆盖配置中的对应字段
+//
+// 便于容器部署时不修改代码或配置文件调整监听地址、community、日志级别和 PEN。
+func (c *Config) ApplyEnv() error {
+	if v := os.Getenv(EnvPEN); v != "" {
+		pen, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || pen == 0 {
+			return fmt.Errorf("%s: invalid PEN: %s", EnvPEN, v)
+		}
+		c.PEN = uint32(pen)
+	}
+	if v := os.Getenv(EnvListenAddr); v != "" {
+		c.ListenAddr = v
+	}
+	if v := os.Getenv(EnvCommunity); v != "" {
+		c.Community = v
+		// 覆盖后的 community 不再作为额外 community 重复出现
+		c.Communities = slices.DeleteFunc(slices.Clone(c.Communities), func(s string) bool { return s == v })
+	}
+	if v := os.Getenv(EnvLogLevel); v != "" {
+		level, err := log.ParseLevel(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", EnvLogLevel, err)
+		}
+		c.LogLevel = level
+	}
+	return nil
+}
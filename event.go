@@ -0,0 +1,134 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"time"
+)
+
+// EventType Agent 事件的类型
+type EventType int
+
+const (
+	EventStarted         EventType = iota + 1 // Agent 开始监听
+	EventStopped                              // Agent 停止
+	EventOIDRegistered                        // 新增了 OID 实例
+	EventOIDUnregistered                      // 删除了 OID 实例
+	EventAuthFailure                          // v1/v2c 请求使用了未知的 community
+	EventDecodeError                          // 请求报文无法解码
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventStarted:
+		return "started"
+	case EventStopped:
+		return "stopped"
+	case EventOIDRegistered:
+		return "oid-registered"
+	case EventOIDUnregistered:
+		return "oid-unregistered"
+	case EventAuthFailure:
+		return "auth-failure"
+	case EventDecodeError:
+		return "decode-error"
+	}
+	return fmt.Sprintf("EventType(%d)", int(t))
+}
+
+// Event Agent 的生命周期、注册和请求错误事件
+type Event struct {
+	Type   EventType
+	OID    string   // EventOIDRegistered、EventOIDUnregistered 的实例 OID
+	Source net.Addr // EventAuthFailure、EventDecodeError 的请求来源
+	Err    error    // EventDecodeError 的解码错误
+	Time   time.Time
+}
+
+// eventHub 事件订阅方
+type eventHub struct {
+	mu     sync.Mutex
+	nextID int
+	subs   map[int]chan Event
+}
+
+// SubscribeEvents 订阅 Agent 事件，buffer 为缓冲的事件数
+//
+// 返回的函数取消订阅并关闭 channel。订阅方处理过慢、缓冲已满时新的事件被丢弃，不阻塞 Agent。
+// 注册事件按实例发出，表格刷新等批量注册会产生大量事件，需要时按 OID 前缀过滤。
+func (a *Agent) SubscribeEvents(buffer int) (<-chan Event, func()) {
+	h := &a.events
+	ch := make(chan Event, max(buffer, 1))
+
+	h.mu.Lock()
+	if h.subs == nil {
+		h.subs = make(map[int]chan Event)
+	}
+	id := h.nextID
+	h.nextID++
+	h.subs[id] = ch
+	h.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, id)
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, cancel
+}
+
+// subscribed 判断是否有订阅方，没有时不计算注册事件
+func (h *eventHub) subscribed() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs) > 0
+}
+
+// publishEvent 将事件分发给所有订阅方
+func (a *Agent) publishEvent(e Event) {
+	h := &a.events
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	for _, ch := range h.subs {
+		select {
+		case ch <- e:
+		default:
+			a.logger.Debug("Event subscriber is full, dropping", "event", e.Type)
+		}
+	}
+}
+
+// publishRegistrations 比较更新前后的快照，发出新增和删除的 OID 实例
+func (a *Agent) publishRegistrations(before, after *oidStore) {
+	var added, removed []string
+	for oid := range after.types {
+		if _, ok := before.types[oid]; !ok {
+			added = append(added, oid)
+		}
+	}
+	for oid := range before.types {
+		if _, ok := after.types[oid]; !ok {
+			removed = append(removed, oid)
+		}
+	}
+	slices.SortFunc(removed, compareOID)
+	slices.SortFunc(added, compareOID)
+
+	now := time.Now()
+	for _, oid := range removed {
+		a.publishEvent(Event{Type: EventOIDUnregistered, OID: oid, Time: now})
+	}
+	for _, oid := range added {
+		a.publishEvent(Event{Type: EventOIDRegistered, OID: oid, Time: now})
+	}
+}
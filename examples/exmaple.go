@@ -20,9 +20,23 @@ func main() {
 		Community:  "public",
 		LogLevel:   log.DebugLevel,
 	}
+	// 允许通过 LZSNMP_* 环境变量覆盖，便于容器部署
+	if err := config.ApplyEnv(); err != nil {
+		log.Fatal("Invalid environment", "error", err)
+	}
 
-	// 创建 Agent
-	agent, err := lzsnmp.NewAgent(config)
+	// 创建 Agent；命令行指定配置文件时按配置文件创建，收到 SIGHUP 时重新加载
+	var configPath string
+	if len(os.Args) > 1 {
+		configPath = os.Args[1]
+	}
+	var agent *lzsnmp.Agent
+	var err error
+	if configPath != "" {
+		agent, err = lzsnmp.NewAgentFromFile(configPath)
+	} else {
+		agent, err = lzsnmp.NewAgent(config)
+	}
 	if err != nil {
 		log.Fatal("Failed to create agent", "error", err)
 	}
@@ -102,8 +116,8 @@ func main() {
 
 	// 列出所有注册的 OID
 	log.Info("Registered OIDs:")
-	for oid, oidType := range agent.ListOIDs() {
-		log.Info("  -", "oid", oid, "type", oidType)
+	for _, info := range agent.ListOIDs() {
+		log.Info("  -", "oid", info.OID, "kind", info.Kind, "type", info.Type)
 	}
 
 	// 启动 Agent
@@ -115,10 +129,21 @@ func main() {
 	log.Info("Test with: snmpget -v2c -c public 127.0.0.1:1161 " + agent.GetPrefix() + ".1.1.0")
 	log.Info("Or: snmpwalk -v2c -c public 127.0.0.1:1161 " + agent.GetPrefix())
 
-	// 等待中断信号
+	// 等待中断信号，SIGHUP 重新加载配置文件
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	<-sigChan
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	for sig := range sigChan {
+		if sig != syscall.SIGHUP {
+			break
+		}
+		if configPath == "" {
+			log.Warn("No config file to reload")
+			continue
+		}
+		if err := agent.ReloadFromFile(configPath); err != nil {
+			log.Error("Failed to reload config", "error", err)
+		}
+	}
 
 	log.Info("Shutting down...")
 
@@ -0,0 +1,120 @@
+package lzsnmp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// 外部程序通过环境变量获得的请求信息
+const (
+	ExecEnvOID  = "LZSNMP_OID"      // 请求的绝对 OID，不带前导点
+	ExecEnvType = "LZSNMP_OID_TYPE" // OID 的类型名称，如 "OctetString"
+)
+
+// maxExecOutput 外部程序标准输出的大小上限，超出时返回错误
+const maxExecOutput = 64 * 1024
+
+// execWaitDelay 超时杀死进程后等待其子进程关闭输出管道的时间
+const execWaitDelay = time.Second
+
+// RegisterExec 注册由外部程序提供值的相对 OID，类似 net-snmp 的 extend
+//
+// 每次 GET 运行一次 cmd（cmd[0] 为程序，其余为参数，不经过 shell），标准输出去掉末尾换行后按 oidType 解析为值，
+// 格式同 ParseValue。超过 timeout 的进程被杀死，退出码非 0 时返回错误。
+// 请求的 OID 和类型通过环境变量 LZSNMP_OID、LZSNMP_OID_TYPE 传给程序。
+// 程序较慢时可以将 ExecHandler 与 RegisterCached 组合使用。
+func (a *Agent) RegisterExec(relativeOID string, oidType gosnmp.Asn1BER, cmd []string, timeout time.Duration) error {
+	absoluteOID := fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID)
+	return a.RegisterExecAbsolute(absoluteOID, oidType, cmd, timeout)
+}
+
+// RegisterExecAbsolute 注册由外部程序提供值的绝对路径 OID
+func (a *Agent) RegisterExecAbsolute(oid string, oidType gosnmp.Asn1BER, cmd []string, timeout time.Duration) error {
+	handler, err := ExecHandler(oid, oidType, cmd, timeout)
+	if err != nil {
+		return err
+	}
+	return a.registerDynamic(oid, oidType, handler, nil)
+}
+
+// ExecHandler 返回运行外部程序的处理函数，oid 为绝对 OID，用于设置环境变量，行为同 RegisterExec
+func ExecHandler(oid string, oidType gosnmp.Asn1BER, cmd []string, timeout time.Duration) (ValueHandler, error) {
+	oid, err := normalizeOID(oid)
+	if err != nil {
+		return nil, err
+	}
+	if len(cmd) == 0 || cmd[0] == "" {
+		return nil, fmt.Errorf("command is required for exec OID: %s", oid)
+	}
+	if timeout <= 0 {
+		return nil, fmt.Errorf("exec timeout must be positive for OID: %s", oid)
+	}
+
+	args := append([]string(nil), cmd...)
+	env := append(os.Environ(), ExecEnvOID+"="+oid, ExecEnvType+"="+oidType.String())
+	return func() (interface{}, error) {
+		out, err := runExec(args, env, timeout)
+		if err != nil {
+			return nil, err
+		}
+		text := strings.TrimRight(out, "\r\n")
+		value, err := parseValueText(oidType, text, false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output of %s for %s: %w", args[0], oidType, err)
+		}
+		return value, nil
+	}, nil
+}
+
+// runExec 运行外部程序并返回标准输出
+func runExec(args, env []string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	c := exec.CommandContext(ctx, args[0], args[1:]...)
+	c.Env = env
+	c.Stdout = &limitedBuffer{buf: &stdout, max: maxExecOutput}
+	c.Stderr = &limitedBuffer{buf: &stderr, max: 1024}
+	c.WaitDelay = execWaitDelay
+
+	err := c.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("%s timed out after %s", args[0], timeout)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s exited with code %d: %s", args[0], exitErr.ExitCode(), msg)
+		}
+		return "", fmt.Errorf("%s exited with code %d", args[0], exitErr.ExitCode())
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+	if stdout.Len() > maxExecOutput {
+		return "", fmt.Errorf("output of %s exceeds %d bytes", args[0], maxExecOutput)
+	}
+	return stdout.String(), nil
+}
+
+// limitedBuffer 最多保存 max+1 字节，多余的输出被丢弃，用于判断是否超出上限
+type limitedBuffer struct {
+	buf *bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max + 1 - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
@@ -0,0 +1,410 @@
+package lzsnmp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// expression 由 RegisterExpression 注册的表达式
+//
+// 语法：数字、{OID}、+ - * /、括号，以及 sum / avg / min / max / count({子树})。
+// OID 以 "." 开头时为绝对 OID，否则相对于企业 OID。
+type expression struct {
+	text string
+	root exprNode
+	refs []exprRef // 引用的 OID 和子树，用于检测循环引用
+}
+
+// exprRef 表达式引用的 OID，subtree 为 true 时引用子树下的所有 OID
+type exprRef struct {
+	oid     string
+	subtree bool
+}
+
+// exprNode 表达式语法树的节点
+type exprNode interface {
+	eval(a *Agent, s *oidStore) (float64, error)
+}
+
+type numberNode float64
+
+func (n numberNode) eval(*Agent, *oidStore) (float64, error) {
+	return float64(n), nil
+}
+
+// oidNode 引用一个 OID 的当前值
+type oidNode string
+
+func (n oidNode) eval(a *Agent, s *oidStore) (float64, error) {
+	oid := string(n)
+	oidType, ok := s.types[oid]
+	if !ok {
+		return 0, fmt.Errorf("OID %s is not registered", oid)
+	}
+	if !isNumericType(oidType) {
+		return 0, fmt.Errorf("OID %s is %v, not numeric", oid, oidType)
+	}
+	_, value, err := a.readOID(oid)
+	if err != nil {
+		return 0, fmt.Errorf("OID %s: %w", oid, err)
+	}
+	return toFloat64(value)
+}
+
+// aggregateNode 对子树下所有数值类型的 OID 求聚合值
+type aggregateNode struct {
+	fn     string
+	prefix string
+}
+
+func (n aggregateNode) eval(a *Agent, s *oidStore) (float64, error) {
+	var values []float64
+	for _, oid := range s.sortedOIDs() {
+		if !hasOIDPrefix(oid, n.prefix) || !isNumericType(s.types[oid]) {
+			continue
+		}
+		_, value, err := a.readOID(oid)
+		if errors.Is(err, ErrNoSuchInstance) {
+			// 稀疏表格中的空洞不参与聚合
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("OID %s: %w", oid, err)
+		}
+		f, err := toFloat64(value)
+		if err != nil {
+			return 0, fmt.Errorf("OID %s: %w", oid, err)
+		}
+		values = append(values, f)
+	}
+
+	switch n.fn {
+	case "count":
+		return float64(len(values)), nil
+	case "sum":
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		return sum, nil
+	}
+	if len(values) == 0 {
+		return 0, fmt.Errorf("%s: no numeric OID under %s", n.fn, n.prefix)
+	}
+	result := values[0]
+	for _, v := range values[1:] {
+		switch n.fn {
+		case "avg":
+			result += v
+		case "min":
+			result = min(result, v)
+		case "max":
+			result = max(result, v)
+		}
+	}
+	if n.fn == "avg" {
+		result /= float64(len(values))
+	}
+	return result, nil
+}
+
+type negNode struct {
+	x exprNode
+}
+
+func (n negNode) eval(a *Agent, s *oidStore) (float64, error) {
+	v, err := n.x.eval(a, s)
+	return -v, err
+}
+
+type binaryNode struct {
+	op   byte
+	l, r exprNode
+}
+
+func (n binaryNode) eval(a *Agent, s *oidStore) (float64, error) {
+	l, err := n.l.eval(a, s)
+	if err != nil {
+		return 0, err
+	}
+	r, err := n.r.eval(a, s)
+	if err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	}
+	if r == 0 {
+		return 0, fmt.Errorf("division by zero")
+	}
+	return l / r, nil
+}
+
+// exprParser 递归下降解析表达式
+type exprParser struct {
+	text   string
+	pos    int
+	prefix string // 企业 OID，用于解析相对 OID
+	refs   []exprRef
+}
+
+// parseExpression 解析表达式，相对 OID 基于 prefix
+func parseExpression(text, prefix string) (*expression, error) {
+	p := &exprParser{text: text, prefix: prefix}
+	root, err := p.parseSum()
+	if err == nil {
+		if p.skipSpace(); p.pos < len(p.text) {
+			err = p.errorf("unexpected %q", p.text[p.pos])
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("expression %q: %w", text, err)
+	}
+	return &expression{text: text, root: root, refs: p.refs}, nil
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s at offset %d", fmt.Sprintf(format, args...), p.pos)
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.text) && unicode.IsSpace(rune(p.text[p.pos])) {
+		p.pos++
+	}
+}
+
+// peek 跳过空白后返回下一个字符，到达末尾时返回 0
+func (p *exprParser) peek() byte {
+	p.skipSpace()
+	if p.pos < len(p.text) {
+		return p.text[p.pos]
+	}
+	return 0
+}
+
+// parseSum 解析 term (('+' | '-') term)*
+func (p *exprParser) parseSum() (exprNode, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '+' || op == '-'; op = p.peek() {
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, l: left, r: right}
+	}
+	return left, nil
+}
+
+// parseProduct 解析 unary (('*' | '/') unary)*
+func (p *exprParser) parseProduct() (exprNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '*' || op == '/'; op = p.peek() {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, l: left, r: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.peek() == '-' {
+		p.pos++
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negNode{x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	switch c := p.peek(); {
+	case c == 0:
+		return nil, p.errorf("unexpected end of expression")
+	case c == '(':
+		p.pos++
+		x, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, p.errorf("missing )")
+		}
+		p.pos++
+		return x, nil
+	case c == '{':
+		oid, err := p.parseOID(false)
+		if err != nil {
+			return nil, err
+		}
+		return oidNode(oid), nil
+	case c >= '0' && c <= '9' || c == '.':
+		start := p.pos
+		for p.pos < len(p.text) && (p.text[p.pos] >= '0' && p.text[p.pos] <= '9' || p.text[p.pos] == '.') {
+			p.pos++
+		}
+		v, err := strconv.ParseFloat(p.text[start:p.pos], 64)
+		if err != nil {
+			p.pos = start
+			return nil, p.errorf("invalid number %q", p.text[start:p.pos])
+		}
+		return numberNode(v), nil
+	case c >= 'a' && c <= 'z':
+		start := p.pos
+		for p.pos < len(p.text) && p.text[p.pos] >= 'a' && p.text[p.pos] <= 'z' {
+			p.pos++
+		}
+		fn := p.text[start:p.pos]
+		switch fn {
+		case "sum", "avg", "min", "max", "count":
+		default:
+			p.pos = start
+			return nil, p.errorf("unknown function %q", fn)
+		}
+		if p.peek() != '(' {
+			return nil, p.errorf("missing ( after %s", fn)
+		}
+		p.pos++
+		if p.peek() != '{' {
+			return nil, p.errorf("%s takes a {subtree} argument", fn)
+		}
+		prefix, err := p.parseOID(true)
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, p.errorf("missing )")
+		}
+		p.pos++
+		return aggregateNode{fn: fn, prefix: prefix}, nil
+	default:
+		return nil, p.errorf("unexpected %q", c)
+	}
+}
+
+// parseOID 解析 {OID} 并记录引用，当前位置为 '{'
+func (p *exprParser) parseOID(subtree bool) (string, error) {
+	end := strings.IndexByte(p.text[p.pos:], '}')
+	if end < 0 {
+		return "", p.errorf("missing }")
+	}
+	text := strings.TrimSpace(p.text[p.pos+1 : p.pos+end])
+	if !strings.HasPrefix(text, ".") {
+		text = p.prefix + "." + text
+	}
+	oid, err := normalizeOID(text)
+	if err != nil {
+		return "", p.errorf("%v", err)
+	}
+	p.pos += end + 1
+	p.refs = append(p.refs, exprRef{oid: oid, subtree: subtree})
+	return oid, nil
+}
+
+// RegisterExpression 注册值由表达式计算的相对 OID，见 RegisterExpressionAbsolute
+func (a *Agent) RegisterExpression(relativeOID string, oidType gosnmp.Asn1BER, expr string) error {
+	absoluteOID := fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID)
+	return a.RegisterExpressionAbsolute(absoluteOID, oidType, expr)
+}
+
+// RegisterExpressionAbsolute 注册值由其他 OID 计算得出的绝对路径 OID，每次请求时求值
+//
+// expr 支持数字、{OID}、+ - * /、括号，以及对子树下所有数值类型 OID 求值的 sum / avg / min / max / count，
+// 如 "sum({5.1.3})" 对表格的一列求和，"{2.1.0} / {2.2.0} * 100" 计算两个 Gauge 的百分比。
+// {} 中的 OID 以 "." 开头时为绝对 OID，否则相对于企业 OID。结果按 oidType 取整和截断到取值范围，
+// oidType 必须为数值类型。表达式直接或间接引用自身时返回错误；引用的 OID 未注册、不是数值类型、
+// 读取失败或除以 0 时，请求得到处理函数错误。
+func (a *Agent) RegisterExpressionAbsolute(oid string, oidType gosnmp.Asn1BER, expr string) error {
+	if !isNumericType(oidType) {
+		return fmt.Errorf("expression OID %s must have a numeric type, got %v", oid, oidType)
+	}
+	oid, err := normalizeOID(oid)
+	if err != nil {
+		return err
+	}
+	e, err := parseExpression(expr, a.oidPrefix)
+	if err != nil {
+		return err
+	}
+
+	handler := func() (interface{}, error) {
+		v, err := e.root.eval(a, a.store.Load())
+		if err != nil {
+			return nil, err
+		}
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("expression %q evaluated to %v", e.text, v)
+		}
+		return variedValue(oidType, v), nil
+	}
+	return a.updateStore(func(s *oidStore) error {
+		if err := s.checkLeaf(a.store.Load(), oid); err != nil {
+			return err
+		}
+		if _, exists := s.types[oid]; exists {
+			a.logger.Warn("OID already registered, overwriting", "oid", oid)
+		}
+		s.putDynamic(oid, oidType, handler, nil)
+		s.exprs[oid] = e
+		if cycle := s.exprCycle(oid); cycle != nil {
+			return fmt.Errorf("expression for %s has a circular reference: %s", oid, strings.Join(cycle, " -> "))
+		}
+		a.logger.Info("Registered expression OID", "oid", oid, "type", oidType, "expr", expr)
+		return nil
+	})
+}
+
+// exprCycle 从 start 沿表达式引用查找回到 start 的路径，没有循环时返回 nil
+func (s *oidStore) exprCycle(start string) []string {
+	visited := make(map[string]bool)
+	var path []string
+	var visit func(oid string) bool
+	visit = func(oid string) bool {
+		path = append(path, oid)
+		for _, ref := range s.exprs[oid].refs {
+			for dep := range s.exprs {
+				if !(dep == ref.oid || ref.subtree && hasOIDPrefix(dep, ref.oid)) {
+					continue
+				}
+				if dep == start {
+					path = append(path, dep)
+					return true
+				}
+				if !visited[dep] {
+					visited[dep] = true
+					if visit(dep) {
+						return true
+					}
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+	if visit(start) {
+		return path
+	}
+	return nil
+}
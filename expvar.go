@@ -0,0 +1,222 @@
+//go:build !lzsnmp_noexpvar
+
+package lzsnmp
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// expvarLeaf expvar 中可轮询的叶子变量
+type expvarLeaf struct {
+	name    string
+	path    []string
+	oidType gosnmp.Asn1BER
+}
+
+// expvarBridge 将 expvar 发布的变量映射到 OID 子树
+type expvarBridge struct {
+	agent *Agent
+	root  string
+
+	mu        sync.Mutex
+	instances []string
+	scannedAt time.Time
+	jsonCache map[string]interface{}
+}
+
+// RegisterExpvar 将 expvar 发布的变量映射到相对 OID prefix 下
+//
+// 变量名按字符串索引编码（长度 + ASCII），expvar.Map 的键逐级追加，
+// 例如 prefix.{len}.{name...}.{len}.{key...}。类型映射：
+// expvar.Int 为 Counter64（负值读取失败），expvar.Float 为 OpaqueDouble，expvar.String 为 OctetString；
+// expvar.Func 等其他变量按 JSON 展开，对象逐级展开，数字为 OpaqueDouble，
+// 字符串为 OctetString，布尔为 TruthValue，数组忽略。
+// 每次 GET 时最多每秒重新扫描一次，新发布的变量和 Map 键会自动出现。
+func (a *Agent) RegisterExpvar(prefix string) error {
+	prefix = strings.Trim(prefix, ".")
+	if prefix == "" {
+		return fmt.Errorf("expvar prefix is required")
+	}
+
+	b := &expvarBridge{
+		agent: a,
+		root:  fmt.Sprintf("%s.%s", a.oidPrefix, prefix),
+	}
+
+	if err := a.claimSubtree(b.root, "expvar bridge"); err != nil {
+		return err
+	}
+	a.annotateGroup(b.root, OIDMeta{Name: "expvar", Description: "Variables published via the expvar package"})
+
+	b.mu.Lock()
+	count := b.scanLocked()
+	b.mu.Unlock()
+
+	a.logger.Info("Registered expvar bridge", "oid", b.root, "vars", count)
+	return nil
+}
+
+// scanLocked 扫描 expvar 并重建实例 OID，返回叶子数量
+func (b *expvarBridge) scanLocked() int {
+	b.jsonCache = make(map[string]interface{})
+	b.scannedAt = time.Now()
+
+	leaves := make(map[string]expvarLeaf)
+	expvar.Do(func(kv expvar.KeyValue) {
+		b.collect(leaves, kv.Key, nil, kv.Value)
+	})
+
+	add := make(map[string]dynamicOID, len(leaves))
+	instances := make([]string, 0, len(leaves))
+	for oid, leaf := range leaves {
+		add[oid] = dynamicOID{Type: leaf.oidType, Handler: b.getter(leaf)}
+		instances = append(instances, oid)
+	}
+	sort.Strings(instances)
+
+	if !slices.Equal(b.instances, instances) {
+		b.agent.replaceDynamic(b.instances, add)
+		b.instances = instances
+	}
+	return len(leaves)
+}
+
+// collect 递归收集变量的叶子
+func (b *expvarBridge) collect(leaves map[string]expvarLeaf, name string, path []string, v expvar.Var) {
+	switch tv := v.(type) {
+	case *expvar.Int:
+		b.addLeaf(leaves, name, path, gosnmp.Counter64)
+	case *expvar.Float:
+		b.addLeaf(leaves, name, path, gosnmp.OpaqueDouble)
+	case *expvar.String:
+		b.addLeaf(leaves, name, path, gosnmp.OctetString)
+	case *expvar.Map:
+		tv.Do(func(kv expvar.KeyValue) {
+			b.collect(leaves, name, appendPath(path, kv.Key), kv.Value)
+		})
+	default:
+		decoded, err := b.decodeJSON(name, path, v)
+		if err != nil {
+			b.agent.logger.Warn("Skipping expvar", "name", name, "error", err)
+			return
+		}
+		b.collectJSON(leaves, name, path, decoded)
+	}
+}
+
+// collectJSON 递归收集 JSON 值的叶子
+func (b *expvarBridge) collectJSON(leaves map[string]expvarLeaf, name string, path []string, v interface{}) {
+	switch tv := v.(type) {
+	case float64:
+		b.addLeaf(leaves, name, path, gosnmp.OpaqueDouble)
+	case string:
+		b.addLeaf(leaves, name, path, gosnmp.OctetString)
+	case bool:
+		b.addLeaf(leaves, name, path, gosnmp.Integer)
+	case map[string]interface{}:
+		for key, child := range tv {
+			b.collectJSON(leaves, name, appendPath(path, key), child)
+		}
+	}
+}
+
+func (b *expvarBridge) addLeaf(leaves map[string]expvarLeaf, name string, path []string, oidType gosnmp.Asn1BER) {
+	parts := []string{b.root, encodeOctetsIndex([]byte(name), false)}
+	for _, p := range path {
+		parts = append(parts, encodeOctetsIndex([]byte(p), false))
+	}
+	leaves[strings.Join(parts, ".")] = expvarLeaf{name: name, path: path, oidType: oidType}
+}
+
+// decodeJSON 解析非原生变量的 JSON，同一扫描周期内按变量缓存
+func (b *expvarBridge) decodeJSON(name string, path []string, v expvar.Var) (interface{}, error) {
+	key := strings.Join(appendPath(path, name), "\x00")
+	if cached, ok := b.jsonCache[key]; ok {
+		return cached, nil
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(v.String()), &decoded); err != nil {
+		return nil, err
+	}
+	b.jsonCache[key] = decoded
+	return decoded, nil
+}
+
+func (b *expvarBridge) getter(leaf expvarLeaf) ValueHandler {
+	return func() (interface{}, error) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if time.Since(b.scannedAt) > tableRefreshInterval {
+			b.scanLocked()
+		}
+		return b.value(leaf)
+	}
+}
+
+// value 读取叶子的当前值
+func (b *expvarBridge) value(leaf expvarLeaf) (interface{}, error) {
+	v := expvar.Get(leaf.name)
+	for i, key := range leaf.path {
+		m, ok := v.(*expvar.Map)
+		if !ok {
+			return b.jsonValue(leaf, v, i)
+		}
+		v = m.Get(key)
+	}
+
+	switch tv := v.(type) {
+	case *expvar.Int:
+		return tv.Value(), nil
+	case *expvar.Float:
+		return tv.Value(), nil
+	case *expvar.String:
+		return tv.Value(), nil
+	case nil:
+		return nil, fmt.Errorf("expvar %s no longer exists", leaf.name)
+	}
+	return b.jsonValue(leaf, v, len(leaf.path))
+}
+
+// jsonValue 从 JSON 展开的变量中读取 path[from:] 指向的值
+func (b *expvarBridge) jsonValue(leaf expvarLeaf, v expvar.Var, from int) (interface{}, error) {
+	if v == nil {
+		return nil, fmt.Errorf("expvar %s no longer exists", leaf.name)
+	}
+
+	decoded, err := b.decodeJSON(leaf.name, leaf.path[:from], v)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range leaf.path[from:] {
+		obj, ok := decoded.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expvar %s: %s is not an object", leaf.name, key)
+		}
+		decoded = obj[key]
+	}
+
+	switch tv := decoded.(type) {
+	case float64, string:
+		return tv, nil
+	case bool:
+		return TruthValue(tv), nil
+	}
+	return nil, fmt.Errorf("expvar %s: unexpected value %v", leaf.name, decoded)
+}
+
+func appendPath(path []string, key string) []string {
+	out := make([]string, len(path), len(path)+1)
+	copy(out, path)
+	return append(out, key)
+}
@@ -0,0 +1,159 @@
+//go:build !lzsnmp_nofsnotify
+
+package lzsnmp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gosnmp/gosnmp"
+)
+
+// FileParser 将文件内容解析为 OID 的值
+type FileParser func(data []byte) (interface{}, error)
+
+// WatchedFile 由 RegisterFile 注册的文件
+type WatchedFile struct {
+	agent   *Agent
+	oid     string
+	path    string
+	oidType gosnmp.Asn1BER
+	parser  FileParser
+	watcher *fsnotify.Watcher
+
+	mu    sync.RWMutex
+	value interface{}
+	err   error
+	done  chan struct{}
+}
+
+// RegisterFile 注册值来自文件的相对 OID，文件变化时自动重新读取
+//
+// parser 为 nil 时，文件内容去掉首尾空白后按 oidType 解析，格式同 ParseValue。
+// 监听的是文件所在目录，支持先写临时文件再 rename 的原子替换；重新读取或解析失败时保留上一次的值，
+// 文件被删除后 GET 返回错误，直到文件重新出现。适用于 cron 任务或其他守护进程写入的值。
+func (a *Agent) RegisterFile(relativeOID string, oidType gosnmp.Asn1BER, path string, parser FileParser) (*WatchedFile, error) {
+	absoluteOID := fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID)
+	return a.RegisterFileAbsolute(absoluteOID, oidType, path, parser)
+}
+
+// RegisterFileAbsolute 注册值来自文件的绝对路径 OID
+func (a *Agent) RegisterFileAbsolute(oid string, oidType gosnmp.Asn1BER, path string, parser FileParser) (*WatchedFile, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file path for OID %s: %w", oid, err)
+	}
+	if parser == nil {
+		parser = func(data []byte) (interface{}, error) {
+			return parseValueText(oidType, strings.TrimSpace(string(data)), false)
+		}
+	}
+
+	f := &WatchedFile{
+		agent:   a,
+		oid:     oid,
+		path:    path,
+		oidType: oidType,
+		parser:  parser,
+		done:    make(chan struct{}),
+	}
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch %s: %w", path, err)
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("failed to watch %s: %w", path, err)
+	}
+	f.watcher = watcher
+
+	if err := a.registerDynamic(oid, oidType, f.get, nil); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	go f.watch()
+
+	a.logger.Info("Registered file-backed OID", "oid", oid, "path", path)
+	return f, nil
+}
+
+// Close 停止监听文件并注销 OID
+func (f *WatchedFile) Close() error {
+	if err := f.watcher.Close(); err != nil {
+		return err
+	}
+	<-f.done
+	return f.agent.UnregisterAbsolute(f.oid)
+}
+
+func (f *WatchedFile) get() (interface{}, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.value, nil
+}
+
+// load 读取并解析文件
+func (f *WatchedFile) load() error {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", f.path, err)
+	}
+	value, err := f.parser(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", f.path, err)
+	}
+	if _, err := normalizeValue(f.oidType, value); err != nil {
+		return fmt.Errorf("invalid value in %s: %w", f.path, err)
+	}
+
+	f.mu.Lock()
+	f.value, f.err = value, nil
+	f.mu.Unlock()
+	return nil
+}
+
+// watch 处理目录中该文件的变化事件，直到 watcher 关闭
+func (f *WatchedFile) watch() {
+	defer close(f.done)
+	for {
+		select {
+		case event, ok := <-f.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != f.path {
+				continue
+			}
+			if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+				if _, err := os.Stat(f.path); os.IsNotExist(err) {
+					f.mu.Lock()
+					f.err = fmt.Errorf("file %s was removed", f.path)
+					f.mu.Unlock()
+					f.agent.logger.Warn("Watched file removed", "oid", f.oid, "path", f.path)
+					continue
+				}
+			}
+			if err := f.load(); err != nil {
+				f.agent.logger.Warn("Failed to reload watched file, keeping previous value", "oid", f.oid, "error", err)
+				continue
+			}
+			f.agent.logger.Debug("Reloaded watched file", "oid", f.oid, "path", f.path)
+		case err, ok := <-f.watcher.Errors:
+			if !ok {
+				return
+			}
+			f.agent.logger.Error("File watcher error", "oid", f.oid, "path", f.path, "error", err)
+		}
+	}
+}
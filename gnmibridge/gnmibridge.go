@@ -0,0 +1,138 @@
+// Package gnmibridge 将通过 gNMI 获取的 OpenConfig 路径映射为 SNMP OID
+//
+// 用于只支持 gNMI 的设备：Agent 作为转换器，现有 SNMP 轮询器无需改动即可采集，
+// 路径的变化也可以通过 Subscribe 作为 SNMP 通知发给 Agent 的通知目标。
+// 本包不依赖 gRPC，gNMI 连接由调用方通过 Client、Subscriber 接口提供，通常是对
+// github.com/openconfig/gnmi/proto/gnmi 中 GNMIClient.Get、GNMIClient.Subscribe 的几行包装。
+package gnmibridge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	lzsnmp "github.com/liuzhen9320/snmp-go"
+)
+
+// Client 读取 OpenConfig 路径当前值的 gNMI 客户端
+//
+// Get 返回 paths 中每个路径的标量值（整数、浮点数、bool 或字符串，即 gNMI TypedValue 解出的值），
+// 设备上不存在的路径不出现在结果中。
+type Client interface {
+	Get(ctx context.Context, paths []string) (map[string]interface{}, error)
+}
+
+// Mapping 一个 OpenConfig 路径到 OID 的映射
+type Mapping struct {
+	Path string         // 如 /interfaces/interface[name=eth0]/state/counters/in-octets
+	OID  string         // 相对企业前缀的 OID
+	Type gosnmp.Asn1BER // SNMP 类型，bool 值映射为 TruthValue 整数（true=1，false=2）
+}
+
+// Options 桥接配置
+type Options struct {
+	Timeout time.Duration // 单次 gNMI Get 超时，默认 5 秒
+	MaxAge  time.Duration // 采集结果的缓存时长，默认 1 秒；一次 SNMP WALK 只触发一次 gNMI Get
+}
+
+// Bridge 已注册的 gNMI 路径映射
+type Bridge struct {
+	agent    *lzsnmp.Agent
+	client   Client
+	opts     Options
+	paths    []string
+	mappings []Mapping
+
+	mu        sync.Mutex
+	values    map[string]interface{}
+	fetchedAt time.Time
+	err       error
+}
+
+// Register 将 mappings 中的路径注册为 agent 的相对 OID，所有 OID 一次注册，任何一项无效时不注册
+func Register(agent *lzsnmp.Agent, client Client, mappings []Mapping, opts Options) (*Bridge, error) {
+	if client == nil {
+		return nil, fmt.Errorf("gNMI client is required")
+	}
+	if len(mappings) == 0 {
+		return nil, fmt.Errorf("at least one gNMI path mapping is required")
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = time.Second
+	}
+
+	b := &Bridge{agent: agent, client: client, opts: opts, mappings: mappings}
+	entries := make([]lzsnmp.OIDEntry, 0, len(mappings))
+	seen := make(map[string]bool, len(mappings))
+	for _, m := range mappings {
+		if !strings.HasPrefix(m.Path, "/") {
+			return nil, fmt.Errorf("gNMI path must be absolute: %q", m.Path)
+		}
+		if !seen[m.Path] {
+			seen[m.Path] = true
+			b.paths = append(b.paths, m.Path)
+		}
+		entries = append(entries, lzsnmp.OIDEntry{OID: m.OID, Type: m.Type, Handler: b.getter(m.Path)})
+	}
+
+	if err := agent.RegisterBatch(entries); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Close 注销桥接注册的所有 OID
+func (b *Bridge) Close() {
+	for _, m := range b.mappings {
+		b.agent.Unregister(strings.TrimPrefix(m.OID, "."))
+	}
+}
+
+func (b *Bridge) getter(path string) lzsnmp.ValueHandler {
+	return func() (interface{}, error) {
+		values, err := b.snapshot()
+		if err != nil {
+			return nil, err
+		}
+		value, ok := values[path]
+		if !ok {
+			return nil, fmt.Errorf("gNMI path not found on device: %s", path)
+		}
+		return snmpValue(value), nil
+	}
+}
+
+// snmpValue 将 gNMI 的 bool 值转换为 TruthValue，其他值不变
+func snmpValue(value interface{}) interface{} {
+	if v, ok := value.(bool); ok {
+		return lzsnmp.TruthValue(v)
+	}
+	return value
+}
+
+// snapshot 返回缓存的采集结果，过期时用一次 gNMI Get 采集所有路径
+func (b *Bridge) snapshot() (map[string]interface{}, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if time.Since(b.fetchedAt) < b.opts.MaxAge {
+		return b.values, b.err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.opts.Timeout)
+	defer cancel()
+	values, err := b.client.Get(ctx, b.paths)
+	b.fetchedAt = time.Now()
+	if err != nil {
+		b.values, b.err = nil, fmt.Errorf("gNMI get: %w", err)
+	} else {
+		b.values, b.err = values, nil
+	}
+	return b.values, b.err
+}
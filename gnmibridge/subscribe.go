@@ -0,0 +1,160 @@
+package gnmibridge
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	lzsnmp "github.com/liuzhen9320/snmp-go"
+)
+
+// Subscriber 订阅 OpenConfig 路径变化的 gNMI 客户端
+//
+// Subscribe 对 paths 建立 STREAM 模式的 gNMI 订阅（通常为 ON_CHANGE），每收到一个 Update 调用 update，
+// 值与 Client.Get 的结果相同；阻塞直到 ctx 取消或订阅流出错。
+type Subscriber interface {
+	Subscribe(ctx context.Context, paths []string, update func(path string, value interface{})) error
+}
+
+// Notify 一个 OpenConfig 路径到通知的映射：路径的值改变时发送 TrapOID，变量为 OID 和新值
+type Notify struct {
+	Path    string         // 订阅的路径
+	TrapOID string         // 相对企业前缀的通知 OID
+	OID     string         // 随通知发送的变量，相对企业前缀
+	Type    gosnmp.Asn1BER // 变量的 SNMP 类型，bool 值同 Mapping 映射为 TruthValue
+	Tags    []string       // 只发送给带有任一标签的通知目标，为空时发送给所有目标
+}
+
+// SubscribeOptions 订阅配置
+type SubscribeOptions struct {
+	Retry time.Duration // 订阅流出错后重新订阅的间隔，默认 10 秒
+	// OnError 订阅流出错或收到的值无法转换为变量类型时调用，通知发送失败由 Agent 记录日志
+	OnError func(err error)
+}
+
+// Subscription 进行中的 gNMI 订阅
+type Subscription struct {
+	agent  *lzsnmp.Agent
+	sub    Subscriber
+	opts   SubscribeOptions
+	paths  []string
+	byPath map[string][]Notify // 路径 → 通知
+
+	mu   sync.Mutex
+	last map[string]interface{} // 路径最近一次收到的值
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Subscribe 订阅 notifies 中的路径，路径的值改变时通过 agent.SendTrap 发送对应的通知
+//
+// 每个路径第一次收到的值（订阅建立时的初始同步）只作为基准，之后值与上一次不同时才发送通知，
+// SAMPLE 模式重复上报的相同值不会产生通知；重新订阅后基准保留。任何一项无效时不订阅。
+func Subscribe(agent *lzsnmp.Agent, sub Subscriber, notifies []Notify, opts SubscribeOptions) (*Subscription, error) {
+	if sub == nil {
+		return nil, fmt.Errorf("gNMI subscriber is required")
+	}
+	if len(notifies) == 0 {
+		return nil, fmt.Errorf("at least one gNMI notification mapping is required")
+	}
+	if opts.Retry <= 0 {
+		opts.Retry = 10 * time.Second
+	}
+
+	s := &Subscription{
+		agent:  agent,
+		sub:    sub,
+		opts:   opts,
+		byPath: make(map[string][]Notify, len(notifies)),
+		last:   make(map[string]interface{}, len(notifies)),
+		done:   make(chan struct{}),
+	}
+	for _, n := range notifies {
+		if !strings.HasPrefix(n.Path, "/") {
+			return nil, fmt.Errorf("gNMI path must be absolute: %q", n.Path)
+		}
+		if n.TrapOID == "" || n.OID == "" {
+			return nil, fmt.Errorf("trap OID and variable OID are required for gNMI path %s", n.Path)
+		}
+		if _, ok := s.byPath[n.Path]; !ok {
+			s.paths = append(s.paths, n.Path)
+		}
+		s.byPath[n.Path] = append(s.byPath[n.Path], n)
+	}
+
+	var ctx context.Context
+	ctx, s.cancel = context.WithCancel(context.Background())
+	go s.run(ctx)
+	return s, nil
+}
+
+// Close 取消订阅，等待订阅协程退出
+func (s *Subscription) Close() {
+	s.cancel()
+	<-s.done
+}
+
+// run 保持订阅，订阅流出错后等待 Retry 重新订阅
+func (s *Subscription) run(ctx context.Context) {
+	defer close(s.done)
+	for {
+		err := s.sub.Subscribe(ctx, s.paths, s.update)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = fmt.Errorf("subscription stream ended")
+		}
+		s.fail(fmt.Errorf("gNMI subscribe: %w", err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.opts.Retry):
+		}
+	}
+}
+
+// update 记录路径的新值，值改变时发送通知
+func (s *Subscription) update(path string, value interface{}) {
+	notifies, ok := s.byPath[path]
+	if !ok {
+		return
+	}
+	value = snmpValue(value)
+
+	s.mu.Lock()
+	last, seen := s.last[path]
+	s.last[path] = value
+	s.mu.Unlock()
+	if !seen || reflect.DeepEqual(last, value) {
+		return
+	}
+
+	prefix := s.agent.GetPrefix()
+	for _, n := range notifies {
+		// 按变量类型规范化，gNMI 的整数、浮点数等统一为 SNMP 编码使用的类型
+		text, hex, err := lzsnmp.FormatValue(n.Type, value)
+		var v interface{}
+		if err == nil {
+			v, err = lzsnmp.ParseValue(n.Type, text, hex)
+		}
+		if err != nil {
+			s.fail(fmt.Errorf("gNMI path %s: invalid %s value %v: %w", path, n.Type, value, err))
+			continue
+		}
+		vars := []gosnmp.SnmpPDU{{Name: fmt.Sprintf(".%s.%s", prefix, strings.Trim(n.OID, ".")), Type: n.Type, Value: v}}
+		s.agent.SendTrap(fmt.Sprintf("%s.%s", prefix, strings.Trim(n.TrapOID, ".")), vars, n.Tags...)
+	}
+}
+
+func (s *Subscription) fail(err error) {
+	if s.opts.OnError != nil {
+		s.opts.OnError(err)
+	}
+}
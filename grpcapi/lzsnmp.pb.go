@@ -0,0 +1,1146 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: lzsnmp.proto
+
+package grpcapi
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Value 带类型的 SNMP 值
+type Value struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 类型名称，如 "OctetString"、"Gauge32"、"Counter64"（不区分大小写）
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// 值的文本形式，如 "42"、"eth0"、"10.0.0.1"
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// OctetString 的 value 为十六进制编码
+	Hex bool `protobuf:"varint,3,opt,name=hex,proto3" json:"hex,omitempty"`
+}
+
+func (x *Value) Reset() {
+	*x = Value{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lzsnmp_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Value) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Value) ProtoMessage() {}
+
+func (x *Value) ProtoReflect() protoreflect.Message {
+	mi := &file_lzsnmp_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Value.ProtoReflect.Descriptor instead.
+func (*Value) Descriptor() ([]byte, []int) {
+	return file_lzsnmp_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Value) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Value) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Value) GetHex() bool {
+	if x != nil {
+		return x.Hex
+	}
+	return false
+}
+
+type RegisterOIDRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 绝对 OID，如 "1.3.6.1.4.1.99999.1.0"
+	Oid string `protobuf:"bytes,1,opt,name=oid,proto3" json:"oid,omitempty"`
+	// 初始值，类型在注册后不能改变
+	Initial *Value `protobuf:"bytes,2,opt,name=initial,proto3" json:"initial,omitempty"`
+	// 文档名称和说明，可选
+	Name        string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Description string `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+}
+
+func (x *RegisterOIDRequest) Reset() {
+	*x = RegisterOIDRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lzsnmp_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegisterOIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterOIDRequest) ProtoMessage() {}
+
+func (x *RegisterOIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lzsnmp_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterOIDRequest.ProtoReflect.Descriptor instead.
+func (*RegisterOIDRequest) Descriptor() ([]byte, []int) {
+	return file_lzsnmp_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RegisterOIDRequest) GetOid() string {
+	if x != nil {
+		return x.Oid
+	}
+	return ""
+}
+
+func (x *RegisterOIDRequest) GetInitial() *Value {
+	if x != nil {
+		return x.Initial
+	}
+	return nil
+}
+
+func (x *RegisterOIDRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RegisterOIDRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type RegisterOIDResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 规范化后的 OID
+	Oid string `protobuf:"bytes,1,opt,name=oid,proto3" json:"oid,omitempty"`
+}
+
+func (x *RegisterOIDResponse) Reset() {
+	*x = RegisterOIDResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lzsnmp_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegisterOIDResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterOIDResponse) ProtoMessage() {}
+
+func (x *RegisterOIDResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lzsnmp_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterOIDResponse.ProtoReflect.Descriptor instead.
+func (*RegisterOIDResponse) Descriptor() ([]byte, []int) {
+	return file_lzsnmp_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RegisterOIDResponse) GetOid() string {
+	if x != nil {
+		return x.Oid
+	}
+	return ""
+}
+
+type UnregisterOIDRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Oid string `protobuf:"bytes,1,opt,name=oid,proto3" json:"oid,omitempty"`
+}
+
+func (x *UnregisterOIDRequest) Reset() {
+	*x = UnregisterOIDRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lzsnmp_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnregisterOIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnregisterOIDRequest) ProtoMessage() {}
+
+func (x *UnregisterOIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lzsnmp_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnregisterOIDRequest.ProtoReflect.Descriptor instead.
+func (*UnregisterOIDRequest) Descriptor() ([]byte, []int) {
+	return file_lzsnmp_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UnregisterOIDRequest) GetOid() string {
+	if x != nil {
+		return x.Oid
+	}
+	return ""
+}
+
+type UnregisterOIDResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *UnregisterOIDResponse) Reset() {
+	*x = UnregisterOIDResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lzsnmp_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnregisterOIDResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnregisterOIDResponse) ProtoMessage() {}
+
+func (x *UnregisterOIDResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lzsnmp_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnregisterOIDResponse.ProtoReflect.Descriptor instead.
+func (*UnregisterOIDResponse) Descriptor() ([]byte, []int) {
+	return file_lzsnmp_proto_rawDescGZIP(), []int{4}
+}
+
+type PushValueRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Oid string `protobuf:"bytes,1,opt,name=oid,proto3" json:"oid,omitempty"`
+	// 新值，type 必须与注册时相同
+	Value *Value `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *PushValueRequest) Reset() {
+	*x = PushValueRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lzsnmp_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PushValueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushValueRequest) ProtoMessage() {}
+
+func (x *PushValueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lzsnmp_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushValueRequest.ProtoReflect.Descriptor instead.
+func (*PushValueRequest) Descriptor() ([]byte, []int) {
+	return file_lzsnmp_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PushValueRequest) GetOid() string {
+	if x != nil {
+		return x.Oid
+	}
+	return ""
+}
+
+func (x *PushValueRequest) GetValue() *Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type PushValueResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Accepted uint64 `protobuf:"varint,1,opt,name=accepted,proto3" json:"accepted,omitempty"`
+}
+
+func (x *PushValueResponse) Reset() {
+	*x = PushValueResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lzsnmp_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PushValueResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushValueResponse) ProtoMessage() {}
+
+func (x *PushValueResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lzsnmp_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushValueResponse.ProtoReflect.Descriptor instead.
+func (*PushValueResponse) Descriptor() ([]byte, []int) {
+	return file_lzsnmp_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PushValueResponse) GetAccepted() uint64 {
+	if x != nil {
+		return x.Accepted
+	}
+	return 0
+}
+
+type StreamTrapsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *StreamTrapsRequest) Reset() {
+	*x = StreamTrapsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lzsnmp_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamTrapsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamTrapsRequest) ProtoMessage() {}
+
+func (x *StreamTrapsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lzsnmp_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamTrapsRequest.ProtoReflect.Descriptor instead.
+func (*StreamTrapsRequest) Descriptor() ([]byte, []int) {
+	return file_lzsnmp_proto_rawDescGZIP(), []int{7}
+}
+
+type VarBind struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Oid   string `protobuf:"bytes,1,opt,name=oid,proto3" json:"oid,omitempty"`
+	Value *Value `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *VarBind) Reset() {
+	*x = VarBind{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lzsnmp_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VarBind) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VarBind) ProtoMessage() {}
+
+func (x *VarBind) ProtoReflect() protoreflect.Message {
+	mi := &file_lzsnmp_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VarBind.ProtoReflect.Descriptor instead.
+func (*VarBind) Descriptor() ([]byte, []int) {
+	return file_lzsnmp_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *VarBind) GetOid() string {
+	if x != nil {
+		return x.Oid
+	}
+	return ""
+}
+
+func (x *VarBind) GetValue() *Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type Trap struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// snmpTrapOID.0 的值
+	TrapOid   string     `protobuf:"bytes,1,opt,name=trap_oid,json=trapOid,proto3" json:"trap_oid,omitempty"`
+	Variables []*VarBind `protobuf:"bytes,2,rep,name=variables,proto3" json:"variables,omitempty"`
+	// 发出时间，Unix 纳秒
+	TimeUnixNano int64 `protobuf:"varint,3,opt,name=time_unix_nano,json=timeUnixNano,proto3" json:"time_unix_nano,omitempty"`
+}
+
+func (x *Trap) Reset() {
+	*x = Trap{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lzsnmp_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Trap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Trap) ProtoMessage() {}
+
+func (x *Trap) ProtoReflect() protoreflect.Message {
+	mi := &file_lzsnmp_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Trap.ProtoReflect.Descriptor instead.
+func (*Trap) Descriptor() ([]byte, []int) {
+	return file_lzsnmp_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Trap) GetTrapOid() string {
+	if x != nil {
+		return x.TrapOid
+	}
+	return ""
+}
+
+func (x *Trap) GetVariables() []*VarBind {
+	if x != nil {
+		return x.Variables
+	}
+	return nil
+}
+
+func (x *Trap) GetTimeUnixNano() int64 {
+	if x != nil {
+		return x.TimeUnixNano
+	}
+	return 0
+}
+
+type ListModulesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListModulesRequest) Reset() {
+	*x = ListModulesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lzsnmp_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListModulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListModulesRequest) ProtoMessage() {}
+
+func (x *ListModulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lzsnmp_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListModulesRequest.ProtoReflect.Descriptor instead.
+func (*ListModulesRequest) Descriptor() ([]byte, []int) {
+	return file_lzsnmp_proto_rawDescGZIP(), []int{10}
+}
+
+type ListModulesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 按添加顺序排列
+	Modules []*ModuleStatus `protobuf:"bytes,1,rep,name=modules,proto3" json:"modules,omitempty"`
+}
+
+func (x *ListModulesResponse) Reset() {
+	*x = ListModulesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lzsnmp_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListModulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListModulesResponse) ProtoMessage() {}
+
+func (x *ListModulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_lzsnmp_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListModulesResponse.ProtoReflect.Descriptor instead.
+func (*ListModulesResponse) Descriptor() ([]byte, []int) {
+	return file_lzsnmp_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListModulesResponse) GetModules() []*ModuleStatus {
+	if x != nil {
+		return x.Modules
+	}
+	return nil
+}
+
+type ModuleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *ModuleRequest) Reset() {
+	*x = ModuleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lzsnmp_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ModuleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModuleRequest) ProtoMessage() {}
+
+func (x *ModuleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_lzsnmp_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModuleRequest.ProtoReflect.Descriptor instead.
+func (*ModuleRequest) Descriptor() ([]byte, []int) {
+	return file_lzsnmp_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ModuleRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// ModuleStatus 模块状态快照
+type ModuleStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name      string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	DependsOn []string `protobuf:"bytes,2,rep,name=depends_on,json=dependsOn,proto3" json:"depends_on,omitempty"`
+	// pending、starting、running、failed、skipped、disabled 或 restarting
+	State string `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	// 失败或跳过的原因
+	Error string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	// Init 耗时（纳秒）
+	InitTimeNanos int64 `protobuf:"varint,5,opt,name=init_time_nanos,json=initTimeNanos,proto3" json:"init_time_nanos,omitempty"`
+	// Run 被重启的次数
+	Restarts uint32 `protobuf:"varint,6,opt,name=restarts,proto3" json:"restarts,omitempty"`
+	// Run 最近一次失败的原因
+	LastError string `protobuf:"bytes,7,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+}
+
+func (x *ModuleStatus) Reset() {
+	*x = ModuleStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_lzsnmp_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ModuleStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModuleStatus) ProtoMessage() {}
+
+func (x *ModuleStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_lzsnmp_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModuleStatus.ProtoReflect.Descriptor instead.
+func (*ModuleStatus) Descriptor() ([]byte, []int) {
+	return file_lzsnmp_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ModuleStatus) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ModuleStatus) GetDependsOn() []string {
+	if x != nil {
+		return x.DependsOn
+	}
+	return nil
+}
+
+func (x *ModuleStatus) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *ModuleStatus) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ModuleStatus) GetInitTimeNanos() int64 {
+	if x != nil {
+		return x.InitTimeNanos
+	}
+	return 0
+}
+
+func (x *ModuleStatus) GetRestarts() uint32 {
+	if x != nil {
+		return x.Restarts
+	}
+	return 0
+}
+
+func (x *ModuleStatus) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+var File_lzsnmp_proto protoreflect.FileDescriptor
+
+var file_lzsnmp_proto_rawDesc = []byte{
+	0x0a, 0x0c, 0x6c, 0x7a, 0x73, 0x6e, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09,
+	0x6c, 0x7a, 0x73, 0x6e, 0x6d, 0x70, 0x2e, 0x76, 0x31, 0x22, 0x43, 0x0a, 0x05, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x10, 0x0a, 0x03,
+	0x68, 0x65, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x68, 0x65, 0x78, 0x22, 0x88,
+	0x01, 0x0a, 0x12, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x4f, 0x49, 0x44, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6f, 0x69, 0x64, 0x12, 0x2a, 0x0a, 0x07, 0x69, 0x6e, 0x69, 0x74, 0x69,
+	0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6c, 0x7a, 0x73, 0x6e, 0x6d,
+	0x70, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x07, 0x69, 0x6e, 0x69, 0x74,
+	0x69, 0x61, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72,
+	0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65,
+	0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x27, 0x0a, 0x13, 0x52, 0x65, 0x67,
+	0x69, 0x73, 0x74, 0x65, 0x72, 0x4f, 0x49, 0x44, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x10, 0x0a, 0x03, 0x6f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6f,
+	0x69, 0x64, 0x22, 0x28, 0x0a, 0x14, 0x55, 0x6e, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72,
+	0x4f, 0x49, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6f, 0x69, 0x64, 0x22, 0x17, 0x0a, 0x15,
+	0x55, 0x6e, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x4f, 0x49, 0x44, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x4c, 0x0a, 0x10, 0x50, 0x75, 0x73, 0x68, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6f, 0x69, 0x64, 0x12, 0x26, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6c, 0x7a, 0x73,
+	0x6e, 0x6d, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x22, 0x2f, 0x0a, 0x11, 0x50, 0x75, 0x73, 0x68, 0x56, 0x61, 0x6c, 0x75, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x63, 0x63, 0x65,
+	0x70, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x61, 0x63, 0x63, 0x65,
+	0x70, 0x74, 0x65, 0x64, 0x22, 0x14, 0x0a, 0x12, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x54, 0x72,
+	0x61, 0x70, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x43, 0x0a, 0x07, 0x56, 0x61,
+	0x72, 0x42, 0x69, 0x6e, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x6f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6f, 0x69, 0x64, 0x12, 0x26, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6c, 0x7a, 0x73, 0x6e, 0x6d, 0x70, 0x2e,
+	0x76, 0x31, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22,
+	0x79, 0x0a, 0x04, 0x54, 0x72, 0x61, 0x70, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x72, 0x61, 0x70, 0x5f,
+	0x6f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x74, 0x72, 0x61, 0x70, 0x4f,
+	0x69, 0x64, 0x12, 0x30, 0x0a, 0x09, 0x76, 0x61, 0x72, 0x69, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x6c, 0x7a, 0x73, 0x6e, 0x6d, 0x70, 0x2e, 0x76,
+	0x31, 0x2e, 0x56, 0x61, 0x72, 0x42, 0x69, 0x6e, 0x64, 0x52, 0x09, 0x76, 0x61, 0x72, 0x69, 0x61,
+	0x62, 0x6c, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x0e, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x75, 0x6e, 0x69,
+	0x78, 0x5f, 0x6e, 0x61, 0x6e, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x74, 0x69,
+	0x6d, 0x65, 0x55, 0x6e, 0x69, 0x78, 0x4e, 0x61, 0x6e, 0x6f, 0x22, 0x14, 0x0a, 0x12, 0x4c, 0x69,
+	0x73, 0x74, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x22, 0x48, 0x0a, 0x13, 0x4c, 0x69, 0x73, 0x74, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x31, 0x0a, 0x07, 0x6d, 0x6f, 0x64, 0x75, 0x6c,
+	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6c, 0x7a, 0x73, 0x6e, 0x6d,
+	0x70, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x52, 0x07, 0x6d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x22, 0x23, 0x0a, 0x0d, 0x4d, 0x6f,
+	0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22,
+	0xd0, 0x01, 0x0a, 0x0c, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x64, 0x65, 0x70, 0x65, 0x6e, 0x64, 0x73, 0x5f,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x64, 0x65, 0x70, 0x65, 0x6e, 0x64,
+	0x73, 0x4f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12,
+	0x26, 0x0a, 0x0f, 0x69, 0x6e, 0x69, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6e, 0x61, 0x6e,
+	0x6f, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x69, 0x6e, 0x69, 0x74, 0x54, 0x69,
+	0x6d, 0x65, 0x4e, 0x61, 0x6e, 0x6f, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x72, 0x65, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x45, 0x72, 0x72,
+	0x6f, 0x72, 0x32, 0x89, 0x04, 0x0a, 0x05, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x12, 0x4c, 0x0a, 0x0b,
+	0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x4f, 0x49, 0x44, 0x12, 0x1d, 0x2e, 0x6c, 0x7a,
+	0x73, 0x6e, 0x6d, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72,
+	0x4f, 0x49, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x6c, 0x7a, 0x73,
+	0x6e, 0x6d, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x4f,
+	0x49, 0x44, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x52, 0x0a, 0x0d, 0x55, 0x6e,
+	0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x4f, 0x49, 0x44, 0x12, 0x1f, 0x2e, 0x6c, 0x7a,
+	0x73, 0x6e, 0x6d, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x6e, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74,
+	0x65, 0x72, 0x4f, 0x49, 0x44, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x6c,
+	0x7a, 0x73, 0x6e, 0x6d, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x6e, 0x72, 0x65, 0x67, 0x69, 0x73,
+	0x74, 0x65, 0x72, 0x4f, 0x49, 0x44, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x48,
+	0x0a, 0x09, 0x50, 0x75, 0x73, 0x68, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1b, 0x2e, 0x6c, 0x7a,
+	0x73, 0x6e, 0x6d, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x75, 0x73, 0x68, 0x56, 0x61, 0x6c, 0x75,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x6c, 0x7a, 0x73, 0x6e, 0x6d,
+	0x70, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x75, 0x73, 0x68, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x12, 0x3f, 0x0a, 0x0b, 0x53, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x54, 0x72, 0x61, 0x70, 0x73, 0x12, 0x1d, 0x2e, 0x6c, 0x7a, 0x73, 0x6e, 0x6d, 0x70,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x54, 0x72, 0x61, 0x70, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x6c, 0x7a, 0x73, 0x6e, 0x6d, 0x70, 0x2e,
+	0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x70, 0x30, 0x01, 0x12, 0x4c, 0x0a, 0x0b, 0x4c, 0x69, 0x73,
+	0x74, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x1d, 0x2e, 0x6c, 0x7a, 0x73, 0x6e, 0x6d,
+	0x70, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x6c, 0x7a, 0x73, 0x6e, 0x6d, 0x70,
+	0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x41, 0x0a, 0x0c, 0x45, 0x6e, 0x61, 0x62, 0x6c,
+	0x65, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x18, 0x2e, 0x6c, 0x7a, 0x73, 0x6e, 0x6d, 0x70,
+	0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x17, 0x2e, 0x6c, 0x7a, 0x73, 0x6e, 0x6d, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f,
+	0x64, 0x75, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x42, 0x0a, 0x0d, 0x44, 0x69,
+	0x73, 0x61, 0x62, 0x6c, 0x65, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x18, 0x2e, 0x6c, 0x7a,
+	0x73, 0x6e, 0x6d, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x6c, 0x7a, 0x73, 0x6e, 0x6d, 0x70, 0x2e, 0x76,
+	0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x42, 0x28,
+	0x5a, 0x26, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x69, 0x75,
+	0x7a, 0x68, 0x65, 0x6e, 0x39, 0x33, 0x32, 0x30, 0x2f, 0x73, 0x6e, 0x6d, 0x70, 0x2d, 0x67, 0x6f,
+	0x2f, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70, 0x69, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_lzsnmp_proto_rawDescOnce sync.Once
+	file_lzsnmp_proto_rawDescData = file_lzsnmp_proto_rawDesc
+)
+
+func file_lzsnmp_proto_rawDescGZIP() []byte {
+	file_lzsnmp_proto_rawDescOnce.Do(func() {
+		file_lzsnmp_proto_rawDescData = protoimpl.X.CompressGZIP(file_lzsnmp_proto_rawDescData)
+	})
+	return file_lzsnmp_proto_rawDescData
+}
+
+var file_lzsnmp_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_lzsnmp_proto_goTypes = []any{
+	(*Value)(nil),                 // 0: lzsnmp.v1.Value
+	(*RegisterOIDRequest)(nil),    // 1: lzsnmp.v1.RegisterOIDRequest
+	(*RegisterOIDResponse)(nil),   // 2: lzsnmp.v1.RegisterOIDResponse
+	(*UnregisterOIDRequest)(nil),  // 3: lzsnmp.v1.UnregisterOIDRequest
+	(*UnregisterOIDResponse)(nil), // 4: lzsnmp.v1.UnregisterOIDResponse
+	(*PushValueRequest)(nil),      // 5: lzsnmp.v1.PushValueRequest
+	(*PushValueResponse)(nil),     // 6: lzsnmp.v1.PushValueResponse
+	(*StreamTrapsRequest)(nil),    // 7: lzsnmp.v1.StreamTrapsRequest
+	(*VarBind)(nil),               // 8: lzsnmp.v1.VarBind
+	(*Trap)(nil),                  // 9: lzsnmp.v1.Trap
+	(*ListModulesRequest)(nil),    // 10: lzsnmp.v1.ListModulesRequest
+	(*ListModulesResponse)(nil),   // 11: lzsnmp.v1.ListModulesResponse
+	(*ModuleRequest)(nil),         // 12: lzsnmp.v1.ModuleRequest
+	(*ModuleStatus)(nil),          // 13: lzsnmp.v1.ModuleStatus
+}
+var file_lzsnmp_proto_depIdxs = []int32{
+	0,  // 0: lzsnmp.v1.RegisterOIDRequest.initial:type_name -> lzsnmp.v1.Value
+	0,  // 1: lzsnmp.v1.PushValueRequest.value:type_name -> lzsnmp.v1.Value
+	0,  // 2: lzsnmp.v1.VarBind.value:type_name -> lzsnmp.v1.Value
+	8,  // 3: lzsnmp.v1.Trap.variables:type_name -> lzsnmp.v1.VarBind
+	13, // 4: lzsnmp.v1.ListModulesResponse.modules:type_name -> lzsnmp.v1.ModuleStatus
+	1,  // 5: lzsnmp.v1.Agent.RegisterOID:input_type -> lzsnmp.v1.RegisterOIDRequest
+	3,  // 6: lzsnmp.v1.Agent.UnregisterOID:input_type -> lzsnmp.v1.UnregisterOIDRequest
+	5,  // 7: lzsnmp.v1.Agent.PushValue:input_type -> lzsnmp.v1.PushValueRequest
+	7,  // 8: lzsnmp.v1.Agent.StreamTraps:input_type -> lzsnmp.v1.StreamTrapsRequest
+	10, // 9: lzsnmp.v1.Agent.ListModules:input_type -> lzsnmp.v1.ListModulesRequest
+	12, // 10: lzsnmp.v1.Agent.EnableModule:input_type -> lzsnmp.v1.ModuleRequest
+	12, // 11: lzsnmp.v1.Agent.DisableModule:input_type -> lzsnmp.v1.ModuleRequest
+	2,  // 12: lzsnmp.v1.Agent.RegisterOID:output_type -> lzsnmp.v1.RegisterOIDResponse
+	4,  // 13: lzsnmp.v1.Agent.UnregisterOID:output_type -> lzsnmp.v1.UnregisterOIDResponse
+	6,  // 14: lzsnmp.v1.Agent.PushValue:output_type -> lzsnmp.v1.PushValueResponse
+	9,  // 15: lzsnmp.v1.Agent.StreamTraps:output_type -> lzsnmp.v1.Trap
+	11, // 16: lzsnmp.v1.Agent.ListModules:output_type -> lzsnmp.v1.ListModulesResponse
+	13, // 17: lzsnmp.v1.Agent.EnableModule:output_type -> lzsnmp.v1.ModuleStatus
+	13, // 18: lzsnmp.v1.Agent.DisableModule:output_type -> lzsnmp.v1.ModuleStatus
+	12, // [12:19] is the sub-list for method output_type
+	5,  // [5:12] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_lzsnmp_proto_init() }
+func file_lzsnmp_proto_init() {
+	if File_lzsnmp_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_lzsnmp_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*Value); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lzsnmp_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*RegisterOIDRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lzsnmp_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*RegisterOIDResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lzsnmp_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*UnregisterOIDRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lzsnmp_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*UnregisterOIDResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lzsnmp_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*PushValueRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lzsnmp_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*PushValueResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lzsnmp_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*StreamTrapsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lzsnmp_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*VarBind); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lzsnmp_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*Trap); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lzsnmp_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*ListModulesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lzsnmp_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*ListModulesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lzsnmp_proto_msgTypes[12].Exporter = func(v any, i int) any {
+			switch v := v.(*ModuleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_lzsnmp_proto_msgTypes[13].Exporter = func(v any, i int) any {
+			switch v := v.(*ModuleStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_lzsnmp_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_lzsnmp_proto_goTypes,
+		DependencyIndexes: file_lzsnmp_proto_depIdxs,
+		MessageInfos:      file_lzsnmp_proto_msgTypes,
+	}.Build()
+	File_lzsnmp_proto = out.File
+	file_lzsnmp_proto_rawDesc = nil
+	file_lzsnmp_proto_goTypes = nil
+	file_lzsnmp_proto_depIdxs = nil
+}
@@ -0,0 +1,364 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: lzsnmp.proto
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Agent_RegisterOID_FullMethodName   = "/lzsnmp.v1.Agent/RegisterOID"
+	Agent_UnregisterOID_FullMethodName = "/lzsnmp.v1.Agent/UnregisterOID"
+	Agent_PushValue_FullMethodName     = "/lzsnmp.v1.Agent/PushValue"
+	Agent_StreamTraps_FullMethodName   = "/lzsnmp.v1.Agent/StreamTraps"
+	Agent_ListModules_FullMethodName   = "/lzsnmp.v1.Agent/ListModules"
+	Agent_EnableModule_FullMethodName  = "/lzsnmp.v1.Agent/EnableModule"
+	Agent_DisableModule_FullMethodName = "/lzsnmp.v1.Agent/DisableModule"
+)
+
+// AgentClient is the client API for Agent service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Agent 管理运行中的 lzsnmp Agent，其他语言编写的 sidecar 可以通过它填充 OID 树
+type AgentClient interface {
+	// RegisterOID 注册 OID，之后通过 PushValue 更新其值
+	RegisterOID(ctx context.Context, in *RegisterOIDRequest, opts ...grpc.CallOption) (*RegisterOIDResponse, error)
+	// UnregisterOID 注销通过 RegisterOID 注册的 OID
+	UnregisterOID(ctx context.Context, in *UnregisterOIDRequest, opts ...grpc.CallOption) (*UnregisterOIDResponse, error)
+	// PushValue 持续推送 OID 的新值，客户端关闭流时返回已接受的数量
+	PushValue(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PushValueRequest, PushValueResponse], error)
+	// StreamTraps 订阅 Agent 发出的通知
+	StreamTraps(ctx context.Context, in *StreamTrapsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Trap], error)
+	// ListModules 返回所有模块的状态
+	ListModules(ctx context.Context, in *ListModulesRequest, opts ...grpc.CallOption) (*ListModulesResponse, error)
+	// EnableModule 启用已停用、初始化失败或被跳过的模块，返回模块的新状态
+	EnableModule(ctx context.Context, in *ModuleRequest, opts ...grpc.CallOption) (*ModuleStatus, error)
+	// DisableModule 停用运行中的模块，返回模块的新状态
+	DisableModule(ctx context.Context, in *ModuleRequest, opts ...grpc.CallOption) (*ModuleStatus, error)
+}
+
+type agentClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAgentClient(cc grpc.ClientConnInterface) AgentClient {
+	return &agentClient{cc}
+}
+
+func (c *agentClient) RegisterOID(ctx context.Context, in *RegisterOIDRequest, opts ...grpc.CallOption) (*RegisterOIDResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterOIDResponse)
+	err := c.cc.Invoke(ctx, Agent_RegisterOID_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) UnregisterOID(ctx context.Context, in *UnregisterOIDRequest, opts ...grpc.CallOption) (*UnregisterOIDResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnregisterOIDResponse)
+	err := c.cc.Invoke(ctx, Agent_UnregisterOID_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) PushValue(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[PushValueRequest, PushValueResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Agent_ServiceDesc.Streams[0], Agent_PushValue_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PushValueRequest, PushValueResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Agent_PushValueClient = grpc.ClientStreamingClient[PushValueRequest, PushValueResponse]
+
+func (c *agentClient) StreamTraps(ctx context.Context, in *StreamTrapsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Trap], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Agent_ServiceDesc.Streams[1], Agent_StreamTraps_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamTrapsRequest, Trap]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Agent_StreamTrapsClient = grpc.ServerStreamingClient[Trap]
+
+func (c *agentClient) ListModules(ctx context.Context, in *ListModulesRequest, opts ...grpc.CallOption) (*ListModulesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListModulesResponse)
+	err := c.cc.Invoke(ctx, Agent_ListModules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) EnableModule(ctx context.Context, in *ModuleRequest, opts ...grpc.CallOption) (*ModuleStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ModuleStatus)
+	err := c.cc.Invoke(ctx, Agent_EnableModule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) DisableModule(ctx context.Context, in *ModuleRequest, opts ...grpc.CallOption) (*ModuleStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ModuleStatus)
+	err := c.cc.Invoke(ctx, Agent_DisableModule_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AgentServer is the server API for Agent service.
+// All implementations must embed UnimplementedAgentServer
+// for forward compatibility.
+//
+// Agent 管理运行中的 lzsnmp Agent，其他语言编写的 sidecar 可以通过它填充 OID 树
+type AgentServer interface {
+	// RegisterOID 注册 OID，之后通过 PushValue 更新其值
+	RegisterOID(context.Context, *RegisterOIDRequest) (*RegisterOIDResponse, error)
+	// UnregisterOID 注销通过 RegisterOID 注册的 OID
+	UnregisterOID(context.Context, *UnregisterOIDRequest) (*UnregisterOIDResponse, error)
+	// PushValue 持续推送 OID 的新值，客户端关闭流时返回已接受的数量
+	PushValue(grpc.ClientStreamingServer[PushValueRequest, PushValueResponse]) error
+	// StreamTraps 订阅 Agent 发出的通知
+	StreamTraps(*StreamTrapsRequest, grpc.ServerStreamingServer[Trap]) error
+	// ListModules 返回所有模块的状态
+	ListModules(context.Context, *ListModulesRequest) (*ListModulesResponse, error)
+	// EnableModule 启用已停用、初始化失败或被跳过的模块，返回模块的新状态
+	EnableModule(context.Context, *ModuleRequest) (*ModuleStatus, error)
+	// DisableModule 停用运行中的模块，返回模块的新状态
+	DisableModule(context.Context, *ModuleRequest) (*ModuleStatus, error)
+	mustEmbedUnimplementedAgentServer()
+}
+
+// UnimplementedAgentServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAgentServer struct{}
+
+func (UnimplementedAgentServer) RegisterOID(context.Context, *RegisterOIDRequest) (*RegisterOIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterOID not implemented")
+}
+func (UnimplementedAgentServer) UnregisterOID(context.Context, *UnregisterOIDRequest) (*UnregisterOIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnregisterOID not implemented")
+}
+func (UnimplementedAgentServer) PushValue(grpc.ClientStreamingServer[PushValueRequest, PushValueResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method PushValue not implemented")
+}
+func (UnimplementedAgentServer) StreamTraps(*StreamTrapsRequest, grpc.ServerStreamingServer[Trap]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTraps not implemented")
+}
+func (UnimplementedAgentServer) ListModules(context.Context, *ListModulesRequest) (*ListModulesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListModules not implemented")
+}
+func (UnimplementedAgentServer) EnableModule(context.Context, *ModuleRequest) (*ModuleStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnableModule not implemented")
+}
+func (UnimplementedAgentServer) DisableModule(context.Context, *ModuleRequest) (*ModuleStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableModule not implemented")
+}
+func (UnimplementedAgentServer) mustEmbedUnimplementedAgentServer() {}
+func (UnimplementedAgentServer) testEmbeddedByValue()               {}
+
+// UnsafeAgentServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AgentServer will
+// result in compilation errors.
+type UnsafeAgentServer interface {
+	mustEmbedUnimplementedAgentServer()
+}
+
+func RegisterAgentServer(s grpc.ServiceRegistrar, srv AgentServer) {
+	// If the following call pancis, it indicates UnimplementedAgentServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Agent_ServiceDesc, srv)
+}
+
+func _Agent_RegisterOID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterOIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).RegisterOID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Agent_RegisterOID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).RegisterOID(ctx, req.(*RegisterOIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_UnregisterOID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnregisterOIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).UnregisterOID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Agent_UnregisterOID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).UnregisterOID(ctx, req.(*UnregisterOIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_PushValue_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AgentServer).PushValue(&grpc.GenericServerStream[PushValueRequest, PushValueResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Agent_PushValueServer = grpc.ClientStreamingServer[PushValueRequest, PushValueResponse]
+
+func _Agent_StreamTraps_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTrapsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentServer).StreamTraps(m, &grpc.GenericServerStream[StreamTrapsRequest, Trap]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Agent_StreamTrapsServer = grpc.ServerStreamingServer[Trap]
+
+func _Agent_ListModules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListModulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).ListModules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Agent_ListModules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).ListModules(ctx, req.(*ListModulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_EnableModule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).EnableModule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Agent_EnableModule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).EnableModule(ctx, req.(*ModuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_DisableModule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ModuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).DisableModule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Agent_DisableModule_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).DisableModule(ctx, req.(*ModuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Agent_ServiceDesc is the grpc.ServiceDesc for Agent service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Agent_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "lzsnmp.v1.Agent",
+	HandlerType: (*AgentServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RegisterOID",
+			Handler:    _Agent_RegisterOID_Handler,
+		},
+		{
+			MethodName: "UnregisterOID",
+			Handler:    _Agent_UnregisterOID_Handler,
+		},
+		{
+			MethodName: "ListModules",
+			Handler:    _Agent_ListModules_Handler,
+		},
+		{
+			MethodName: "EnableModule",
+			Handler:    _Agent_EnableModule_Handler,
+		},
+		{
+			MethodName: "DisableModule",
+			Handler:    _Agent_DisableModule_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "PushValue",
+			Handler:       _Agent_PushValue_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "StreamTraps",
+			Handler:       _Agent_StreamTraps_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "lzsnmp.proto",
+}
@@ -0,0 +1,243 @@
+// Package grpcapi 通过 gRPC 管理运行中的 lzsnmp Agent
+//
+// 其他语言编写的 sidecar 进程可以注册 OID、持续推送值、订阅通知和启停模块，
+// 把 lzsnmp 当作通用的 SNMP 前端守护进程使用。服务定义见 lzsnmp.proto，
+// lzsnmp.pb.go 和 lzsnmp_grpc.pb.go 由 protoc-gen-go 和 protoc-gen-go-grpc 生成。
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/gosnmp/gosnmp"
+	lzsnmp "github.com/liuzhen9320/snmp-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// trapBuffer StreamTraps 每个订阅方缓冲的通知数
+const trapBuffer = 64
+
+// Server Agent 服务的实现
+//
+// 通过 RegisterOID 注册的 OID 的值保存在 Server 中，PushValue 只更新值，不重建 Agent 的 OID 列表。
+type Server struct {
+	UnimplementedAgentServer
+
+	agent *lzsnmp.Agent
+
+	mu     sync.RWMutex
+	values map[string]registeredValue
+}
+
+// registeredValue 通过 RegisterOID 注册的 OID 的类型和当前值
+type registeredValue struct {
+	oidType gosnmp.Asn1BER
+	value   interface{}
+}
+
+// NewServer 创建 Agent 服务
+func NewServer(agent *lzsnmp.Agent) *Server {
+	return &Server{agent: agent, values: make(map[string]registeredValue)}
+}
+
+// Register 创建 Agent 服务并注册到 grpc.Server
+func Register(s *grpc.Server, agent *lzsnmp.Agent) *Server {
+	srv := NewServer(agent)
+	RegisterAgentServer(s, srv)
+	return srv
+}
+
+// RegisterOID 注册 OID 并设置初始值
+func (s *Server) RegisterOID(ctx context.Context, req *RegisterOIDRequest) (*RegisterOIDResponse, error) {
+	oid := strings.TrimPrefix(req.GetOid(), ".")
+	oidType, value, err := parseValue(req.GetInitial())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "oid %s: %v", oid, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.values[oid]; exists {
+		return nil, status.Errorf(codes.AlreadyExists, "oid %s is already registered", oid)
+	}
+
+	err = s.agent.RegisterAbsolute(oid, oidType, func() (interface{}, error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		v, ok := s.values[oid]
+		if !ok {
+			return nil, fmt.Errorf("oid %s was unregistered", oid)
+		}
+		return v.value, nil
+	})
+	if err != nil {
+		var overlap *lzsnmp.OverlapError
+		if errors.As(err, &overlap) {
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	s.values[oid] = registeredValue{oidType: oidType, value: value}
+
+	if req.GetName() != "" || req.GetDescription() != "" {
+		meta := lzsnmp.OIDMeta{Name: req.GetName(), Description: req.GetDescription()}
+		if err := s.agent.AnnotateAbsolute(oid, meta); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	return &RegisterOIDResponse{Oid: oid}, nil
+}
+
+// UnregisterOID 注销通过 RegisterOID 注册的 OID
+func (s *Server) UnregisterOID(ctx context.Context, req *UnregisterOIDRequest) (*UnregisterOIDResponse, error) {
+	oid := strings.TrimPrefix(req.GetOid(), ".")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.values[oid]; !exists {
+		return nil, status.Errorf(codes.NotFound, "oid %s was not registered through this service", oid)
+	}
+	if err := s.agent.UnregisterAbsolute(oid); err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	delete(s.values, oid)
+	return &UnregisterOIDResponse{}, nil
+}
+
+// PushValue 更新 OID 的值，遇到无效的值时中止并返回错误，之前接受的值仍然有效
+func (s *Server) PushValue(stream grpc.ClientStreamingServer[PushValueRequest, PushValueResponse]) error {
+	var accepted uint64
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&PushValueResponse{Accepted: accepted})
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.setValue(req); err != nil {
+			return err
+		}
+		accepted++
+	}
+}
+
+// setValue 更新一个 OID 的值
+func (s *Server) setValue(req *PushValueRequest) error {
+	oid := strings.TrimPrefix(req.GetOid(), ".")
+	oidType, value, err := parseValue(req.GetValue())
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "oid %s: %v", oid, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.values[oid]
+	if !ok {
+		return status.Errorf(codes.NotFound, "oid %s is not registered", oid)
+	}
+	if current.oidType != oidType {
+		return status.Errorf(codes.InvalidArgument, "oid %s: type %s does not match registered type %s", oid, oidType, current.oidType)
+	}
+	s.values[oid] = registeredValue{oidType: oidType, value: value}
+	return nil
+}
+
+// StreamTraps 将 Agent 发出的通知推送给客户端，直到客户端断开
+func (s *Server) StreamTraps(req *StreamTrapsRequest, stream grpc.ServerStreamingServer[Trap]) error {
+	notifications, cancel := s.agent.SubscribeNotifications(trapBuffer)
+	defer cancel()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case n := <-notifications:
+			trap := &Trap{TrapOid: n.TrapOID, TimeUnixNano: n.Time.UnixNano()}
+			for _, pdu := range n.Variables {
+				text, isHex, err := lzsnmp.FormatValue(pdu.Type, pdu.Value)
+				if err != nil {
+					continue
+				}
+				trap.Variables = append(trap.Variables, &VarBind{
+					Oid:   strings.TrimPrefix(pdu.Name, "."),
+					Value: &Value{Type: pdu.Type.String(), Value: text, Hex: isHex},
+				})
+			}
+			if err := stream.Send(trap); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ListModules 返回所有模块的状态
+func (s *Server) ListModules(ctx context.Context, req *ListModulesRequest) (*ListModulesResponse, error) {
+	statuses := s.agent.Modules()
+	resp := &ListModulesResponse{Modules: make([]*ModuleStatus, len(statuses))}
+	for i, st := range statuses {
+		resp.Modules[i] = moduleStatus(st)
+	}
+	return resp, nil
+}
+
+// EnableModule 启用模块，见 lzsnmp.Agent.EnableModule
+func (s *Server) EnableModule(ctx context.Context, req *ModuleRequest) (*ModuleStatus, error) {
+	return s.moduleAction(req.GetName(), s.agent.EnableModule)
+}
+
+// DisableModule 停用模块，见 lzsnmp.Agent.DisableModule
+func (s *Server) DisableModule(ctx context.Context, req *ModuleRequest) (*ModuleStatus, error) {
+	return s.moduleAction(req.GetName(), s.agent.DisableModule)
+}
+
+// moduleAction 启用或停用模块，返回模块的新状态
+func (s *Server) moduleAction(name string, action func(name string) error) (*ModuleStatus, error) {
+	if err := action(name); err != nil {
+		if errors.Is(err, lzsnmp.ErrModuleNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	for _, st := range s.agent.Modules() {
+		if st.Name == name {
+			return moduleStatus(st), nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "module not found: %s", name)
+}
+
+// moduleStatus 转换为 gRPC 的模块状态
+func moduleStatus(st lzsnmp.ModuleStatus) *ModuleStatus {
+	return &ModuleStatus{
+		Name:          st.Name,
+		DependsOn:     st.DependsOn,
+		State:         string(st.State),
+		Error:         st.Error,
+		InitTimeNanos: st.InitTime.Nanoseconds(),
+		Restarts:      uint32(st.Restarts),
+		LastError:     st.LastError,
+	}
+}
+
+// parseValue 解析带类型的文本值
+func parseValue(v *Value) (gosnmp.Asn1BER, interface{}, error) {
+	if v == nil {
+		return 0, nil, fmt.Errorf("value is required")
+	}
+	oidType, err := lzsnmp.ParseType(v.GetType())
+	if err != nil {
+		return 0, nil, err
+	}
+	value, err := lzsnmp.ParseValue(oidType, v.GetValue(), v.GetHex())
+	if err != nil {
+		return 0, nil, err
+	}
+	return oidType, value, nil
+}
@@ -0,0 +1,119 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// HeartbeatConfig 周期性心跳通知的配置
+type HeartbeatConfig struct {
+	TrapOID  string        // 心跳通知的绝对 OID（必需）
+	Interval time.Duration // 发送间隔（必需）
+	Jitter   time.Duration // 每个间隔额外随机延迟 [0, Jitter)，必须小于 Interval
+	OIDs     []string      // 附带当前值的已注册绝对 OID，如 sysDescr.0 "1.3.6.1.2.1.1.1.0"
+	Tags     []string      // 只发送给带有任一标签的目标，为空时发送给所有目标
+}
+
+// Heartbeat 由 Agent.StartHeartbeat 创建的心跳
+type Heartbeat struct {
+	agent *Agent
+	cfg   HeartbeatConfig
+
+	mu     sync.Mutex
+	stop   chan struct{}
+	done   chan struct{}
+	closed bool
+}
+
+// StartHeartbeat 每隔 cfg.Interval（加上随机抖动）通过 SendTrap 发送一次 cfg.TrapOID 通知
+//
+// 通知总是带有 sysUpTime.0，另外附带 cfg.OIDs 的当前值（如版本号），读取失败的 OID 被跳过。
+// 管理端在连续几个间隔内没有收到心跳时即可判定 Agent 失联，不必等待轮询超时。
+// 抖动错开大量 Agent 同时启动时的发送时间。Agent.Stop 不会停止心跳，需要时调用 Heartbeat.Stop。
+func (a *Agent) StartHeartbeat(cfg HeartbeatConfig) (*Heartbeat, error) {
+	trapOID, err := normalizeOID(cfg.TrapOID)
+	if err != nil {
+		return nil, fmt.Errorf("heartbeat: invalid trap OID: %w", err)
+	}
+	cfg.TrapOID = trapOID
+	if cfg.Interval <= 0 {
+		return nil, fmt.Errorf("heartbeat: interval must be positive")
+	}
+	if cfg.Jitter < 0 || cfg.Jitter >= cfg.Interval {
+		return nil, fmt.Errorf("heartbeat: jitter must be between 0 and the interval")
+	}
+	oids := make([]string, len(cfg.OIDs))
+	for i, oid := range cfg.OIDs {
+		if oids[i], err = normalizeOID(oid); err != nil {
+			return nil, fmt.Errorf("heartbeat: %w", err)
+		}
+	}
+	cfg.OIDs = oids
+	cfg.Tags = append([]string(nil), cfg.Tags...)
+
+	h := &Heartbeat{
+		agent: a,
+		cfg:   cfg,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go h.run()
+
+	a.logger.Info("Started heartbeat", "trap", trapOID, "interval", cfg.Interval, "jitter", cfg.Jitter)
+	return h, nil
+}
+
+// Stop 停止发送心跳
+func (h *Heartbeat) Stop() {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.closed = true
+	close(h.stop)
+	h.mu.Unlock()
+	<-h.done
+}
+
+func (h *Heartbeat) run() {
+	defer close(h.done)
+
+	timer := time.NewTimer(h.next())
+	defer timer.Stop()
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-timer.C:
+			h.send()
+			timer.Reset(h.next())
+		}
+	}
+}
+
+// next 返回到下一次发送的间隔
+func (h *Heartbeat) next() time.Duration {
+	if h.cfg.Jitter <= 0 {
+		return h.cfg.Interval
+	}
+	return h.cfg.Interval + rand.N(h.cfg.Jitter)
+}
+
+// send 读取附带的 OID 并发送一次心跳
+func (h *Heartbeat) send() {
+	vars := make([]gosnmp.SnmpPDU, 0, len(h.cfg.OIDs))
+	for _, oid := range h.cfg.OIDs {
+		oidType, value, err := h.agent.readOID(oid)
+		if err != nil {
+			h.agent.logger.Warn("Heartbeat skipped varbind", "oid", oid, "error", err)
+			continue
+		}
+		vars = append(vars, gosnmp.SnmpPDU{Name: "." + oid, Type: oidType, Value: value})
+	}
+	h.agent.SendTrap(h.cfg.TrapOID, vars, h.cfg.Tags...)
+}
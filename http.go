@@ -0,0 +1,223 @@
+//go:build !lzsnmp_nohttp
+
+package lzsnmp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// HTTP 后端的请求超时和响应体大小上限
+const (
+	httpBackendTimeout = 10 * time.Second
+	maxHTTPBackendBody = 4 << 20
+)
+
+// httpBackendClient HTTP 后端共用的客户端
+var httpBackendClient = &http.Client{Timeout: httpBackendTimeout}
+
+// RegisterHTTP 注册值来自 HTTP JSON 接口的相对 OID
+//
+// 每次获取对 url 发起 GET，按 jsonPath 取出字段并按 oidType 解析，结果缓存 ttl（行为同 RegisterCached）。
+// jsonPath 支持 JSONPath 的子集：$、.name、['name'] 和数组下标 [n]（负数从末尾计），如 $.data.items[0].count。
+// bool 字段映射为 TruthValue（true=1，false=2），对象和数组只能用于 OctetString，值为其 JSON 文本。
+func (a *Agent) RegisterHTTP(relativeOID string, oidType gosnmp.Asn1BER, url, jsonPath string, ttl time.Duration) error {
+	absoluteOID := fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID)
+	return a.RegisterHTTPAbsolute(absoluteOID, oidType, url, jsonPath, ttl)
+}
+
+// RegisterHTTPAbsolute 注册值来自 HTTP JSON 接口的绝对路径 OID
+func (a *Agent) RegisterHTTPAbsolute(oid string, oidType gosnmp.Asn1BER, url, jsonPath string, ttl time.Duration) error {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return fmt.Errorf("invalid URL for OID %s: %s", oid, url)
+	}
+	path, err := parseJSONPath(jsonPath)
+	if err != nil {
+		return fmt.Errorf("invalid JSONPath for OID %s: %w", oid, err)
+	}
+
+	handler := func() (interface{}, error) {
+		doc, err := fetchJSON(url)
+		if err != nil {
+			return nil, err
+		}
+		field, err := path.eval(doc)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", url, err)
+		}
+		return jsonFieldValue(oidType, field)
+	}
+	return a.RegisterCachedAbsolute(oid, oidType, ttl, handler)
+}
+
+// fetchJSON 获取并解码 JSON 文档，数字保留为 json.Number
+func fetchJSON(url string) (interface{}, error) {
+	resp, err := httpBackendClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPBackendBody+1))
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", url, err)
+	}
+	if len(body) > maxHTTPBackendBody {
+		return nil, fmt.Errorf("GET %s: response exceeds %d bytes", url, maxHTTPBackendBody)
+	}
+
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("GET %s: invalid JSON: %w", url, err)
+	}
+	return doc, nil
+}
+
+// jsonFieldValue 将 JSON 字段转换为 oidType 对应的值
+func jsonFieldValue(oidType gosnmp.Asn1BER, field interface{}) (interface{}, error) {
+	var text string
+	switch v := field.(type) {
+	case nil:
+		return nil, fmt.Errorf("field is null")
+	case string:
+		text = v
+	case json.Number:
+		text = v.String()
+		// 整数类型接受 1.0、1e3 这类写法
+		if isIntegerType(oidType) && strings.ContainsAny(text, ".eE") {
+			if f, err := v.Float64(); err == nil && f == math.Trunc(f) && math.Abs(f) < 1<<63 {
+				text = strconv.FormatInt(int64(f), 10)
+			}
+		}
+	case bool:
+		text = "2"
+		if v {
+			text = "1"
+		}
+	default:
+		if oidType != gosnmp.OctetString {
+			return nil, fmt.Errorf("field is %T, which cannot be converted to %s", field, oidType)
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		text = string(b)
+	}
+	return parseValueText(oidType, text, false)
+}
+
+// isIntegerType 是否为整数类型
+func isIntegerType(oidType gosnmp.Asn1BER) bool {
+	switch oidType {
+	case gosnmp.Integer, gosnmp.Counter32, gosnmp.Gauge32, gosnmp.Uinteger32, gosnmp.TimeTicks, gosnmp.Counter64:
+		return true
+	}
+	return false
+}
+
+// jsonPathStep JSONPath 的一步：对象成员或数组下标
+type jsonPathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// jsonPath 解析后的 JSONPath
+type jsonPath []jsonPathStep
+
+// parseJSONPath 解析 JSONPath 子集：$、.name、['name']、["name"]、[n]
+func parseJSONPath(path string) (jsonPath, error) {
+	rest := strings.TrimSpace(path)
+	if rest == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+	rest = strings.TrimPrefix(rest, "$")
+
+	var steps jsonPath
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty member name in %q", path)
+			}
+			steps = append(steps, jsonPathStep{key: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated [ in %q", path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, jsonPathStep{key: inner[1 : len(inner)-1]})
+				continue
+			}
+			n, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("unsupported subscript [%s] in %q", inner, path)
+			}
+			steps = append(steps, jsonPathStep{index: n, isIndex: true})
+		default:
+			if len(steps) > 0 || strings.HasPrefix(strings.TrimSpace(path), "$") {
+				return nil, fmt.Errorf("unexpected %q in %q", rest[0], path)
+			}
+			// 允许省略开头的 $.，如 data.count
+			rest = "." + rest
+		}
+	}
+	return steps, nil
+}
+
+// eval 在 JSON 文档中取出路径对应的值
+func (p jsonPath) eval(doc interface{}) (interface{}, error) {
+	cur := doc
+	for i, step := range p {
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			if step.isIndex {
+				return nil, fmt.Errorf("step %d: cannot index object with [%d]", i+1, step.index)
+			}
+			next, ok := v[step.key]
+			if !ok {
+				return nil, fmt.Errorf("step %d: member %q not found", i+1, step.key)
+			}
+			cur = next
+		case []interface{}:
+			if !step.isIndex {
+				return nil, fmt.Errorf("step %d: cannot select member %q of array", i+1, step.key)
+			}
+			n := step.index
+			if n < 0 {
+				n += len(v)
+			}
+			if n < 0 || n >= len(v) {
+				return nil, fmt.Errorf("step %d: index %d out of range (length %d)", i+1, step.index, len(v))
+			}
+			cur = v[n]
+		default:
+			return nil, fmt.Errorf("step %d: cannot descend into %T", i+1, cur)
+		}
+	}
+	return cur, nil
+}
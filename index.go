@@ -0,0 +1,255 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// IndexKind 表格索引组成部分的 SMI 类型
+type IndexKind int
+
+const (
+	IndexInteger       IndexKind = iota // INTEGER / Unsigned32，一个子标识
+	IndexString                         // 可变长度 OCTET STRING，长度前缀 + 每字节一个子标识
+	IndexFixedString                    // 固定长度 OCTET STRING（SIZE(n)），没有长度前缀
+	IndexImpliedString                  // IMPLIED OCTET STRING，没有长度前缀，只能是最后一个
+	IndexIPAddress                      // IpAddress，4 个子标识
+	IndexOID                            // OBJECT IDENTIFIER，长度前缀 + 子标识
+	IndexImpliedOID                     // IMPLIED OBJECT IDENTIFIER，只能是最后一个
+)
+
+func (k IndexKind) String() string {
+	switch k {
+	case IndexInteger:
+		return "integer"
+	case IndexString:
+		return "string"
+	case IndexFixedString:
+		return "fixed string"
+	case IndexImpliedString:
+		return "implied string"
+	case IndexIPAddress:
+		return "IpAddress"
+	case IndexOID:
+		return "OID"
+	case IndexImpliedOID:
+		return "implied OID"
+	}
+	return fmt.Sprintf("IndexKind(%d)", int(k))
+}
+
+// IndexField 索引的一个组成部分，Size 为 IndexFixedString 的字节数
+type IndexField struct {
+	Kind IndexKind
+	Size int
+}
+
+// IndexSpec 表格的索引（MIB 中 INDEX 子句的各个部分），按 SMI 规则与实例 OID 后缀相互转换
+//
+// Encode 接受的值：IndexInteger 为 0 ~ 4294967295 的整数；字符串类为 string 或 []byte；
+// IndexIPAddress 为 IPv4 的 net.IP 或点分字符串；OID 类为点分字符串。Decode 返回 int64、string、
+// net.IP 和点分字符串。
+type IndexSpec []IndexField
+
+// check 检查 IMPLIED 只出现在最后
+func (s IndexSpec) check() error {
+	for i, f := range s {
+		if (f.Kind == IndexImpliedString || f.Kind == IndexImpliedOID) && i != len(s)-1 {
+			return fmt.Errorf("index part %d: %v must be the last part", i+1, f.Kind)
+		}
+		if f.Kind == IndexFixedString && f.Size <= 0 {
+			return fmt.Errorf("index part %d: fixed string requires a positive size", i+1)
+		}
+	}
+	return nil
+}
+
+// Encode 将各部分的值编码为实例 OID 后缀，如 IndexSpec{{Kind: IndexString}, {Kind: IndexInteger}}
+// 编码 ("eth0", 3) 得到 "4.101.116.104.48.3"
+func (s IndexSpec) Encode(values ...interface{}) (string, error) {
+	if err := s.check(); err != nil {
+		return "", err
+	}
+	if len(values) != len(s) {
+		return "", fmt.Errorf("index has %d parts, got %d values", len(s), len(values))
+	}
+
+	parts := make([]string, 0, len(s))
+	for i, f := range s {
+		part, err := f.encode(values[i])
+		if err != nil {
+			return "", fmt.Errorf("index part %d (%v): %w", i+1, f.Kind, err)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "."), nil
+}
+
+func (f IndexField) encode(value interface{}) (string, error) {
+	switch f.Kind {
+	case IndexInteger:
+		n, err := toInt64(value)
+		if err != nil {
+			return "", err
+		}
+		if n < 0 || n > 0xffffffff {
+			return "", fmt.Errorf("%d is out of range", n)
+		}
+		return strconv.FormatInt(n, 10), nil
+	case IndexString, IndexFixedString, IndexImpliedString:
+		var b []byte
+		switch v := value.(type) {
+		case string:
+			b = []byte(v)
+		case []byte:
+			b = v
+		default:
+			return "", fmt.Errorf("cannot use %T as a string", value)
+		}
+		if f.Kind == IndexFixedString && len(b) != f.Size {
+			return "", fmt.Errorf("length %d, want %d", len(b), f.Size)
+		}
+		if f.Kind == IndexImpliedString && len(b) == 0 {
+			return "", fmt.Errorf("implied string must not be empty")
+		}
+		return encodeOctetsIndex(b, f.Kind != IndexString), nil
+	case IndexIPAddress:
+		var ip net.IP
+		switch v := value.(type) {
+		case net.IP:
+			ip = v
+		case string:
+			ip = net.ParseIP(v)
+		}
+		if ip = ip.To4(); ip == nil {
+			return "", fmt.Errorf("%v is not an IPv4 address", value)
+		}
+		return fmt.Sprintf("%d.%d.%d.%d", ip[0], ip[1], ip[2], ip[3]), nil
+	case IndexOID, IndexImpliedOID:
+		text, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("cannot use %T as an OID", value)
+		}
+		oid, err := normalizeOID(text)
+		if err != nil {
+			return "", err
+		}
+		if f.Kind == IndexImpliedOID {
+			return oid, nil
+		}
+		return fmt.Sprintf("%d.%s", strings.Count(oid, ".")+1, oid), nil
+	}
+	return "", fmt.Errorf("unknown index kind")
+}
+
+// Decode 将实例 OID 后缀解码为各部分的值，多余或不足的子标识返回错误
+func (s IndexSpec) Decode(index string) ([]interface{}, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	var arcs []uint32
+	if index = strings.TrimPrefix(index, "."); index != "" {
+		for _, text := range strings.Split(index, ".") {
+			n, err := strconv.ParseUint(text, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid index %q: %q is not a sub-identifier", index, text)
+			}
+			arcs = append(arcs, uint32(n))
+		}
+	}
+
+	values := make([]interface{}, 0, len(s))
+	for i, f := range s {
+		value, n, err := f.decode(arcs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index %q: part %d (%v): %w", index, i+1, f.Kind, err)
+		}
+		values = append(values, value)
+		arcs = arcs[n:]
+	}
+	if len(arcs) > 0 {
+		return nil, fmt.Errorf("invalid index %q: %d extra sub-identifiers", index, len(arcs))
+	}
+	return values, nil
+}
+
+// decode 从 arcs 开头解码一个部分，返回值和使用的子标识数
+func (f IndexField) decode(arcs []uint32) (interface{}, int, error) {
+	// take 返回 arcs 中从 start 开始的 n 个子标识
+	take := func(start, n int) ([]uint32, error) {
+		if start+n > len(arcs) {
+			return nil, fmt.Errorf("need %d sub-identifiers, have %d", start+n, len(arcs))
+		}
+		return arcs[start : start+n], nil
+	}
+	octets := func(sub []uint32) (string, error) {
+		b := make([]byte, len(sub))
+		for i, c := range sub {
+			if c > 255 {
+				return "", fmt.Errorf("sub-identifier %d is not a byte", c)
+			}
+			b[i] = byte(c)
+		}
+		return string(b), nil
+	}
+	dotted := func(sub []uint32) string {
+		parts := make([]string, len(sub))
+		for i, c := range sub {
+			parts[i] = strconv.FormatUint(uint64(c), 10)
+		}
+		return strings.Join(parts, ".")
+	}
+
+	switch f.Kind {
+	case IndexInteger:
+		sub, err := take(0, 1)
+		if err != nil {
+			return nil, 0, err
+		}
+		return int64(sub[0]), 1, nil
+	case IndexString, IndexOID:
+		if len(arcs) == 0 {
+			return nil, 0, fmt.Errorf("missing length")
+		}
+		sub, err := take(1, int(arcs[0]))
+		if err != nil {
+			return nil, 0, err
+		}
+		if f.Kind == IndexOID {
+			return dotted(sub), len(sub) + 1, nil
+		}
+		s, err := octets(sub)
+		return s, len(sub) + 1, err
+	case IndexFixedString:
+		sub, err := take(0, f.Size)
+		if err != nil {
+			return nil, 0, err
+		}
+		s, err := octets(sub)
+		return s, len(sub), err
+	case IndexImpliedString:
+		if len(arcs) == 0 {
+			return nil, 0, fmt.Errorf("implied string must not be empty")
+		}
+		s, err := octets(arcs)
+		return s, len(arcs), err
+	case IndexImpliedOID:
+		return dotted(arcs), len(arcs), nil
+	case IndexIPAddress:
+		sub, err := take(0, 4)
+		if err != nil {
+			return nil, 0, err
+		}
+		s, err := octets(sub)
+		return net.IP(s), 4, err
+	}
+	return nil, 0, fmt.Errorf("unknown index kind")
+}
+
+// Validate 检查实例 OID 后缀能否按 s 解码，可用作 RowTableConfig.ValidateIndex
+func (s IndexSpec) Validate(index string) error {
+	_, err := s.Decode(index)
+	return err
+}
@@ -0,0 +1,145 @@
+package lzsnmp
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestIndexSpecRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   IndexSpec
+		values []interface{}
+		index  string
+		want   []interface{} // Decode 的结果，nil 时与 values 相同
+	}{
+		{
+			name:   "integers",
+			spec:   IndexSpec{{Kind: IndexInteger}, {Kind: IndexInteger}, {Kind: IndexInteger}},
+			values: []interface{}{int64(1), int64(0), int64(4294967295)},
+			index:  "1.0.4294967295",
+		},
+		{
+			name:   "variable string and integer",
+			spec:   IndexSpec{{Kind: IndexString}, {Kind: IndexInteger}},
+			values: []interface{}{"eth0", int64(3)},
+			index:  "4.101.116.104.48.3",
+		},
+		{
+			name:   "empty variable string",
+			spec:   IndexSpec{{Kind: IndexString}, {Kind: IndexInteger}},
+			values: []interface{}{"", int64(7)},
+			index:  "0.7",
+		},
+		{
+			name:   "fixed string",
+			spec:   IndexSpec{{Kind: IndexFixedString, Size: 6}, {Kind: IndexInteger}},
+			values: []interface{}{[]byte{0x00, 0x1b, 0x21, 0xff, 0x00, 0x01}, int64(2)},
+			index:  "0.27.33.255.0.1.2",
+			want:   []interface{}{"\x00\x1b\x21\xff\x00\x01", int64(2)},
+		},
+		{
+			name:   "implied string last",
+			spec:   IndexSpec{{Kind: IndexInteger}, {Kind: IndexImpliedString}},
+			values: []interface{}{int64(5), "abc"},
+			index:  "5.97.98.99",
+		},
+		{
+			name:   "IpAddress",
+			spec:   IndexSpec{{Kind: IndexIPAddress}, {Kind: IndexInteger}},
+			values: []interface{}{"192.168.1.20", int64(161)},
+			index:  "192.168.1.20.161",
+			want:   []interface{}{net.IP{192, 168, 1, 20}, int64(161)},
+		},
+		{
+			name:   "OID and implied OID",
+			spec:   IndexSpec{{Kind: IndexOID}, {Kind: IndexImpliedOID}},
+			values: []interface{}{"1.3.6", ".1.2"},
+			index:  "3.1.3.6.1.2",
+			want:   []interface{}{"1.3.6", "1.2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, err := tt.spec.Encode(tt.values...)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if index != tt.index {
+				t.Fatalf("Encode = %q, want %q", index, tt.index)
+			}
+			got, err := tt.spec.Decode(index)
+			if err != nil {
+				t.Fatalf("Decode(%q): %v", index, err)
+			}
+			want := tt.want
+			if want == nil {
+				want = tt.values
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("Decode(%q) = %#v, want %#v", index, got, want)
+			}
+		})
+	}
+}
+
+func TestIndexSpecDecodeMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  IndexSpec
+		index string
+	}{
+		{"empty", IndexSpec{{Kind: IndexInteger}}, ""},
+		{"extra sub-identifiers", IndexSpec{{Kind: IndexInteger}}, "1.2"},
+		{"not a number", IndexSpec{{Kind: IndexInteger}}, "1.x"},
+		{"sub-identifier overflow", IndexSpec{{Kind: IndexInteger}}, "4294967296"},
+		{"string shorter than length", IndexSpec{{Kind: IndexString}}, "4.101.116"},
+		{"string missing length", IndexSpec{{Kind: IndexInteger}, {Kind: IndexString}}, "1"},
+		{"string byte out of range", IndexSpec{{Kind: IndexString}}, "1.256"},
+		{"short fixed string", IndexSpec{{Kind: IndexFixedString, Size: 6}}, "0.27.33"},
+		{"empty implied string", IndexSpec{{Kind: IndexInteger}, {Kind: IndexImpliedString}}, "1"},
+		{"short IpAddress", IndexSpec{{Kind: IndexIPAddress}}, "10.0.0"},
+		{"IpAddress byte out of range", IndexSpec{{Kind: IndexIPAddress}}, "10.0.0.300"},
+		{"short OID", IndexSpec{{Kind: IndexOID}}, "3.1.3"},
+		{"implied not last", IndexSpec{{Kind: IndexImpliedString}, {Kind: IndexInteger}}, "97.1"},
+		{"fixed string without size", IndexSpec{{Kind: IndexFixedString}}, "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if values, err := tt.spec.Decode(tt.index); err == nil {
+				t.Fatalf("Decode(%q) = %#v, want error", tt.index, values)
+			}
+			if err := tt.spec.Validate(tt.index); err == nil {
+				t.Fatalf("Validate(%q) = nil, want error", tt.index)
+			}
+		})
+	}
+}
+
+func TestIndexSpecEncodeInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   IndexSpec
+		values []interface{}
+	}{
+		{"value count", IndexSpec{{Kind: IndexInteger}, {Kind: IndexInteger}}, []interface{}{1}},
+		{"negative integer", IndexSpec{{Kind: IndexInteger}}, []interface{}{-1}},
+		{"integer overflow", IndexSpec{{Kind: IndexInteger}}, []interface{}{int64(4294967296)}},
+		{"fixed string length", IndexSpec{{Kind: IndexFixedString, Size: 6}}, []interface{}{"abc"}},
+		{"empty implied string", IndexSpec{{Kind: IndexImpliedString}}, []interface{}{""}},
+		{"IPv6 address", IndexSpec{{Kind: IndexIPAddress}}, []interface{}{"::1"}},
+		{"string type", IndexSpec{{Kind: IndexString}}, []interface{}{42}},
+		{"invalid OID", IndexSpec{{Kind: IndexOID}}, []interface{}{"1.x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if index, err := tt.spec.Encode(tt.values...); err == nil {
+				t.Fatalf("Encode(%v) = %q, want error", tt.values, index)
+			}
+		})
+	}
+}
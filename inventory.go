@@ -0,0 +1,204 @@
+package lzsnmp
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// inventoryRow 清单文件中的一行，OID 在文件中相对于企业前缀，校验后为绝对 OID
+type inventoryRow struct {
+	OID         string `yaml:"oid" json:"oid"`
+	Type        string `yaml:"type" json:"type"`
+	Value       string `yaml:"value" json:"value"`
+	Hex         bool   `yaml:"hex" json:"hex"` // OctetString 的 Value 为十六进制
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+	Units       string `yaml:"units" json:"units"`
+}
+
+// inventoryColumns CSV 表头支持的列名
+var inventoryColumns = []string{"oid", "type", "value", "hex", "name", "description", "units"}
+
+// LoadStaticInventory 读取 CSV 或 YAML 清单文件，将其中的行注册为静态 OID，返回注册的 OID 数量
+//
+// 每行为相对于企业前缀的 OID、类型（同 ParseType）、值和可选的说明，用于在代码外维护资产编号、序列号等大量静态数据。
+// 扩展名为 .csv 时按 CSV 解析，.yaml、.yml、.json 按 YAML 解析（lzsnmp_noyaml 时只接受 JSON 格式的内容）。所有行校验通过后一次注册，任何一行无效时不注册任何 OID；
+// 已注册的同名 OID 被覆盖。
+func (a *Agent) LoadStaticInventory(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var rows []inventoryRow
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		rows, err = parseInventoryCSV(bytes.NewReader(data), a.oidPrefix)
+	case ".yaml", ".yml", ".json":
+		rows, err = parseInventoryYAML(data, a.oidPrefix)
+	default:
+		return 0, fmt.Errorf("%s: unsupported inventory format, use .csv or .yaml", path)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(rows) == 0 {
+		return 0, fmt.Errorf("%s: no entries found", path)
+	}
+
+	entries := make([]SubtreeEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = SubtreeEntry{
+			OID:         row.OID,
+			Type:        row.Type,
+			Value:       row.Value,
+			Hex:         row.Hex,
+			Name:        row.Name,
+			Description: row.Description,
+			Units:       row.Units,
+		}
+	}
+	n, err := a.ImportSubtree(&Subtree{Root: a.oidPrefix, Entries: entries}, ImportOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+	a.logger.Info("Loaded static inventory", "path", path, "entries", n)
+	return n, nil
+}
+
+// parseInventoryCSV 解析 CSV 清单，# 开头的行为注释
+//
+// 第一行的第一列为 "oid" 时视为表头，按列名取值（列名见 inventoryColumns）；
+// 否则每行依次为 oid、type、value 和可选的 description。
+func parseInventoryCSV(r io.Reader, prefix string) ([]inventoryRow, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	columns := []string{"oid", "type", "value", "description"}
+	minFields := 3
+	var rows []inventoryRow
+	seen := make(map[string]int)
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		if first && strings.EqualFold(strings.TrimSpace(record[0]), "oid") {
+			if columns, err = inventoryHeader(record); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			minFields = 0
+			continue
+		}
+		if len(record) < minFields || len(record) > len(columns) {
+			return nil, fmt.Errorf("line %d: expected %d to %d fields, got %d", line, minFields, len(columns), len(record))
+		}
+
+		var row inventoryRow
+		for i, field := range record {
+			switch columns[i] {
+			case "oid":
+				row.OID = field
+			case "type":
+				row.Type = field
+			case "value":
+				row.Value = field
+			case "hex":
+				if strings.TrimSpace(field) == "" {
+					continue
+				}
+				if row.Hex, err = strconv.ParseBool(strings.TrimSpace(field)); err != nil {
+					return nil, fmt.Errorf("line %d: invalid hex flag %q", line, field)
+				}
+			case "name":
+				row.Name = field
+			case "description":
+				row.Description = field
+			case "units":
+				row.Units = field
+			}
+		}
+		if err := checkInventoryRow(&row, prefix, seen, line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// inventoryHeader 检查 CSV 表头，必须包含 oid、type 和 value 列
+func inventoryHeader(record []string) ([]string, error) {
+	columns := make([]string, len(record))
+	for i, name := range record {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(inventoryColumns, name) {
+			return nil, fmt.Errorf("unknown column %q", record[i])
+		}
+		if slices.Contains(columns[:i], name) {
+			return nil, fmt.Errorf("duplicate column %q", record[i])
+		}
+		columns[i] = name
+	}
+	for _, required := range []string{"oid", "type", "value"} {
+		if !slices.Contains(columns, required) {
+			return nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+	return columns, nil
+}
+
+// parseInventoryYAML 解析 YAML 清单，文件内容为 inventoryRow 的列表，未知字段视为错误
+func parseInventoryYAML(data []byte, prefix string) ([]inventoryRow, error) {
+	var rows []inventoryRow
+	if err := decodeYAML(data, &rows); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	seen := make(map[string]int)
+	for i := range rows {
+		if err := checkInventoryRow(&rows[i], prefix, seen, i+1); err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+	}
+	return rows, nil
+}
+
+// checkInventoryRow 校验一行并将 OID 转换为 prefix 下的绝对 OID，pos 为行号或条目序号，用于报告重复的 OID
+func checkInventoryRow(row *inventoryRow, prefix string, seen map[string]int, pos int) error {
+	relative := strings.TrimSpace(row.OID)
+	if relative == "" || strings.HasPrefix(relative, ".") {
+		return fmt.Errorf("OID %q must be relative to the enterprise prefix", row.OID)
+	}
+	oid, err := normalizeOID(prefix + "." + relative)
+	if err != nil {
+		return err
+	}
+	if prev, dup := seen[oid]; dup {
+		return fmt.Errorf("duplicate OID %s (first at %d)", relative, prev)
+	}
+	seen[oid] = pos
+
+	oidType, err := ParseType(strings.TrimSpace(row.Type))
+	if err != nil {
+		return fmt.Errorf("OID %s: %w", relative, err)
+	}
+	if _, err := parseValueText(oidType, row.Value, row.Hex); err != nil {
+		return fmt.Errorf("OID %s: invalid %v value %q: %w", relative, oidType, row.Value, err)
+	}
+	row.OID, row.Type = oid, oidType.String()
+	return nil
+}
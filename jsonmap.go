@@ -0,0 +1,312 @@
+package lzsnmp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// JSONProvider 返回要映射的 JSON 文档
+//
+// []byte、json.RawMessage 和 string 按 JSON 文本解析；其他值（map、切片、结构体等）先用 encoding/json 编码再解析。
+type JSONProvider func() (interface{}, error)
+
+// JSONMapOptions RegisterJSON 的选项
+type JSONMapOptions struct {
+	// HashKeys 对象的键映射为键的 FNV-1a 哈希（一个 0..2^31-1 的分量），OID 长度不随键长增长；
+	// 默认按字符串索引编码（长度 + ASCII）。同一对象中哈希冲突的键按字典序保留第一个
+	HashKeys bool
+}
+
+// JSONMapper 将 JSON 文档映射为 OID 子树
+type JSONMapper struct {
+	agent    *Agent
+	root     string
+	provider JSONProvider
+	opts     JSONMapOptions
+
+	mu          sync.Mutex
+	values      map[string]interface{}
+	types       map[string]gosnmp.Asn1BER
+	instances   []string
+	annotated   map[string]bool
+	refreshedAt time.Time
+	refreshErr  error
+	stop        chan struct{}
+}
+
+// RegisterJSON 将 provider 返回的 JSON 文档映射到相对 OID prefix 下，已有的 JSON 状态接口无需改造即可被 SNMP 轮询
+//
+// 对象的键按 JSONMapOptions.HashKeys 编码，数组元素按位置编号（从 1 开始），逐级追加到 OID，
+// 例如 {"disks":[{"free":10}]} 的 free 为 prefix.5.100.105.115.107.115.1.4.102.114.101.101。
+// 根为标量时映射为 prefix.0。类型按值推断：整数在 Integer 范围内为 Integer，更大的非负整数为 Counter64，
+// 其他数字为 OpaqueDouble，字符串为 OctetString，布尔为 TruthValue，null 被忽略。
+// 每个实例的 OID 说明为其 JSON 路径（如 $.disks[0].free）。
+// 每次 GET 时最多每秒调用一次 provider，也可以调用 RefreshEvery 定时刷新；键或类型变化时自动重建实例 OID。
+func (a *Agent) RegisterJSON(prefix string, provider JSONProvider, opts JSONMapOptions) (*JSONMapper, error) {
+	prefix = strings.Trim(prefix, ".")
+	if prefix == "" {
+		return nil, fmt.Errorf("JSON mapper prefix is required")
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("JSON provider is required")
+	}
+
+	m := &JSONMapper{
+		agent:     a,
+		root:      fmt.Sprintf("%s.%s", a.oidPrefix, prefix),
+		provider:  provider,
+		opts:      opts,
+		annotated: make(map[string]bool),
+	}
+
+	// 先读取一次文档，provider 不可用时不占用子树
+	values, types, paths, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.claimSubtree(m.root, "JSON mapper"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	m.installLocked(values, types, paths)
+	m.mu.Unlock()
+
+	a.logger.Info("Registered JSON mapper", "oid", m.root, "instances", len(values))
+	return m, nil
+}
+
+// Refresh 立即调用 provider，并在键或类型变化时重建实例 OID
+func (m *JSONMapper) Refresh() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.refreshLocked()
+}
+
+// RefreshEvery 启动后台定时刷新，调用 Close 停止
+func (m *JSONMapper) RefreshEvery(interval time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stop != nil {
+		close(m.stop)
+	}
+	stop := make(chan struct{})
+	m.stop = stop
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := m.Refresh(); err != nil {
+					m.agent.logger.Error("JSON mapper refresh failed", "oid", m.root, "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Close 停止后台定时刷新
+func (m *JSONMapper) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+}
+
+// refreshLocked 刷新文档，失败时保留上一次的实例，同一刷新周期内不重复调用 provider
+func (m *JSONMapper) refreshLocked() error {
+	m.refreshedAt = time.Now()
+	values, types, paths, err := m.load()
+	m.refreshErr = err
+	if err != nil {
+		return err
+	}
+	m.installLocked(values, types, paths)
+	return nil
+}
+
+// load 调用 provider 并将文档展开为实例 OID 的值、类型和 JSON 路径
+func (m *JSONMapper) load() (map[string]interface{}, map[string]gosnmp.Asn1BER, map[string]string, error) {
+	raw, err := m.provider()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("JSON provider: %w", err)
+	}
+	doc, err := decodeJSONDocument(raw)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("JSON provider: %w", err)
+	}
+
+	values := make(map[string]interface{})
+	types := make(map[string]gosnmp.Asn1BER)
+	paths := make(map[string]string)
+	switch doc.(type) {
+	case map[string]interface{}, []interface{}:
+		m.collect(values, types, paths, m.root, "$", doc)
+	default:
+		m.collect(values, types, paths, m.root+".0", "$", doc)
+	}
+	return values, types, paths, nil
+}
+
+// collect 递归展开 JSON 值，oid 和 path 为当前值的 OID 和 JSON 路径
+func (m *JSONMapper) collect(values map[string]interface{}, types map[string]gosnmp.Asn1BER, paths map[string]string, oid, path string, v interface{}) {
+	switch tv := v.(type) {
+	case map[string]interface{}:
+		keys := slices.Sorted(maps.Keys(tv))
+		seen := make(map[string]string, len(keys))
+		for _, key := range keys {
+			arcs := m.keyArcs(key)
+			if first, dup := seen[arcs]; dup {
+				m.agent.logger.Warn("JSON key hash collision, skipping", "oid", m.root, "path", path, "key", key, "kept", first)
+				continue
+			}
+			seen[arcs] = key
+			m.collect(values, types, paths, oid+"."+arcs, jsonChildPath(path, key), tv[key])
+		}
+	case []interface{}:
+		for i, child := range tv {
+			m.collect(values, types, paths, fmt.Sprintf("%s.%d", oid, i+1), fmt.Sprintf("%s[%d]", path, i), child)
+		}
+	case nil:
+	default:
+		value, oidType := inferJSONValue(tv)
+		values[oid], types[oid], paths[oid] = value, oidType, path
+	}
+}
+
+// keyArcs 返回对象键对应的 OID 分量
+func (m *JSONMapper) keyArcs(key string) string {
+	if !m.opts.HashKeys {
+		return encodeOctetsIndex([]byte(key), false)
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return strconv.FormatUint(uint64(h.Sum32()&math.MaxInt32), 10)
+}
+
+// installLocked 保存新的值，实例或类型变化时重建实例 OID，并将新实例的 JSON 路径记为说明
+func (m *JSONMapper) installLocked(values map[string]interface{}, types map[string]gosnmp.Asn1BER, paths map[string]string) {
+	m.values = values
+
+	instances := make([]string, 0, len(values))
+	for oid := range values {
+		instances = append(instances, oid)
+	}
+	sort.Strings(instances)
+	if slices.Equal(m.instances, instances) && maps.Equal(m.types, types) {
+		return
+	}
+
+	for _, oid := range instances {
+		if !m.annotated[oid] {
+			m.agent.AnnotateAbsolute(oid, OIDMeta{Description: paths[oid]})
+			m.annotated[oid] = true
+		}
+	}
+	add := make(map[string]dynamicOID, len(instances))
+	for _, oid := range instances {
+		add[oid] = dynamicOID{Type: types[oid], Handler: m.getter(oid)}
+	}
+	m.agent.replaceDynamic(m.instances, add)
+	m.instances, m.types = instances, types
+}
+
+func (m *JSONMapper) getter(oid string) ValueHandler {
+	return func() (interface{}, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if time.Since(m.refreshedAt) > tableRefreshInterval {
+			m.refreshLocked()
+		}
+		if m.refreshErr != nil {
+			return nil, m.refreshErr
+		}
+		value, ok := m.values[oid]
+		if !ok {
+			return nil, fmt.Errorf("JSON value %s no longer exists", oid)
+		}
+		return value, nil
+	}
+}
+
+// decodeJSONDocument 将 provider 的返回值解析为 JSON 值，数字保留为 json.Number
+func decodeJSONDocument(raw interface{}) (interface{}, error) {
+	var data []byte
+	switch tv := raw.(type) {
+	case []byte:
+		data = tv
+	case json.RawMessage:
+		data = tv
+	case string:
+		data = []byte(tv)
+	default:
+		encoded, err := json.Marshal(raw)
+		if err != nil {
+			return nil, err
+		}
+		data = encoded
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// inferJSONValue 按 JSON 标量推断 SNMP 类型
+func inferJSONValue(v interface{}) (interface{}, gosnmp.Asn1BER) {
+	switch tv := v.(type) {
+	case json.Number:
+		if n, err := strconv.ParseInt(tv.String(), 10, 64); err == nil {
+			if n >= math.MinInt32 && n <= math.MaxInt32 {
+				return int(n), gosnmp.Integer
+			}
+			if n > 0 {
+				return uint64(n), gosnmp.Counter64
+			}
+		}
+		if n, err := strconv.ParseUint(tv.String(), 10, 64); err == nil {
+			return n, gosnmp.Counter64
+		}
+		f, _ := tv.Float64()
+		return f, gosnmp.OpaqueDouble
+	case bool:
+		return TruthValue(tv), gosnmp.Integer
+	case string:
+		return tv, gosnmp.OctetString
+	}
+	return fmt.Sprint(v), gosnmp.OctetString
+}
+
+// jsonChildPath 返回对象成员的 JSON 路径，非标识符的键使用 ['key'] 形式
+func jsonChildPath(path, key string) string {
+	if key != "" && strings.IndexFunc(key, func(r rune) bool {
+		return !(r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+	}) < 0 {
+		return path + "." + key
+	}
+	return path + "['" + strings.ReplaceAll(key, "'", `\'`) + "']"
+}
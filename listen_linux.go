@@ -0,0 +1,32 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// socketControl 在绑定地址前设置 socket 选项：SO_REUSEPORT 允许多个 socket 监听同一地址，
+// 由内核按来源分配报文；SO_BINDTODEVICE 将 socket 绑定到网卡，只接收从该网卡到达的请求
+func socketControl(opts listenOptions) func(network, address string, c syscall.RawConn) error {
+	return func(_, _ string, c syscall.RawConn) error {
+		var sockErr error
+		if err := c.Control(func(fd uintptr) {
+			if opts.reusePort {
+				if err := unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1); err != nil {
+					sockErr = fmt.Errorf("set SO_REUSEPORT: %w", err)
+					return
+				}
+			}
+			if opts.iface != "" {
+				if err := unix.BindToDevice(int(fd), opts.iface); err != nil {
+					sockErr = fmt.Errorf("bind to interface %s: %w", opts.iface, err)
+				}
+			}
+		}); err != nil {
+			return err
+		}
+		return sockErr
+	}
+}
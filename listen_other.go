@@ -0,0 +1,18 @@
+//go:build !linux
+
+package lzsnmp
+
+import (
+	"errors"
+	"syscall"
+)
+
+// socketControl 当前平台不支持绑定网卡和 SO_REUSEPORT 多 socket 接收
+func socketControl(opts listenOptions) func(network, address string, c syscall.RawConn) error {
+	return func(string, string, syscall.RawConn) error {
+		if opts.iface != "" {
+			return errors.New("ListenInterface is only supported on Linux")
+		}
+		return errors.New("ReusePortSockets is only supported on Linux")
+	}
+}
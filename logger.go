@@ -0,0 +1,85 @@
+package lzsnmp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/charmbracelet/log"
+)
+
+// Logger Agent、Receiver、Manager、Poller 输出日志的接口，keyvals 为交替的键和值
+//
+// 默认使用 charmbracelet/log 输出到 stderr，*log.Logger 直接满足该接口；
+// 使用 log/slog 时通过 NewSlogLogger 适配，zap、zerolog 等实现这四个方法即可。
+type Logger interface {
+	Debug(msg interface{}, keyvals ...interface{})
+	Info(msg interface{}, keyvals ...interface{})
+	Warn(msg interface{}, keyvals ...interface{})
+	Error(msg interface{}, keyvals ...interface{})
+}
+
+// DiscardLogger 丢弃所有日志
+var DiscardLogger Logger = discardLogger{}
+
+type discardLogger struct{}
+
+func (discardLogger) Debug(interface{}, ...interface{}) {}
+func (discardLogger) Info(interface{}, ...interface{})  {}
+func (discardLogger) Warn(interface{}, ...interface{})  {}
+func (discardLogger) Error(interface{}, ...interface{}) {}
+
+// NewSlogLogger 将 *slog.Logger 适配为 Logger，日志级别由 slog 的 Handler 决定
+func NewSlogLogger(l *slog.Logger) Logger {
+	return slogLogger{l}
+}
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func (s slogLogger) Debug(msg interface{}, keyvals ...interface{}) {
+	s.log(slog.LevelDebug, msg, keyvals)
+}
+
+func (s slogLogger) Info(msg interface{}, keyvals ...interface{}) {
+	s.log(slog.LevelInfo, msg, keyvals)
+}
+
+func (s slogLogger) Warn(msg interface{}, keyvals ...interface{}) {
+	s.log(slog.LevelWarn, msg, keyvals)
+}
+
+func (s slogLogger) Error(msg interface{}, keyvals ...interface{}) {
+	s.log(slog.LevelError, msg, keyvals)
+}
+
+func (s slogLogger) log(level slog.Level, msg interface{}, keyvals []interface{}) {
+	s.l.Log(context.Background(), level, fmt.Sprint(msg), keyvals...)
+}
+
+// newDefaultLogger 创建默认的 charmbracelet/log 日志，输出到 stderr
+func newDefaultLogger(level log.Level, reportCaller bool) *log.Logger {
+	return log.NewWithOptions(os.Stderr, log.Options{
+		ReportTimestamp: true,
+		ReportCaller:    reportCaller,
+		Level:           level,
+		Prefix:          "lzsnmp",
+	})
+}
+
+// setLogLevel 修改默认日志的级别，自定义的 Logger 不支持时忽略
+func setLogLevel(l Logger, level log.Level) {
+	if leveled, ok := l.(interface{ SetLevel(log.Level) }); ok {
+		leveled.SetLevel(level)
+	}
+}
+
+// debugEnabled 判断是否输出 Debug 日志，用于在热路径上跳过构造日志参数；无法查询级别的日志视为输出
+func debugEnabled(l Logger) bool {
+	if leveled, ok := l.(interface{ GetLevel() log.Level }); ok {
+		return leveled.GetLevel() <= log.DebugLevel
+	}
+	return true
+}
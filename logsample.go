@@ -0,0 +1,117 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LogLimit 一类日志的采样和限速
+type LogLimit struct {
+	Every int     // 每 N 条输出 1 条，0 或 1 表示全部输出
+	Rate  float64 // 采样后每秒最多输出的条数，0 表示不限制
+}
+
+// LogSampling 每个请求输出的日志的采样和限速，批量 WALK 时避免刷屏
+//
+// 处理函数和 setter 的错误日志总是输出。被丢弃的条数附加在同类下一条输出的日志中（键为 "suppressed"）。
+type LogSampling struct {
+	Get    LogLimit // GET/GETNEXT/GETBULK 每个变量绑定的调试日志
+	Set    LogLimit // SET 请求日志
+	Packet LogLimit // 无法处理、重复和因繁忙丢弃的报文日志
+}
+
+// logLimits 按类别的日志限制器，未配置的类别为 nil，全部输出
+type logLimits struct {
+	get    *logLimiter
+	set    *logLimiter
+	packet *logLimiter
+}
+
+// newLogLimits 检查采样配置并创建限制器
+func newLogLimits(cfg LogSampling) (logLimits, error) {
+	var limits logLimits
+	for _, c := range []struct {
+		name  string
+		limit LogLimit
+		dst   **logLimiter
+	}{
+		{"Get", cfg.Get, &limits.get},
+		{"Set", cfg.Set, &limits.set},
+		{"Packet", cfg.Packet, &limits.packet},
+	} {
+		if c.limit.Every < 0 || c.limit.Rate < 0 {
+			return logLimits{}, fmt.Errorf("LogSampling.%s must not be negative", c.name)
+		}
+		*c.dst = newLogLimiter(c.limit)
+	}
+	return limits, nil
+}
+
+// logLimiter 一类日志的计数采样和令牌桶限速
+type logLimiter struct {
+	every uint64
+	rate  float64
+	burst float64
+
+	mu         sync.Mutex
+	count      uint64
+	tokens     float64
+	last       time.Time
+	suppressed uint64
+}
+
+// newLogLimiter 创建限制器，不采样也不限速时返回 nil
+func newLogLimiter(limit LogLimit) *logLimiter {
+	if limit.Every <= 1 && limit.Rate == 0 {
+		return nil
+	}
+	burst := max(limit.Rate, 1)
+	return &logLimiter{
+		every:  uint64(max(limit.Every, 1)),
+		rate:   limit.Rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// allow 判断是否输出本条日志，输出时返回自上次输出以来丢弃的条数
+func (l *logLimiter) allow() (bool, uint64) {
+	if l == nil {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.count++
+	if (l.count-1)%l.every != 0 {
+		l.suppressed++
+		return false, 0
+	}
+	if l.rate > 0 {
+		now := time.Now()
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+		l.last = now
+		if l.tokens < 1 {
+			l.suppressed++
+			return false, 0
+		}
+		l.tokens--
+	}
+	suppressed := l.suppressed
+	l.suppressed = 0
+	return true, suppressed
+}
+
+// logSampled 经过 l 的采样和限速后调用 log 输出日志
+func logSampled(l *logLimiter, log func(msg interface{}, keyvals ...interface{}), msg string, keyvals ...interface{}) {
+	ok, suppressed := l.allow()
+	if !ok {
+		return
+	}
+	if suppressed > 0 {
+		keyvals = append(keyvals, "suppressed", suppressed)
+	}
+	log(msg, keyvals...)
+}
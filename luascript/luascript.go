@@ -0,0 +1,358 @@
+// Package luascript 使用内嵌的 Lua 解释器（gopher-lua）实现 OID 处理函数，
+// 运维人员可以在配置文件中用脚本添加 OID，无需重新编译
+//
+// 单独成包，不使用脚本时不引入 gopher-lua 依赖。
+package luascript
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	lzsnmp "github.com/liuzhen9320/snmp-go"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// DefaultTimeout 每次调用脚本的默认超时，包括脚本中 exec、http_get 的耗时
+const DefaultTimeout = 5 * time.Second
+
+// 辅助函数的输出大小上限，超出时脚本出错
+const (
+	maxExecOutput = 64 * 1024
+	maxHTTPBody   = 4 << 20
+	maxFileSize   = 4 << 20
+)
+
+// Options 脚本 OID 的注册选项
+type Options struct {
+	Timeout     time.Duration // 每次调用 get / set 的超时，默认 DefaultTimeout
+	Name        string        // 文档信息，见 lzsnmp.RegisterOpts
+	Description string
+	Units       string
+}
+
+// Script 编译后的脚本，get / set 在同一个 Lua 状态中串行执行，脚本可以用全局变量保存状态
+type Script struct {
+	name    string
+	oidType gosnmp.Asn1BER
+	timeout time.Duration
+
+	mu    sync.Mutex
+	state *lua.LState
+	get   *lua.LFunction
+	set   *lua.LFunction
+}
+
+// Compile 编译并执行脚本的顶层代码，name 用于错误信息
+//
+// 脚本需要定义 get() 函数，返回值按 oidType 解析：数字和字符串同 lzsnmp.ParseValue，布尔为 TruthValue；
+// 返回 nil, "错误信息" 时请求失败。定义了 set(value) 时 OID 可写，value 为数字或字符串，
+// 同样可以返回 nil, "错误信息" 拒绝写入。
+//
+// 脚本只能使用 base、table、string、math 标准库和以下辅助函数，失败时抛出 Lua 错误（可用 pcall 捕获）：
+//   - exec(cmd, args...): 运行外部程序（不经过 shell），返回去掉末尾换行的标准输出，退出码非 0 时出错
+//   - http_get(url): 返回响应体，状态码不是 2xx 时出错
+//   - read_file(path): 返回文件内容
+func Compile(name, source string, oidType gosnmp.Asn1BER, timeout time.Duration) (*Script, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	// base 库中可以加载任意文件的函数
+	for _, unsafe := range []string{"dofile", "loadfile"} {
+		L.SetGlobal(unsafe, lua.LNil)
+	}
+	L.SetGlobal("exec", L.NewFunction(luaExec))
+	L.SetGlobal("http_get", L.NewFunction(luaHTTPGet))
+	L.SetGlobal("read_file", L.NewFunction(luaReadFile))
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	L.SetContext(ctx)
+	err := L.DoString(source)
+	L.RemoveContext()
+	if err != nil {
+		L.Close()
+		return nil, fmt.Errorf("script %s: %w", name, err)
+	}
+
+	s := &Script{name: name, oidType: oidType, timeout: timeout, state: L}
+	var ok bool
+	if s.get, ok = L.GetGlobal("get").(*lua.LFunction); !ok {
+		L.Close()
+		return nil, fmt.Errorf("script %s: function get() is not defined", name)
+	}
+	s.set, _ = L.GetGlobal("set").(*lua.LFunction)
+	return s, nil
+}
+
+// Writable 判断脚本是否定义了 set(value)
+func (s *Script) Writable() bool {
+	return s.set != nil
+}
+
+// Get 调用 get() 并按类型解析返回值，可以作为 lzsnmp.ValueHandler
+func (s *Script) Get() (interface{}, error) {
+	ret, err := s.call(s.get)
+	if err != nil {
+		return nil, err
+	}
+	return s.value(ret)
+}
+
+// Set 调用 set(value)，可以作为 lzsnmp.SetHandler
+func (s *Script) Set(value interface{}) error {
+	if s.set == nil {
+		return fmt.Errorf("script %s: function set(value) is not defined", s.name)
+	}
+	_, err := s.call(s.set, luaValue(value))
+	return err
+}
+
+// call 在超时内调用 fn，fn 返回 nil, "错误信息" 时返回错误
+func (s *Script) call(fn *lua.LFunction, args ...lua.LValue) (lua.LValue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	s.state.SetContext(ctx)
+	defer s.state.RemoveContext()
+
+	if err := s.state.CallByParam(lua.P{Fn: fn, NRet: 2, Protect: true}, args...); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("script %s timed out after %s", s.name, s.timeout)
+		}
+		return nil, fmt.Errorf("script %s: %w", s.name, err)
+	}
+	ret, msg := s.state.Get(-2), s.state.Get(-1)
+	s.state.Pop(2)
+	if ret == lua.LNil && msg != lua.LNil {
+		return nil, fmt.Errorf("script %s: %s", s.name, msg.String())
+	}
+	return ret, nil
+}
+
+// value 将 get() 的返回值转换为 oidType 对应的值
+func (s *Script) value(ret lua.LValue) (interface{}, error) {
+	var text string
+	switch v := ret.(type) {
+	case lua.LBool:
+		if s.oidType != gosnmp.Integer {
+			return nil, fmt.Errorf("script %s returned a boolean for %s", s.name, s.oidType)
+		}
+		return lzsnmp.TruthValue(bool(v)), nil
+	case lua.LNumber:
+		text = strconv.FormatFloat(float64(v), 'f', -1, 64)
+	case lua.LString:
+		text = string(v)
+	default:
+		return nil, fmt.Errorf("script %s returned %s, want number or string", s.name, ret.Type())
+	}
+	value, err := lzsnmp.ParseValue(s.oidType, text, false)
+	if err != nil {
+		return nil, fmt.Errorf("script %s returned invalid %s value %q: %w", s.name, s.oidType, text, err)
+	}
+	return value, nil
+}
+
+// Register 注册由 Lua 脚本提供值的绝对路径 OID，脚本写法见 Compile
+func Register(a *lzsnmp.Agent, oid string, oidType gosnmp.Asn1BER, source string, opts Options) error {
+	s, err := Compile(oid, source, oidType, opts.Timeout)
+	if err != nil {
+		return err
+	}
+	ro := lzsnmp.RegisterOpts{Name: opts.Name, Description: opts.Description, Units: opts.Units}
+	if s.Writable() {
+		ro.Setter = s.Set
+	}
+	return a.RegisterAbsoluteWithOpts(oid, oidType, s.Get, ro)
+}
+
+// RegisterScripts 注册配置文件中的脚本 OID，File 的相对路径相对于 baseDir（通常为配置文件所在目录）
+//
+// 重新加载配置文件后再次调用时，同名 OID 使用新的脚本。
+func RegisterScripts(a *lzsnmp.Agent, fc *lzsnmp.FileConfig, baseDir string) error {
+	for _, sc := range fc.Scripts {
+		oidType, err := lzsnmp.ParseType(sc.Type)
+		if err != nil {
+			return fmt.Errorf("script %s: %w", sc.OID, err)
+		}
+		source, err := scriptSource(sc, baseDir)
+		if err != nil {
+			return fmt.Errorf("script %s: %w", sc.OID, err)
+		}
+		var timeout time.Duration
+		if sc.Timeout != "" {
+			if timeout, err = time.ParseDuration(sc.Timeout); err != nil {
+				return fmt.Errorf("script %s: invalid timeout: %w", sc.OID, err)
+			}
+		}
+
+		oid := sc.OID
+		if !strings.HasPrefix(oid, ".") {
+			if oid, err = a.OIDIn(sc.Prefix, oid); err != nil {
+				return fmt.Errorf("script %s: %w", sc.OID, err)
+			}
+		}
+		opts := Options{Timeout: timeout, Name: sc.Name, Description: sc.Description, Units: sc.Units}
+		if err := Register(a, oid, oidType, source, opts); err != nil {
+			return fmt.Errorf("script %s: %w", sc.OID, err)
+		}
+	}
+	return nil
+}
+
+// scriptSource 返回内联脚本或读取脚本文件，两者只能指定一个
+func scriptSource(sc lzsnmp.FileScript, baseDir string) (string, error) {
+	switch {
+	case sc.Script != "" && sc.File != "":
+		return "", fmt.Errorf("script and file are mutually exclusive")
+	case sc.Script != "":
+		return sc.Script, nil
+	case sc.File != "":
+		path := sc.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		data, err := os.ReadFile(path)
+		return string(data), err
+	}
+	return "", fmt.Errorf("script or file is required")
+}
+
+// luaValue 将 SET 写入的值转换为 Lua 值
+func luaValue(value interface{}) lua.LValue {
+	switch v := value.(type) {
+	case int:
+		return lua.LNumber(v)
+	case int64:
+		return lua.LNumber(v)
+	case uint:
+		return lua.LNumber(v)
+	case uint32:
+		return lua.LNumber(v)
+	case uint64:
+		return lua.LNumber(v)
+	case float32:
+		return lua.LNumber(v)
+	case float64:
+		return lua.LNumber(v)
+	case string:
+		return lua.LString(v)
+	case []byte:
+		return lua.LString(v)
+	}
+	return lua.LString(fmt.Sprint(value))
+}
+
+// luaExec exec(cmd, args...)
+func luaExec(L *lua.LState) int {
+	args := []string{L.CheckString(1)}
+	for i := 2; i <= L.GetTop(); i++ {
+		args = append(args, L.CheckString(i))
+	}
+
+	var stdout, stderr bytes.Buffer
+	c := exec.CommandContext(L.Context(), args[0], args[1:]...)
+	c.Stdout = &limitedWriter{buf: &stdout, max: maxExecOutput}
+	c.Stderr = &limitedWriter{buf: &stderr, max: 1024}
+	c.WaitDelay = time.Second
+
+	err := c.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		L.RaiseError("%s exited with code %d: %s", args[0], exitErr.ExitCode(), strings.TrimSpace(stderr.String()))
+	case err != nil:
+		L.RaiseError("failed to run %s: %v", args[0], err)
+	case stdout.Len() > maxExecOutput:
+		L.RaiseError("output of %s exceeds %d bytes", args[0], maxExecOutput)
+	}
+	L.Push(lua.LString(strings.TrimRight(stdout.String(), "\r\n")))
+	return 1
+}
+
+// luaHTTPGet http_get(url)
+func luaHTTPGet(L *lua.LState) int {
+	url := L.CheckString(1)
+	req, err := http.NewRequestWithContext(L.Context(), http.MethodGet, url, nil)
+	if err != nil {
+		L.RaiseError("GET %s: %v", url, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		L.RaiseError("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		L.RaiseError("GET %s: %s", url, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPBody+1))
+	if err != nil {
+		L.RaiseError("GET %s: %v", url, err)
+	}
+	if len(body) > maxHTTPBody {
+		L.RaiseError("GET %s: response exceeds %d bytes", url, maxHTTPBody)
+	}
+	L.Push(lua.LString(body))
+	return 1
+}
+
+// luaReadFile read_file(path)
+func luaReadFile(L *lua.LState) int {
+	path := L.CheckString(1)
+	f, err := os.Open(path)
+	if err != nil {
+		L.RaiseError("%v", err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxFileSize+1))
+	if err != nil {
+		L.RaiseError("%v", err)
+	}
+	if len(data) > maxFileSize {
+		L.RaiseError("%s exceeds %d bytes", path, maxFileSize)
+	}
+	L.Push(lua.LString(data))
+	return 1
+}
+
+// limitedWriter 最多保存 max+1 字节，多余的输出被丢弃，用于判断是否超出上限
+type limitedWriter struct {
+	buf *bytes.Buffer
+	max int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if room := w.max + 1 - w.buf.Len(); room > 0 {
+		w.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
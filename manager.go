@@ -0,0 +1,423 @@
+package lzsnmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/gosnmp/gosnmp"
+)
+
+// ErrNoSuchObject 远端返回 noSuchObject、noSuchInstance 或 endOfMibView，用 errors.Is 判断
+var ErrNoSuchObject = errors.New("no such object")
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// ManagerConfig 访问远端 Agent 的客户端配置
+type ManagerConfig struct {
+	Address        string             // 远端地址，如 "10.0.0.2:161"，省略端口时为 161
+	Version        gosnmp.SnmpVersion // 与 gosnmp 相同，零值为 Version1；设置 User 时为 Version3
+	Community      string             // v1/v2c 的 community，默认 "public"
+	User           *User              // Version3 使用的 USM 用户
+	Timeout        time.Duration      // 单次请求超时，默认 2 秒
+	Retries        int                // 超时后的重发次数
+	MaxRepetitions uint32             // BulkWalk 每个 GETBULK 请求的最大重复数，默认 25
+
+	LogLevel log.Level
+	Logger   Logger
+}
+
+// Manager 访问一个远端 Agent 的客户端，请求串行执行，可以被多个协程共享
+type Manager struct {
+	address string
+	logger  Logger
+
+	mu     sync.Mutex
+	client *gosnmp.GoSNMP
+	closed bool
+}
+
+// NewManager 创建访问 cfg.Address 的客户端，UDP 无连接，创建时不检查远端是否可达
+func NewManager(cfg ManagerConfig) (*Manager, error) {
+	client, err := newProxyClient(ProxyTarget{
+		Address:   cfg.Address,
+		Version:   cfg.Version,
+		Community: cfg.Community,
+		User:      cfg.User,
+		Timeout:   cfg.Timeout,
+		Retries:   cfg.Retries,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("manager: %w", err)
+	}
+	if cfg.MaxRepetitions > 0 {
+		client.MaxRepetitions = cfg.MaxRepetitions
+	}
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = newDefaultLogger(cfg.LogLevel, false)
+	}
+
+	return &Manager{address: cfg.Address, logger: logger, client: client}, nil
+}
+
+// Close 关闭与远端的连接
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+	return m.client.Conn.Close()
+}
+
+// do 持有锁并绑定 ctx 执行一次请求，ctx 取消时立即中断等待中的读取
+func (m *Manager) do(ctx context.Context, op string, fn func(client *gosnmp.GoSNMP) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return fmt.Errorf("manager for %s is closed", m.address)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.client.Context = ctx
+	stop := context.AfterFunc(ctx, func() {
+		m.client.Conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	err := fn(m.client)
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		err = ctxErr
+	}
+	if err != nil {
+		m.logger.Debug("SNMP request failed", "op", op, "target", m.address, "error", err)
+		return fmt.Errorf("%s %s: %w", op, m.address, err)
+	}
+	return nil
+}
+
+// Get 读取一个或多个 OID，结果与 oids 顺序相同
+//
+// 超过 gosnmp.MaxOids 个 OID 时分多个请求发送。不存在的 OID 不返回错误，
+// 对应结果的 Type 为 NoSuchObject / NoSuchInstance，v1 的 noSuchName 也转换为 NoSuchObject。
+func (m *Manager) Get(ctx context.Context, oids ...string) ([]gosnmp.SnmpPDU, error) {
+	names := make([]string, len(oids))
+	for i, oid := range oids {
+		oid, err := normalizeOID(oid)
+		if err != nil {
+			return nil, err
+		}
+		names[i] = "." + oid
+	}
+
+	result := make([]gosnmp.SnmpPDU, 0, len(names))
+	err := m.do(ctx, "get", func(client *gosnmp.GoSNMP) error {
+		for len(names) > 0 {
+			n := min(len(names), gosnmp.MaxOids)
+			vars, err := getChunk(client, names[:n])
+			if err != nil {
+				return err
+			}
+			result = append(result, vars...)
+			names = names[n:]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// getChunk 读取一组 OID；远端以 noSuchName 错误状态报告缺失的变量（SNMPv1）时，
+// 将该变量标记为 NoSuchObject 并重新请求其余变量
+func getChunk(client *gosnmp.GoSNMP, names []string) ([]gosnmp.SnmpPDU, error) {
+	result := make([]gosnmp.SnmpPDU, len(names))
+	pending := make([]int, len(names))
+	for i := range pending {
+		pending[i] = i
+	}
+
+	for len(pending) > 0 {
+		req := make([]string, len(pending))
+		for i, idx := range pending {
+			req[i] = names[idx]
+		}
+		pkt, err := client.Get(req)
+		if err != nil {
+			return nil, err
+		}
+		if pkt.Error == gosnmp.NoSuchName {
+			if len(pkt.Variables) == len(req) && slices.ContainsFunc(pkt.Variables, func(v gosnmp.SnmpPDU) bool { return isNoSuch(v.Type) }) {
+				// 部分 v2c 实现在返回异常值的同时设置 noSuchName
+				pkt.Error = gosnmp.NoError
+			} else if i := int(pkt.ErrorIndex); i >= 1 && i <= len(pending) {
+				result[pending[i-1]] = gosnmp.SnmpPDU{Name: req[i-1], Type: gosnmp.NoSuchObject}
+				pending = slices.Delete(pending, i-1, i)
+				continue
+			}
+		}
+		if err := packetError(pkt, req); err != nil {
+			return nil, err
+		}
+		if len(pkt.Variables) != len(req) {
+			return nil, fmt.Errorf("response has %d variables, expected %d", len(pkt.Variables), len(req))
+		}
+		for i, v := range pkt.Variables {
+			result[pending[i]] = v
+		}
+		break
+	}
+	return result, nil
+}
+
+// GetInto 读取一个 OID 并解码到 dst，解码规则见 DecodeValue
+func (m *Manager) GetInto(ctx context.Context, oid string, dst interface{}) error {
+	vars, err := m.Get(ctx, oid)
+	if err != nil {
+		return err
+	}
+	return DecodeValue(vars[0], dst)
+}
+
+// GetStruct 按结构体的 snmp 标签读取 baseOID 下的 OID 并填充字段
+//
+// 标签格式与 Bind 相同，OID 相对于 baseOID，类型和 rw 选项被忽略，如：
+//
+//	var sys struct {
+//		Descr  string        `snmp:"1.0"`
+//		Uptime time.Duration `snmp:"3.0"`
+//		Name   string        `snmp:"5.0"`
+//	}
+//	err := m.GetStruct(ctx, "1.3.6.1.2.1.1", &sys)
+//
+// 远端不存在的 OID 保持字段原值；结构体实现 sync.Locker 时写入字段前加锁。
+func (m *Manager) GetStruct(ctx context.Context, baseOID string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decode target must be a non-nil pointer to struct, got %T", v)
+	}
+	base, err := normalizeOID(baseOID)
+	if err != nil {
+		return err
+	}
+	fields, err := parseStructFields(rv.Elem())
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("no snmp tagged fields in %T", v)
+	}
+
+	oids := make([]string, len(fields))
+	for i, f := range fields {
+		oids[i] = fmt.Sprintf("%s.%s", base, f.relativeOID)
+	}
+	vars, err := m.Get(ctx, oids...)
+	if err != nil {
+		return err
+	}
+
+	if locker, ok := v.(sync.Locker); ok {
+		locker.Lock()
+		defer locker.Unlock()
+	}
+	for i, f := range fields {
+		if isNoSuch(vars[i].Type) {
+			continue
+		}
+		if err := decodeField(vars[i], f); err != nil {
+			return fmt.Errorf("field %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// parseStructFields 解析结构体中带 snmp 标签的字段，与 parseBoundFields 不同，不要求能推断出 SNMP 类型
+func parseStructFields(sv reflect.Value) ([]boundField, error) {
+	st := sv.Type()
+	var fields []boundField
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		tag, ok := sf.Tag.Lookup("snmp")
+		if !ok || tag == "" || tag == "-" {
+			continue
+		}
+		if !sf.IsExported() {
+			return nil, fmt.Errorf("field %s.%s: snmp tag on unexported field", st.Name(), sf.Name)
+		}
+		f := boundField{
+			relativeOID: strings.TrimSpace(strings.Split(tag, ",")[0]),
+			name:        sf.Name,
+			field:       sv.Field(i),
+		}
+		if f.relativeOID == "" {
+			return nil, fmt.Errorf("field %s.%s: empty OID in snmp tag", st.Name(), sf.Name)
+		}
+		_, f.atomic = atomicLoadType(f.field)
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// decodeField 将变量的值写入字段，sync/atomic 类型的字段通过 Store 写入
+func decodeField(pdu gosnmp.SnmpPDU, f boundField) error {
+	if f.atomic {
+		store := f.field.Addr().MethodByName("Store")
+		converted, err := decodeValue(pdu, store.Type().In(0))
+		if err != nil {
+			return err
+		}
+		store.Call([]reflect.Value{converted})
+		return nil
+	}
+	converted, err := decodeValue(pdu, f.field.Type())
+	if err != nil {
+		return err
+	}
+	f.field.Set(converted)
+	return nil
+}
+
+// DecodeValue 将变量的值解码到 dst 指向的 Go 值
+//
+// 支持与 Bind 相同的字段类型（整数、浮点数、string、[]byte、net.IP、bool 按 TruthValue），
+// 另外 time.Duration 接受 TimeTicks，*interface{} 接收 gosnmp 原始值。
+// ObjectIdentifier 解码为不带前导点的字符串；变量为 noSuchObject 等时返回 ErrNoSuchObject。
+func DecodeValue(pdu gosnmp.SnmpPDU, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("decode target must be a non-nil pointer, got %T", dst)
+	}
+	converted, err := decodeValue(pdu, rv.Elem().Type())
+	if err != nil {
+		return err
+	}
+	rv.Elem().Set(converted)
+	return nil
+}
+
+// decodeValue 将变量的值转换为类型 t
+func decodeValue(pdu gosnmp.SnmpPDU, t reflect.Type) (reflect.Value, error) {
+	name := strings.TrimPrefix(pdu.Name, ".")
+	if isNoSuch(pdu.Type) {
+		return reflect.Value{}, fmt.Errorf("%s: %w (%v)", name, ErrNoSuchObject, pdu.Type)
+	}
+
+	value := pdu.Value
+	if pdu.Type == gosnmp.ObjectIdentifier {
+		if s, ok := value.(string); ok {
+			value = strings.TrimPrefix(s, ".")
+		}
+	}
+
+	switch {
+	case t.Kind() == reflect.Interface && value == nil:
+		return reflect.Zero(t), nil
+	case t.Kind() == reflect.Interface && reflect.TypeOf(value).AssignableTo(t):
+		return reflect.ValueOf(value), nil
+	case t == durationType && pdu.Type == gosnmp.TimeTicks:
+		ticks, err := toInt64(value)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%s: %w", name, err)
+		}
+		return reflect.ValueOf(time.Duration(ticks) * 10 * time.Millisecond), nil
+	}
+
+	converted, err := convertToType(value, t)
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return converted, nil
+}
+
+// isNoSuch 是否为表示不存在的异常值
+func isNoSuch(t gosnmp.Asn1BER) bool {
+	return t == gosnmp.NoSuchObject || t == gosnmp.NoSuchInstance || t == gosnmp.EndOfMibView
+}
+
+// Walk 使用 GETNEXT 遍历 rootOID 子树，对每个实例调用 fn，fn 返回错误时停止
+func (m *Manager) Walk(ctx context.Context, rootOID string, fn gosnmp.WalkFunc) error {
+	return m.walk(ctx, "walk", rootOID, fn, false)
+}
+
+// BulkWalk 使用 GETBULK 遍历 rootOID 子树，v1 时退化为 GETNEXT
+func (m *Manager) BulkWalk(ctx context.Context, rootOID string, fn gosnmp.WalkFunc) error {
+	return m.walk(ctx, "bulkwalk", rootOID, fn, true)
+}
+
+func (m *Manager) walk(ctx context.Context, op, rootOID string, fn gosnmp.WalkFunc, bulk bool) error {
+	root, err := normalizeOID(rootOID)
+	if err != nil {
+		return err
+	}
+	return m.do(ctx, op, func(client *gosnmp.GoSNMP) error {
+		walk := client.Walk
+		if bulk && client.Version != gosnmp.Version1 {
+			walk = client.BulkWalk
+		}
+		return walk("."+root, func(pdu gosnmp.SnmpPDU) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return fn(pdu)
+		})
+	})
+}
+
+// Set 在一个请求中设置一个或多个变量，值按 Type 规范化（与 Register 的值相同），
+// 远端返回错误状态时返回包含出错 OID 的错误
+func (m *Manager) Set(ctx context.Context, vars ...gosnmp.SnmpPDU) error {
+	if len(vars) == 0 {
+		return nil
+	}
+	pdus := make([]gosnmp.SnmpPDU, len(vars))
+	names := make([]string, len(vars))
+	for i, v := range vars {
+		oid, err := normalizeOID(v.Name)
+		if err != nil {
+			return err
+		}
+		value, err := normalizeValue(v.Type, v.Value)
+		if err != nil {
+			return fmt.Errorf("set %s: %w", oid, err)
+		}
+		names[i] = "." + oid
+		pdus[i] = gosnmp.SnmpPDU{Name: names[i], Type: v.Type, Value: value}
+	}
+
+	return m.do(ctx, "set", func(client *gosnmp.GoSNMP) error {
+		pkt, err := client.Set(pdus)
+		if err != nil {
+			return err
+		}
+		return packetError(pkt, names)
+	})
+}
+
+// SetValue 设置单个 OID
+func (m *Manager) SetValue(ctx context.Context, oid string, oidType gosnmp.Asn1BER, value interface{}) error {
+	return m.Set(ctx, gosnmp.SnmpPDU{Name: oid, Type: oidType, Value: value})
+}
+
+// packetError 将响应的错误状态转换为错误，ErrorIndex 从 1 开始指向出错的变量
+func packetError(pkt *gosnmp.SnmpPacket, names []string) error {
+	if pkt.Error == gosnmp.NoError {
+		return nil
+	}
+	if i := int(pkt.ErrorIndex); i >= 1 && i <= len(names) {
+		return fmt.Errorf("%s: %s", strings.TrimPrefix(names[i-1], "."), pkt.Error)
+	}
+	return fmt.Errorf("%s", pkt.Error)
+}
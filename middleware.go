@@ -0,0 +1,114 @@
+package lzsnmp
+
+import (
+	"context"
+	"net"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// RequestInfo 一次变量绑定解析的请求信息
+type RequestInfo struct {
+	Source       net.Addr           // 请求来源地址
+	Version      gosnmp.SnmpVersion // SNMP 版本
+	Community    string             // v1/v2c community，v3 时为空
+	SecurityName string             // v3 用户名，v1/v2c 时与 Community 相同
+	Security     SecurityLevel      // 请求的安全级别，v1/v2c 时为 SecurityNoAuthNoPriv
+	PDUType      gosnmp.PDUType     // 请求 PDU 类型，如 GetNextRequest
+	OID          string             // 正在解析的实例 OID
+	Value        interface{}        // SET 请求写入的值，GET 时为 nil
+	RequestID    string             // 请求 ID，与该请求的日志中 "request" 键的值相同
+	Context      context.Context    // 请求的 context，包含请求 ID 和 Tracer 创建的 span
+}
+
+// HandlerFunc 解析一个变量绑定，GET 时返回值，SET 时写入 req.Value 并返回 nil
+type HandlerFunc func(req *RequestInfo) (interface{}, error)
+
+// Middleware 包装 HandlerFunc，可以在解析前后执行逻辑或直接返回错误拒绝请求
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// requestContext worker 正在处理的请求，只由该 worker 读写
+type requestContext struct {
+	id     string
+	ctx    context.Context
+	source net.Addr
+	pkt    *gosnmp.SnmpPacket
+}
+
+// Use 添加中间件，按添加顺序由外到内执行，对 GET/GETNEXT/GETBULK/SET 的每个变量绑定生效
+//
+// 中间件返回的错误与处理函数返回的错误处理方式相同。
+func (a *Agent) Use(mw ...Middleware) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var chain []Middleware
+	if old := a.middleware.Load(); old != nil {
+		chain = append(chain, *old...)
+	}
+	chain = append(chain, mw...)
+	a.middleware.Store(&chain)
+	a.logger.Info("Registered middleware", "count", len(chain))
+}
+
+// resolve 通过中间件链调用 final，w 为处理请求的 worker
+func (a *Agent) resolve(w *worker, oid string, value interface{}, final HandlerFunc) (result interface{}, err error) {
+	var chain []Middleware
+	if p := a.middleware.Load(); p != nil {
+		chain = *p
+	}
+
+	h := final
+	for i := len(chain) - 1; i >= 0; i-- {
+		h = chain[i](h)
+	}
+	req := w.requestInfo(oid, value)
+	var span Span
+	if a.tracing {
+		req.Context, span = a.tracer.Start(req.Context, "snmp.handler", "snmp.oid", oid)
+	} else {
+		req.Context, span = a.tracer.Start(req.Context, "snmp.handler")
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			span.End(errHandlerPanic)
+			panic(r)
+		}
+		span.End(err)
+	}()
+	return h(req)
+}
+
+// requestInfo 根据 worker 正在处理的请求生成 RequestInfo
+func (w *worker) requestInfo(oid string, value interface{}) *RequestInfo {
+	req := requestInfoOf(w.current.source, w.current.pkt)
+	req.OID = oid
+	req.Value = value
+	req.RequestID = w.current.id
+	req.Context = w.current.ctx
+	if req.Context == nil {
+		req.Context = context.Background()
+	}
+	return req
+}
+
+// requestInfoOf 从解码后的请求中提取来源、版本和安全名，pkt 可以为 nil
+func requestInfoOf(source net.Addr, pkt *gosnmp.SnmpPacket) *RequestInfo {
+	req := &RequestInfo{Source: source}
+	if pkt == nil {
+		return req
+	}
+
+	req.Version = pkt.Version
+	req.PDUType = pkt.PDUType
+	req.Security = securityLevelOf(pkt)
+	if pkt.Version == gosnmp.Version3 {
+		if usm, ok := pkt.SecurityParameters.(*gosnmp.UsmSecurityParameters); ok {
+			req.SecurityName = usm.UserName
+		}
+	} else {
+		req.Community = pkt.Community
+		req.SecurityName = pkt.Community
+	}
+	return req
+}
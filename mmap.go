@@ -0,0 +1,161 @@
+package lzsnmp
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// MappedFile 以只读方式映射到内存的值文件，由其他进程写入
+//
+// 每次 GET 直接读取映射的内存，其他进程写入后立即可见，无需系统调用。
+// 整数按本机字节序存储，8 字节和 4 字节的值必须按自身大小对齐，
+// 写入方使用原子写（如 C 的 __atomic_store_n、Go 的 sync/atomic）即可保证读到完整的值。
+type MappedFile struct {
+	agent *Agent
+	path  string
+
+	mu     sync.RWMutex
+	data   []byte
+	oids   []string
+	closed bool
+}
+
+// OpenMappedFile 映射 path 指向的文件，映射长度为打开时的文件大小
+func (a *Agent) OpenMappedFile(path string) (*MappedFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open mapped file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat mapped file: %w", err)
+	}
+	if info.Size() == 0 {
+		return nil, fmt.Errorf("mapped file is empty: %s", path)
+	}
+
+	data, err := mapFile(f, int(info.Size()))
+	if err != nil {
+		return nil, fmt.Errorf("map %s: %w", path, err)
+	}
+	a.logger.Info("Mapped value file", "path", path, "size", len(data))
+	return &MappedFile{agent: a, path: path, data: data}, nil
+}
+
+// RegisterUint64 将 offset 处的 8 字节无符号整数注册为相对 OID
+func (m *MappedFile) RegisterUint64(relativeOID string, oidType gosnmp.Asn1BER, offset int) error {
+	if err := m.checkField(offset, 8, 8); err != nil {
+		return err
+	}
+	return m.register(relativeOID, oidType, func(data []byte) interface{} {
+		return atomic.LoadUint64((*uint64)(unsafe.Pointer(&data[offset])))
+	})
+}
+
+// RegisterUint32 将 offset 处的 4 字节无符号整数注册为相对 OID
+func (m *MappedFile) RegisterUint32(relativeOID string, oidType gosnmp.Asn1BER, offset int) error {
+	if err := m.checkField(offset, 4, 4); err != nil {
+		return err
+	}
+	return m.register(relativeOID, oidType, func(data []byte) interface{} {
+		return atomic.LoadUint32((*uint32)(unsafe.Pointer(&data[offset])))
+	})
+}
+
+// RegisterInt64 将 offset 处的 8 字节有符号整数注册为相对 OID
+func (m *MappedFile) RegisterInt64(relativeOID string, oidType gosnmp.Asn1BER, offset int) error {
+	if err := m.checkField(offset, 8, 8); err != nil {
+		return err
+	}
+	return m.register(relativeOID, oidType, func(data []byte) interface{} {
+		return atomic.LoadInt64((*int64)(unsafe.Pointer(&data[offset])))
+	})
+}
+
+// RegisterFloat64 将 offset 处的 IEEE 754 双精度浮点数注册为相对 OID
+func (m *MappedFile) RegisterFloat64(relativeOID string, oidType gosnmp.Asn1BER, offset int) error {
+	if err := m.checkField(offset, 8, 8); err != nil {
+		return err
+	}
+	return m.register(relativeOID, oidType, func(data []byte) interface{} {
+		return math.Float64frombits(atomic.LoadUint64((*uint64)(unsafe.Pointer(&data[offset]))))
+	})
+}
+
+// RegisterString 将 offset 处最长 size 字节、以 NUL 结尾的字符串注册为相对 OID
+//
+// 字符串不能原子读取，写入方需要自行保证读者不会看到写了一半的内容。
+func (m *MappedFile) RegisterString(relativeOID string, offset, size int) error {
+	if err := m.checkField(offset, size, 1); err != nil {
+		return err
+	}
+	return m.register(relativeOID, gosnmp.OctetString, func(data []byte) interface{} {
+		field := data[offset : offset+size]
+		if i := bytes.IndexByte(field, 0); i >= 0 {
+			field = field[:i]
+		}
+		return string(field)
+	})
+}
+
+// Close 注销通过该文件注册的所有 OID 并解除映射
+func (m *MappedFile) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+
+	m.agent.replaceDynamic(m.oids, nil)
+	err := unmapFile(m.data)
+	m.data = nil
+	m.agent.logger.Info("Unmapped value file", "path", m.path, "oids", len(m.oids))
+	return err
+}
+
+// checkField 检查字段是否位于映射范围内并满足对齐要求
+func (m *MappedFile) checkField(offset, size, align int) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.closed {
+		return fmt.Errorf("mapped file is closed: %s", m.path)
+	}
+	if size <= 0 || offset < 0 || offset+size > len(m.data) {
+		return fmt.Errorf("field at offset %d with size %d is outside mapped file %s (%d bytes)", offset, size, m.path, len(m.data))
+	}
+	if offset%align != 0 {
+		return fmt.Errorf("offset %d is not %d-byte aligned", offset, align)
+	}
+	return nil
+}
+
+// register 注册读取映射内存的动态 OID，文件关闭后读取返回错误
+func (m *MappedFile) register(relativeOID string, oidType gosnmp.Asn1BER, read func(data []byte) interface{}) error {
+	oid := fmt.Sprintf("%s.%s", m.agent.oidPrefix, relativeOID)
+	handler := func() (interface{}, error) {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		if m.closed {
+			return nil, fmt.Errorf("mapped file is closed: %s", m.path)
+		}
+		return read(m.data), nil
+	}
+	if err := m.agent.registerDynamic(oid, oidType, handler, nil); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.oids = append(m.oids, oid)
+	m.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package lzsnmp
+
+import (
+	"errors"
+	"os"
+)
+
+// mapFile 当前平台不支持内存映射
+func mapFile(f *os.File, size int) ([]byte, error) {
+	return nil, errors.New("memory-mapped files are not supported on this platform")
+}
+
+func unmapFile(data []byte) error {
+	return nil
+}
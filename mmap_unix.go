@@ -0,0 +1,17 @@
+//go:build unix
+
+package lzsnmp
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile 以共享只读方式映射文件的前 size 字节
+func mapFile(f *os.File, size int) ([]byte, error) {
+	return syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+}
+
+func unmapFile(data []byte) error {
+	return syscall.Munmap(data)
+}
@@ -0,0 +1,410 @@
+package lzsnmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Module 可独立初始化的功能模块，如采集器或数据源，在 Init 中注册 OID
+type Module struct {
+	Name      string
+	DependsOn []string // 依赖的模块名，依赖全部初始化成功后才会调用 Init
+	Init      func(a *Agent) error
+	// Stop 停用模块时调用，应注销 Init 注册的 OID 并停止后台任务；为 nil 时模块不能停用
+	Stop func(a *Agent) error
+	// Run 后台任务（如定期采集），Init 成功后在独立 goroutine 中运行，ctx 在模块停用或 Agent 停止时取消；
+	// panic 或返回错误时按指数退避重启，返回 nil 表示任务正常结束
+	Run func(ctx context.Context, a *Agent) error
+	// Disabled 为 true 时 Start 不初始化该模块，之后可以通过 EnableModule 启用
+	Disabled bool
+	// Capability 模块实现的 MIB 模块或 AGENT-CAPABILITIES 的 OID，Init 成功后添加到 sysORTable，停用时删除
+	Capability string
+	// Description Capability 在 sysORTable 中的说明（sysORDescr）
+	Description string
+}
+
+// ErrModuleNotFound EnableModule 和 DisableModule 的模块不存在
+var ErrModuleNotFound = errors.New("module not found")
+
+// ModuleState 模块状态
+type ModuleState string
+
+const (
+	ModulePending    ModuleState = "pending"    // 尚未初始化
+	ModuleStarting   ModuleState = "starting"   // 正在调用 Init
+	ModuleRunning    ModuleState = "running"    // 初始化成功
+	ModuleFailed     ModuleState = "failed"     // Init 返回错误或 panic
+	ModuleSkipped    ModuleState = "skipped"    // 依赖的模块未能初始化
+	ModuleDisabled   ModuleState = "disabled"   // 已停用
+	ModuleRestarting ModuleState = "restarting" // Run 失败后等待重启，Init 注册的 OID 仍然有效
+)
+
+// active 模块是否已初始化成功且未停用
+func (s ModuleState) active() bool {
+	return s == ModuleRunning || s == ModuleRestarting
+}
+
+// ModuleStatus 模块状态快照
+type ModuleStatus struct {
+	Name      string        `json:"name"`
+	DependsOn []string      `json:"dependsOn,omitempty"`
+	State     ModuleState   `json:"state"`
+	Error     string        `json:"error,omitempty"`     // 失败或跳过的原因
+	InitTime  time.Duration `json:"initTime"`            // Init 耗时
+	Restarts  int           `json:"restarts"`            // Run 被重启的次数
+	LastError string        `json:"lastError,omitempty"` // Run 最近一次失败的原因
+}
+
+// moduleEntry 已添加的模块
+type moduleEntry struct {
+	Module
+	state ModuleState
+	err   error
+	took  time.Duration
+
+	restarts int
+	lastErr  error
+	cancel   context.CancelFunc // 停止 Run，Run 未运行时为 nil
+	done     chan struct{}      // Run 的监督 goroutine 退出时关闭
+}
+
+// moduleRegistry 模块注册表，与 Agent.mu 分开加锁，以便 Init 中调用 Register
+type moduleRegistry struct {
+	mu      sync.Mutex
+	entries []*moduleEntry
+	byName  map[string]*moduleEntry
+	started bool
+	health  *TableBinding // RegisterModuleHealth 注册的模块状态表
+}
+
+// AddModule 添加模块
+//
+// Start 之前添加的模块在 Start 时按依赖顺序初始化，互不依赖的模块并行初始化；
+// Start 之后添加的模块立即初始化，其依赖必须已经初始化成功。
+func (a *Agent) AddModule(m Module) error {
+	if m.Name == "" {
+		return fmt.Errorf("module name is required")
+	}
+	if m.Init == nil {
+		return fmt.Errorf("module %s has no Init function", m.Name)
+	}
+
+	if enabled, ok := a.config.Modules[m.Name]; ok {
+		m.Disabled = !enabled
+	}
+
+	r := &a.modules
+	r.mu.Lock()
+	if r.byName == nil {
+		r.byName = make(map[string]*moduleEntry)
+	}
+	if _, exists := r.byName[m.Name]; exists {
+		r.mu.Unlock()
+		return fmt.Errorf("module already added: %s", m.Name)
+	}
+	entry := &moduleEntry{Module: m, state: ModulePending}
+	if m.Disabled {
+		entry.state = ModuleDisabled
+	}
+	r.entries = append(r.entries, entry)
+	r.byName[m.Name] = entry
+	started := r.started
+	r.mu.Unlock()
+
+	a.logger.Info("Added module", "name", m.Name, "dependsOn", m.DependsOn, "disabled", m.Disabled)
+	a.refreshModuleHealth()
+	if !started || m.Disabled {
+		return nil
+	}
+
+	r.mu.Lock()
+	if entry.state != ModulePending {
+		// 并发的 EnableModule 或 DisableModule 已经改变了状态
+		r.mu.Unlock()
+		return nil
+	}
+	for _, dep := range m.DependsOn {
+		if d, ok := r.byName[dep]; !ok || !d.state.active() {
+			entry.state = ModuleSkipped
+			entry.err = fmt.Errorf("dependency %s is not running", dep)
+			r.mu.Unlock()
+			a.logger.Error("Module skipped", "name", m.Name, "error", entry.err)
+			return fmt.Errorf("module %s: %w", m.Name, entry.err)
+		}
+	}
+	entry.state = ModuleStarting
+	r.mu.Unlock()
+
+	if err := a.runModule(entry); err != nil {
+		return fmt.Errorf("module %s: %w", m.Name, err)
+	}
+	return nil
+}
+
+// Modules 返回所有模块的状态，按添加顺序排列
+func (a *Agent) Modules() []ModuleStatus {
+	r := &a.modules
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]ModuleStatus, 0, len(r.entries))
+	for _, e := range r.entries {
+		status := ModuleStatus{
+			Name:      e.Name,
+			DependsOn: append([]string(nil), e.DependsOn...),
+			State:     e.state,
+			InitTime:  e.took,
+			Restarts:  e.restarts,
+		}
+		if e.err != nil {
+			status.Error = e.err.Error()
+		}
+		if e.lastErr != nil {
+			status.LastError = e.lastErr.Error()
+		}
+		out = append(out, status)
+	}
+	return out
+}
+
+// initModules 按依赖顺序初始化 Start 之前添加的模块
+//
+// 依赖图错误（未知依赖、循环依赖）返回错误；单个模块失败只记录在其状态中，
+// 依赖它的模块被跳过，其余模块照常初始化。
+func (a *Agent) initModules() error {
+	r := &a.modules
+	r.mu.Lock()
+	r.started = true
+	entries := append([]*moduleEntry(nil), r.entries...)
+	pending := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		pending[e.Name] = e.state == ModulePending
+	}
+	if err := r.checkGraph(); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	r.mu.Unlock()
+
+	done := make(map[string]chan struct{}, len(entries))
+	for _, e := range entries {
+		done[e.Name] = make(chan struct{})
+	}
+
+	var wg sync.WaitGroup
+	for _, e := range entries {
+		if !pending[e.Name] {
+			// 重复调用 Start 时已初始化的模块保持原状态
+			close(done[e.Name])
+			continue
+		}
+		wg.Add(1)
+		go func(e *moduleEntry) {
+			defer wg.Done()
+			defer close(done[e.Name])
+
+			for _, dep := range e.DependsOn {
+				<-done[dep]
+				r.mu.Lock()
+				state := r.byName[dep].state
+				r.mu.Unlock()
+				if !state.active() {
+					r.mu.Lock()
+					e.state = ModuleSkipped
+					e.err = fmt.Errorf("dependency %s %s", dep, state)
+					r.mu.Unlock()
+					a.logger.Error("Module skipped", "name", e.Name, "error", e.err)
+					return
+				}
+			}
+			r.mu.Lock()
+			if e.state != ModulePending {
+				// 等待依赖期间已被 EnableModule 初始化或被 DisableModule 停用
+				r.mu.Unlock()
+				return
+			}
+			e.state = ModuleStarting
+			r.mu.Unlock()
+			a.runModule(e)
+		}(e)
+	}
+	wg.Wait()
+
+	var failed []string
+	for _, s := range a.Modules() {
+		if !s.State.active() && s.State != ModuleDisabled {
+			failed = append(failed, s.Name)
+		}
+	}
+	if len(failed) > 0 {
+		a.logger.Warn("Some modules are not running", "modules", strings.Join(failed, ","))
+	}
+	return nil
+}
+
+// runModule 调用模块的 Init 并记录结果，调用方需先在 r.mu 下将状态设为 ModuleStarting，
+// 保证同一模块的 Init 不会被并发调用
+func (a *Agent) runModule(e *moduleEntry) error {
+	start := time.Now()
+	err := a.callModule(e.Name, func() error { return e.Init(a) })
+	took := time.Since(start)
+
+	r := &a.modules
+	r.mu.Lock()
+	e.took = took
+	e.err = err
+	if err != nil {
+		e.state = ModuleFailed
+	} else {
+		e.state = ModuleRunning
+	}
+	r.mu.Unlock()
+
+	if err != nil {
+		a.logger.Error("Module failed to initialize", "name", e.Name, "error", err)
+		return err
+	}
+	a.logger.Info("Module initialized", "name", e.Name, "took", took)
+	if e.Capability != "" {
+		if _, err := a.addSysOR(e.Capability, e.Description, e.Name); err != nil {
+			a.logger.Warn("Invalid module capability", "name", e.Name, "capability", e.Capability, "error", err)
+		}
+	}
+	if e.Run != nil {
+		a.startModuleTask(e)
+	}
+	return nil
+}
+
+// checkGraph 检查依赖是否存在且没有循环，调用方需持有 r.mu
+func (r *moduleRegistry) checkGraph() error {
+	for _, e := range r.entries {
+		for _, dep := range e.DependsOn {
+			if _, ok := r.byName[dep]; !ok {
+				return fmt.Errorf("module %s depends on unknown module %s", e.Name, dep)
+			}
+		}
+	}
+
+	// 0: 未访问，1: 访问中，2: 已完成
+	visit := make(map[string]int, len(r.entries))
+	var path []string
+	var walk func(name string) error
+	walk = func(name string) error {
+		switch visit[name] {
+		case 1:
+			return fmt.Errorf("module dependency cycle: %s -> %s", strings.Join(path, " -> "), name)
+		case 2:
+			return nil
+		}
+		visit[name] = 1
+		path = append(path, name)
+		for _, dep := range r.byName[name].DependsOn {
+			if err := walk(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		visit[name] = 2
+		return nil
+	}
+	for _, e := range r.entries {
+		if err := walk(e.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnableModule 启用已停用（或初始化失败、被跳过）的模块，其依赖必须已经初始化成功
+//
+// Start 之前调用时只清除 Disabled 标记，模块在 Start 时初始化。
+func (a *Agent) EnableModule(name string) error {
+	r := &a.modules
+	r.mu.Lock()
+	e, ok := r.byName[name]
+	if !ok {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrModuleNotFound, name)
+	}
+	if e.state.active() {
+		r.mu.Unlock()
+		return nil
+	}
+	if e.state == ModuleStarting {
+		r.mu.Unlock()
+		return fmt.Errorf("module %s is starting", name)
+	}
+	if !r.started {
+		e.state, e.err = ModulePending, nil
+		r.mu.Unlock()
+		a.logger.Info("Module enabled", "name", name)
+		return nil
+	}
+	for _, dep := range e.DependsOn {
+		if d := r.byName[dep]; !d.state.active() {
+			r.mu.Unlock()
+			return fmt.Errorf("module %s: dependency %s is %s", name, dep, d.state)
+		}
+	}
+	e.state, e.err = ModuleStarting, nil
+	r.mu.Unlock()
+
+	if err := a.runModule(e); err != nil {
+		return fmt.Errorf("module %s: %w", name, err)
+	}
+	return nil
+}
+
+// DisableModule 停用运行中的模块，调用其 Stop；依赖它的模块必须先停用
+func (a *Agent) DisableModule(name string) error {
+	r := &a.modules
+	r.mu.Lock()
+	e, ok := r.byName[name]
+	if !ok {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrModuleNotFound, name)
+	}
+	if e.state == ModuleDisabled {
+		r.mu.Unlock()
+		return nil
+	}
+	if e.state == ModuleStarting {
+		r.mu.Unlock()
+		return fmt.Errorf("module %s is starting", name)
+	}
+	if e.state.active() && e.Stop == nil {
+		r.mu.Unlock()
+		return fmt.Errorf("module %s does not support disabling", name)
+	}
+	for _, other := range r.entries {
+		if other.state.active() && slices.Contains(other.DependsOn, name) {
+			r.mu.Unlock()
+			return fmt.Errorf("module %s is required by running module %s", name, other.Name)
+		}
+	}
+	running := e.state.active()
+	r.mu.Unlock()
+
+	if running {
+		a.stopModuleTask(e)
+		if err := e.Stop(a); err != nil {
+			a.logger.Error("Module failed to stop", "name", name, "error", err)
+			return fmt.Errorf("module %s: %w", name, err)
+		}
+	}
+
+	r.mu.Lock()
+	e.state, e.err = ModuleDisabled, nil
+	r.mu.Unlock()
+	if running {
+		a.removeModuleSysOR(name)
+	}
+	a.logger.Info("Module disabled", "name", name)
+	return nil
+}
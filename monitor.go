@@ -0,0 +1,191 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// Condition 阈值条件，Above 与 Below 二选一，零值表示未设置
+//
+// 超过阈值时告警；值回到阈值另一侧并超出 Hysteresis 后恢复，避免在阈值附近抖动时反复发送通知。
+// 例如 Above: 90、Hysteresis: 5 在值大于 90 时告警，降到 85 及以下时恢复。
+type Condition struct {
+	Above        float64 // 值大于 Above 时告警
+	Below        float64 // 值小于 Below 时告警
+	Hysteresis   float64 // 恢复所需的回差，默认 0
+	ClearTrapOID string  // 恢复时发送的通知，为空时与告警使用同一个通知
+}
+
+// Monitor 由 Agent.Monitor 创建的阈值监控
+type Monitor struct {
+	agent    *Agent
+	oid      string
+	cond     Condition
+	trapOID  string
+	interval time.Duration
+
+	mu     sync.Mutex
+	alarm  bool
+	stop   chan struct{}
+	done   chan struct{}
+	closed bool
+}
+
+// Monitor 每隔 every 读取一次相对 OID 的值，越过阈值时发送 trapOID 通知，恢复时发送 cond.ClearTrapOID
+//
+// 类似 DISMAN-EVENT-MIB 的阈值触发：只在状态变化时发送，持续超限不会重复发送。
+// 通知携带被监控 OID 的当前值，通过 SendTrap 发送给所有通知目标。值必须是数值类型，读取失败的周期被跳过。
+func (a *Agent) Monitor(relativeOID string, cond Condition, every time.Duration, trapOID string) (*Monitor, error) {
+	absoluteOID := fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID)
+	return a.MonitorAbsolute(absoluteOID, cond, every, trapOID)
+}
+
+// MonitorAbsolute 监控绝对路径 OID 的值
+func (a *Agent) MonitorAbsolute(oid string, cond Condition, every time.Duration, trapOID string) (*Monitor, error) {
+	oid, err := normalizeOID(oid)
+	if err != nil {
+		return nil, err
+	}
+	if (cond.Above == 0) == (cond.Below == 0) {
+		return nil, fmt.Errorf("monitor %s: exactly one of Above and Below must be set", oid)
+	}
+	if cond.Hysteresis < 0 {
+		return nil, fmt.Errorf("monitor %s: hysteresis must not be negative", oid)
+	}
+	if every <= 0 {
+		return nil, fmt.Errorf("monitor %s: interval must be positive", oid)
+	}
+	if trapOID, err = normalizeOID(trapOID); err != nil {
+		return nil, fmt.Errorf("monitor %s: invalid trap OID: %w", oid, err)
+	}
+	if cond.ClearTrapOID == "" {
+		cond.ClearTrapOID = trapOID
+	} else if cond.ClearTrapOID, err = normalizeOID(cond.ClearTrapOID); err != nil {
+		return nil, fmt.Errorf("monitor %s: invalid clear trap OID: %w", oid, err)
+	}
+
+	m := &Monitor{
+		agent:    a,
+		oid:      oid,
+		cond:     cond,
+		trapOID:  trapOID,
+		interval: every,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go m.run()
+
+	a.logger.Info("Started monitor", "oid", oid, "above", cond.Above, "below", cond.Below, "every", every)
+	return m, nil
+}
+
+// Alarm 返回当前是否处于告警状态
+func (m *Monitor) Alarm() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.alarm
+}
+
+// Stop 停止监控，不发送恢复通知
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return
+	}
+	m.closed = true
+	close(m.stop)
+	m.mu.Unlock()
+	<-m.done
+}
+
+func (m *Monitor) run() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.check()
+		}
+	}
+}
+
+// check 读取一次值并在状态变化时发送通知
+func (m *Monitor) check() {
+	oidType, value, err := m.agent.readOID(m.oid)
+	if err != nil {
+		m.agent.logger.Warn("Monitor failed to read value", "oid", m.oid, "error", err)
+		return
+	}
+	v, err := toFloat64(value)
+	if err != nil {
+		m.agent.logger.Warn("Monitor value is not numeric", "oid", m.oid, "type", oidType, "error", err)
+		return
+	}
+
+	m.mu.Lock()
+	alarm := m.alarm
+	if alarm {
+		alarm = !m.cleared(v)
+	} else {
+		alarm = m.triggered(v)
+	}
+	changed := alarm != m.alarm
+	m.alarm = alarm
+	m.mu.Unlock()
+	if !changed {
+		return
+	}
+
+	trapOID := m.trapOID
+	if !alarm {
+		trapOID = m.cond.ClearTrapOID
+	}
+	m.agent.logger.Info("Monitor state changed", "oid", m.oid, "alarm", alarm, "value", v)
+	m.agent.SendTrap(trapOID, []gosnmp.SnmpPDU{{Name: "." + m.oid, Type: oidType, Value: value}})
+}
+
+// triggered 值是否越过阈值
+func (m *Monitor) triggered(v float64) bool {
+	if m.cond.Above != 0 {
+		return v > m.cond.Above
+	}
+	return v < m.cond.Below
+}
+
+// cleared 值是否回到阈值另一侧并超出回差
+func (m *Monitor) cleared(v float64) bool {
+	if m.cond.Above != 0 {
+		return v <= m.cond.Above-m.cond.Hysteresis
+	}
+	return v >= m.cond.Below+m.cond.Hysteresis
+}
+
+// readOID 读取已注册 OID 的类型和当前值，动态 OID 调用处理函数
+func (a *Agent) readOID(oid string) (gosnmp.Asn1BER, interface{}, error) {
+	s := a.store.Load()
+	oidType, ok := s.types[oid]
+	if !ok {
+		return 0, nil, fmt.Errorf("OID %s is not registered", oid)
+	}
+	value := s.staticVals[oid]
+	if handler, dynamic := s.handlers[oid]; dynamic {
+		v, err := a.guard(oid, handler)
+		if err != nil {
+			return 0, nil, err
+		}
+		value = v
+	}
+	value, err := normalizeValue(oidType, value)
+	if err != nil {
+		return 0, nil, err
+	}
+	return oidType, value, nil
+}
@@ -0,0 +1,363 @@
+package lzsnmp
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// NetconfSession 执行 NETCONF <get> 的会话
+//
+// Get 发送带 subtree 过滤器的 <get>，返回 <rpc-reply> 中 <data> 元素的内容。
+// 本包不实现 SSH 传输，通常包装现有的 NETCONF 客户端库。
+type NetconfSession interface {
+	Get(ctx context.Context, filter string) ([]byte, error)
+}
+
+// NetconfColumn NETCONF 表格的一列
+type NetconfColumn struct {
+	ID   int            // 列号
+	Path string         // 相对行元素的路径，如 name 或 state/counters/in-octets
+	Type gosnmp.Asn1BER // 元素文本按该类型解析
+}
+
+// NetconfTable NETCONF 结果到 SNMP 表格的映射
+//
+// 路径是 XPath 的子集：以 / 分隔的元素名（不含命名空间前缀），每一步可以带 [子元素='值'] 条件，
+// 如 /interfaces/interface[type='ethernetCsmacd']。
+type NetconfTable struct {
+	Filter       string // <get> 的 subtree 过滤器 XML，为空时获取全部数据
+	Rows         string // 行元素路径，相对 <data>，如 /interfaces/interface
+	Index        string // 索引元素路径，相对行元素；为空时使用行号（从 1 开始）
+	IntegerIndex bool   // 索引为整数；默认按字符串索引编码（长度 + ASCII）
+	Columns      []NetconfColumn
+	Timeout      time.Duration // 单次 <get> 超时，默认 10 秒
+}
+
+// NetconfProvider 由 RegisterNetconfTable 创建的表格
+type NetconfProvider struct {
+	agent    *Agent
+	entryOID string
+	session  NetconfSession
+	table    NetconfTable
+
+	mu        sync.Mutex
+	values    map[string]interface{}
+	instances []string
+	fetchedAt time.Time
+}
+
+// RegisterNetconfTable 执行 table.Filter 并将结果映射为相对 OID 下的表格
+//
+// 实例 OID 为 {relativeOID}.1.{列号}.{索引}。每次 GET 时最多每秒执行一次 <get>，
+// 行集合变化时自动重建实例 OID；元素不存在或无法按列类型解析的单元格不导出。
+func (a *Agent) RegisterNetconfTable(relativeOID string, session NetconfSession, table NetconfTable) (*NetconfProvider, error) {
+	if session == nil {
+		return nil, fmt.Errorf("netconf session is required")
+	}
+	if table.Rows == "" {
+		return nil, fmt.Errorf("netconf rows path is required")
+	}
+	if len(table.Columns) == 0 {
+		return nil, fmt.Errorf("netconf table needs at least one column")
+	}
+	seen := make(map[int]bool, len(table.Columns))
+	for _, col := range table.Columns {
+		if col.ID <= 0 || seen[col.ID] {
+			return nil, fmt.Errorf("invalid or duplicate netconf column id: %d", col.ID)
+		}
+		seen[col.ID] = true
+		if col.Path == "" {
+			return nil, fmt.Errorf("netconf column %d needs a path", col.ID)
+		}
+	}
+	if table.Timeout <= 0 {
+		table.Timeout = 10 * time.Second
+	}
+
+	p := &NetconfProvider{
+		agent:    a,
+		entryOID: fmt.Sprintf("%s.%s.1", a.oidPrefix, strings.Trim(relativeOID, ".")),
+		session:  session,
+		table:    table,
+	}
+	if err := a.claimSubtree(parentOID(p.entryOID), "netconf table"); err != nil {
+		return nil, err
+	}
+	if err := p.Refresh(); err != nil {
+		return nil, err
+	}
+
+	a.logger.Info("Registered netconf table", "oid", p.entryOID, "rows", table.Rows, "instances", len(p.instances))
+	return p, nil
+}
+
+// Refresh 立即执行一次 <get> 并在行集合变化时重建实例 OID
+func (p *NetconfProvider) Refresh() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.refreshLocked()
+}
+
+func (p *NetconfProvider) refreshLocked() error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.table.Timeout)
+	defer cancel()
+	data, err := p.session.Get(ctx, p.table.Filter)
+	if err != nil {
+		return fmt.Errorf("netconf get: %w", err)
+	}
+	root, err := parseXMLTree(data)
+	if err != nil {
+		return fmt.Errorf("parse netconf reply: %w", err)
+	}
+	rows, err := root.selectPath(p.table.Rows)
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]interface{})
+	types := make(map[string]gosnmp.Asn1BER)
+	for pos, row := range rows {
+		index, err := p.rowIndex(row, pos)
+		if err != nil {
+			p.agent.logger.Warn("Skipping netconf row", "oid", p.entryOID, "row", pos+1, "error", err)
+			continue
+		}
+		for _, col := range p.table.Columns {
+			text, ok, err := row.textAt(col.Path)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			value, err := parseValueText(col.Type, text, false)
+			if err != nil {
+				p.agent.logger.Debug("Skipping netconf cell", "path", col.Path, "text", text, "error", err)
+				continue
+			}
+			oid := fmt.Sprintf("%s.%d.%s", p.entryOID, col.ID, index)
+			values[oid], types[oid] = value, col.Type
+		}
+	}
+
+	p.values = values
+	p.fetchedAt = time.Now()
+
+	instances := make([]string, 0, len(values))
+	for oid := range values {
+		instances = append(instances, oid)
+	}
+	sort.Strings(instances)
+	if slices.Equal(p.instances, instances) {
+		return nil
+	}
+
+	add := make(map[string]dynamicOID, len(instances))
+	for _, oid := range instances {
+		add[oid] = dynamicOID{Type: types[oid], Handler: p.getter(oid)}
+	}
+	p.agent.replaceDynamic(p.instances, add)
+	p.instances = instances
+	return nil
+}
+
+// rowIndex 返回行的实例索引
+func (p *NetconfProvider) rowIndex(row *xmlNode, pos int) (string, error) {
+	if p.table.Index == "" {
+		return strconv.Itoa(pos + 1), nil
+	}
+	text, ok, err := row.textAt(p.table.Index)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("index element %s not found", p.table.Index)
+	}
+	if p.table.IntegerIndex {
+		n, err := strconv.ParseUint(text, 10, 32)
+		if err != nil {
+			return "", fmt.Errorf("index %q is not an unsigned integer", text)
+		}
+		return strconv.FormatUint(n, 10), nil
+	}
+	return encodeOctetsIndex([]byte(text), false), nil
+}
+
+func (p *NetconfProvider) getter(oid string) ValueHandler {
+	return func() (interface{}, error) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		if time.Since(p.fetchedAt) > tableRefreshInterval {
+			if err := p.refreshLocked(); err != nil {
+				return nil, err
+			}
+		}
+		value, ok := p.values[oid]
+		if !ok {
+			return nil, fmt.Errorf("netconf row %s no longer exists", oid)
+		}
+		return value, nil
+	}
+}
+
+// xmlNode 解析后的 XML 元素，只保留本地名、文本和子元素
+type xmlNode struct {
+	name     string
+	text     string
+	children []*xmlNode
+}
+
+// parseXMLTree 解析 XML 片段，返回以顶层元素为子元素的虚拟根节点
+func parseXMLTree(data []byte) (*xmlNode, error) {
+	root := &xmlNode{}
+	stack := []*xmlNode{root}
+	var text strings.Builder
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name.Local}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+			text.Reset()
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			node := stack[len(stack)-1]
+			if len(node.children) == 0 {
+				node.text = strings.TrimSpace(text.String())
+			}
+			stack = stack[:len(stack)-1]
+			text.Reset()
+		}
+	}
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("unexpected end of XML")
+	}
+	return root, nil
+}
+
+// pathStep 路径中的一步
+type pathStep struct {
+	name      string
+	predicate string // 条件中的子元素名，为空表示没有条件
+	value     string
+}
+
+// parsePath 解析路径，方括号中的 / 不作为分隔符
+func parsePath(path string) ([]pathStep, error) {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range path {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '/':
+			if depth == 0 {
+				parts = append(parts, path[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, path[start:])
+
+	var steps []pathStep
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		step := pathStep{name: part}
+		if open := strings.IndexByte(part, '['); open >= 0 {
+			if !strings.HasSuffix(part, "]") {
+				return nil, fmt.Errorf("invalid path step %q in %s", part, path)
+			}
+			step.name = part[:open]
+			cond := part[open+1 : len(part)-1]
+			key, value, ok := strings.Cut(cond, "=")
+			value = strings.TrimSpace(value)
+			if !ok || len(value) < 2 || (value[0] != '\'' && value[0] != '"') || value[len(value)-1] != value[0] {
+				return nil, fmt.Errorf("unsupported predicate [%s] in %s, expected [child='value']", cond, path)
+			}
+			step.predicate = strings.TrimSpace(key)
+			if i := strings.IndexByte(step.predicate, ':'); i >= 0 {
+				step.predicate = step.predicate[i+1:]
+			}
+			step.value = value[1 : len(value)-1]
+		}
+		if i := strings.IndexByte(step.name, ':'); i >= 0 {
+			step.name = step.name[i+1:]
+		}
+		steps = append(steps, step)
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("empty path: %q", path)
+	}
+	return steps, nil
+}
+
+// selectPath 返回 path 匹配的所有元素
+func (n *xmlNode) selectPath(path string) ([]*xmlNode, error) {
+	steps, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	nodes := []*xmlNode{n}
+	for _, step := range steps {
+		var next []*xmlNode
+		for _, node := range nodes {
+			for _, child := range node.children {
+				if child.name == step.name && child.matches(step) {
+					next = append(next, child)
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes, nil
+}
+
+// matches 判断元素是否满足步骤的条件
+func (n *xmlNode) matches(step pathStep) bool {
+	if step.predicate == "" {
+		return true
+	}
+	for _, child := range n.children {
+		if child.name == step.predicate && child.text == step.value {
+			return true
+		}
+	}
+	return false
+}
+
+// textAt 返回 path 匹配的第一个元素的文本
+func (n *xmlNode) textAt(path string) (string, bool, error) {
+	nodes, err := n.selectPath(path)
+	if err != nil || len(nodes) == 0 {
+		return "", false, err
+	}
+	return nodes[0].text, true, nil
+}
@@ -0,0 +1,83 @@
+package lzsnmp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// Notification Agent 发出的通知（trap）
+type Notification struct {
+	TrapOID   string           // snmpTrapOID.0 的值
+	Variables []gosnmp.SnmpPDU // 随通知发送的变量，不含 sysUpTime.0 和 snmpTrapOID.0
+	Time      time.Time
+}
+
+// DedupKey 返回由通知 OID 和变量计算的键，不含发送时间
+//
+// 集群中各实例为同一事件发出的通知键相同，用于 ClusterConfig.ShouldSendTrap 在共享存储中去重。
+func (n Notification) DedupKey() string {
+	h := sha256.New()
+	io.WriteString(h, n.TrapOID)
+	for _, v := range n.Variables {
+		text, _, _ := formatValueText(v.Type, v.Value)
+		fmt.Fprintf(h, "\x00%s\x00%d\x00%s", strings.TrimPrefix(v.Name, "."), v.Type, text)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// notificationHub 通知订阅方
+type notificationHub struct {
+	mu     sync.Mutex
+	nextID int
+	subs   map[int]chan Notification
+}
+
+// SubscribeNotifications 订阅 Agent 发出的通知，buffer 为缓冲的通知数
+//
+// 返回的函数取消订阅并关闭 channel。订阅方处理过慢、缓冲已满时新的通知被丢弃，不阻塞发送。
+func (a *Agent) SubscribeNotifications(buffer int) (<-chan Notification, func()) {
+	h := &a.notifications
+	ch := make(chan Notification, max(buffer, 1))
+
+	h.mu.Lock()
+	if h.subs == nil {
+		h.subs = make(map[int]chan Notification)
+	}
+	id := h.nextID
+	h.nextID++
+	h.subs[id] = ch
+	h.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, id)
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, cancel
+}
+
+// publishNotification 将通知分发给所有订阅方
+func (a *Agent) publishNotification(n Notification) {
+	h := &a.notifications
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, ch := range h.subs {
+		select {
+		case ch <- n:
+		default:
+			a.logger.Debug("Notification subscriber is full, dropping", "trap", n.TrapOID)
+		}
+	}
+}
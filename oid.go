@@ -0,0 +1,78 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// normalizeOID 校验并规范化 OID：去掉开头的点，要求每段都是 32 位无符号整数，
+// 第一段为 0、1 或 2，第一段为 0 或 1 时第二段不超过 39（X.660 的约束）
+func normalizeOID(oid string) (string, error) {
+	normalized := strings.TrimPrefix(oid, ".")
+	if normalized == "" {
+		return "", fmt.Errorf("invalid OID %q: empty", oid)
+	}
+	if strings.HasSuffix(normalized, ".") {
+		return "", fmt.Errorf("invalid OID %q: trailing dot", oid)
+	}
+
+	arcs := strings.Split(normalized, ".")
+	if len(arcs) < 2 {
+		return "", fmt.Errorf("invalid OID %q: at least two components are required", oid)
+	}
+	for i, arc := range arcs {
+		if arc == "" {
+			return "", fmt.Errorf("invalid OID %q: empty component at position %d", oid, i+1)
+		}
+		n, err := strconv.ParseUint(arc, 10, 32)
+		if err != nil {
+			return "", fmt.Errorf("invalid OID %q: component %d (%q) is not a 32-bit unsigned integer", oid, i+1, arc)
+		}
+		if len(arc) > 1 && arc[0] == '0' {
+			return "", fmt.Errorf("invalid OID %q: component %d (%q) has a leading zero", oid, i+1, arc)
+		}
+		switch {
+		case i == 0 && n > 2:
+			return "", fmt.Errorf("invalid OID %q: first component must be 0, 1 or 2", oid)
+		case i == 1 && arcs[0] != "2" && n > 39:
+			return "", fmt.Errorf("invalid OID %q: second component must be at most 39 under %s", oid, arcs[0])
+		}
+	}
+	return normalized, nil
+}
+
+// compareOID 按数值逐段比较两个 OID，返回 -1、0 或 1，不分配内存
+func compareOID(a, b string) int {
+	a, b = strings.Trim(a, "."), strings.Trim(b, ".")
+	for a != "" && b != "" {
+		var as, bs string
+		as, a, _ = strings.Cut(a, ".")
+		bs, b, _ = strings.Cut(b, ".")
+		if as == bs {
+			continue
+		}
+		an, aerr := strconv.ParseUint(as, 10, 64)
+		bn, berr := strconv.ParseUint(bs, 10, 64)
+		if aerr != nil || berr != nil {
+			return strings.Compare(as, bs)
+		}
+		if an < bn {
+			return -1
+		}
+		return 1
+	}
+
+	switch {
+	case a == "" && b != "":
+		return -1
+	case a != "" && b == "":
+		return 1
+	}
+	return 0
+}
+
+// hasOIDPrefix 判断 oid 是否等于 prefix 或位于其子树中
+func hasOIDPrefix(oid, prefix string) bool {
+	return oid == prefix || strings.HasPrefix(oid, prefix+".")
+}
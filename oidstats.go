@@ -0,0 +1,182 @@
+package lzsnmp
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OIDLatencyBuckets 每个 OID 处理耗时分布的上界，OIDStats.Latency 的最后一项为超过最大上界的调用
+var OIDLatencyBuckets = [...]time.Duration{time.Millisecond, 10 * time.Millisecond, 100 * time.Millisecond, time.Second}
+
+// OIDStats 一个 OID 的处理函数调用统计，只包含被调用过的动态 OID 和可写 OID
+type OIDStats struct {
+	OID     string
+	Calls   uint64                             // 调用次数（GET 和 SET）
+	Errors  uint64                             // 返回错误的次数（含 panic）
+	Time    time.Duration                      // 累计耗时
+	Latency [len(OIDLatencyBuckets) + 1]uint64 // 按 OIDLatencyBuckets 划分的调用次数
+}
+
+// oidMetrics 一个 OID 的计数器
+type oidMetrics struct {
+	calls   atomic.Uint64
+	errors  atomic.Uint64
+	nanos   atomic.Uint64
+	latency [len(OIDLatencyBuckets) + 1]atomic.Uint64
+
+	mu        sync.Mutex
+	lastCall  time.Time   // 最近一次调用的时间
+	lastValue interface{} // 最近一次 GET 返回或 SET 写入的值
+}
+
+// oidMetricsMap OID → *oidMetrics
+type oidMetricsMap struct {
+	m       sync.Map
+	table   atomic.Pointer[TableBinding] // RegisterOIDStats 注册的表格
+	pending atomic.Bool                  // 已安排表格刷新
+}
+
+// observe 记录 oid 的一次调用
+func (o *oidMetricsMap) observe(oid string, elapsed time.Duration, err error) {
+	v, ok := o.m.Load(oid)
+	if !ok {
+		var loaded bool
+		if v, loaded = o.m.LoadOrStore(oid, &oidMetrics{}); !loaded {
+			o.refreshTable()
+		}
+	}
+	m := v.(*oidMetrics)
+	m.mu.Lock()
+	m.lastCall = time.Now()
+	m.mu.Unlock()
+	m.calls.Add(1)
+	m.nanos.Add(uint64(elapsed))
+	if err != nil {
+		m.errors.Add(1)
+	}
+	bucket := len(OIDLatencyBuckets)
+	for i, bound := range OIDLatencyBuckets {
+		if elapsed <= bound {
+			bucket = i
+			break
+		}
+	}
+	m.latency[bucket].Add(1)
+}
+
+// remember 记录 oid 最近一次返回或写入的值，在 observe 之后调用
+func (o *oidMetricsMap) remember(oid string, value interface{}) {
+	if v, ok := o.m.Load(oid); ok {
+		m := v.(*oidMetrics)
+		m.mu.Lock()
+		m.lastValue = value
+		m.mu.Unlock()
+	}
+}
+
+// last 返回 oid 的调用次数、错误次数、最近一次调用的时间和值，未调用过时 ok 为 false
+func (o *oidMetricsMap) last(oid string) (calls, errors uint64, at time.Time, value interface{}, ok bool) {
+	v, ok := o.m.Load(oid)
+	if !ok {
+		return 0, 0, time.Time{}, nil, false
+	}
+	m := v.(*oidMetrics)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls.Load(), m.errors.Load(), m.lastCall, m.lastValue, true
+}
+
+// refreshTable 出现新的 OID 后刷新统计表格的行，每秒最多一次
+func (o *oidMetricsMap) refreshTable() {
+	t := o.table.Load()
+	if t == nil || !o.pending.CompareAndSwap(false, true) {
+		return
+	}
+	time.AfterFunc(tableRefreshInterval, func() {
+		o.pending.Store(false)
+		if err := t.Refresh(); err != nil {
+			t.agent.logger.Warn("Failed to refresh OID stats table", "error", err)
+		}
+	})
+}
+
+// snapshot 返回 s 中仍注册的 OID 的统计，按累计耗时从大到小排序，并删除已注销 OID 的计数器
+func (o *oidMetricsMap) snapshot(s *oidStore) []OIDStats {
+	var out []OIDStats
+	o.m.Range(func(key, value interface{}) bool {
+		oid := key.(string)
+		if _, ok := s.handlers[oid]; !ok {
+			o.m.Delete(oid)
+			return true
+		}
+		m := value.(*oidMetrics)
+		st := OIDStats{
+			OID:    oid,
+			Calls:  m.calls.Load(),
+			Errors: m.errors.Load(),
+			Time:   time.Duration(m.nanos.Load()),
+		}
+		for i := range m.latency {
+			st.Latency[i] = m.latency[i].Load()
+		}
+		out = append(out, st)
+		return true
+	})
+	slices.SortFunc(out, func(a, b OIDStats) int {
+		return cmp.Or(cmp.Compare(b.Time, a.Time), compareOID(a.OID, b.OID))
+	})
+	return out
+}
+
+// oidStatsRow 每个 OID 调用统计表的一行
+type oidStatsRow struct {
+	OID          string `snmp:"1,objectidentifier" snmpdesc:"Instance OID served by the handler"`
+	Calls        uint64 `snmp:"2,counter64" snmpdesc:"Number of handler invocations"`
+	Errors       uint64 `snmp:"3,counter64" snmpdesc:"Number of invocations that returned an error or panicked"`
+	TimeMicros   uint64 `snmp:"4,counter64" snmpdesc:"Total handler time in microseconds"`
+	Latency1ms   uint64 `snmp:"5,counter64" snmpdesc:"Invocations that took at most 1ms"`
+	Latency10ms  uint64 `snmp:"6,counter64" snmpdesc:"Invocations that took more than 1ms and at most 10ms"`
+	Latency100ms uint64 `snmp:"7,counter64" snmpdesc:"Invocations that took more than 10ms and at most 100ms"`
+	Latency1s    uint64 `snmp:"8,counter64" snmpdesc:"Invocations that took more than 100ms and at most 1s"`
+	LatencySlow  uint64 `snmp:"9,counter64" snmpdesc:"Invocations that took more than 1s"`
+}
+
+// RegisterOIDStats 将每个 OID 的调用统计注册为表格，行按累计耗时从大到小排列，行号从 1 开始
+//
+// 实例 OID 为 {relativeOID}.1.{列号}.{行号}，列为 1: 实例 OID、2: 调用次数、3: 错误次数、4: 累计耗时（微秒）、
+// 5~9: 耗时不超过 1ms / 10ms / 100ms / 1s 和超过 1s 的调用次数。行号随排序变化，按第 1 列识别 OID；
+// 表格自身的 OID 不计入。OID 第一次被调用后最多 1 秒出现在表格中。
+func (a *Agent) RegisterOIDStats(relativeOID string) error {
+	root := fmt.Sprintf("%s.%s", a.oidPrefix, strings.Trim(relativeOID, "."))
+	table, err := a.BindTable(relativeOID, func() []oidStatsRow {
+		stats := a.stats.oids.snapshot(a.store.Load())
+		rows := make([]oidStatsRow, 0, len(stats))
+		for _, s := range stats {
+			if strings.HasPrefix(s.OID, root+".") {
+				continue
+			}
+			rows = append(rows, oidStatsRow{
+				OID:          s.OID,
+				Calls:        s.Calls,
+				Errors:       s.Errors,
+				TimeMicros:   uint64(s.Time.Microseconds()),
+				Latency1ms:   s.Latency[0],
+				Latency10ms:  s.Latency[1],
+				Latency100ms: s.Latency[2],
+				Latency1s:    s.Latency[3],
+				LatencySlow:  s.Latency[4],
+			})
+		}
+		return rows
+	})
+	if err != nil {
+		return fmt.Errorf("register OID stats: %w", err)
+	}
+	a.stats.oids.table.Store(table)
+	return nil
+}
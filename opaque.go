@@ -0,0 +1,126 @@
+package lzsnmp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// net-snmp 在 Opaque 中封装的扩展类型（ASN_OPAQUE_TAG2 之后的标签）
+const (
+	opaqueTagExtension = 0x9f
+	opaqueTagCounter64 = 0x76
+	opaqueTagFloat     = 0x78
+	opaqueTagDouble    = 0x79
+	opaqueTagInteger64 = 0x7a
+	opaqueTagUnsigned  = 0x7b
+)
+
+// EncodeOpaqueFloat 将 float32 编码为 net-snmp 的 Opaque/Float 内容，可作为 gosnmp.Opaque 类型的值
+//
+// 注册为 gosnmp.OpaqueFloat 的 OID 直接返回数值即可，不需要手动编码。
+func EncodeOpaqueFloat(f float32) []byte {
+	return binary.BigEndian.AppendUint32([]byte{opaqueTagExtension, opaqueTagFloat, 4}, math.Float32bits(f))
+}
+
+// EncodeOpaqueDouble 将 float64 编码为 net-snmp 的 Opaque/Double 内容，可作为 gosnmp.Opaque 类型的值
+func EncodeOpaqueDouble(f float64) []byte {
+	return binary.BigEndian.AppendUint64([]byte{opaqueTagExtension, opaqueTagDouble, 8}, math.Float64bits(f))
+}
+
+// EncodeOpaqueCounter64 将 uint64 编码为 net-snmp 的 Opaque/Counter64 内容，用于只支持 SNMPv1 的 NMS
+func EncodeOpaqueCounter64(v uint64) []byte {
+	return encodeOpaqueUnsigned(opaqueTagCounter64, v)
+}
+
+// EncodeOpaqueInteger64 将 int64 编码为 net-snmp 的 Opaque/Integer64 内容
+func EncodeOpaqueInteger64(v int64) []byte {
+	b := binary.BigEndian.AppendUint64(nil, uint64(v))
+	// 去掉多余的符号字节，保留最短的补码表示
+	for len(b) > 1 && (b[0] == 0 && b[1]&0x80 == 0 || b[0] == 0xff && b[1]&0x80 != 0) {
+		b = b[1:]
+	}
+	return append([]byte{opaqueTagExtension, opaqueTagInteger64, byte(len(b))}, b...)
+}
+
+// EncodeOpaqueUnsigned64 将 uint64 编码为 net-snmp 的 Opaque/UInteger64 内容
+func EncodeOpaqueUnsigned64(v uint64) []byte {
+	return encodeOpaqueUnsigned(opaqueTagUnsigned, v)
+}
+
+func encodeOpaqueUnsigned(tag byte, v uint64) []byte {
+	b := binary.BigEndian.AppendUint64([]byte{0}, v)
+	for len(b) > 1 && b[0] == 0 && b[1]&0x80 == 0 {
+		b = b[1:]
+	}
+	return append([]byte{opaqueTagExtension, tag, byte(len(b))}, b...)
+}
+
+// DecodeOpaque 解码 Opaque 内容中 net-snmp 封装的扩展类型
+//
+// Float/Double 返回 gosnmp.OpaqueFloat/OpaqueDouble 和 float32/float64，Counter64 和 UInteger64 返回
+// gosnmp.Counter64 和 uint64，Integer64 返回 gosnmp.Integer 和 int64；其他内容原样返回 gosnmp.Opaque。
+func DecodeOpaque(b []byte) (gosnmp.Asn1BER, interface{}, error) {
+	if len(b) < 3 || b[0] != opaqueTagExtension {
+		return gosnmp.Opaque, b, nil
+	}
+	tag, content := b[1], b[3:]
+	if int(b[2]) != len(content) {
+		return 0, nil, fmt.Errorf("invalid Opaque length: %d, have %d bytes", b[2], len(content))
+	}
+
+	switch tag {
+	case opaqueTagFloat:
+		if len(content) != 4 {
+			return 0, nil, fmt.Errorf("invalid Opaque/Float length: %d", len(content))
+		}
+		return gosnmp.OpaqueFloat, math.Float32frombits(binary.BigEndian.Uint32(content)), nil
+	case opaqueTagDouble:
+		if len(content) != 8 {
+			return 0, nil, fmt.Errorf("invalid Opaque/Double length: %d", len(content))
+		}
+		return gosnmp.OpaqueDouble, math.Float64frombits(binary.BigEndian.Uint64(content)), nil
+	case opaqueTagCounter64, opaqueTagUnsigned:
+		if len(content) == 0 || len(content) > 9 || len(content) == 9 && content[0] != 0 {
+			return 0, nil, fmt.Errorf("invalid Opaque/Counter64 length: %d", len(content))
+		}
+		var v uint64
+		for _, c := range content {
+			v = v<<8 | uint64(c)
+		}
+		return gosnmp.Counter64, v, nil
+	case opaqueTagInteger64:
+		if len(content) == 0 || len(content) > 8 {
+			return 0, nil, fmt.Errorf("invalid Opaque/Integer64 length: %d", len(content))
+		}
+		v := int64(int8(content[0]))
+		for _, c := range content[1:] {
+			v = v<<8 | int64(c)
+		}
+		return gosnmp.Integer, v, nil
+	}
+	return gosnmp.Opaque, b, nil
+}
+
+// normalizeSetValue 将 SET 的值转换为 oidType 对应的 Go 类型，目前只处理 OpaqueFloat/OpaqueDouble
+//
+// NMS 可能以 Opaque/Float、Opaque/Double 或未解码的 Opaque 字节串写入浮点 OID，setter 统一收到
+// float32（OpaqueFloat）或 float64（OpaqueDouble）；其他类型的值原样交给 setter。
+func normalizeSetValue(oidType gosnmp.Asn1BER, value interface{}) (interface{}, error) {
+	if oidType != gosnmp.OpaqueFloat && oidType != gosnmp.OpaqueDouble {
+		return value, nil
+	}
+	if b, ok := value.([]byte); ok {
+		decoded, v, err := DecodeOpaque(b)
+		if err != nil {
+			return nil, err
+		}
+		if decoded == gosnmp.Opaque {
+			return nil, fmt.Errorf("cannot use %x as %v", b, oidType)
+		}
+		value = v
+	}
+	return normalizeValue(oidType, value)
+}
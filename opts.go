@@ -0,0 +1,104 @@
+package lzsnmp
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// RegisterOpts 动态 OID 的注册选项
+type RegisterOpts struct {
+	// ServeStaleFor 处理函数返回错误时，如果上一次成功的值不超过该时长，返回该值而不是 SNMP 错误；
+	// 0 表示直接返回错误；与 Breaker 同时使用时熔断期间同样返回上一次成功的值。
+	// ErrNoSuchInstance 表示实例已不存在，原样返回并丢弃上一次的值
+	ServeStaleFor time.Duration
+	// Timeout 处理函数超过该时长未返回时视为失败，请求立即返回 ErrHandlerTimeout；0 表示不限制
+	Timeout time.Duration
+	// Breaker 连续失败（错误或超时）后熔断，保护 Agent 不被故障的后端拖慢；为 nil 时不熔断
+	Breaker *BreakerConfig
+	// Override 允许 OID 与已注册的 OID 或子树重叠，位于表格等子树中时覆盖子树的同名实例；
+	// 默认重叠时返回 *OverlapError
+	Override bool
+
+	// Setter 不为 nil 时 OID 可写，与 RegisterWritable 相同；Timeout、Breaker 等选项只作用于 handler
+	Setter SetHandler
+	// Name、Description、Units 为 OID 的文档信息，与 Annotate 相同，可通过 Describe 读取，
+	// 并用于 ExportDocs、管理接口和处理函数出错时的日志
+	Name        string
+	Description string
+	Units       string
+}
+
+// staleFallback 记录处理函数上一次成功的值，出错时在允许的时长内返回
+type staleFallback struct {
+	maxAge  time.Duration
+	handler ValueHandler
+	onStale func(age time.Duration, err error)
+	mu      sync.Mutex
+	value   interface{}
+	fetched time.Time
+	valid   bool
+}
+
+func (s *staleFallback) get() (interface{}, error) {
+	value, err := s.handler()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		s.value, s.fetched, s.valid = value, time.Now(), true
+		return value, nil
+	}
+	if errors.Is(err, ErrNoSuchInstance) {
+		// 实例不存在不是故障，之后的错误也不能再返回已删除实例的值
+		s.value, s.valid = nil, false
+		return nil, err
+	}
+	if age := time.Since(s.fetched); s.valid && age <= s.maxAge {
+		s.onStale(age, err)
+		return s.value, nil
+	}
+	return nil, err
+}
+
+// RegisterWithOpts 按选项注册相对 OID
+func (a *Agent) RegisterWithOpts(relativeOID string, oidType gosnmp.Asn1BER, handler ValueHandler, opts RegisterOpts) error {
+	absoluteOID := fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID)
+	return a.RegisterAbsoluteWithOpts(absoluteOID, oidType, handler, opts)
+}
+
+// RegisterAbsoluteWithOpts 按选项注册绝对路径 OID
+func (a *Agent) RegisterAbsoluteWithOpts(oid string, oidType gosnmp.Asn1BER, handler ValueHandler, opts RegisterOpts) error {
+	oid, err := normalizeOID(oid)
+	if err != nil {
+		return err
+	}
+	if opts.ServeStaleFor < 0 || opts.Timeout < 0 {
+		return fmt.Errorf("ServeStaleFor and Timeout must not be negative for OID: %s", oid)
+	}
+	if opts.Timeout > 0 {
+		handler = a.withTimeout(oid, opts.Timeout, handler)
+	}
+	if opts.Breaker != nil {
+		b, err := a.newCircuitBreaker(oid, *opts.Breaker, handler)
+		if err != nil {
+			return err
+		}
+		handler = b.get
+	}
+	if opts.ServeStaleFor > 0 {
+		s := &staleFallback{
+			maxAge:  opts.ServeStaleFor,
+			handler: handler,
+			onStale: func(age time.Duration, err error) {
+				a.logger.Warn("Handler failed, serving stale value", "oid", oid, "age", age, "error", err)
+			},
+		}
+		handler = s.get
+	}
+	meta := OIDMeta{Name: opts.Name, Description: opts.Description, Units: opts.Units}
+	return a.registerDynamicOverride(oid, oidType, handler, opts.Setter, opts.Override, meta)
+}
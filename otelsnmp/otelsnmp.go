@@ -0,0 +1,85 @@
+// Package otelsnmp 基于 OpenTelemetry 的 lzsnmp.Tracer，为 Agent 处理的每个请求创建 span
+//
+// 单独成包，不使用追踪时不引入 OpenTelemetry 依赖。
+package otelsnmp
+
+import (
+	"context"
+	"fmt"
+
+	lzsnmp "github.com/liuzhen9320/snmp-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName 创建 trace.Tracer 时使用的 instrumentation scope 名称
+const instrumentationName = "github.com/liuzhen9320/snmp-go"
+
+// tracer 将 lzsnmp.Tracer 适配到 trace.Tracer
+type tracer struct {
+	t trace.Tracer
+}
+
+var _ lzsnmp.Tracer = (*tracer)(nil)
+
+// NewTracer 返回使用 tp 创建 span 的 lzsnmp.Tracer，tp 为 nil 时使用全局的 TracerProvider
+//
+// snmp.request 为 SERVER 类型的根 span，其余阶段为其子 span；ContextHandler 收到的 ctx 中包含 snmp.handler span，
+// 处理函数访问数据库、调用下游服务时传递该 ctx 即可串起完整的调用链。
+func NewTracer(tp trace.TracerProvider) lzsnmp.Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &tracer{t: tp.Tracer(instrumentationName)}
+}
+
+func (t *tracer) Start(ctx context.Context, name string, keyvals ...interface{}) (context.Context, lzsnmp.Span) {
+	kind := trace.SpanKindInternal
+	if name == "snmp.request" {
+		kind = trace.SpanKindServer
+	}
+	ctx, span := t.t.Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attributes(keyvals)...))
+	return ctx, otelSpan{span}
+}
+
+// otelSpan 将 lzsnmp.Span 适配到 trace.Span
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s otelSpan) SetAttributes(keyvals ...interface{}) {
+	s.span.SetAttributes(attributes(keyvals)...)
+}
+
+func (s otelSpan) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+// attributes 将交替的键和值转换为 span 属性，无法识别的值类型转为字符串
+func attributes(keyvals []interface{}) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(keyvals)/2)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		switch v := keyvals[i+1].(type) {
+		case string:
+			attrs = append(attrs, attribute.String(key, v))
+		case bool:
+			attrs = append(attrs, attribute.Bool(key, v))
+		case int:
+			attrs = append(attrs, attribute.Int(key, v))
+		case int64:
+			attrs = append(attrs, attribute.Int64(key, v))
+		case float64:
+			attrs = append(attrs, attribute.Float64(key, v))
+		default:
+			attrs = append(attrs, attribute.String(key, fmt.Sprint(v)))
+		}
+	}
+	return attrs
+}
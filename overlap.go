@@ -0,0 +1,107 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// OverlapError 新注册的 OID 与已注册的 OID 或子树重叠
+//
+// 一个 OID 位于另一个已注册 OID 之下（或反过来），或位于表格、桥接等占用的子树中时返回。
+// 需要在子树中覆盖某个实例时，使用 RegisterWithOpts 并设置 Override。
+type OverlapError struct {
+	OID      string // 新注册的 OID 或子树根
+	Existing string // 冲突的已注册 OID 或子树根
+	Owner    string // Existing 为子树时，占用该子树的注册方
+}
+
+func (e *OverlapError) Error() string {
+	if e.Owner != "" {
+		return fmt.Sprintf("OID %s overlaps subtree %s owned by %s", e.OID, e.Existing, e.Owner)
+	}
+	return fmt.Sprintf("OID %s overlaps registered OID %s", e.OID, e.Existing)
+}
+
+// parentOID 返回 oid 的父节点，没有父节点时返回空字符串
+func parentOID(oid string) string {
+	if i := strings.LastIndexByte(oid, '.'); i >= 0 {
+		return oid[:i]
+	}
+	return ""
+}
+
+// sortedOIDs 返回按字符串排序的所有 OID，同一子树中的 OID 相邻；只在已发布的快照上调用
+func (s *oidStore) sortedOIDs() []string {
+	s.sortOnce.Do(func() {
+		s.sorted = make([]string, 0, len(s.types))
+		for oid := range s.types {
+			s.sorted = append(s.sorted, oid)
+		}
+		sort.Strings(s.sorted)
+	})
+	return s.sorted
+}
+
+// firstDescendant 返回 base 中位于 oid 之下的任意一个 OID
+func (s *oidStore) firstDescendant(oid string) (string, bool) {
+	sorted := s.sortedOIDs()
+	prefix := oid + "."
+	i := sort.SearchStrings(sorted, prefix)
+	if i < len(sorted) && strings.HasPrefix(sorted[i], prefix) {
+		return sorted[i], true
+	}
+	return "", false
+}
+
+// checkLeaf 检查新的叶子 OID 是否与已注册的 OID 或子树重叠，重复注册同一 OID 不算重叠
+//
+// s 为正在修改的快照，base 为修改前发布的快照，用于查找 oid 之下的 OID。
+func (s *oidStore) checkLeaf(base *oidStore, oid string) error {
+	for p := oid; p != ""; p = parentOID(p) {
+		if owner, ok := s.subtrees[p]; ok {
+			return &OverlapError{OID: oid, Existing: p, Owner: owner}
+		}
+	}
+	for root, owner := range s.subtrees {
+		if hasOIDPrefix(root, oid) {
+			return &OverlapError{OID: oid, Existing: root, Owner: owner}
+		}
+	}
+	for p := parentOID(oid); p != ""; p = parentOID(p) {
+		if _, ok := s.types[p]; ok {
+			return &OverlapError{OID: oid, Existing: p}
+		}
+	}
+	if d, ok := base.firstDescendant(oid); ok {
+		return &OverlapError{OID: oid, Existing: d}
+	}
+	return nil
+}
+
+// claimSubtree 将 root 下的整个子树交给 owner 管理，子树中的实例由 owner 通过 replaceDynamic 维护
+//
+// 子树与已注册的 OID 或其他子树重叠时返回 *OverlapError。
+func (a *Agent) claimSubtree(root, owner string) error {
+	root, err := normalizeOID(root)
+	if err != nil {
+		return err
+	}
+	return a.updateStore(func(s *oidStore) error {
+		for existing, other := range s.subtrees {
+			if hasOIDPrefix(root, existing) || hasOIDPrefix(existing, root) {
+				return &OverlapError{OID: root, Existing: existing, Owner: other}
+			}
+		}
+		for p := root; p != ""; p = parentOID(p) {
+			if _, ok := s.types[p]; ok {
+				return &OverlapError{OID: root, Existing: p}
+			}
+		}
+		if d, ok := a.store.Load().firstDescendant(root); ok {
+			return &OverlapError{OID: root, Existing: d}
+		}
+		s.subtrees[root] = owner
+		return nil
+	})
+}
@@ -0,0 +1,305 @@
+package lzsnmp
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// PacketDumpFormat 报文转储格式
+type PacketDumpFormat int
+
+const (
+	PacketDumpHex  PacketDumpFormat = iota // 文本：报文方向、地址、解码后的 PDU 摘要和十六进制内容
+	PacketDumpPcap                         // pcap 格式（LINKTYPE_RAW，IP/UDP 头由地址合成），可用 Wireshark 打开
+)
+
+// pcap 文件头字段
+const (
+	pcapMagic    = 0xa1b2c3d4
+	pcapLinkRaw  = 101
+	pcapSnapLen  = 65535
+	ipv4HeaderSz = 20
+	ipv6HeaderSz = 40
+	udpHeaderSz  = 8
+)
+
+// PacketDumpConfig 报文转储配置
+type PacketDumpConfig struct {
+	Writer io.Writer // 转储输出，为 nil 时关闭转储
+	Format PacketDumpFormat
+	Peers  []string // 只转储与这些 IP 地址之间的报文，为空时转储全部
+}
+
+// packetDumper 将收发的报文写入 Writer，可以被多个 worker 并发调用
+type packetDumper struct {
+	format PacketDumpFormat
+	peers  []net.IP
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// SetPacketDump 开启报文转储，记录 Agent 收到的每个请求报文和发出的响应报文，用于排查与特定 NMS 的互通问题
+//
+// 可以在运行时随时开启、切换输出或关闭（cfg.Writer 为 nil），Writer 由调用方负责关闭。
+// 转储在收发报文的协程中同步写入，只应在排查问题时短期开启。SNMPv3 加密报文只能解码出报头。
+func (a *Agent) SetPacketDump(cfg PacketDumpConfig) error {
+	if cfg.Writer == nil {
+		if a.packetDump.Swap(nil) != nil {
+			a.logger.Info("Packet dump disabled")
+		}
+		return nil
+	}
+	if cfg.Format != PacketDumpHex && cfg.Format != PacketDumpPcap {
+		return fmt.Errorf("invalid packet dump format: %d", cfg.Format)
+	}
+
+	d := &packetDumper{format: cfg.Format, w: cfg.Writer}
+	for _, peer := range cfg.Peers {
+		ip := net.ParseIP(strings.TrimSpace(peer))
+		if ip == nil {
+			return fmt.Errorf("invalid packet dump peer: %s", peer)
+		}
+		d.peers = append(d.peers, ip)
+	}
+	if d.format == PacketDumpPcap {
+		if err := d.writePcapHeader(); err != nil {
+			return fmt.Errorf("write pcap header: %w", err)
+		}
+	}
+
+	a.packetDump.Store(d)
+	a.logger.Info("Packet dump enabled", "format", cfg.Format, "peers", len(d.peers))
+	return nil
+}
+
+func (f PacketDumpFormat) String() string {
+	switch f {
+	case PacketDumpHex:
+		return "hex"
+	case PacketDumpPcap:
+		return "pcap"
+	}
+	return fmt.Sprintf("PacketDumpFormat(%d)", int(f))
+}
+
+// dumpPacket 开启转储时记录一个报文，inbound 为 true 表示收到的请求；local 为本地地址，未知时为 nil
+func (a *Agent) dumpPacket(inbound bool, conn transport, local net.IP, remote net.Addr, packet []byte) {
+	d := a.packetDump.Load()
+	if d == nil {
+		return
+	}
+	peer, ok := remote.(*net.UDPAddr)
+	if !ok || !d.match(peer.IP) {
+		return
+	}
+
+	self := &net.UDPAddr{IP: local}
+	if laddr, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+		self.Port = laddr.Port
+		if self.IP == nil {
+			self.IP = laddr.IP
+		}
+	}
+	if err := d.dump(time.Now(), inbound, self, peer, packet); err != nil {
+		logSampled(a.logLimits.packet, a.logger.Warn, "Failed to write packet dump", "error", err)
+	}
+}
+
+// match 判断是否转储与 ip 之间的报文
+func (d *packetDumper) match(ip net.IP) bool {
+	return len(d.peers) == 0 || slices.ContainsFunc(d.peers, ip.Equal)
+}
+
+func (d *packetDumper) dump(t time.Time, inbound bool, self, peer *net.UDPAddr, packet []byte) error {
+	src, dst := peer, self
+	if !inbound {
+		src, dst = self, peer
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.format == PacketDumpPcap {
+		return d.writePcapRecord(t, src, dst, packet)
+	}
+
+	direction := "in"
+	if !inbound {
+		direction = "out"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s %s > %s %d bytes\n", t.UTC().Format(time.RFC3339Nano), direction, src, dst, len(packet))
+	for _, line := range summarizePacket(packet) {
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+	b.WriteString(hex.Dump(packet))
+	b.WriteString("\n")
+	_, err := io.WriteString(d.w, b.String())
+	return err
+}
+
+// summarizePacket 解码报文，返回版本、安全参数、PDU 类型和每个变量绑定的摘要
+func summarizePacket(packet []byte) []string {
+	decoder := gosnmp.GoSNMP{SecurityParameters: &gosnmp.UsmSecurityParameters{}}
+	pkt, err := decoder.SnmpDecodePacket(packet)
+	if pkt == nil {
+		return []string{fmt.Sprintf("undecodable: %v", err)}
+	}
+
+	head := []string{"version=" + pkt.Version.String()}
+	if pkt.Version == gosnmp.Version3 {
+		if usm, ok := pkt.SecurityParameters.(*gosnmp.UsmSecurityParameters); ok {
+			head = append(head, fmt.Sprintf("user=%q engineID=%x", usm.UserName, usm.AuthoritativeEngineID))
+		}
+		head = append(head, fmt.Sprintf("msgID=%d level=%s", pkt.MsgID, securityLevelOf(pkt)))
+	} else {
+		head = append(head, fmt.Sprintf("community=%q", pkt.Community))
+	}
+	if err != nil {
+		if pkt.Version == gosnmp.Version3 && pkt.MsgFlags&gosnmp.AuthPriv == gosnmp.AuthPriv {
+			return []string{strings.Join(head, " "), "encrypted scoped PDU"}
+		}
+		return []string{strings.Join(head, " "), fmt.Sprintf("undecodable PDU: %v", err)}
+	}
+
+	head = append(head, pkt.PDUType.String(), fmt.Sprintf("request-id=%d", pkt.RequestID))
+	switch pkt.PDUType {
+	case gosnmp.GetBulkRequest:
+		head = append(head, fmt.Sprintf("non-repeaters=%d max-repetitions=%d", pkt.NonRepeaters, pkt.MaxRepetitions))
+	case gosnmp.GetResponse, gosnmp.Report:
+		head = append(head, fmt.Sprintf("error-status=%s error-index=%d", pkt.Error, pkt.ErrorIndex))
+	}
+
+	lines := []string{strings.Join(head, " ")}
+	for _, v := range pkt.Variables {
+		lines = append(lines, fmt.Sprintf("%s %s %s", strings.TrimPrefix(v.Name, "."), v.Type, formatDumpValue(v)))
+	}
+	return lines
+}
+
+// formatDumpValue 以可读形式输出变量值，不可打印的字节串输出为十六进制
+func formatDumpValue(v gosnmp.SnmpPDU) string {
+	switch value := v.Value.(type) {
+	case nil:
+		return "-"
+	case []byte:
+		for _, r := range string(value) {
+			if !unicode.IsPrint(r) {
+				return fmt.Sprintf("0x%x", value)
+			}
+		}
+		return fmt.Sprintf("%q", value)
+	}
+	return fmt.Sprint(v.Value)
+}
+
+// writePcapHeader 写入 pcap 文件头
+func (d *packetDumper) writePcapHeader() error {
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint32(header[0:], pcapMagic)
+	binary.LittleEndian.PutUint16(header[4:], 2)
+	binary.LittleEndian.PutUint16(header[6:], 4)
+	binary.LittleEndian.PutUint32(header[16:], pcapSnapLen)
+	binary.LittleEndian.PutUint32(header[20:], pcapLinkRaw)
+	_, err := d.w.Write(header)
+	return err
+}
+
+// writePcapRecord 为 UDP 载荷合成 IP 和 UDP 头，写入一条 pcap 记录
+func (d *packetDumper) writePcapRecord(t time.Time, src, dst *net.UDPAddr, payload []byte) error {
+	frame := ipUDPFrame(src, dst, payload)
+	record := make([]byte, 16, 16+len(frame))
+	binary.LittleEndian.PutUint32(record[0:], uint32(t.Unix()))
+	binary.LittleEndian.PutUint32(record[4:], uint32(t.Nanosecond()/1000))
+	binary.LittleEndian.PutUint32(record[8:], uint32(len(frame)))
+	binary.LittleEndian.PutUint32(record[12:], uint32(len(frame)))
+	_, err := d.w.Write(append(record, frame...))
+	return err
+}
+
+// ipUDPFrame 构造 src 到 dst 的 IPv4 或 IPv6 UDP 报文
+//
+// 一端为 IPv4（含 IPv4 映射地址）时按 IPv4 输出，另一端不是 IPv4 地址时使用 0.0.0.0。
+func ipUDPFrame(src, dst *net.UDPAddr, payload []byte) []byte {
+	udpLen := udpHeaderSz + len(payload)
+	src4, dst4 := src.IP.To4(), dst.IP.To4()
+	if src4 != nil || dst4 != nil {
+		if src4 == nil {
+			src4 = net.IPv4zero.To4()
+		}
+		if dst4 == nil {
+			dst4 = net.IPv4zero.To4()
+		}
+		frame := make([]byte, ipv4HeaderSz+udpLen)
+		ip := frame[:ipv4HeaderSz]
+		ip[0] = 0x45
+		binary.BigEndian.PutUint16(ip[2:], uint16(len(frame)))
+		binary.BigEndian.PutUint16(ip[6:], 0x4000) // DF
+		ip[8] = 64
+		ip[9] = 17 // UDP
+		copy(ip[12:], src4)
+		copy(ip[16:], dst4)
+		binary.BigEndian.PutUint16(ip[10:], ^onesSum(0, ip))
+
+		pseudo := append(append(append([]byte(nil), src4...), dst4...), 0, 17, byte(udpLen>>8), byte(udpLen))
+		writeUDP(frame[ipv4HeaderSz:], src.Port, dst.Port, payload, pseudo)
+		return frame
+	}
+
+	src16, dst16 := src.IP.To16(), dst.IP.To16()
+	if src16 == nil {
+		src16 = net.IPv6unspecified
+	}
+	if dst16 == nil {
+		dst16 = net.IPv6unspecified
+	}
+	frame := make([]byte, ipv6HeaderSz+udpLen)
+	ip := frame[:ipv6HeaderSz]
+	ip[0] = 0x60
+	binary.BigEndian.PutUint16(ip[4:], uint16(udpLen))
+	ip[6] = 17 // UDP
+	ip[7] = 64
+	copy(ip[8:], src16)
+	copy(ip[24:], dst16)
+
+	pseudo := append(append(append([]byte(nil), src16...), dst16...), 0, 0, byte(udpLen>>8), byte(udpLen), 0, 0, 0, 17)
+	writeUDP(frame[ipv6HeaderSz:], src.Port, dst.Port, payload, pseudo)
+	return frame
+}
+
+// writeUDP 写入 UDP 头、载荷和校验和
+func writeUDP(udp []byte, srcPort, dstPort int, payload, pseudo []byte) {
+	binary.BigEndian.PutUint16(udp[0:], uint16(srcPort))
+	binary.BigEndian.PutUint16(udp[2:], uint16(dstPort))
+	binary.BigEndian.PutUint16(udp[4:], uint16(len(udp)))
+	copy(udp[udpHeaderSz:], payload)
+	sum := ^onesSum(uint32(onesSum(0, pseudo)), udp)
+	if sum == 0 {
+		sum = 0xffff
+	}
+	binary.BigEndian.PutUint16(udp[6:], sum)
+}
+
+// onesSum 按 RFC 1071 累加 16 位反码和
+func onesSum(sum uint32, b []byte) uint16 {
+	for i := 0; i+1 < len(b); i += 2 {
+		sum += uint32(b[i])<<8 | uint32(b[i+1])
+	}
+	if len(b)%2 == 1 {
+		sum += uint32(b[len(b)-1]) << 8
+	}
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	return uint16(sum)
+}
@@ -0,0 +1,33 @@
+package lzsnmp
+
+import (
+	"errors"
+	"runtime/debug"
+)
+
+// errHandlerPanic 处理函数 panic 时返回的错误，不向请求方暴露 panic 内容
+var errHandlerPanic = errors.New("handler panic")
+
+// guard 调用 fn，panic 时记录堆栈、增加计数并返回 errHandlerPanic
+func (a *Agent) guard(oid string, fn func() (interface{}, error)) (value interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			a.stats.handlerPanics.Add(1)
+			a.logger.Error("Handler panic", "oid", oid, "panic", r, "stack", string(debug.Stack()))
+			value, err = nil, errHandlerPanic
+		}
+	}()
+	return fn()
+}
+
+// guardRequest 在 worker 中调用 fn，panic 时将本次响应标记为 genErr
+//
+// GoSNMPServer 只有在 UserErrorMarkPacket 开启时才把处理函数错误报告为 genErr，
+// 每个 worker 顺序处理请求，因此只在出现 panic 的请求中开启，handlePacket 处理完后关闭。
+func (a *Agent) guardRequest(w *worker, oid string, fn func() (interface{}, error)) (interface{}, error) {
+	value, err := a.guard(oid, fn)
+	if errors.Is(err, errHandlerPanic) {
+		w.markGenErr(true)
+	}
+	return value, err
+}
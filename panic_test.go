@@ -0,0 +1,63 @@
+package lzsnmp_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/gosnmp/gosnmp"
+
+	lzsnmp "github.com/liuzhen9320/snmp-go"
+	"github.com/liuzhen9320/snmp-go/testutil"
+)
+
+func TestHandlerPanicReturnsGenErr(t *testing.T) {
+	agent, err := lzsnmp.NewAgent(lzsnmp.Config{PEN: benchPEN, DisableStartTraps: true, LogLevel: log.FatalLevel})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := agent.Register("1.1.0", gosnmp.Integer, func() (interface{}, error) { panic("boom") }); err != nil {
+		t.Fatal(err)
+	}
+	if err := agent.RegisterStatic("1.2.0", gosnmp.Integer, 2); err != nil {
+		t.Fatal(err)
+	}
+	client, err := testutil.StartInMemory(agent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer agent.Stop()
+	defer client.Close()
+
+	oid := fmt.Sprintf(".1.3.6.1.4.1.%d.1.1.0", benchPEN)
+	tests := []struct {
+		name    string
+		request func() (*gosnmp.SnmpPacket, error)
+	}{
+		{"GET", func() (*gosnmp.SnmpPacket, error) { return client.Get(oid) }},
+		{"GETNEXT", func() (*gosnmp.SnmpPacket, error) { return client.GetNext(fmt.Sprintf(".1.3.6.1.4.1.%d.1", benchPEN)) }},
+		{"GETBULK", func() (*gosnmp.SnmpPacket, error) {
+			return client.GetBulk(0, 5, fmt.Sprintf(".1.3.6.1.4.1.%d.1", benchPEN))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := tt.request()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Error != gosnmp.GenErr || resp.ErrorIndex != 1 {
+				t.Fatalf("error = %s, index %d; want genErr, index 1", resp.Error, resp.ErrorIndex)
+			}
+		})
+	}
+
+	// genErr 只影响 panic 的请求
+	resp, err := client.Get(fmt.Sprintf(".1.3.6.1.4.1.%d.1.2.0", benchPEN))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error != gosnmp.NoError || resp.Variables[0].Value != 2 {
+		t.Fatalf("GET after panic = %s, %v; want noError, 2", resp.Error, resp.Variables[0].Value)
+	}
+}
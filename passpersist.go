@@ -0,0 +1,407 @@
+package lzsnmp
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// maxPassPersistInstances 一次遍历最多导出的实例数，防止脚本的 getnext 不收敛
+const maxPassPersistInstances = 10000
+
+// passPersistTypes pass_persist 协议的类型名称
+var passPersistTypes = map[string]gosnmp.Asn1BER{
+	"integer":   gosnmp.Integer,
+	"unsigned":  gosnmp.Gauge32,
+	"gauge":     gosnmp.Gauge32,
+	"counter":   gosnmp.Counter32,
+	"counter64": gosnmp.Counter64,
+	"timeticks": gosnmp.TimeTicks,
+	"ipaddress": gosnmp.IPAddress,
+	"objectid":  gosnmp.ObjectIdentifier,
+	"string":    gosnmp.OctetString,
+	"octet":     gosnmp.OctetString,
+	"opaque":    gosnmp.Opaque,
+}
+
+// PassPersist 由 RegisterPassPersist 创建的子树，值由 net-snmp pass_persist 脚本提供
+type PassPersist struct {
+	agent   *Agent
+	root    string
+	args    []string
+	timeout time.Duration
+
+	mu        sync.Mutex
+	proc      *passProcess
+	values    map[string]interface{}
+	types     map[string]gosnmp.Asn1BER
+	instances []string
+	walkedAt  time.Time
+	closed    bool
+}
+
+// passProcess 运行中的 pass_persist 脚本
+type passProcess struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	lines chan string // 脚本退出后关闭
+}
+
+// RegisterPassPersist 使用 net-snmp pass_persist 脚本提供相对 OID 下的子树，现有脚本无需修改即可复用
+//
+// cmd 为脚本及其参数（不经过 shell），启动后保持运行，通过标准输入输出按 pass_persist 协议交互；
+// 脚本退出或响应超过 timeout 时被杀死，下一次请求重新启动。
+// 子树的实例由 getnext 遍历得到，每次 GET 时最多每秒重新遍历一次；SET 转发给脚本。
+func (a *Agent) RegisterPassPersist(relativeOID string, cmd []string, timeout time.Duration) (*PassPersist, error) {
+	absoluteOID := fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID)
+	return a.RegisterPassPersistAbsolute(absoluteOID, cmd, timeout)
+}
+
+// RegisterPassPersistAbsolute 使用 pass_persist 脚本提供绝对路径 OID 下的子树，对应 snmpd.conf 中的
+// pass_persist .1.3.6.1.4.1.2021.255 /path/to/script
+func (a *Agent) RegisterPassPersistAbsolute(oid string, cmd []string, timeout time.Duration) (*PassPersist, error) {
+	root, err := normalizeOID(oid)
+	if err != nil {
+		return nil, err
+	}
+	if len(cmd) == 0 || cmd[0] == "" {
+		return nil, fmt.Errorf("command is required for pass_persist OID: %s", root)
+	}
+	if timeout <= 0 {
+		return nil, fmt.Errorf("pass_persist timeout must be positive for OID: %s", root)
+	}
+
+	p := &PassPersist{
+		agent:   a,
+		root:    root,
+		args:    slices.Clone(cmd),
+		timeout: timeout,
+	}
+	if err := a.claimSubtree(root, "pass_persist "+cmd[0]); err != nil {
+		return nil, err
+	}
+	if err := p.Refresh(); err != nil {
+		return nil, err
+	}
+
+	a.logger.Info("Registered pass_persist script", "oid", root, "command", cmd[0], "instances", len(p.instances))
+	return p, nil
+}
+
+// Refresh 立即遍历一次子树并在实例集合变化时重建实例 OID
+func (p *PassPersist) Refresh() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.refreshLocked()
+}
+
+// Close 注销子树中的实例并停止脚本
+func (p *PassPersist) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	p.closed = true
+
+	p.agent.replaceDynamic(p.instances, nil)
+	p.instances = nil
+	p.stopLocked()
+	p.agent.logger.Info("Stopped pass_persist script", "oid", p.root, "command", p.args[0])
+}
+
+func (p *PassPersist) refreshLocked() error {
+	if p.closed {
+		return fmt.Errorf("pass_persist %s is closed", p.root)
+	}
+
+	values := make(map[string]interface{})
+	types := make(map[string]gosnmp.Asn1BER)
+	instances := make([]string, 0, len(p.instances))
+	for prev := p.root; ; {
+		oid, oidType, value, ok, err := p.query("getnext", prev)
+		if err != nil {
+			return err
+		}
+		if !ok || !hasOIDPrefix(oid, p.root) || oid == p.root {
+			break
+		}
+		if compareOID(oid, prev) <= 0 {
+			return fmt.Errorf("pass_persist %s: getnext %s returned %s, which does not advance", p.args[0], prev, oid)
+		}
+		if len(instances) >= maxPassPersistInstances {
+			p.agent.logger.Warn("pass_persist subtree truncated", "oid", p.root, "limit", maxPassPersistInstances)
+			break
+		}
+		values[oid], types[oid] = value, oidType
+		instances = append(instances, oid)
+		prev = oid
+	}
+
+	p.walkedAt = time.Now()
+	changed := !slices.Equal(p.instances, instances)
+	for _, oid := range instances {
+		if !changed && p.types[oid] != types[oid] {
+			changed = true
+		}
+	}
+	p.values, p.types = values, types
+	if !changed {
+		return nil
+	}
+
+	add := make(map[string]dynamicOID, len(instances))
+	for _, oid := range instances {
+		add[oid] = dynamicOID{Type: types[oid], Handler: p.getter(oid), Setter: p.setter(oid)}
+	}
+	p.agent.replaceDynamic(p.instances, add)
+	p.instances = instances
+	return nil
+}
+
+func (p *PassPersist) getter(oid string) ValueHandler {
+	return func() (interface{}, error) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		if time.Since(p.walkedAt) > tableRefreshInterval {
+			if err := p.refreshLocked(); err != nil {
+				return nil, err
+			}
+		}
+		value, ok := p.values[oid]
+		if !ok {
+			return nil, fmt.Errorf("pass_persist instance %s no longer exists", oid)
+		}
+		return value, nil
+	}
+}
+
+func (p *PassPersist) setter(oid string) SetHandler {
+	return func(value interface{}) error {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		oidType, ok := p.types[oid]
+		if !ok {
+			return fmt.Errorf("pass_persist instance %s no longer exists", oid)
+		}
+		typeName, text, err := formatPassValue(oidType, value)
+		if err != nil {
+			return err
+		}
+		reply, err := p.exchange([]string{"set", "." + oid, typeName + " " + text}, 1)
+		if err != nil {
+			return err
+		}
+		if reply[0] != "DONE" {
+			return fmt.Errorf("pass_persist set %s: %s", oid, reply[0])
+		}
+		// 下一次 GET 重新遍历，读到脚本中的新值
+		p.walkedAt = time.Time{}
+		return nil
+	}
+}
+
+// query 发送 get 或 getnext，脚本返回 NONE 时 ok 为 false
+func (p *PassPersist) query(command, oid string) (string, gosnmp.Asn1BER, interface{}, bool, error) {
+	reply, err := p.exchange([]string{command, "." + oid}, 1)
+	if err != nil {
+		return "", 0, nil, false, err
+	}
+	first := reply[0]
+	if first == "NONE" {
+		return "", 0, nil, false, nil
+	}
+	rest, err := p.readLines(p.proc, 2)
+	if err != nil {
+		return "", 0, nil, false, err
+	}
+
+	next, err := normalizeOID(first)
+	if err != nil {
+		return "", 0, nil, false, fmt.Errorf("pass_persist %s returned %w", p.args[0], err)
+	}
+	oidType, value, err := parsePassValue(rest[0], rest[1])
+	if err != nil {
+		return "", 0, nil, false, fmt.Errorf("pass_persist %s returned invalid value for %s: %w", p.args[0], next, err)
+	}
+	return next, oidType, value, true, nil
+}
+
+// exchange 发送一条命令并读取 n 行响应
+func (p *PassPersist) exchange(lines []string, n int) ([]string, error) {
+	proc, err := p.processLocked()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(proc.stdin, strings.Join(lines, "\n")+"\n"); err != nil {
+		p.stopLocked()
+		return nil, fmt.Errorf("pass_persist %s: %w", p.args[0], err)
+	}
+	return p.readLines(proc, n)
+}
+
+// processLocked 返回运行中的脚本，脚本未运行时启动并用 PING 确认其可用
+func (p *PassPersist) processLocked() (*passProcess, error) {
+	if p.proc != nil {
+		return p.proc, nil
+	}
+
+	cmd := exec.Command(p.args[0], p.args[1:]...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start pass_persist %s: %w", p.args[0], err)
+	}
+
+	proc := &passProcess{cmd: cmd, stdin: stdin, lines: make(chan string, 16)}
+	go func() {
+		defer close(proc.lines)
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			proc.lines <- strings.TrimRight(scanner.Text(), "\r")
+		}
+	}()
+	p.proc = proc
+
+	reply, err := p.exchange([]string{"PING"}, 1)
+	if err != nil {
+		return nil, err
+	}
+	if reply[0] != "PONG" {
+		p.stopLocked()
+		return nil, fmt.Errorf("pass_persist %s answered %q to PING", p.args[0], reply[0])
+	}
+	p.agent.logger.Debug("Started pass_persist script", "oid", p.root, "command", p.args[0], "pid", cmd.Process.Pid)
+	return proc, nil
+}
+
+// readLines 读取 n 行响应
+func (p *PassPersist) readLines(proc *passProcess, n int) ([]string, error) {
+	lines := make([]string, 0, n)
+	for range n {
+		line, err := p.readLine(proc)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// readLine 在超时内读取一行，脚本退出或超时时停止脚本，避免之后读到错位的响应
+func (p *PassPersist) readLine(proc *passProcess) (string, error) {
+	timer := time.NewTimer(p.timeout)
+	defer timer.Stop()
+
+	select {
+	case line, ok := <-proc.lines:
+		if !ok {
+			p.stopLocked()
+			return "", fmt.Errorf("pass_persist %s exited", p.args[0])
+		}
+		return line, nil
+	case <-timer.C:
+		p.stopLocked()
+		return "", fmt.Errorf("pass_persist %s timed out after %s", p.args[0], p.timeout)
+	}
+}
+
+// stopLocked 停止脚本并回收进程
+func (p *PassPersist) stopLocked() {
+	proc := p.proc
+	if proc == nil {
+		return
+	}
+	p.proc = nil
+
+	proc.stdin.Close()
+	proc.cmd.Process.Kill()
+	go func() {
+		for range proc.lines {
+		}
+		proc.cmd.Wait()
+	}()
+}
+
+// parsePassValue 解析脚本返回的类型和值
+func parsePassValue(typeName, text string) (gosnmp.Asn1BER, interface{}, error) {
+	typeName = strings.ToLower(strings.TrimSpace(typeName))
+	oidType, ok := passPersistTypes[typeName]
+	if !ok {
+		return 0, nil, fmt.Errorf("unknown type %q", typeName)
+	}
+	switch typeName {
+	case "octet":
+		// 以空格分隔的十六进制字节，如 "01 02 ff"
+		b, err := hex.DecodeString(strings.Join(strings.Fields(text), ""))
+		return oidType, b, err
+	case "objectid":
+		value, err := parseValueText(oidType, strings.TrimPrefix(strings.TrimSpace(text), "."), false)
+		return oidType, value, err
+	}
+	value, err := parseValueText(oidType, text, false)
+	return oidType, value, err
+}
+
+// formatPassValue 将 SET 的值格式化为 pass_persist 协议的类型和值
+func formatPassValue(oidType gosnmp.Asn1BER, value interface{}) (string, string, error) {
+	text, isHex, err := formatValueText(oidType, value)
+	if err != nil {
+		return "", "", err
+	}
+	switch oidType {
+	case gosnmp.Integer:
+		return "integer", text, nil
+	case gosnmp.Gauge32, gosnmp.Uinteger32:
+		return "gauge", text, nil
+	case gosnmp.Counter32:
+		return "counter", text, nil
+	case gosnmp.Counter64:
+		return "counter64", text, nil
+	case gosnmp.TimeTicks:
+		return "timeticks", text, nil
+	case gosnmp.IPAddress:
+		return "ipaddress", text, nil
+	case gosnmp.ObjectIdentifier:
+		return "objectid", "." + strings.TrimPrefix(text, "."), nil
+	case gosnmp.OctetString:
+		if isHex {
+			b, err := hex.DecodeString(text)
+			if err != nil {
+				return "", "", err
+			}
+			return "octet", spacedHex(b), nil
+		}
+		if strings.ContainsAny(text, "\r\n") {
+			// 换行会破坏按行的协议
+			return "octet", spacedHex([]byte(text)), nil
+		}
+		return "string", text, nil
+	}
+	return "", "", fmt.Errorf("type %s is not supported by pass_persist", oidType)
+}
+
+// spacedHex 以空格分隔的十六进制字节
+func spacedHex(b []byte) string {
+	parts := make([]string, len(b))
+	for i, c := range b {
+		parts[i] = fmt.Sprintf("%02x", c)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,466 @@
+package lzsnmp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// PersistMode 持久化写入方式
+type PersistMode int
+
+const (
+	// PersistWriteThrough 每次修改后立即写入存储
+	PersistWriteThrough PersistMode = iota
+	// PersistSnapshot 每隔 Interval 将有修改的完整状态写入一次，Stop 时再写入一次
+	PersistSnapshot
+)
+
+// PersistConfig 静态值和 SET 修改的值的持久化配置
+type PersistConfig struct {
+	Store    ValueStore    // 保存值的存储，如 NewJSONValueStore 或 boltstore 的 Values
+	Mode     PersistMode   // 默认 PersistWriteThrough
+	Interval time.Duration // PersistSnapshot 的写入间隔，默认 30 秒
+}
+
+// ValueStore 保存 OID 值的存储，条目使用与 ExportSubtree 相同的文本格式
+type ValueStore interface {
+	Load() ([]SubtreeEntry, error)
+	Put(entries []SubtreeEntry) error     // 添加或替换条目
+	Delete(oids []string) error           // 删除条目，不存在的 OID 被忽略
+	Replace(entries []SubtreeEntry) error // 用 entries 替换全部内容
+}
+
+// persister 在内存中维护持久化的值，按模式写入 ValueStore
+//
+// 只保存运行时的修改（SET、再次注册静态值、ImportSubtree 等），进程中第一次注册某个 OID 时用保存的值替代注册的值
+// （可写 OID 通过 setter 恢复）。静态值的修改同时保存代码中原值的摘要（SubtreeEntry.Base），新版本中代码的值改变后
+// 丢弃保存的修改，使用新的值；修改回代码中的值时删除保存的条目。只有经过注册恢复的 OID 会被保存，表格等子树实例由其提供方管理。
+type persister struct {
+	agent  *Agent
+	config PersistConfig
+
+	mu         sync.Mutex
+	values     map[string]SubtreeEntry
+	tracked    map[string]bool   // 本进程中已注册的 OID
+	base       map[string]string // 静态值第一次注册时代码中的值的摘要
+	pendingPut map[string]SubtreeEntry
+	pendingDel map[string]bool
+	dirty      bool
+
+	writeMu sync.Mutex // 串行化对存储的写入，保证顺序
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// newPersister 从存储加载保存的值
+func newPersister(a *Agent, cfg PersistConfig) (*persister, error) {
+	if cfg.Store == nil {
+		return nil, fmt.Errorf("persist store is required")
+	}
+	if cfg.Mode != PersistWriteThrough && cfg.Mode != PersistSnapshot {
+		return nil, fmt.Errorf("invalid persist mode: %d", cfg.Mode)
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+
+	entries, err := cfg.Store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load persisted values: %w", err)
+	}
+	p := &persister{
+		agent:      a,
+		config:     cfg,
+		values:     make(map[string]SubtreeEntry, len(entries)),
+		tracked:    make(map[string]bool),
+		base:       make(map[string]string),
+		pendingPut: make(map[string]SubtreeEntry),
+		pendingDel: make(map[string]bool),
+	}
+	for _, e := range entries {
+		p.values[e.OID] = e
+	}
+	a.logger.Info("Loaded persisted values", "entries", len(entries), "mode", cfg.Mode)
+	return p, nil
+}
+
+// restore 返回 OID 第一次注册时应使用的保存值，类型与注册的类型不一致时忽略
+//
+// base 为静态值在代码中的值的摘要，与保存修改时的原值不同时丢弃保存的值；可写 OID 的 base 为空。
+func (p *persister) restore(oid string, oidType gosnmp.Asn1BER, base string) (interface{}, bool) {
+	if p.tracked[oid] {
+		return nil, false
+	}
+	p.tracked[oid] = true
+
+	e, ok := p.values[oid]
+	if !ok {
+		return nil, false
+	}
+	if base != "" && e.Base != base {
+		p.agent.logger.Info("Registered value changed since it was persisted, discarding persisted value", "oid", oid, "persisted", e.Value)
+		p.deleteLocked(oid)
+		return nil, false
+	}
+	if e.Type != oidType.String() {
+		p.agent.logger.Warn("Persisted value type mismatch, ignoring", "oid", oid, "persisted", e.Type, "registered", oidType)
+		return nil, false
+	}
+	value, err := parseValueText(oidType, e.Value, e.Hex)
+	if err != nil {
+		p.agent.logger.Warn("Invalid persisted value, ignoring", "oid", oid, "error", err)
+		return nil, false
+	}
+	return value, true
+}
+
+// registerStatic 注册静态值时调用，返回实际使用的值（第一次注册时为保存的值）
+func (p *persister) registerStatic(oid string, oidType gosnmp.Asn1BER, value interface{}) interface{} {
+	if p == nil {
+		return value
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.tracked[oid] {
+		p.base[oid] = valueDigest(oidType, value)
+		if restored, ok := p.restore(oid, oidType, p.base[oid]); ok {
+			p.agent.logger.Info("Restored persisted value", "oid", oid, "value", restored)
+			return restored
+		}
+		return value
+	}
+	p.recordLocked(oid, oidType, value)
+	return value
+}
+
+// registerWritable 注册可写 OID 时调用，返回第一次注册时应通过 setter 恢复的值
+//
+// OctetString 以 []byte 返回，与 SET 请求传给 setter 的值一致。
+func (p *persister) registerWritable(oid string, oidType gosnmp.Asn1BER) (interface{}, bool) {
+	if p == nil {
+		return nil, false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	value, ok := p.restore(oid, oidType, "")
+	if s, isString := value.(string); ok && isString && (oidType == gosnmp.OctetString || oidType == gosnmp.Opaque) {
+		value = []byte(s)
+	}
+	return value, ok
+}
+
+// record 保存 OID 的新值，未注册恢复的 OID 被忽略
+func (p *persister) record(oid string, oidType gosnmp.Asn1BER, value interface{}) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.tracked[oid] {
+		p.recordLocked(oid, oidType, value)
+	}
+}
+
+// track 标记 OID 已注册并保存其值，用于 ImportSubtree 等显式设置值的场景，不恢复保存的值
+func (p *persister) track(oid string, oidType gosnmp.Asn1BER, value interface{}) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tracked[oid] = true
+	p.recordLocked(oid, oidType, value)
+}
+
+// recordLocked 保存 OID 的新值，静态值改回代码中的值时删除保存的条目
+func (p *persister) recordLocked(oid string, oidType gosnmp.Asn1BER, value interface{}) {
+	text, isHex, err := formatValueText(oidType, value)
+	if err != nil {
+		p.agent.logger.Warn("Cannot persist value", "oid", oid, "error", err)
+		return
+	}
+	base := p.base[oid]
+	if base != "" && valueDigest(oidType, value) == base {
+		p.deleteLocked(oid)
+		return
+	}
+	e := SubtreeEntry{OID: oid, Type: oidType.String(), Value: text, Hex: isHex, Base: base}
+	if old, ok := p.values[oid]; ok && old == e {
+		return
+	}
+	p.values[oid] = e
+	p.pendingPut[oid] = e
+	delete(p.pendingDel, oid)
+	p.dirty = true
+}
+
+// forget 删除注销的 OID 的保存值
+func (p *persister) forget(oid string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.tracked, oid)
+	delete(p.base, oid)
+	p.deleteLocked(oid)
+}
+
+// deleteLocked 删除 OID 的保存值
+func (p *persister) deleteLocked(oid string) {
+	if _, ok := p.values[oid]; !ok {
+		return
+	}
+	delete(p.values, oid)
+	delete(p.pendingPut, oid)
+	p.pendingDel[oid] = true
+	p.dirty = true
+}
+
+// valueDigest 返回值的摘要，用于判断代码中注册的值是否改变，无法格式化的值返回空字符串
+func valueDigest(oidType gosnmp.Asn1BER, value interface{}) string {
+	text, isHex, err := formatValueText(oidType, value)
+	if err != nil {
+		return ""
+	}
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%t\x00%s", oidType, isHex, text)
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// sync 在 PersistWriteThrough 模式下写入待保存的修改，在修改发布后、不持有 Agent 锁时调用
+func (p *persister) sync() {
+	if p == nil || p.config.Mode != PersistWriteThrough {
+		return
+	}
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+
+	p.mu.Lock()
+	put := slices.Collect(maps.Values(p.pendingPut))
+	del := slices.Collect(maps.Keys(p.pendingDel))
+	clear(p.pendingPut)
+	clear(p.pendingDel)
+	p.dirty = false
+	p.mu.Unlock()
+
+	if len(put) > 0 {
+		if err := p.config.Store.Put(put); err != nil {
+			p.agent.logger.Error("Failed to persist values", "entries", len(put), "error", err)
+			p.requeue(put, nil)
+		}
+	}
+	if len(del) > 0 {
+		if err := p.config.Store.Delete(del); err != nil {
+			p.agent.logger.Error("Failed to delete persisted values", "entries", len(del), "error", err)
+			p.requeue(nil, del)
+		}
+	}
+}
+
+// requeue 将写入失败的修改放回队列，下一次写入时重试；期间有更新的 OID 不放回
+func (p *persister) requeue(put []SubtreeEntry, del []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, e := range put {
+		if _, newer := p.pendingDel[e.OID]; !newer {
+			if _, newer := p.pendingPut[e.OID]; !newer {
+				p.pendingPut[e.OID] = e
+			}
+		}
+	}
+	for _, oid := range del {
+		if _, newer := p.pendingPut[oid]; !newer {
+			p.pendingDel[oid] = true
+		}
+	}
+	p.dirty = true
+}
+
+// flush 有修改时将完整状态写入存储
+func (p *persister) flush() error {
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+
+	p.mu.Lock()
+	if !p.dirty {
+		p.mu.Unlock()
+		return nil
+	}
+	entries := slices.Collect(maps.Values(p.values))
+	clear(p.pendingPut)
+	clear(p.pendingDel)
+	p.dirty = false
+	p.mu.Unlock()
+
+	slices.SortFunc(entries, func(a, b SubtreeEntry) int { return compareOID(a.OID, b.OID) })
+	if err := p.config.Store.Replace(entries); err != nil {
+		p.mu.Lock()
+		p.dirty = true
+		p.mu.Unlock()
+		return err
+	}
+	p.agent.logger.Debug("Persisted snapshot", "entries", len(entries))
+	return nil
+}
+
+// start 在 PersistSnapshot 模式下开始定期写入，Agent.Start 时调用
+func (p *persister) start() {
+	if p == nil || p.config.Mode != PersistSnapshot || p.stop != nil {
+		return
+	}
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+	go p.snapshotLoop(p.stop, p.done)
+}
+
+// close 停止定期写入并写入未保存的修改，Agent.Stop 时调用
+func (p *persister) close() {
+	if p == nil {
+		return
+	}
+	if p.stop != nil {
+		close(p.stop)
+		<-p.done
+		p.stop, p.done = nil, nil
+	}
+	if p.config.Mode == PersistSnapshot {
+		if err := p.flush(); err != nil {
+			p.agent.logger.Error("Failed to persist snapshot", "error", err)
+		}
+	} else {
+		p.sync()
+	}
+}
+
+func (p *persister) snapshotLoop(stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := p.flush(); err != nil {
+				p.agent.logger.Error("Failed to persist snapshot", "error", err)
+			}
+		}
+	}
+}
+
+// jsonValueStore 保存在 JSON 文件中的值，文件格式与 ExportSubtree 导出的 Subtree 相同
+type jsonValueStore struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]SubtreeEntry
+}
+
+// NewJSONValueStore 返回保存在 path 处 JSON 文件中的 ValueStore，文件不存在时在第一次写入时创建
+//
+// 每次写入都重写整个文件（先写临时文件再重命名），适合数量不多的配置类 OID。
+func NewJSONValueStore(path string) (ValueStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("value store path is required")
+	}
+	return &jsonValueStore{path: path}, nil
+}
+
+func (s *jsonValueStore) Load() ([]SubtreeEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = make(map[string]SubtreeEntry)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var subtree Subtree
+	if err := json.Unmarshal(data, &subtree); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", s.path, err)
+	}
+	for _, e := range subtree.Entries {
+		s.entries[e.OID] = e
+	}
+	return subtree.Entries, nil
+}
+
+func (s *jsonValueStore) Put(entries []SubtreeEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := maps.Clone(s.entries)
+	if next == nil {
+		next = make(map[string]SubtreeEntry)
+	}
+	for _, e := range entries {
+		next[e.OID] = e
+	}
+	return s.writeLocked(next)
+}
+
+func (s *jsonValueStore) Delete(oids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := maps.Clone(s.entries)
+	for _, oid := range oids {
+		delete(next, oid)
+	}
+	return s.writeLocked(next)
+}
+
+func (s *jsonValueStore) Replace(entries []SubtreeEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := make(map[string]SubtreeEntry, len(entries))
+	for _, e := range entries {
+		next[e.OID] = e
+	}
+	return s.writeLocked(next)
+}
+
+// writeLocked 原子地重写文件，成功后替换内存中的条目
+func (s *jsonValueStore) writeLocked(entries map[string]SubtreeEntry) error {
+	subtree := Subtree{ExportedAt: time.Now(), Entries: slices.Collect(maps.Values(entries))}
+	slices.SortFunc(subtree.Entries, func(a, b SubtreeEntry) int { return compareOID(a.OID, b.OID) })
+	data, err := json.MarshalIndent(subtree, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+	s.entries = entries
+	return nil
+}
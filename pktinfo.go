@@ -0,0 +1,57 @@
+//go:build !lzsnmp_nopktinfo
+
+package lzsnmp
+
+import (
+	"net"
+
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// pktinfo 通过 IP_PKTINFO / IPV6_PKTINFO 控制消息获取请求的目的地址、指定响应源地址
+type pktinfo struct {
+	p4 *ipv4.PacketConn
+	p6 *ipv6.PacketConn
+}
+
+// enablePktinfo 在绑定通配地址的连接上开启控制消息，平台不支持时返回 nil
+func enablePktinfo(conn *net.UDPConn, v4 bool) *pktinfo {
+	if v4 {
+		p := ipv4.NewPacketConn(conn)
+		if p.SetControlMessage(ipv4.FlagDst|ipv4.FlagInterface, true) == nil {
+			return &pktinfo{p4: p}
+		}
+		return nil
+	}
+	p := ipv6.NewPacketConn(conn)
+	if p.SetControlMessage(ipv6.FlagDst|ipv6.FlagInterface, true) == nil {
+		return &pktinfo{p6: p}
+	}
+	return nil
+}
+
+func (p *pktinfo) readFrom(b []byte) (int, net.IP, net.Addr, error) {
+	if p.p4 != nil {
+		n, cm, remote, err := p.p4.ReadFrom(b)
+		if cm != nil {
+			return n, cm.Dst, remote, err
+		}
+		return n, nil, remote, err
+	}
+	n, cm, remote, err := p.p6.ReadFrom(b)
+	if cm != nil {
+		return n, cm.Dst, remote, err
+	}
+	return n, nil, remote, err
+}
+
+func (p *pktinfo) writeTo(b []byte, local net.IP, remote net.Addr) error {
+	var err error
+	if p.p4 != nil {
+		_, err = p.p4.WriteTo(b, &ipv4.ControlMessage{Src: local}, remote)
+	} else {
+		_, err = p.p6.WriteTo(b, &ipv6.ControlMessage{Src: local}, remote)
+	}
+	return err
+}
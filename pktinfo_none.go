@@ -0,0 +1,24 @@
+//go:build lzsnmp_nopktinfo
+
+package lzsnmp
+
+import (
+	"errors"
+	"net"
+)
+
+// pktinfo 排除 PKTINFO 支持时不使用，监听通配地址时由内核选择响应源地址，SourceAddr 不生效
+type pktinfo struct{}
+
+// enablePktinfo 排除 PKTINFO 支持时总是返回 nil
+func enablePktinfo(conn *net.UDPConn, v4 bool) *pktinfo {
+	return nil
+}
+
+func (p *pktinfo) readFrom(b []byte) (int, net.IP, net.Addr, error) {
+	return 0, nil, nil, errors.New("PKTINFO is excluded by the lzsnmp_nopktinfo build tag")
+}
+
+func (p *pktinfo) writeTo(b []byte, local net.IP, remote net.Addr) error {
+	return errors.New("PKTINFO is excluded by the lzsnmp_nopktinfo build tag")
+}
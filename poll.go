@@ -0,0 +1,194 @@
+package lzsnmp
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// maxPollBackoff 连续失败时轮询间隔最多放大的倍数
+const maxPollBackoff = 8
+
+// errNotPolled 第一次采集尚未完成
+var errNotPolled = errors.New("value not polled yet")
+
+// poller 由 RegisterPolled 创建的后台采集任务，GET 总是返回最近一次成功采集的值
+type poller struct {
+	agent    *Agent
+	oid      string
+	interval time.Duration
+	fetch    ValueHandler
+
+	mu       sync.Mutex
+	value    interface{}
+	valid    bool
+	lastErr  error
+	failures int
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// RegisterPolled 注册由后台定时采集的相对 OID，见 RegisterPolledAbsolute
+func (a *Agent) RegisterPolled(relativeOID string, oidType gosnmp.Asn1BER, interval time.Duration, fetch ValueHandler) error {
+	absoluteOID := fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID)
+	return a.RegisterPolledAbsolute(absoluteOID, oidType, interval, fetch)
+}
+
+// RegisterPolledAbsolute 注册由后台定时采集的绝对路径 OID，GET 不调用 fetch，立即返回最近一次成功采集的值
+//
+// 注册后立即在后台采集一次，之后每隔 interval（±10% 随机抖动，错开多个采集任务）采集一次；
+// 第一次采集成功前 GET 返回错误。采集失败时继续返回上一次的值，重试间隔按 2 倍递增，最多为 interval 的 8 倍，
+// 成功后恢复。适用于查询耗时可能超过管理端超时的后端，轮询延迟与后端延迟无关。
+// 采集任务在 Agent.Stop、OID 被注销或再次调用 RegisterPolled 时停止。
+func (a *Agent) RegisterPolledAbsolute(oid string, oidType gosnmp.Asn1BER, interval time.Duration, fetch ValueHandler) error {
+	if interval <= 0 {
+		return fmt.Errorf("poll interval must be positive for OID: %s", oid)
+	}
+	if fetch == nil {
+		return fmt.Errorf("fetch function is required for OID: %s", oid)
+	}
+	oid, err := normalizeOID(oid)
+	if err != nil {
+		return err
+	}
+
+	p := &poller{
+		agent:    a,
+		oid:      oid,
+		interval: interval,
+		fetch:    fetch,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if err := a.registerDynamic(oid, oidType, p.get, nil); err != nil {
+		return err
+	}
+
+	a.pollMu.Lock()
+	if a.pollers == nil {
+		a.pollers = make(map[string]*poller)
+	}
+	old := a.pollers[oid]
+	a.pollers[oid] = p
+	a.pollMu.Unlock()
+	if old != nil {
+		// 不等待旧任务正在进行的采集完成
+		old.cancel()
+	}
+
+	go p.run()
+	a.logger.Info("Registered polled OID", "oid", oid, "type", oidType, "interval", interval)
+	return nil
+}
+
+// get 返回最近一次成功采集的值
+func (p *poller) get() (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.valid {
+		return p.value, nil
+	}
+	if p.lastErr != nil {
+		return nil, fmt.Errorf("%w: %w", errNotPolled, p.lastErr)
+	}
+	return nil, errNotPolled
+}
+
+func (p *poller) run() {
+	defer close(p.done)
+
+	p.poll()
+	timer := time.NewTimer(p.next())
+	defer timer.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-timer.C:
+			if !p.registered() {
+				return
+			}
+			p.poll()
+			timer.Reset(p.next())
+		}
+	}
+}
+
+// poll 采集一次并更新值，失败时保留上一次的值
+func (p *poller) poll() {
+	value, err := p.agent.guard(p.oid, p.fetch)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		p.lastErr = err
+		p.failures++
+		p.agent.logger.Warn("Polled OID fetch failed", "oid", p.oid, "failures", p.failures, "error", err)
+		return
+	}
+	if p.failures > 0 {
+		p.agent.logger.Info("Polled OID fetch recovered", "oid", p.oid, "failures", p.failures)
+	}
+	p.value, p.valid, p.lastErr, p.failures = value, true, nil, 0
+}
+
+// next 返回到下一次采集的间隔：连续失败时按 2 倍退避，并加上 ±10% 的随机抖动
+func (p *poller) next() time.Duration {
+	p.mu.Lock()
+	failures := p.failures
+	p.mu.Unlock()
+
+	delay := p.interval
+	for i := 0; i < failures && delay < p.interval*maxPollBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, p.interval*maxPollBackoff)
+	if jitter := int64(delay / 5); jitter > 0 {
+		delay += time.Duration(rand.Int64N(jitter) - jitter/2)
+	}
+	return delay
+}
+
+// registered 判断 OID 是否仍由该采集任务提供，被注销或替换为静态值时返回 false
+func (p *poller) registered() bool {
+	p.agent.pollMu.Lock()
+	current := p.agent.pollers[p.oid] == p
+	p.agent.pollMu.Unlock()
+	if !current {
+		return false
+	}
+	if _, ok := p.agent.store.Load().handlers[p.oid]; ok {
+		return true
+	}
+	p.agent.pollMu.Lock()
+	if p.agent.pollers[p.oid] == p {
+		delete(p.agent.pollers, p.oid)
+	}
+	p.agent.pollMu.Unlock()
+	return false
+}
+
+// cancel 通知采集任务停止，不等待正在进行的采集
+func (p *poller) cancel() {
+	p.stopOnce.Do(func() { close(p.stop) })
+}
+
+// stopPollers 停止所有采集任务并等待退出
+func (a *Agent) stopPollers() {
+	a.pollMu.Lock()
+	pollers := a.pollers
+	a.pollers = nil
+	a.pollMu.Unlock()
+	for _, p := range pollers {
+		p.cancel()
+	}
+	for _, p := range pollers {
+		<-p.done
+	}
+}
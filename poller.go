@@ -0,0 +1,209 @@
+package lzsnmp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/gosnmp/gosnmp"
+)
+
+// PollPlan 对一个目标执行的采集计划
+type PollPlan struct {
+	Get  []string // 用 GET 读取的 OID，如 sysUpTime.0
+	Walk []string // 用 BulkWalk 遍历的子树，如 ifTable
+}
+
+// PollTarget 采集目标
+type PollTarget struct {
+	Name    string        // 目标名称，原样放入结果，为空时使用 Config.Address
+	Config  ManagerConfig // 连接参数，Logger 为空时使用 Poller 的 Logger
+	Plan    PollPlan
+	Timeout time.Duration // 整个计划的超时，为 0 时使用 PollerConfig.Timeout
+}
+
+// PollResult 一个目标的采集结果
+type PollResult struct {
+	Target    string
+	Variables []gosnmp.SnmpPDU // GET 结果在前（不存在的 OID 也保留），之后是各子树的遍历结果
+	Err       error            // 失败时为第一个错误，Variables 为出错前已采集的部分
+	Start     time.Time
+	Duration  time.Duration
+}
+
+// PollerConfig 并发采集器配置
+type PollerConfig struct {
+	Concurrency int           // 同时采集的目标数上限，默认 16
+	Rate        float64       // 每秒开始采集的目标数上限，0 表示不限制，避免大量请求同时到达设备或防火墙
+	Timeout     time.Duration // 每个目标整个计划的默认超时，默认 30 秒
+
+	LogLevel log.Level
+	Logger   Logger
+}
+
+// Poller 对大量目标并发执行采集计划，每个目标使用独立的 Manager
+type Poller struct {
+	config PollerConfig
+	logger Logger
+}
+
+// NewPoller 创建并发采集器
+func NewPoller(cfg PollerConfig) (*Poller, error) {
+	if cfg.Concurrency < 0 || cfg.Rate < 0 || cfg.Timeout < 0 {
+		return nil, fmt.Errorf("poller concurrency, rate and timeout must not be negative")
+	}
+	if cfg.Concurrency == 0 {
+		cfg.Concurrency = 16
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = newDefaultLogger(cfg.LogLevel, false)
+	}
+	return &Poller{config: cfg, logger: logger}, nil
+}
+
+// Poll 开始一轮采集，每个目标完成时将结果发送到返回的通道，全部完成后关闭通道
+//
+// 通道的缓冲区可以容纳所有结果，调用方不必及时读取。ctx 取消时尚未开始的目标以 ctx 的错误作为结果。
+func (p *Poller) Poll(ctx context.Context, targets []PollTarget) <-chan *PollResult {
+	results := make(chan *PollResult, len(targets))
+	go func() {
+		defer close(results)
+
+		var tick <-chan time.Time
+		if p.config.Rate > 0 {
+			ticker := time.NewTicker(time.Duration(float64(time.Second) / p.config.Rate))
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		sem := make(chan struct{}, p.config.Concurrency)
+		var wg sync.WaitGroup
+		for i, t := range targets {
+			if i > 0 && tick != nil {
+				select {
+				case <-ctx.Done():
+				case <-tick:
+				}
+			}
+			select {
+			case <-ctx.Done():
+			case sem <- struct{}{}:
+			}
+			if ctx.Err() != nil {
+				results <- &PollResult{Target: t.name(), Err: ctx.Err(), Start: time.Now()}
+				continue
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				results <- p.pollTarget(ctx, t)
+			}()
+		}
+		wg.Wait()
+	}()
+	return results
+}
+
+// PollFunc 执行一轮采集，在调用方协程中依次对每个结果调用 fn，全部完成后返回
+func (p *Poller) PollFunc(ctx context.Context, targets []PollTarget, fn func(*PollResult)) {
+	for r := range p.Poll(ctx, targets) {
+		fn(r)
+	}
+}
+
+// Run 每隔 interval 执行一轮采集直到 ctx 取消，上一轮未完成时下一轮顺延
+func (p *Poller) Run(ctx context.Context, interval time.Duration, targets []PollTarget, fn func(*PollResult)) error {
+	if interval <= 0 {
+		return fmt.Errorf("poll interval must be positive")
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		start := time.Now()
+		p.PollFunc(ctx, targets, fn)
+		p.logger.Debug("Poll round finished", "targets", len(targets), "duration", time.Since(start))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t PollTarget) name() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return t.Config.Address
+}
+
+// pollTarget 在目标的超时内执行采集计划
+func (p *Poller) pollTarget(ctx context.Context, t PollTarget) *PollResult {
+	r := &PollResult{Target: t.name(), Start: time.Now()}
+	defer func() {
+		r.Duration = time.Since(r.Start)
+		if r.Err != nil {
+			p.logger.Debug("Poll failed", "target", r.Target, "duration", r.Duration, "error", r.Err)
+		}
+	}()
+
+	timeout := t.Timeout
+	if timeout <= 0 {
+		timeout = p.config.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cfg := t.Config
+	if cfg.Logger == nil {
+		cfg.Logger = p.logger
+	}
+	m, err := NewManager(cfg)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	defer m.Close()
+
+	if len(t.Plan.Get) > 0 {
+		vars, err := m.Get(ctx, t.Plan.Get...)
+		if err != nil {
+			r.Err = err
+			return r
+		}
+		r.Variables = append(r.Variables, vars...)
+	}
+	for _, root := range t.Plan.Walk {
+		err := m.BulkWalk(ctx, root, func(pdu gosnmp.SnmpPDU) error {
+			r.Variables = append(r.Variables, pdu)
+			return nil
+		})
+		if err != nil {
+			r.Err = err
+			return r
+		}
+	}
+	return r
+}
+
+// Value 返回结果中 oid 的变量，不存在时返回 false
+func (r *PollResult) Value(oid string) (gosnmp.SnmpPDU, bool) {
+	oid = strings.TrimPrefix(oid, ".")
+	for _, v := range r.Variables {
+		if strings.TrimPrefix(v.Name, ".") == oid {
+			return v, true
+		}
+	}
+	return gosnmp.SnmpPDU{}, false
+}
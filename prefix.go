@@ -0,0 +1,103 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// newPrefixes 检查 Config.Prefixes，返回规范化后的前缀，前缀之间及与默认前缀之间不能重叠
+func newPrefixes(prefixes map[string]string, defaultPrefix string) (map[string]string, error) {
+	result := make(map[string]string, len(prefixes))
+	owners := map[string]string{defaultPrefix: "default"}
+	for id, text := range prefixes {
+		if id == "" {
+			return nil, fmt.Errorf("prefix ID must not be empty")
+		}
+		oid, err := normalizeOID(text)
+		if err != nil {
+			return nil, fmt.Errorf("prefix %s: %w", id, err)
+		}
+		for other, owner := range owners {
+			if hasOIDPrefix(oid, other) || hasOIDPrefix(other, oid) {
+				return nil, fmt.Errorf("prefix %s (%s) overlaps %s prefix %s", id, oid, owner, other)
+			}
+		}
+		owners[oid] = id
+		result[id] = oid
+	}
+	return result, nil
+}
+
+// Prefix 返回 Config.Prefixes 中 prefixID 对应的前缀，prefixID 为空时返回默认前缀（GetPrefix）
+func (a *Agent) Prefix(prefixID string) (string, error) {
+	if prefixID == "" {
+		return a.oidPrefix, nil
+	}
+	prefix, ok := a.prefixes[prefixID]
+	if !ok {
+		return "", fmt.Errorf("unknown prefix: %s", prefixID)
+	}
+	return prefix, nil
+}
+
+// Prefixes 返回 Config.Prefixes 中的所有前缀，键为前缀 ID，不含默认前缀
+func (a *Agent) Prefixes() map[string]string {
+	return maps.Clone(a.prefixes)
+}
+
+// OIDIn 返回 prefixID 对应前缀下的绝对 OID，可以传给任意 *Absolute 方法
+func (a *Agent) OIDIn(prefixID, relativeOID string) (string, error) {
+	prefix, err := a.Prefix(prefixID)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s.%s", prefix, strings.TrimPrefix(relativeOID, ".")), nil
+}
+
+// RegisterIn 在 prefixID 对应的前缀下注册相对 OID
+func (a *Agent) RegisterIn(prefixID, relativeOID string, oidType gosnmp.Asn1BER, handler ValueHandler) error {
+	oid, err := a.OIDIn(prefixID, relativeOID)
+	if err != nil {
+		return err
+	}
+	return a.RegisterAbsolute(oid, oidType, handler)
+}
+
+// RegisterWritableIn 在 prefixID 对应的前缀下注册可写的相对 OID
+func (a *Agent) RegisterWritableIn(prefixID, relativeOID string, oidType gosnmp.Asn1BER, handler ValueHandler, setter SetHandler) error {
+	oid, err := a.OIDIn(prefixID, relativeOID)
+	if err != nil {
+		return err
+	}
+	return a.RegisterWritableAbsolute(oid, oidType, handler, setter)
+}
+
+// RegisterStaticIn 在 prefixID 对应的前缀下注册静态值
+func (a *Agent) RegisterStaticIn(prefixID, relativeOID string, oidType gosnmp.Asn1BER, value interface{}) error {
+	oid, err := a.OIDIn(prefixID, relativeOID)
+	if err != nil {
+		return err
+	}
+	return a.RegisterStaticAbsolute(oid, oidType, value)
+}
+
+// UnregisterIn 注销 prefixID 对应前缀下的相对 OID
+func (a *Agent) UnregisterIn(prefixID, relativeOID string) error {
+	oid, err := a.OIDIn(prefixID, relativeOID)
+	if err != nil {
+		return err
+	}
+	return a.UnregisterAbsolute(oid)
+}
+
+// ListOIDsIn 列出 prefixID 对应前缀下已注册的 OID，格式与 ListOIDs 相同
+func (a *Agent) ListOIDsIn(prefixID string) ([]OIDInfo, error) {
+	prefix, err := a.Prefix(prefixID)
+	if err != nil {
+		return nil, err
+	}
+	return a.ListOIDs(prefix), nil
+}
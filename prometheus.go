@@ -0,0 +1,339 @@
+//go:build !lzsnmp_noprometheus
+
+package lzsnmp
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Prometheus 桥接表格的列
+const (
+	promColumnLabels      = 1 // 标签，Prometheus 文本格式，如 code="200",method="get"
+	promColumnValue       = 2 // 值，counter/summary/histogram 为 Counter64，gauge/untyped 为 Gauge32
+	promColumnValueString = 3 // 原始浮点值，summary/histogram 为 sum
+)
+
+// PrometheusBridge 将 prometheus.Gatherer 中的指标族映射为 SNMP 表格
+type PrometheusBridge struct {
+	agent    *Agent
+	root     string
+	gatherer prometheus.Gatherer
+
+	mu         sync.Mutex
+	values     map[string]interface{}
+	instances  []string
+	annotated  map[string]bool
+	gatheredAt time.Time
+	stop       chan struct{}
+}
+
+// RegisterPrometheus 将 gatherer 中的每个指标族映射为相对 OID prefix 下的一张表
+//
+// 表格 OID 为 prefix.{len}.{指标名...}，实例 OID 为 {表格}.1.{列号}.{索引}。
+// 索引由按标签名排序的标签值组成，每个值按字符串索引编码（长度 + ASCII），
+// 没有标签的指标索引为 0。列定义：
+//   - 1: 标签文本，如 code="200",method="get"
+//   - 2: 值，counter 为 Counter64，gauge/untyped 为 Gauge32（取整并截断到 0..2^32-1），
+//     summary/histogram 为样本数 Counter64
+//   - 3: 原始浮点值的字符串，summary/histogram 为样本总和
+//
+// 每次 GET 时最多每秒采集一次，也可以调用 RefreshEvery 定时采集。
+func (a *Agent) RegisterPrometheus(prefix string, gatherer prometheus.Gatherer) (*PrometheusBridge, error) {
+	prefix = strings.Trim(prefix, ".")
+	if prefix == "" {
+		return nil, fmt.Errorf("prometheus prefix is required")
+	}
+	if gatherer == nil {
+		return nil, fmt.Errorf("prometheus gatherer is required")
+	}
+
+	b := &PrometheusBridge{
+		agent:     a,
+		root:      fmt.Sprintf("%s.%s", a.oidPrefix, prefix),
+		gatherer:  gatherer,
+		annotated: make(map[string]bool),
+	}
+
+	if err := a.claimSubtree(b.root, "prometheus bridge"); err != nil {
+		return nil, err
+	}
+	if err := b.Refresh(); err != nil {
+		return nil, err
+	}
+
+	a.logger.Info("Registered prometheus bridge", "oid", b.root, "families", len(b.annotated))
+	return b, nil
+}
+
+// Refresh 立即采集一次并在指标集合变化时重建实例 OID
+func (b *PrometheusBridge) Refresh() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.refreshLocked()
+}
+
+// RefreshEvery 启动后台定时采集，调用 Close 停止
+func (b *PrometheusBridge) RefreshEvery(interval time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.stop != nil {
+		close(b.stop)
+	}
+	stop := make(chan struct{})
+	b.stop = stop
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := b.Refresh(); err != nil {
+					b.agent.logger.Error("Prometheus refresh failed", "oid", b.root, "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Close 停止后台定时采集
+func (b *PrometheusBridge) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.stop != nil {
+		close(b.stop)
+		b.stop = nil
+	}
+}
+
+func (b *PrometheusBridge) refreshLocked() error {
+	families, err := b.gatherer.Gather()
+	if err != nil {
+		if len(families) == 0 {
+			return fmt.Errorf("gather prometheus metrics: %w", err)
+		}
+		// 部分失败时仍然导出成功采集的指标
+		b.agent.logger.Warn("Prometheus gather returned errors", "error", err)
+	}
+
+	values := make(map[string]interface{})
+	types := make(map[string]gosnmp.Asn1BER)
+	for _, mf := range families {
+		tableOID := b.root + "." + encodeOctetsIndex([]byte(mf.GetName()), false)
+		if !b.annotated[tableOID] {
+			b.agent.annotateGroup(tableOID, OIDMeta{Name: mf.GetName(), Description: mf.GetHelp()})
+			b.annotated[tableOID] = true
+		}
+
+		for _, m := range mf.GetMetric() {
+			index, labels := promIndex(m.GetLabel())
+			value, valueType, raw := promValue(mf.GetType(), m)
+
+			entry := tableOID + ".1"
+			labelsOID := fmt.Sprintf("%s.%d.%s", entry, promColumnLabels, index)
+			valueOID := fmt.Sprintf("%s.%d.%s", entry, promColumnValue, index)
+			rawOID := fmt.Sprintf("%s.%d.%s", entry, promColumnValueString, index)
+
+			values[labelsOID], types[labelsOID] = labels, gosnmp.OctetString
+			values[valueOID], types[valueOID] = value, valueType
+			values[rawOID], types[rawOID] = strconv.FormatFloat(raw, 'g', -1, 64), gosnmp.OctetString
+		}
+	}
+
+	b.values = values
+	b.gatheredAt = time.Now()
+
+	instances := make([]string, 0, len(values))
+	for oid := range values {
+		instances = append(instances, oid)
+	}
+	sort.Strings(instances)
+	if slices.Equal(b.instances, instances) {
+		return nil
+	}
+
+	add := make(map[string]dynamicOID, len(instances))
+	for _, oid := range instances {
+		add[oid] = dynamicOID{Type: types[oid], Handler: b.getter(oid)}
+	}
+	b.agent.replaceDynamic(b.instances, add)
+	b.instances = instances
+	return nil
+}
+
+func (b *PrometheusBridge) getter(oid string) ValueHandler {
+	return func() (interface{}, error) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if time.Since(b.gatheredAt) > tableRefreshInterval {
+			if err := b.refreshLocked(); err != nil {
+				return nil, err
+			}
+		}
+		value, ok := b.values[oid]
+		if !ok {
+			return nil, fmt.Errorf("prometheus metric %s no longer exists", oid)
+		}
+		return value, nil
+	}
+}
+
+// promIndex 将标签编码为表格索引，并返回 Prometheus 格式的标签文本
+func promIndex(pairs []*dto.LabelPair) (string, string) {
+	if len(pairs) == 0 {
+		return "0", ""
+	}
+
+	sorted := make([]*dto.LabelPair, len(pairs))
+	copy(sorted, pairs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].GetName() < sorted[j].GetName() })
+
+	index := make([]string, 0, len(sorted))
+	text := make([]string, 0, len(sorted))
+	for _, p := range sorted {
+		index = append(index, encodeOctetsIndex([]byte(p.GetValue()), false))
+		text = append(text, fmt.Sprintf("%s=%q", p.GetName(), p.GetValue()))
+	}
+	return strings.Join(index, "."), strings.Join(text, ",")
+}
+
+// promValue 返回值列的值和类型，以及原始浮点值
+func promValue(t dto.MetricType, m *dto.Metric) (interface{}, gosnmp.Asn1BER, float64) {
+	switch t {
+	case dto.MetricType_COUNTER:
+		v := m.GetCounter().GetValue()
+		return clampUint64(v), gosnmp.Counter64, v
+	case dto.MetricType_GAUGE:
+		v := m.GetGauge().GetValue()
+		return uint(clampUint32(v)), gosnmp.Gauge32, v
+	case dto.MetricType_SUMMARY:
+		s := m.GetSummary()
+		return s.GetSampleCount(), gosnmp.Counter64, s.GetSampleSum()
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		h := m.GetHistogram()
+		return h.GetSampleCount(), gosnmp.Counter64, h.GetSampleSum()
+	}
+	v := m.GetUntyped().GetValue()
+	return uint(clampUint32(v)), gosnmp.Gauge32, v
+}
+
+// statsCollector 将 Agent 内部计数器导出为 Prometheus 指标
+type statsCollector struct {
+	agent *Agent
+
+	packets       *prometheus.Desc
+	familyPackets *prometheus.Desc
+	requests      *prometheus.Desc
+	requestVars   *prometheus.Desc
+	badVersions   *prometheus.Desc
+	authFailures  *prometheus.Desc
+	decodeErrors  *prometheus.Desc
+	silentDrops   *prometheus.Desc
+	duplicates    *prometheus.Desc
+	handlerErrors *prometheus.Desc
+	handlerPanics *prometheus.Desc
+	coalesced     *prometheus.Desc
+	handlerTime   *prometheus.Desc
+	overload      *prometheus.Desc
+	queued        *prometheus.Desc
+	inFlight      *prometheus.Desc
+}
+
+// StatsCollector 返回导出 Agent 内部计数器的 prometheus.Collector
+//
+// 处理耗时以 summary 导出，分位数基于最近 1024 次调用。
+func (a *Agent) StatsCollector() prometheus.Collector {
+	return &statsCollector{
+		agent:         a,
+		packets:       prometheus.NewDesc("lzsnmp_packets_total", "SNMP packets received and sent.", []string{"direction"}, nil),
+		familyPackets: prometheus.NewDesc("lzsnmp_packets_by_family_total", "SNMP packets received and sent by IP address family.", []string{"direction", "family"}, nil),
+		requests:      prometheus.NewDesc("lzsnmp_requests_total", "SNMP requests by PDU type.", []string{"type"}, nil),
+		requestVars:   prometheus.NewDesc("lzsnmp_request_varbinds_total", "Variable bindings in SNMP requests by PDU type.", []string{"type"}, nil),
+		badVersions:   prometheus.NewDesc("lzsnmp_bad_versions_total", "Packets with an unsupported SNMP version.", nil, nil),
+		authFailures:  prometheus.NewDesc("lzsnmp_auth_failures_total", "Packets with an unknown community.", nil, nil),
+		decodeErrors:  prometheus.NewDesc("lzsnmp_decode_errors_total", "Packets that could not be decoded.", nil, nil),
+		silentDrops:   prometheus.NewDesc("lzsnmp_silent_drops_total", "Requests dropped without a response.", nil, nil),
+		duplicates:    prometheus.NewDesc("lzsnmp_duplicate_requests_total", "Retransmitted requests by cause (slow: the original response took over 1s).", []string{"cause"}, nil),
+		handlerErrors: prometheus.NewDesc("lzsnmp_handler_errors_total", "OID handler calls that returned an error.", nil, nil),
+		handlerPanics: prometheus.NewDesc("lzsnmp_handler_panics_total", "OID handler calls that panicked.", nil, nil),
+		coalesced:     prometheus.NewDesc("lzsnmp_handler_coalesced_total", "GET calls answered from a coalesced handler call.", nil, nil),
+		handlerTime:   prometheus.NewDesc("lzsnmp_handler_duration_seconds", "OID handler latency.", nil, nil),
+		overload:      prometheus.NewDesc("lzsnmp_overload_drops_total", "Requests dropped because all workers were busy and the queue was full.", nil, nil),
+		queued:        prometheus.NewDesc("lzsnmp_request_queue_depth", "Requests waiting for a worker.", nil, nil),
+		inFlight:      prometheus.NewDesc("lzsnmp_requests_in_flight", "Requests being processed by workers.", nil, nil),
+	}
+}
+
+// Describe 实现 prometheus.Collector
+func (c *statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.packets
+	ch <- c.familyPackets
+	ch <- c.requests
+	ch <- c.requestVars
+	ch <- c.badVersions
+	ch <- c.authFailures
+	ch <- c.decodeErrors
+	ch <- c.silentDrops
+	ch <- c.duplicates
+	ch <- c.handlerErrors
+	ch <- c.handlerPanics
+	ch <- c.coalesced
+	ch <- c.handlerTime
+	ch <- c.overload
+	ch <- c.queued
+	ch <- c.inFlight
+}
+
+// Collect 实现 prometheus.Collector
+func (c *statsCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.agent.stats.snapshot()
+	counter := func(desc *prometheus.Desc, v uint64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(v), labels...)
+	}
+
+	counter(c.packets, s.InPkts, "in")
+	counter(c.packets, s.OutPkts, "out")
+	counter(c.familyPackets, s.InPktsIPv4, "in", "ipv4")
+	counter(c.familyPackets, s.InPktsIPv6, "in", "ipv6")
+	counter(c.familyPackets, s.OutPktsIPv4, "out", "ipv4")
+	counter(c.familyPackets, s.OutPktsIPv6, "out", "ipv6")
+	counter(c.requests, s.InGetRequests, "get")
+	counter(c.requests, s.InGetNexts, "getnext")
+	counter(c.requests, s.InGetBulks, "getbulk")
+	counter(c.requests, s.InSetRequests, "set")
+	counter(c.requestVars, s.InTotalReqVars, "get")
+	counter(c.requestVars, s.InTotalSetVars, "set")
+	counter(c.badVersions, s.InBadVersions)
+	counter(c.authFailures, s.InBadCommunityNames)
+	counter(c.decodeErrors, s.InASNParseErrs)
+	counter(c.silentDrops, s.SilentDrops)
+	counter(c.duplicates, s.InDuplicatesSlow, "slow")
+	counter(c.duplicates, s.InDuplicates-s.InDuplicatesSlow, "network")
+	counter(c.handlerErrors, s.HandlerErrors)
+	counter(c.handlerPanics, s.HandlerPanics)
+	counter(c.coalesced, s.HandlerCoalesced)
+	counter(c.overload, s.InOverloadDrops)
+	ch <- prometheus.MustNewConstMetric(c.queued, prometheus.GaugeValue, float64(s.RequestsQueued))
+	ch <- prometheus.MustNewConstMetric(c.inFlight, prometheus.GaugeValue, float64(s.RequestsInFlight))
+
+	ch <- prometheus.MustNewConstSummary(c.handlerTime, s.HandlerCalls, s.HandlerTime.Seconds(), map[float64]float64{
+		0.5:  s.HandlerLatencyP50.Seconds(),
+		0.9:  s.HandlerLatencyP90.Seconds(),
+		0.99: s.HandlerLatencyP99.Seconds(),
+	})
+}
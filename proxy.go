@@ -0,0 +1,267 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// maxProxyInstances 一次遍历最多导出的远端实例数
+const maxProxyInstances = 10000
+
+// ProxyTarget 被代理的远端 SNMP Agent
+type ProxyTarget struct {
+	Address   string             // 远端地址，如 "10.0.0.2:161"，省略端口时为 161
+	Version   gosnmp.SnmpVersion // 与 gosnmp 相同，零值为 Version1；设置 User 时为 Version3
+	Community string             // v1/v2c 的 community，默认 "public"
+	User      *User              // Version3 使用的 USM 用户
+	RemoteOID string             // 远端子树的绝对 OID，为空时与本地子树相同
+	Timeout   time.Duration      // 单次请求超时，默认 2 秒
+	Retries   int
+}
+
+// Proxy 由 RegisterProxy 创建的代理子树
+type Proxy struct {
+	agent  *Agent
+	local  string
+	remote string
+
+	mu        sync.Mutex
+	client    *gosnmp.GoSNMP
+	values    map[string]interface{}
+	types     map[string]gosnmp.Asn1BER
+	instances []string
+	walkedAt  time.Time
+	closed    bool
+}
+
+// RegisterProxy 将相对 OID 下的子树代理到另一个 SNMP Agent，可以作为多台设备的汇聚前端
+//
+// 子树的实例由对远端的遍历（v2c/v3 使用 GETBULK）得到，GET/GETNEXT 使用最多 1 秒前的遍历结果，
+// 同一次 WALK 看到一致的快照；SET 直接转发给远端，远端返回的错误原样传回。
+// target.RemoteOID 不为空时，远端子树映射到本地子树下，不同设备的同一张表可以挂在不同的本地 OID 下。
+func (a *Agent) RegisterProxy(relativeOID string, target ProxyTarget) (*Proxy, error) {
+	absoluteOID := fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID)
+	return a.RegisterProxyAbsolute(absoluteOID, target)
+}
+
+// RegisterProxyAbsolute 将绝对路径 OID 下的子树代理到另一个 SNMP Agent
+func (a *Agent) RegisterProxyAbsolute(oid string, target ProxyTarget) (*Proxy, error) {
+	local, err := normalizeOID(oid)
+	if err != nil {
+		return nil, err
+	}
+	remote := local
+	if target.RemoteOID != "" {
+		if remote, err = normalizeOID(target.RemoteOID); err != nil {
+			return nil, fmt.Errorf("invalid remote OID for proxy %s: %w", local, err)
+		}
+	}
+	client, err := newProxyClient(target)
+	if err != nil {
+		return nil, fmt.Errorf("proxy %s: %w", local, err)
+	}
+
+	p := &Proxy{agent: a, local: local, remote: remote, client: client}
+	if err := a.claimSubtree(local, "proxy "+target.Address); err != nil {
+		client.Conn.Close()
+		return nil, err
+	}
+	if err := p.Refresh(); err != nil {
+		client.Conn.Close()
+		return nil, err
+	}
+
+	a.logger.Info("Registered proxy", "oid", local, "target", target.Address, "remoteOID", remote, "instances", len(p.instances))
+	return p, nil
+}
+
+// newProxyClient 按 target 创建并连接 gosnmp 客户端
+func newProxyClient(target ProxyTarget) (*gosnmp.GoSNMP, error) {
+	host, portText, err := net.SplitHostPort(target.Address)
+	if err != nil {
+		host, portText = target.Address, "161"
+	}
+	port, err := strconv.ParseUint(portText, 10, 16)
+	if host == "" || err != nil {
+		return nil, fmt.Errorf("invalid target address: %s", target.Address)
+	}
+
+	client := &gosnmp.GoSNMP{
+		Target:         host,
+		Port:           uint16(port),
+		Transport:      "udp",
+		Version:        target.Version,
+		Community:      target.Community,
+		Timeout:        target.Timeout,
+		Retries:        target.Retries,
+		MaxOids:        gosnmp.MaxOids,
+		MaxRepetitions: 25,
+	}
+	if client.Community == "" {
+		client.Community = "public"
+	}
+	if client.Timeout <= 0 {
+		client.Timeout = 2 * time.Second
+	}
+
+	if client.Version == gosnmp.Version3 || target.User != nil {
+		if target.User == nil {
+			return nil, fmt.Errorf("SNMPv3 target requires a user")
+		}
+		user := *target.User
+		if err := user.validate(); err != nil {
+			return nil, err
+		}
+		usm := user.usm()
+		client.Version = gosnmp.Version3
+		client.SecurityModel = gosnmp.UserSecurityModel
+		client.SecurityParameters = &usm
+		switch {
+		case user.PrivProtocol != gosnmp.NoPriv:
+			client.MsgFlags = gosnmp.AuthPriv
+		case user.AuthProtocol != gosnmp.NoAuth:
+			client.MsgFlags = gosnmp.AuthNoPriv
+		default:
+			client.MsgFlags = gosnmp.NoAuthNoPriv
+		}
+	}
+
+	if err := client.Connect(); err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", target.Address, err)
+	}
+	return client, nil
+}
+
+// Refresh 立即遍历一次远端子树并在实例集合变化时重建实例 OID
+func (p *Proxy) Refresh() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.refreshLocked()
+}
+
+// Close 注销代理子树中的实例并关闭与远端的连接
+func (p *Proxy) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+
+	p.agent.replaceDynamic(p.instances, nil)
+	p.instances = nil
+	return p.client.Conn.Close()
+}
+
+func (p *Proxy) refreshLocked() error {
+	if p.closed {
+		return fmt.Errorf("proxy %s is closed", p.local)
+	}
+
+	values := make(map[string]interface{})
+	types := make(map[string]gosnmp.Asn1BER)
+	instances := make([]string, 0, len(p.instances))
+	walk := p.client.BulkWalk
+	if p.client.Version == gosnmp.Version1 {
+		walk = p.client.Walk
+	}
+	err := walk(p.remote, func(pdu gosnmp.SnmpPDU) error {
+		switch pdu.Type {
+		case gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView, gosnmp.Null:
+			return nil
+		}
+		if len(instances) >= maxProxyInstances {
+			return fmt.Errorf("remote subtree exceeds %d instances", maxProxyInstances)
+		}
+		oid := p.toLocal(strings.TrimPrefix(pdu.Name, "."))
+		values[oid], types[oid] = pdu.Value, pdu.Type
+		instances = append(instances, oid)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("proxy walk %s on %s: %w", p.remote, p.client.Target, err)
+	}
+
+	p.walkedAt = time.Now()
+	slices.SortFunc(instances, compareOID)
+	changed := !slices.Equal(p.instances, instances)
+	for _, oid := range instances {
+		if !changed && p.types[oid] != types[oid] {
+			changed = true
+		}
+	}
+	p.values, p.types = values, types
+	if !changed {
+		return nil
+	}
+
+	add := make(map[string]dynamicOID, len(instances))
+	for _, oid := range instances {
+		add[oid] = dynamicOID{Type: types[oid], Handler: p.getter(oid), Setter: p.setter(oid)}
+	}
+	p.agent.replaceDynamic(p.instances, add)
+	p.instances = instances
+	return nil
+}
+
+// toLocal 将远端 OID 转换为本地 OID
+func (p *Proxy) toLocal(oid string) string {
+	return p.local + strings.TrimPrefix(oid, p.remote)
+}
+
+// toRemote 将本地 OID 转换为远端 OID
+func (p *Proxy) toRemote(oid string) string {
+	return p.remote + strings.TrimPrefix(oid, p.local)
+}
+
+func (p *Proxy) getter(oid string) ValueHandler {
+	return func() (interface{}, error) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		if time.Since(p.walkedAt) > tableRefreshInterval {
+			if err := p.refreshLocked(); err != nil {
+				return nil, err
+			}
+		}
+		value, ok := p.values[oid]
+		if !ok {
+			return nil, fmt.Errorf("proxied instance %s no longer exists", oid)
+		}
+		return value, nil
+	}
+}
+
+func (p *Proxy) setter(oid string) SetHandler {
+	return func(value interface{}) error {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		oidType, ok := p.types[oid]
+		if !ok {
+			return fmt.Errorf("proxied instance %s no longer exists", oid)
+		}
+		value, err := normalizeValue(oidType, value)
+		if err != nil {
+			return err
+		}
+		remote := p.toRemote(oid)
+		result, err := p.client.Set([]gosnmp.SnmpPDU{{Name: "." + remote, Type: oidType, Value: value}})
+		if err != nil {
+			return fmt.Errorf("proxy set %s on %s: %w", remote, p.client.Target, err)
+		}
+		if result.Error != gosnmp.NoError {
+			return fmt.Errorf("proxy set %s on %s: %s", remote, p.client.Target, result.Error)
+		}
+		// 下一次 GET 重新遍历，读到远端的新值
+		p.walkedAt = time.Time{}
+		return nil
+	}
+}
@@ -0,0 +1,108 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// minRateInterval 计算速率的两个样本之间的最短间隔，间隔内的读取返回上一次的速率
+const minRateInterval = time.Second
+
+// rateSampler 根据计数器的连续样本计算每秒速率
+type rateSampler struct {
+	counterType gosnmp.Asn1BER
+
+	mu       sync.Mutex
+	base     uint64
+	baseTime time.Time
+	rate     float64
+}
+
+// observe 记录一个样本，返回当前的每秒速率；第一个样本之前速率为 0
+func (r *rateSampler) observe(value uint64, now time.Time) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.baseTime.IsZero() {
+		r.base, r.baseTime = value, now
+		return 0
+	}
+	elapsed := now.Sub(r.baseTime)
+	if elapsed < minRateInterval {
+		return r.rate
+	}
+
+	var delta uint64
+	switch {
+	case value >= r.base:
+		delta = value - r.base
+	case r.counterType == gosnmp.Counter32 && r.base <= math.MaxUint32:
+		// Counter32 回绕一次
+		delta = value + (1 << 32) - r.base
+	default:
+		// Counter64 不会在实际时间内回绕，减小说明计数器被重置
+		delta = 0
+	}
+	r.rate = float64(delta) / elapsed.Seconds()
+	r.base, r.baseTime = value, now
+	return r.rate
+}
+
+// RegisterCounterRate 注册相对 OID 的计数器及其速率 OID，见 RegisterCounterRateAbsolute
+func (a *Agent) RegisterCounterRate(relativeOID string, counterType gosnmp.Asn1BER, handler ValueHandler, relativeRateOID string) error {
+	return a.RegisterCounterRateAbsolute(
+		fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID), counterType, handler,
+		fmt.Sprintf("%s.%s", a.oidPrefix, relativeRateOID))
+}
+
+// RegisterCounterRateAbsolute 注册计数器 OID，并在 rateOID 注册由它派生的每秒速率（Gauge32，四舍五入）
+//
+// 供不能计算差值的 NMS 直接读取速率。读取任一 OID 都会调用 handler 并记录一个样本，速率由相隔至少 1 秒的
+// 两个样本计算，1 秒内的读取返回上一次的速率；第一次读取速率时为 0。Counter32 按回绕一次处理，
+// Counter64 减小时视为计数器重置，速率为 0。counterType 必须为 Counter32 或 Counter64。
+func (a *Agent) RegisterCounterRateAbsolute(oid string, counterType gosnmp.Asn1BER, handler ValueHandler, rateOID string) error {
+	if counterType != gosnmp.Counter32 && counterType != gosnmp.Counter64 {
+		return fmt.Errorf("counter rate requires Counter32 or Counter64, got %v for OID: %s", counterType, oid)
+	}
+	if handler == nil {
+		return fmt.Errorf("handler is required for OID: %s", oid)
+	}
+
+	sampler := &rateSampler{counterType: counterType}
+	// sample 调用 handler 并记录样本，返回计数器的值和当前速率
+	sample := func() (uint64, float64, error) {
+		value, err := handler()
+		if err != nil {
+			return 0, 0, err
+		}
+		n, err := toUint64(value)
+		if err != nil {
+			return 0, 0, err
+		}
+		return n, sampler.observe(n, time.Now()), nil
+	}
+
+	if err := a.registerDynamic(oid, counterType, func() (interface{}, error) {
+		n, _, err := sample()
+		if err != nil {
+			return nil, err
+		}
+		return normalizeValue(counterType, n)
+	}, nil); err != nil {
+		return err
+	}
+	if err := a.registerDynamic(rateOID, gosnmp.Gauge32, func() (interface{}, error) {
+		_, rate, err := sample()
+		if err != nil {
+			return nil, err
+		}
+		return uint(clampUint32(math.Round(rate))), nil
+	}, nil); err != nil {
+		a.UnregisterAbsolute(oid)
+		return err
+	}
+	return nil
+}
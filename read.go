@@ -0,0 +1,259 @@
+package lzsnmp
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/slayercat/GoSNMPServer"
+)
+
+// ErrNoSuchInstance 处理函数返回此错误（或包装它的错误）时，该实例视为表格中的空洞：
+// GET 返回 noSuchInstance（v1 为 noSuchName），GETNEXT/GETBULK 跳到下一个有值的实例
+var ErrNoSuchInstance = errors.New("no such instance")
+
+// respond 处理请求报文，返回编码后的响应
+//
+// GET/GETNEXT/GETBULK 由 serveRead 处理，按 RFC 3416 返回 noSuchObject、noSuchInstance
+// 和 endOfMibView 异常值（v1 为 noSuchName 错误）；SET 由 serveSet 按两阶段处理。
+// SNMPv3 请求先由 decodeV3 校验和解密，contextName 为子 Agent 的 community 时使用子 Agent 的视图；发现报文和未知 community 的请求交给 GoSNMPServer。
+func (a *Agent) respond(w *worker, packet []byte, pkt *gosnmp.SnmpPacket) ([]byte, error) {
+	if pkt != nil && pkt.Version == gosnmp.Version3 {
+		req, err := a.decodeV3(w, packet, pkt)
+		if err != nil {
+			return nil, err
+		}
+		if req == nil {
+			return w.server.ResponseForBuffer(packet)
+		}
+		pkt = req
+		w.current.pkt = req
+		// 按解密后的 contextName 和安全级别重新选择可见的 OID 列表
+		w.syncOIDs(req)
+	} else if pkt == nil || !a.knownCommunity(pkt.Community) {
+		return w.server.ResponseForBuffer(packet)
+	}
+	switch {
+	case isReadRequest(pkt):
+		return a.serveRead(w, pkt)
+	case pkt.PDUType == gosnmp.SetRequest:
+		return a.serveSet(w, pkt)
+	}
+	return w.server.ResponseForBuffer(packet)
+}
+
+// serveRead 按 worker 当前的 OID 列表处理读请求
+func (a *Agent) serveRead(w *worker, pkt *gosnmp.SnmpPacket) ([]byte, error) {
+	r := readRequest{agent: a, items: w.server.SubAgents[0].OIDs, markGenErr: w.server.SubAgents[0].UserErrorMarkPacket, v1: pkt.Version == gosnmp.Version1}
+	resp := *pkt
+	resp.PDUType = gosnmp.GetResponse
+	resp.Error, resp.ErrorIndex = gosnmp.NoError, 0
+
+	switch pkt.PDUType {
+	case gosnmp.GetRequest:
+		resp.Variables = r.get(pkt.Variables)
+	case gosnmp.GetNextRequest:
+		resp.Variables = r.getNext(pkt.Variables)
+	case gosnmp.GetBulkRequest:
+		resp.Variables = r.getBulk(pkt.Variables, int(pkt.NonRepeaters), int(pkt.MaxRepetitions))
+	}
+	resp.NonRepeaters, resp.MaxRepetitions = 0, 0
+	if r.err != gosnmp.NoError {
+		resp.Error, resp.ErrorIndex = r.err, r.errIndex
+		if r.v1 {
+			// v1 的错误响应带回请求的变量绑定
+			resp.Variables = pkt.Variables
+		}
+	}
+
+	out, err := resp.MarshalMsg()
+	for err == nil && len(out) > maxPacketSize {
+		if pkt.PDUType == gosnmp.GetBulkRequest && len(resp.Variables) > 1 {
+			// GETBULK 从末尾截断到能放进一个报文
+			resp.Variables = resp.Variables[:len(resp.Variables)*3/4]
+		} else {
+			resp.Error, resp.ErrorIndex, resp.Variables = gosnmp.TooBig, 0, nil
+		}
+		out, err = resp.MarshalMsg()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+	return out, nil
+}
+
+// readRequest 一个读请求的处理状态
+type readRequest struct {
+	agent      *Agent
+	items      []*GoSNMPServer.PDUValueControlItem // 按 OID 排序
+	markGenErr bool                                // 处理函数错误报告为 genErr
+	v1         bool
+
+	err      gosnmp.SNMPError // 第一个错误
+	errIndex uint8            // 出错的变量绑定位置，从 1 开始
+}
+
+// fail 记录第一个错误，index 为变量绑定在请求中的位置（从 0 开始）
+func (r *readRequest) fail(err gosnmp.SNMPError, index int) {
+	if r.err == gosnmp.NoError {
+		r.err, r.errIndex = err, uint8(min(index+1, 255))
+	}
+}
+
+// search 返回第一个不小于 oid 的 OID 的位置
+func (r *readRequest) search(oid string) int {
+	return sort.Search(len(r.items), func(i int) bool {
+		return compareOID(r.items[i].OID, oid) >= 0
+	})
+}
+
+func (r *readRequest) get(vars []gosnmp.SnmpPDU) []gosnmp.SnmpPDU {
+	result := make([]gosnmp.SnmpPDU, len(vars))
+	for i, v := range vars {
+		oid := trimOID(v.Name)
+		if j := r.search(oid); j < len(r.items) && r.items[j].OID == oid {
+			if pdu, ok := r.value(r.items[j], i); ok {
+				result[i] = pdu
+				continue
+			}
+			if r.v1 {
+				r.fail(gosnmp.NoSuchName, i)
+			}
+			result[i] = gosnmp.SnmpPDU{Name: v.Name, Type: gosnmp.NoSuchInstance}
+			continue
+		}
+		if r.v1 {
+			r.fail(gosnmp.NoSuchName, i)
+		}
+		result[i] = gosnmp.SnmpPDU{Name: v.Name, Type: r.missing(oid)}
+	}
+	return result
+}
+
+func (r *readRequest) getNext(vars []gosnmp.SnmpPDU) []gosnmp.SnmpPDU {
+	result := make([]gosnmp.SnmpPDU, len(vars))
+	for i, v := range vars {
+		result[i] = r.next(v.Name, i)
+	}
+	return result
+}
+
+// getBulk 前 nonRepeaters 个变量按 GETNEXT 处理，其余变量重复 maxRepetitions 次，
+// 每次从上一次的结果继续；所有变量都到达末尾后提前结束
+func (r *readRequest) getBulk(vars []gosnmp.SnmpPDU, nonRepeaters, maxRepetitions int) []gosnmp.SnmpPDU {
+	nonRepeaters = min(nonRepeaters, len(vars))
+	repeaters := len(vars) - nonRepeaters
+	if repeaters == 0 {
+		maxRepetitions = 0
+	}
+	result := make([]gosnmp.SnmpPDU, 0, nonRepeaters+repeaters*min(maxRepetitions, len(r.items)+1))
+	for i := range nonRepeaters {
+		result = append(result, r.next(vars[i].Name, i))
+	}
+
+	last := make([]string, repeaters)
+	for k := range last {
+		last[k] = vars[nonRepeaters+k].Name
+	}
+	for range maxRepetitions {
+		ended := true
+		for k, name := range last {
+			pdu := r.next(name, nonRepeaters+k)
+			if pdu.Type != gosnmp.EndOfMibView {
+				ended = false
+				last[k] = pdu.Name
+			}
+			result = append(result, pdu)
+		}
+		if ended {
+			break
+		}
+	}
+	return result
+}
+
+// next 返回 name 之后的第一个有值的 OID 的值，之后没有 OID 时返回 endOfMibView（v1 为 noSuchName 错误）
+func (r *readRequest) next(name string, index int) gosnmp.SnmpPDU {
+	oid := trimOID(name)
+	j := r.search(oid)
+	if j < len(r.items) && r.items[j].OID == oid {
+		j++
+	}
+	for ; j < len(r.items); j++ {
+		if item := r.items[j]; !item.NonWalkable && item.OnGet != nil {
+			if pdu, ok := r.value(item, index); ok {
+				return pdu
+			}
+		}
+	}
+	if r.v1 {
+		r.fail(gosnmp.NoSuchName, index)
+	}
+	return gosnmp.SnmpPDU{Name: name, Type: gosnmp.EndOfMibView}
+}
+
+// value 调用 OID 的处理函数，实例是空洞（ErrNoSuchInstance）时 ok 为 false
+//
+// 其他错误与 GoSNMPServer 一致：值为错误文本，UserErrorMarkPacket 时报告 genErr；处理函数 panic 时总是报告 genErr。
+func (r *readRequest) value(item *GoSNMPServer.PDUValueControlItem, index int) (pdu gosnmp.SnmpPDU, ok bool) {
+	defer func() {
+		if p := recover(); p != nil {
+			pdu, ok = r.handlerError(item, fmt.Errorf("%w: %v", errHandlerPanic, p), index), true
+		}
+	}()
+	value, err := item.OnGet()
+	if errors.Is(err, ErrNoSuchInstance) {
+		return gosnmp.SnmpPDU{}, false
+	}
+	if err != nil {
+		return r.handlerError(item, err, index), true
+	}
+	return gosnmp.SnmpPDU{Name: item.OID, Type: item.Type, Value: value}, true
+}
+
+// handlerError 返回处理函数出错时的变量绑定
+//
+// markGenErr 在请求开始时读取，guardRequest 在同一请求中因 panic 开启的 genErr 标记对本请求不可见，
+// 因此 panic 的错误直接按 genErr 报告。
+func (r *readRequest) handlerError(item *GoSNMPServer.PDUValueControlItem, err error, index int) gosnmp.SnmpPDU {
+	if r.markGenErr || errors.Is(err, errHandlerPanic) {
+		r.fail(gosnmp.GenErr, index)
+	}
+	return gosnmp.SnmpPDU{Name: item.OID, Type: gosnmp.OctetString, Value: fmt.Sprintf("ERROR: %+v", err)}
+}
+
+// missing 判断 GET 的 OID 缺失的是对象还是实例
+//
+// OID 的某个祖先节点下直接注册了实例（如只有 sysDescr.0 时 GET sysDescr.1），或 OID 位于表格等管理的
+// 子树中时为 noSuchInstance，否则为 noSuchObject。
+func (r *readRequest) missing(oid string) gosnmp.Asn1BER {
+	for p := parentOID(oid); p != ""; p = parentOID(p) {
+		// 逐个检查 p 的子节点，子节点下有更深的 OID 时跳过整棵子树
+		for j := r.search(p); j < len(r.items) && hasOIDPrefix(r.items[j].OID, p) && r.items[j].OID != p; {
+			arc, _, deeper := strings.Cut(r.items[j].OID[len(p)+1:], ".")
+			if !deeper {
+				return gosnmp.NoSuchInstance
+			}
+			child := p + "." + arc
+			j = sort.Search(len(r.items), func(i int) bool {
+				return compareOID(r.items[i].OID, child) > 0 && !hasOIDPrefix(r.items[i].OID, child)
+			})
+		}
+	}
+	for root := range r.agent.store.Load().subtrees {
+		if hasOIDPrefix(oid, root) {
+			return gosnmp.NoSuchInstance
+		}
+	}
+	return gosnmp.NoSuchObject
+}
+
+// trimOID 去掉请求中 OID 开头的点
+func trimOID(name string) string {
+	if len(name) > 0 && name[0] == '.' {
+		return name[1:]
+	}
+	return name
+}
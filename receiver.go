@@ -0,0 +1,506 @@
+package lzsnmp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/gosnmp/gosnmp"
+	"github.com/slayercat/GoSNMPServer"
+)
+
+// usmStatsUnknownEngineIDsOID SNMP-USER-BASED-SM-MIB usmStatsUnknownEngineIDs.0，用于 SNMPv3 引擎发现
+const usmStatsUnknownEngineIDsOID = "1.3.6.1.6.3.15.1.1.4.0"
+
+// ReceiverConfig trap 接收器配置
+type ReceiverConfig struct {
+	ListenAddr  string   // 监听地址，默认 "0.0.0.0:162"；"systemd:名称" 使用 socket 激活传入的同名 socket
+	Communities []string // 接受的 v1/v2c community，默认 ["public"]
+	Users       []User   // 接受的 SNMPv3 USM 用户，报文的安全级别必须与用户配置一致
+
+	// EngineID 接收器的 engineID（管理员指定部分，1..27 字节），v3 inform 的发送方通过引擎发现获取；
+	// 为空时使用与 Agent 相同的默认 engineID
+	EngineID string
+
+	LogLevel log.Level
+	Logger   Logger
+}
+
+// ReceivedTrap 接收到的 trap 或 inform，v1 trap 按 RFC 3584 转换为 SNMPv2 形式
+type ReceivedTrap struct {
+	Source       net.Addr           // 发送方地址
+	Version      gosnmp.SnmpVersion // SNMP 版本
+	Community    string             // v1/v2c community，v3 时为空
+	SecurityName string             // v3 用户名，v1/v2c 时与 Community 相同
+	Inform       bool               // 是否为 inform（已经回复确认）
+	TrapOID      string             // snmpTrapOID.0 的值，不带前导点
+	Uptime       uint32             // 发送方的 sysUpTime.0，百分之一秒
+	AgentAddress string             // v1 trap 的 agent-addr，其他版本为空
+	Variables    []gosnmp.SnmpPDU   // 不含 sysUpTime.0 和 snmpTrapOID.0
+	Time         time.Time          // 接收时间
+}
+
+// TrapHandler 处理接收到的 trap，在接收协程中依次调用，应尽快返回
+type TrapHandler func(t *ReceivedTrap)
+
+// ReceiverStats 接收器计数器
+type ReceiverStats struct {
+	Traps       uint64 // 接受的 trap 数
+	Informs     uint64 // 接受并确认的 inform 数
+	Rejected    uint64 // community、用户或安全级别不匹配而丢弃的报文数
+	ParseErrors uint64 // 无法解码或认证失败的报文数
+}
+
+// receiverRoute 按通知 OID 前缀分发的处理函数
+type receiverRoute struct {
+	prefix  string
+	handler TrapHandler
+}
+
+// Receiver 接收 v1/v2c/v3 trap 和 inform 并分发给处理函数，与 Agent 相互独立
+type Receiver struct {
+	config   ReceiverConfig
+	logger   Logger
+	engineID string
+	started  time.Time
+
+	mu        sync.RWMutex
+	routes    []receiverRoute
+	conn      transport
+	store     TrapStore
+	pruneStop chan struct{}
+	closers   []func()
+
+	traps       atomic.Uint64
+	informs     atomic.Uint64
+	rejected    atomic.Uint64
+	parseErrors atomic.Uint64
+	unknownIDs  atomic.Uint32
+}
+
+// NewReceiver 创建 trap 接收器，调用 Start 后开始接收
+func NewReceiver(cfg ReceiverConfig) (*Receiver, error) {
+	if cfg.ListenAddr == "" {
+		cfg.ListenAddr = "0.0.0.0:162"
+	}
+	if len(cfg.Communities) == 0 {
+		cfg.Communities = []string{"public"}
+	}
+	for _, c := range cfg.Communities {
+		if c == "" {
+			return nil, fmt.Errorf("receiver community must not be empty")
+		}
+	}
+	cfg.Communities = slices.Clone(cfg.Communities)
+
+	users := make([]User, len(cfg.Users))
+	seen := make(map[string]bool, len(cfg.Users))
+	for i, u := range cfg.Users {
+		if err := u.validate(); err != nil {
+			return nil, err
+		}
+		if seen[u.Name] {
+			return nil, fmt.Errorf("duplicate user: %s", u.Name)
+		}
+		seen[u.Name] = true
+		users[i] = u
+	}
+	cfg.Users = users
+
+	id := GoSNMPServer.DefaultAuthoritativeEngineID()
+	if cfg.EngineID != "" {
+		if len(cfg.EngineID) > maxEngineIDData {
+			return nil, fmt.Errorf("receiver EngineID must be 1 to %d bytes", maxEngineIDData)
+		}
+		id = GoSNMPServer.SNMPEngineID{EngineIDData: cfg.EngineID}
+	}
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = newDefaultLogger(cfg.LogLevel, false)
+	}
+
+	return &Receiver{
+		config:   cfg,
+		logger:   logger,
+		engineID: string(id.Marshal()),
+		started:  time.Now(),
+	}, nil
+}
+
+// Handle 注册处理函数，只接收 snmpTrapOID 位于 trapOIDPrefix 子树下的通知，前缀为空时接收所有通知
+//
+// 一个通知依次交给所有匹配的处理函数。可以在 Start 之后调用。
+func (r *Receiver) Handle(trapOIDPrefix string, h TrapHandler) error {
+	prefix := ""
+	if trapOIDPrefix != "" {
+		var err error
+		if prefix, err = normalizeOID(trapOIDPrefix); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = append(r.routes, receiverRoute{prefix: prefix, handler: h})
+	return nil
+}
+
+// addForwarder 注册接收所有通知的处理函数，closer 在 Close 时调用
+func (r *Receiver) addForwarder(h TrapHandler, closer func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = append(r.routes, receiverRoute{handler: h})
+	r.closers = append(r.closers, closer)
+}
+
+// SetStore 将接受的通知保存到 store，并每分钟按 retention 清理一次；store 为 nil 时停止保存
+//
+// 通知先保存再分发给处理函数，保存失败只记录日志。Receiver.Close 停止清理但不关闭 store。
+func (r *Receiver) SetStore(store TrapStore, retention TrapRetention) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pruneStop != nil {
+		close(r.pruneStop)
+		r.pruneStop = nil
+	}
+	r.store = store
+	if store != nil && (retention.MaxAge > 0 || retention.MaxCount > 0) {
+		r.pruneStop = make(chan struct{})
+		go r.pruneLoop(store, retention, r.pruneStop)
+	}
+}
+
+// Query 查询 SetStore 设置的存储
+func (r *Receiver) Query(q TrapQuery) ([]*ReceivedTrap, error) {
+	r.mu.RLock()
+	store := r.store
+	r.mu.RUnlock()
+	if store == nil {
+		return nil, fmt.Errorf("trap receiver has no store")
+	}
+	return store.Query(q)
+}
+
+func (r *Receiver) pruneLoop(store TrapStore, retention TrapRetention, stop chan struct{}) {
+	ticker := time.NewTicker(trapPruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			var before time.Time
+			if retention.MaxAge > 0 {
+				before = time.Now().Add(-retention.MaxAge)
+			}
+			n, err := store.Prune(before, retention.MaxCount)
+			if err != nil {
+				r.logger.Error("Failed to prune trap store", "error", err)
+			} else if n > 0 {
+				r.logger.Debug("Pruned trap store", "removed", n)
+			}
+		}
+	}
+}
+
+// Start 开始监听，报文在后台协程中处理
+func (r *Receiver) Start() error {
+	conn, err := listenUDP(r.config.ListenAddr, listenOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to start trap receiver: %w", err)
+	}
+	r.mu.Lock()
+	r.conn = conn
+	r.mu.Unlock()
+
+	go r.serve(conn)
+	r.logger.Info("Trap receiver started", "addr", conn.LocalAddr(), "communities", len(r.config.Communities), "users", len(r.config.Users))
+	return nil
+}
+
+// Addr 返回实际监听的地址，未启动时为 nil
+func (r *Receiver) Addr() net.Addr {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.conn == nil {
+		return nil
+	}
+	return r.conn.LocalAddr()
+}
+
+// Close 停止监听、存储清理和转发
+func (r *Receiver) Close() error {
+	r.mu.Lock()
+	if r.pruneStop != nil {
+		close(r.pruneStop)
+		r.pruneStop = nil
+	}
+	closers := r.closers
+	r.closers = nil
+	var err error
+	if r.conn != nil {
+		err = r.conn.Close()
+		r.conn = nil
+	}
+	r.mu.Unlock()
+
+	// 转发协程可能正在等待请求超时，不持有锁等待
+	for _, closer := range closers {
+		closer()
+	}
+	return err
+}
+
+// Stats 返回计数器快照
+func (r *Receiver) Stats() ReceiverStats {
+	return ReceiverStats{
+		Traps:       r.traps.Load(),
+		Informs:     r.informs.Load(),
+		Rejected:    r.rejected.Load(),
+		ParseErrors: r.parseErrors.Load(),
+	}
+}
+
+func (r *Receiver) serve(conn transport) {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, local, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				r.logger.Debug("Trap receiver stopped")
+				return
+			}
+			r.logger.Error("Failed to read notification", "error", err)
+			continue
+		}
+		r.handlePacket(conn, local, addr, slices.Clone(buf[:n]))
+	}
+}
+
+// handlePacket 校验并解码一个报文，inform 先回复确认再分发
+func (r *Receiver) handlePacket(conn transport, local net.IP, addr net.Addr, msg []byte) {
+	defer func() {
+		if p := recover(); p != nil {
+			r.logger.Error("Trap receiver panic", "from", addr, "panic", p, "stack", string(debug.Stack()))
+		}
+	}()
+
+	pkt, err := r.decode(conn, local, addr, msg)
+	if err != nil {
+		r.logger.Debug("Notification discarded", "from", addr, "error", err)
+		return
+	}
+	if pkt == nil {
+		return
+	}
+
+	switch pkt.PDUType {
+	case gosnmp.Trap, gosnmp.SNMPv2Trap:
+		r.traps.Add(1)
+	case gosnmp.InformRequest:
+		r.informs.Add(1)
+		r.acknowledge(conn, local, addr, pkt)
+	default:
+		r.parseErrors.Add(1)
+		r.logger.Debug("Unexpected PDU on trap receiver", "from", addr, "pdu", pkt.PDUType)
+		return
+	}
+
+	trap := receivedTrap(pkt, addr)
+	r.logger.Debug("Received notification", "from", addr, "version", trap.Version, "trap", trap.TrapOID, "inform", trap.Inform)
+	r.dispatch(trap)
+}
+
+// decode 按版本校验 community 或 USM 用户并解码报文；返回 nil, nil 表示已经回复了引擎发现请求
+func (r *Receiver) decode(conn transport, local net.IP, addr net.Addr, msg []byte) (*gosnmp.SnmpPacket, error) {
+	peek, err := (&gosnmp.GoSNMP{SecurityParameters: &gosnmp.UsmSecurityParameters{}}).SnmpDecodePacket(msg)
+	if peek == nil || (peek.Version != gosnmp.Version3 && err != nil) {
+		r.parseErrors.Add(1)
+		return nil, err
+	}
+
+	switch peek.Version {
+	case gosnmp.Version1, gosnmp.Version2c:
+		if !slices.Contains(r.config.Communities, peek.Community) {
+			r.rejected.Add(1)
+			return nil, fmt.Errorf("unknown community")
+		}
+		return peek, nil
+	case gosnmp.Version3:
+	default:
+		r.parseErrors.Add(1)
+		return nil, fmt.Errorf("unsupported version %d", peek.Version)
+	}
+
+	params, ok := peek.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	if !ok || peek.SecurityModel != gosnmp.UserSecurityModel {
+		r.parseErrors.Add(1)
+		return nil, fmt.Errorf("unsupported security model")
+	}
+	if params.AuthoritativeEngineID == "" {
+		// 引擎发现：inform 的发送方先获取接收器的 engineID、boots 和 time
+		r.reportEngineID(conn, local, addr, peek)
+		return nil, nil
+	}
+
+	i := slices.IndexFunc(r.config.Users, func(u User) bool { return u.Name == params.UserName })
+	if i < 0 {
+		r.rejected.Add(1)
+		return nil, fmt.Errorf("unknown user %q", params.UserName)
+	}
+	user := r.config.Users[i]
+	if peek.MsgFlags&gosnmp.AuthPriv != userMsgFlags(user) {
+		r.rejected.Add(1)
+		return nil, fmt.Errorf("security level of user %q does not match", user.Name)
+	}
+
+	// trap 的权威引擎是发送方，inform 的权威引擎是接收器；两种情况下密钥都按报文中的 engineID 本地化
+	usm := user.usm()
+	usm.AuthoritativeEngineID = params.AuthoritativeEngineID
+	decoder := &gosnmp.GoSNMP{
+		Version:            gosnmp.Version3,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		MsgFlags:           peek.MsgFlags,
+		SecurityParameters: &usm,
+	}
+	pkt, err := decoder.UnmarshalTrap(msg, false)
+	if err != nil {
+		r.parseErrors.Add(1)
+		return nil, err
+	}
+	if pkt.PDUType == gosnmp.InformRequest && params.AuthoritativeEngineID != r.engineID {
+		r.rejected.Add(1)
+		return nil, fmt.Errorf("inform for unknown engine ID %x", params.AuthoritativeEngineID)
+	}
+	return pkt, nil
+}
+
+// userMsgFlags 返回用户配置对应的安全级别
+func userMsgFlags(u User) gosnmp.SnmpV3MsgFlags {
+	switch {
+	case u.PrivProtocol != 0 && u.PrivProtocol != gosnmp.NoPriv:
+		return gosnmp.AuthPriv
+	case u.AuthProtocol != 0 && u.AuthProtocol != gosnmp.NoAuth:
+		return gosnmp.AuthNoPriv
+	}
+	return gosnmp.NoAuthNoPriv
+}
+
+// reportEngineID 回复 usmStatsUnknownEngineIDs 报告，携带接收器的引擎参数
+func (r *Receiver) reportEngineID(conn transport, local net.IP, addr net.Addr, peek *gosnmp.SnmpPacket) {
+	params := peek.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	report := &gosnmp.SnmpPacket{
+		Version:       gosnmp.Version3,
+		MsgFlags:      gosnmp.NoAuthNoPriv,
+		SecurityModel: gosnmp.UserSecurityModel,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{
+			AuthoritativeEngineID:    r.engineID,
+			AuthoritativeEngineBoots: 1,
+			AuthoritativeEngineTime:  uint32(time.Since(r.started) / time.Second),
+			UserName:                 params.UserName,
+		},
+		ContextEngineID: r.engineID,
+		PDUType:         gosnmp.Report,
+		MsgID:           peek.MsgID,
+		RequestID:       peek.RequestID,
+		Variables: []gosnmp.SnmpPDU{{
+			Name:  "." + usmStatsUnknownEngineIDsOID,
+			Type:  gosnmp.Counter32,
+			Value: uint32(r.unknownIDs.Add(1)),
+		}},
+	}
+	r.send(conn, local, addr, report)
+}
+
+// acknowledge 回复 inform，响应与请求的变量相同
+func (r *Receiver) acknowledge(conn transport, local net.IP, addr net.Addr, pkt *gosnmp.SnmpPacket) {
+	resp := *pkt
+	resp.PDUType = gosnmp.GetResponse
+	resp.MsgFlags &^= gosnmp.Reportable
+	resp.Error, resp.ErrorIndex = gosnmp.NoError, 0
+	r.send(conn, local, addr, &resp)
+}
+
+func (r *Receiver) send(conn transport, local net.IP, addr net.Addr, pkt *gosnmp.SnmpPacket) {
+	out, err := pkt.MarshalMsg()
+	if err != nil {
+		r.logger.Error("Failed to encode trap receiver response", "to", addr, "error", err)
+		return
+	}
+	if err := conn.WriteTo(out, local, addr); err != nil {
+		r.logger.Error("Failed to send trap receiver response", "to", addr, "error", err)
+	}
+}
+
+// dispatch 保存通知并交给所有匹配的处理函数
+func (r *Receiver) dispatch(trap *ReceivedTrap) {
+	r.mu.RLock()
+	routes, store := r.routes, r.store
+	r.mu.RUnlock()
+
+	if store != nil {
+		if err := store.Add(trap); err != nil {
+			r.logger.Error("Failed to store notification", "from", trap.Source, "trap", trap.TrapOID, "error", err)
+		}
+	}
+
+	for _, route := range routes {
+		if route.prefix == "" || hasOIDPrefix(trap.TrapOID, route.prefix) {
+			route.handler(trap)
+		}
+	}
+}
+
+// receivedTrap 从解码后的报文中提取通知，v1 trap 按 RFC 3584 第 3.1 节转换
+func receivedTrap(pkt *gosnmp.SnmpPacket, addr net.Addr) *ReceivedTrap {
+	trap := &ReceivedTrap{
+		Source:  addr,
+		Version: pkt.Version,
+		Inform:  pkt.PDUType == gosnmp.InformRequest,
+		Time:    time.Now(),
+	}
+	if pkt.Version == gosnmp.Version3 {
+		if usm, ok := pkt.SecurityParameters.(*gosnmp.UsmSecurityParameters); ok {
+			trap.SecurityName = usm.UserName
+		}
+	} else {
+		trap.Community, trap.SecurityName = pkt.Community, pkt.Community
+	}
+
+	if pkt.PDUType == gosnmp.Trap {
+		enterprise := strings.TrimPrefix(pkt.Enterprise, ".")
+		if pkt.GenericTrap >= 0 && pkt.GenericTrap < 6 {
+			trap.TrapOID = fmt.Sprintf("%s.%d", snmpTrapsOID, pkt.GenericTrap+1)
+		} else {
+			trap.TrapOID = fmt.Sprintf("%s.0.%d", enterprise, pkt.SpecificTrap)
+		}
+		trap.Uptime = uint32(pkt.Timestamp)
+		trap.AgentAddress = pkt.AgentAddress
+		trap.Variables = pkt.Variables
+		return trap
+	}
+
+	for _, v := range pkt.Variables {
+		switch strings.TrimPrefix(v.Name, ".") {
+		case sysUpTimeOID:
+			if ticks, err := toInt64(v.Value); err == nil {
+				trap.Uptime = uint32(ticks)
+			}
+		case snmpTrapOID:
+			if oid, ok := v.Value.(string); ok {
+				trap.TrapOID = strings.TrimPrefix(oid, ".")
+			}
+		default:
+			trap.Variables = append(trap.Variables, v)
+		}
+	}
+	return trap
+}
@@ -0,0 +1,127 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// accessConfig community 与 SNMPv3 用户的快照，发布后不再修改，Reload 时整体替换
+type accessConfig struct {
+	communities []string // 第一个为 Config.Community
+	users       []User
+	subAgents   []SubAgentConfig // AddSubAgent 添加的子 Agent
+}
+
+// newAccessConfig 检查配置中的 community 和用户，填充默认值并返回快照
+func newAccessConfig(cfg *Config) (*accessConfig, error) {
+	if cfg.Community == "" {
+		cfg.Community = "public"
+	}
+
+	seenCommunities := map[string]bool{cfg.Community: true}
+	for _, c := range cfg.Communities {
+		if c == "" || seenCommunities[c] {
+			return nil, fmt.Errorf("invalid or duplicate community: %q", c)
+		}
+		seenCommunities[c] = true
+	}
+	cfg.Communities = slices.Clone(cfg.Communities)
+
+	users := make([]User, len(cfg.Users))
+	seenUsers := make(map[string]bool, len(cfg.Users))
+	for i, u := range cfg.Users {
+		if err := u.validate(); err != nil {
+			return nil, err
+		}
+		if seenUsers[u.Name] {
+			return nil, fmt.Errorf("duplicate user: %s", u.Name)
+		}
+		seenUsers[u.Name] = true
+		users[i] = u
+	}
+	cfg.Users = users
+
+	return &accessConfig{
+		communities: append([]string{cfg.Community}, cfg.Communities...),
+		users:       slices.Clone(users),
+	}, nil
+}
+
+// communityIDs 返回 SubAgent 接受的 community 列表
+func (c *accessConfig) communityIDs() []string {
+	ids := slices.Clone(c.communities)
+	for _, s := range c.subAgents {
+		ids = append(ids, s.Communities...)
+	}
+	if len(c.users) > 0 {
+		// SNMPv3 请求按 contextName 查找 SubAgent，默认 context 为空字符串
+		ids = append(ids, "")
+	}
+	return ids
+}
+
+// usm 返回 MasterAgent 使用的 USM 用户列表
+func (c *accessConfig) usm() []gosnmp.UsmSecurityParameters {
+	users := make([]gosnmp.UsmSecurityParameters, 0, len(c.users))
+	for _, u := range c.users {
+		users = append(users, u.usm())
+	}
+	return users
+}
+
+// syncAccess 为 worker 换入最新的访问控制配置，只在 worker 处理请求的协程中调用
+func (a *Agent) syncAccess(w *worker) {
+	access := a.access.Load()
+	if access == w.access {
+		return
+	}
+	w.server.SecurityConfig.Users = access.usm()
+	w.server.SubAgents[0].CommunityIDs = access.communityIDs()
+	if err := w.server.SyncConfig(); err != nil {
+		a.logger.Error("Failed to apply access config", "error", err)
+	}
+	w.access = access
+}
+
+// Reload 重新应用 cfg 中的 community、SNMPv3 用户、EnableAuthenTraps 和日志级别
+//
+// 监听的 socket 和已注册的 OID 保持不变，正在处理的请求仍使用原配置，之后的请求使用新配置。
+// 其他字段（PEN、OIDPrefix、ListenAddr、并发数等）只在 NewAgent 时生效，Reload 时忽略。
+// 用户列表整体替换为 cfg.Users，AddUser 等在运行时所做的修改不保留。
+// cfg 无效时返回错误，原配置保持不变。
+func (a *Agent) Reload(cfg Config) error {
+	access, err := newAccessConfig(&cfg)
+	if err != nil {
+		return fmt.Errorf("reload: %w", err)
+	}
+	if cfg.PEN != 0 && cfg.PEN != a.config.PEN {
+		a.logger.Warn("PEN cannot be changed by reload, ignored", "current", a.config.PEN, "requested", cfg.PEN)
+	}
+	if cfg.OIDPrefix != "" && cfg.OIDPrefix != a.config.OIDPrefix {
+		a.logger.Warn("OID prefix cannot be changed by reload, ignored", "current", a.oidPrefix, "requested", cfg.OIDPrefix)
+	}
+	if cfg.ListenAddr != "" && cfg.ListenAddr != a.config.ListenAddr {
+		a.logger.Warn("Listen address cannot be changed by reload, ignored", "current", a.config.ListenAddr, "requested", cfg.ListenAddr)
+	}
+	if len(cfg.ListenAddrs) > 0 && !slices.Equal(cfg.ListenAddrs, a.config.ListenAddrs) {
+		a.logger.Warn("Listen addresses cannot be changed by reload, ignored", "current", a.config.ListenAddrs, "requested", cfg.ListenAddrs)
+	}
+
+	a.accessMu.Lock()
+	access.subAgents = a.access.Load().subAgents
+	if err := access.checkCommunities(); err != nil {
+		a.accessMu.Unlock()
+		return fmt.Errorf("reload: %w", err)
+	}
+	a.access.Store(access)
+	a.accessMu.Unlock()
+	a.authenTraps.Store(cfg.EnableAuthenTraps)
+	setLogLevel(a.logger, cfg.LogLevel)
+	a.logger.Info("Configuration reloaded",
+		"communities", len(access.communities),
+		"users", len(access.users),
+		"logLevel", cfg.LogLevel)
+	return nil
+}
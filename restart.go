@@ -0,0 +1,130 @@
+package lzsnmp
+
+import (
+	"cmp"
+	"sync"
+	"time"
+)
+
+// 读取请求连续失败的处理：每次失败后等待，间隔按 2 倍递增，连续失败 readErrorLimit 次后认为 socket 已不可用
+const (
+	readErrorLimit      = 10
+	readErrorBackoffMin = 10 * time.Millisecond
+	readErrorBackoffMax = time.Second
+)
+
+// RestartPolicy 服务循环因 socket 错误退出后的重启策略
+//
+// 重启关闭所有监听 socket 后按原配置重新监听；StartPacketConn 传入的连接无法重新打开，不重启。
+type RestartPolicy struct {
+	MaxRestarts int           // 连续重启的次数上限，0 表示不限；稳定运行超过 MaxBackoff 后重新计数
+	MinBackoff  time.Duration // 第一次重启前的等待，默认 100ms
+	MaxBackoff  time.Duration // 等待按 2 倍递增的上限，默认 30s
+}
+
+// serveRun 一次 Start 对应的服务循环
+type serveRun struct {
+	done     chan struct{} // 服务循环退出后关闭
+	stop     chan struct{} // Stop 时关闭
+	stopOnce sync.Once
+	err      error // 服务循环因错误退出时的错误，由 Agent.mu 保护
+}
+
+// Done 返回在服务循环退出后关闭的 channel，调用 Stop 或 socket 出错且不再重启时关闭；未启动时为 nil
+func (a *Agent) Done() <-chan struct{} {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.run == nil {
+		return nil
+	}
+	return a.run.done
+}
+
+// Err 返回服务循环因 socket 错误退出（且不再重启）时的错误，运行中或通过 Stop 停止时为 nil
+func (a *Agent) Err() error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.run == nil {
+		return nil
+	}
+	return a.run.err
+}
+
+// runServe 运行服务循环，socket 出错时按 Config.Restart 通过 listen 重新监听，listen 为 nil 时不重启
+func (a *Agent) runServe(r *serveRun, conns []transport, listen func() ([]transport, error)) {
+	defer close(r.done)
+
+	policy := a.config.Restart
+	var minBackoff, maxBackoff time.Duration
+	if policy != nil {
+		minBackoff = cmp.Or(policy.MinBackoff, 100*time.Millisecond)
+		maxBackoff = max(cmp.Or(policy.MaxBackoff, 30*time.Second), minBackoff)
+	}
+	backoff, restarts := minBackoff, 0
+	for {
+		start := time.Now()
+		err := a.serve(conns)
+		if err == nil {
+			return
+		}
+		a.reportServeError(err)
+		if policy == nil || listen == nil {
+			a.failServe(r, err)
+			return
+		}
+		if time.Since(start) > maxBackoff {
+			backoff, restarts = minBackoff, 0
+		}
+
+		// 重新监听，失败时继续等待重试
+		for {
+			if policy.MaxRestarts > 0 && restarts >= policy.MaxRestarts {
+				a.logger.Error("SNMP server restart limit reached", "restarts", restarts)
+				a.failServe(r, err)
+				return
+			}
+			a.logger.Warn("Restarting SNMP server", "backoff", backoff, "restarts", restarts)
+			select {
+			case <-r.stop:
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxBackoff)
+			restarts++
+			if conns, err = listen(); err == nil {
+				break
+			}
+			a.reportServeError(err)
+		}
+
+		a.mu.Lock()
+		a.conns = conns
+		a.mu.Unlock()
+		select {
+		case <-r.stop:
+			// Stop 在重新监听期间被调用，可能没有看到新的连接
+			for _, conn := range conns {
+				conn.Close()
+			}
+			return
+		default:
+		}
+		a.logger.Info("SNMP server restarted", "restarts", restarts)
+	}
+}
+
+// reportServeError 记录服务循环的错误并调用 Config.OnError
+func (a *Agent) reportServeError(err error) {
+	a.logger.Error("SNMP server loop failed", "error", err)
+	if a.config.OnError != nil {
+		a.config.OnError(err)
+	}
+}
+
+// failServe 记录服务循环最终的错误
+func (a *Agent) failServe(r *serveRun, err error) {
+	a.mu.Lock()
+	r.err = err
+	a.mu.Unlock()
+	a.notifySystemd("STATUS=SNMP server stopped: " + err.Error())
+}
@@ -0,0 +1,552 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// RowStatus 取值（SNMPv2-TC）
+const (
+	RowStatusActive        = 1
+	RowStatusNotInService  = 2
+	RowStatusNotReady      = 3
+	RowStatusCreateAndGo   = 4
+	RowStatusCreateAndWait = 5
+	RowStatusDestroy       = 6
+)
+
+// RowStatusEnum RowStatus 的取值名称
+var RowStatusEnum = Enum{1: "active", 2: "notInService", 3: "notReady", 4: "createAndGo", 5: "createAndWait", 6: "destroy"}
+
+// RowColumn 可创建行的表格的列
+type RowColumn struct {
+	ID       int
+	Name     string // 用于 ExportDocs
+	Type     gosnmp.Asn1BER
+	Default  interface{}    // 创建行时的初始值，为 nil 时必须在激活前通过 SET 设置
+	ReadOnly bool           // 只能通过 AddRow 设置
+	TC       TextConvention // 可选，SET 的值不符合约定时返回 wrongValue
+}
+
+// Row 可创建行的表格中的一行
+type Row struct {
+	Index  string              // 实例 OID 中列号之后的部分，如 "3.97.98.99"
+	Status int                 // RowStatusActive、RowStatusNotInService 或 RowStatusNotReady
+	Values map[int]interface{} // 列号 → 值，不含行状态列；OctetString 的 SET 值为 []byte
+}
+
+func (r *Row) clone() *Row {
+	c := *r
+	c.Values = maps.Clone(r.Values)
+	return &c
+}
+
+// RowTableConfig RegisterRowTable 的配置
+//
+// 回调在行的生命周期变化时调用，参数为变化后的行（OnDestroy 为删除前的行）。
+// 回调返回错误时整个 SET 请求失败（commitFailed），表格和同一请求中的其他变量保持原状。
+type RowTableConfig struct {
+	Columns       []RowColumn
+	StatusColumn  int                      // 行状态列的列号
+	ValidateIndex func(index string) error // 可选，检查新行的索引，返回错误时 SET 得到 noCreation
+
+	OnCreate     func(row Row) error // 行被创建（createAndGo / createAndWait）
+	OnActivate   func(row Row) error // 行变为 active
+	OnDeactivate func(row Row) error // active 的行变为 notInService
+	OnUpdate     func(row Row) error // active 的行的列值被修改
+	OnDestroy    func(row Row) error // 行被删除（destroy）
+}
+
+// RowTable 由 RegisterRowTable 创建、管理端可以通过 RowStatus 创建和删除行的表格
+type RowTable struct {
+	agent    *Agent
+	entryOID string
+	config   RowTableConfig
+	columns  map[int]RowColumn
+
+	mu        sync.Mutex
+	rows      map[string]*Row
+	instances []string
+}
+
+// RegisterRowTable 注册管理端可以通过 SET 行状态列创建、激活和删除行的相对 OID 表格
+//
+// 实例 OID 为 {relativeOID}.1.{列号}.{索引}。管理端写入 createAndGo(4) 创建并激活行，写入
+// createAndWait(5) 创建未激活的行（所有列都有值时为 notInService，否则为 notReady），之后写入
+// active(1) 激活、notInService(2) 停用、destroy(6) 删除。同一请求中的列值和行状态一起生效，
+// 顺序不限。行不持久化。
+func (a *Agent) RegisterRowTable(relativeOID string, config RowTableConfig) (*RowTable, error) {
+	t := &RowTable{
+		agent:    a,
+		entryOID: fmt.Sprintf("%s.%s.1", a.oidPrefix, strings.Trim(relativeOID, ".")),
+		config:   config,
+		columns:  make(map[int]RowColumn, len(config.Columns)),
+		rows:     make(map[string]*Row),
+	}
+	if config.StatusColumn <= 0 {
+		return nil, fmt.Errorf("row table %s: invalid status column %d", t.entryOID, config.StatusColumn)
+	}
+	for _, col := range config.Columns {
+		if col.ID <= 0 {
+			return nil, fmt.Errorf("row table %s: invalid column number %d", t.entryOID, col.ID)
+		}
+		if _, dup := t.columns[col.ID]; dup || col.ID == config.StatusColumn {
+			return nil, fmt.Errorf("row table %s: column %d defined twice", t.entryOID, col.ID)
+		}
+		if col.Default != nil {
+			if _, err := normalizeValue(col.Type, col.Default); err != nil {
+				return nil, fmt.Errorf("row table %s column %d: invalid default: %w", t.entryOID, col.ID, err)
+			}
+		}
+		t.columns[col.ID] = col
+	}
+
+	if err := a.claimSubtree(parentOID(t.entryOID), "row table"); err != nil {
+		return nil, err
+	}
+	for _, col := range config.Columns {
+		a.annotateGroup(fmt.Sprintf("%s.%d", t.entryOID, col.ID), OIDMeta{Name: col.Name})
+	}
+	a.updateStore(func(s *oidStore) error {
+		s.rowTables[t.entryOID] = t
+		return nil
+	})
+	a.logger.Info("Registered row table", "oid", t.entryOID, "columns", len(config.Columns))
+	return t, nil
+}
+
+// rowTable 返回 oid 所在的可创建行的表格
+func (s *oidStore) rowTable(oid string) *RowTable {
+	for entry, t := range s.rowTables {
+		if hasOIDPrefix(oid, entry) && oid != entry {
+			return t
+		}
+	}
+	return nil
+}
+
+// OID 返回表格 entry 的绝对 OID
+func (t *RowTable) OID() string {
+	return t.entryOID
+}
+
+// Rows 返回所有行的副本，按索引排序
+func (t *RowTable) Rows() []Row {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rows := make([]Row, 0, len(t.rows))
+	for _, row := range t.rows {
+		rows = append(rows, *row.clone())
+	}
+	slices.SortFunc(rows, func(a, b Row) int { return compareOID(a.Index, b.Index) })
+	return rows
+}
+
+// AddRow 由应用添加或替换一行，不调用回调；Status 为 0 时为 active，缺少的列使用默认值
+func (t *RowTable) AddRow(row Row) error {
+	if _, err := normalizeOID(t.entryOID + ".1." + row.Index); err != nil || row.Index == "" {
+		return fmt.Errorf("row table %s: invalid index %q", t.entryOID, row.Index)
+	}
+	r := t.newRow(row.Index)
+	for id, value := range row.Values {
+		if _, ok := t.columns[id]; !ok {
+			return fmt.Errorf("row table %s: undefined column %d", t.entryOID, id)
+		}
+		r.Values[id] = value
+	}
+	switch row.Status {
+	case 0:
+		r.Status = RowStatusActive
+	case RowStatusActive, RowStatusNotInService, RowStatusNotReady:
+		r.Status = row.Status
+	default:
+		return fmt.Errorf("row table %s: invalid row status %d", t.entryOID, row.Status)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rows[r.Index] = r
+	t.syncLocked()
+	return nil
+}
+
+// RemoveRow 由应用删除一行，不调用回调
+func (t *RowTable) RemoveRow(index string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.rows[index]; !ok {
+		return fmt.Errorf("row table %s: row %s does not exist", t.entryOID, index)
+	}
+	delete(t.rows, index)
+	t.syncLocked()
+	return nil
+}
+
+// newRow 返回使用默认值的新行
+func (t *RowTable) newRow(index string) *Row {
+	row := &Row{Index: index, Values: make(map[int]interface{}, len(t.columns))}
+	for id, col := range t.columns {
+		if col.Default != nil {
+			row.Values[id], _ = normalizeValue(col.Type, col.Default)
+		}
+	}
+	return row
+}
+
+// complete 判断行的所有列是否都有值
+func (t *RowTable) complete(row *Row) bool {
+	for id := range t.columns {
+		if row.Values[id] == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// syncLocked 按当前的行重建实例，没有值的列不注册实例
+func (t *RowTable) syncLocked() {
+	add := make(map[string]dynamicOID)
+	instances := make([]string, 0, len(t.rows)*(len(t.columns)+1))
+	cell := func(col int, oidType gosnmp.Asn1BER, index string, readOnly bool) {
+		oid := fmt.Sprintf("%s.%d.%s", t.entryOID, col, index)
+		def := dynamicOID{Type: oidType, Handler: t.cellGetter(col, index)}
+		if !readOnly {
+			def.Setter = t.cellSetter(oid, oidType)
+		}
+		add[oid] = def
+		instances = append(instances, oid)
+	}
+	for index, row := range t.rows {
+		cell(t.config.StatusColumn, gosnmp.Integer, index, false)
+		for id, col := range t.columns {
+			if row.Values[id] != nil {
+				cell(id, col.Type, index, col.ReadOnly)
+			}
+		}
+	}
+
+	slices.SortFunc(instances, compareOID)
+	if slices.Equal(instances, t.instances) {
+		return
+	}
+	t.agent.replaceDynamic(t.instances, add)
+	t.instances = instances
+}
+
+func (t *RowTable) cellGetter(col int, index string) ValueHandler {
+	return func() (interface{}, error) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		row, ok := t.rows[index]
+		if !ok {
+			return nil, fmt.Errorf("row table %s: row %s no longer exists: %w", t.entryOID, index, ErrNoSuchInstance)
+		}
+		if col == t.config.StatusColumn {
+			return row.Status, nil
+		}
+		if value := row.Values[col]; value != nil {
+			return value, nil
+		}
+		return nil, ErrNoSuchInstance
+	}
+}
+
+// cellSetter 已有实例的 setter，每个变量单独生效；SET 请求中表格的变量由 serveSet 按表格合并提交，不经过此 setter
+func (t *RowTable) cellSetter(oid string, oidType gosnmp.Asn1BER) SetHandler {
+	return func(value interface{}) error {
+		b := t.begin()
+		if _, err := b.add(0, oid, oidType, value); err != nil {
+			return err
+		}
+		if _, _, err := b.check(); err != nil {
+			return err
+		}
+		return b.commit()
+	}
+}
+
+// rowBatch 一个 SET 请求对表格的修改
+type rowBatch struct {
+	table   *RowTable
+	changes map[string]*rowChange
+	order   []string        // 按第一次出现的顺序排列的行索引
+	applied []rowTransition // 已提交的变化，用于撤销
+}
+
+// rowChange 一个 SET 请求对一行的修改
+type rowChange struct {
+	pos       int // 第一个变量在请求中的位置
+	status    int // 写入的行状态，0 表示未写入
+	statusPos int
+	values    map[int]interface{}
+}
+
+// rowTransition 一行从 before 变为 after，nil 表示行不存在
+type rowTransition struct {
+	index         string
+	before, after *Row
+}
+
+func (t *RowTable) begin() *rowBatch {
+	return &rowBatch{table: t, changes: make(map[string]*rowChange)}
+}
+
+// add 检查并记录一个变量，pos 为变量在请求中的位置，返回错误时同时返回错误状态
+func (b *rowBatch) add(pos int, oid string, valueType gosnmp.Asn1BER, value interface{}) (gosnmp.SNMPError, error) {
+	t := b.table
+	colText, index, ok := strings.Cut(oid[len(t.entryOID)+1:], ".")
+	id, err := strconv.Atoi(colText)
+	if !ok || err != nil {
+		return gosnmp.NoCreation, fmt.Errorf("%s is not a column instance", oid)
+	}
+
+	ch := b.changes[index]
+	if ch == nil {
+		ch = &rowChange{pos: pos, values: make(map[int]interface{})}
+		b.changes[index] = ch
+		b.order = append(b.order, index)
+	}
+
+	if id == t.config.StatusColumn {
+		if valueType != gosnmp.Integer {
+			return gosnmp.WrongType, fmt.Errorf("wrong type %v, want %v", valueType, gosnmp.Integer)
+		}
+		status, err := toInt64(value)
+		if err != nil || status < RowStatusActive || status > RowStatusDestroy || status == RowStatusNotReady {
+			return gosnmp.WrongValue, fmt.Errorf("invalid row status %v", value)
+		}
+		ch.status, ch.statusPos = int(status), pos
+		return gosnmp.NoError, nil
+	}
+
+	col, ok := t.columns[id]
+	switch {
+	case !ok:
+		return gosnmp.NoCreation, fmt.Errorf("undefined column %d", id)
+	case col.ReadOnly:
+		return gosnmp.NotWritable, fmt.Errorf("column %d is read-only", id)
+	case !setTypeMatches(col.Type, valueType):
+		return gosnmp.WrongType, fmt.Errorf("wrong type %v, want %v", valueType, col.Type)
+	}
+	if col.TC != nil {
+		if err := col.TC.Validate(value); err != nil {
+			return gosnmp.WrongValue, err
+		}
+	}
+	value, err = normalizeSetValue(col.Type, value)
+	if err != nil {
+		return gosnmp.WrongValue, err
+	}
+	ch.values[id] = value
+	return gosnmp.NoError, nil
+}
+
+// check 按表格的当前状态检查所有修改，返回错误状态和出错的变量位置
+func (b *rowBatch) check() (gosnmp.SNMPError, int, error) {
+	b.table.mu.Lock()
+	defer b.table.mu.Unlock()
+	_, status, pos, err := b.plan()
+	return status, pos, err
+}
+
+// plan 计算每一行的变化，调用时持有表格的锁
+func (b *rowBatch) plan() ([]rowTransition, gosnmp.SNMPError, int, error) {
+	t := b.table
+	transitions := make([]rowTransition, 0, len(b.order))
+	for _, index := range b.order {
+		ch := b.changes[index]
+		before := t.rows[index]
+		tr := rowTransition{index: index, before: before}
+
+		switch ch.status {
+		case RowStatusCreateAndGo, RowStatusCreateAndWait:
+			if before != nil {
+				return nil, gosnmp.InconsistentValue, ch.statusPos, fmt.Errorf("row %s already exists", index)
+			}
+			if index == "" {
+				return nil, gosnmp.NoCreation, ch.statusPos, fmt.Errorf("empty index")
+			}
+			if t.config.ValidateIndex != nil {
+				if err := t.config.ValidateIndex(index); err != nil {
+					return nil, gosnmp.NoCreation, ch.statusPos, fmt.Errorf("invalid index %s: %w", index, err)
+				}
+			}
+			tr.after = t.newRow(index)
+		case RowStatusDestroy:
+			transitions = append(transitions, tr)
+			continue
+		default:
+			if before == nil {
+				if ch.status == 0 {
+					return nil, gosnmp.InconsistentName, ch.pos, fmt.Errorf("row %s does not exist", index)
+				}
+				return nil, gosnmp.InconsistentValue, ch.statusPos, fmt.Errorf("row %s does not exist", index)
+			}
+			tr.after = before.clone()
+		}
+
+		maps.Copy(tr.after.Values, ch.values)
+		complete := t.complete(tr.after)
+		switch ch.status {
+		case RowStatusCreateAndGo, RowStatusActive, RowStatusNotInService:
+			if !complete {
+				return nil, gosnmp.InconsistentValue, ch.statusPos, fmt.Errorf("row %s has columns without values", index)
+			}
+			tr.after.Status = RowStatusActive
+			if ch.status == RowStatusNotInService {
+				tr.after.Status = RowStatusNotInService
+			}
+		case RowStatusCreateAndWait:
+			tr.after.Status = RowStatusNotReady
+			if complete {
+				tr.after.Status = RowStatusNotInService
+			}
+		default:
+			if tr.after.Status == RowStatusNotReady && complete {
+				tr.after.Status = RowStatusNotInService
+			}
+		}
+		transitions = append(transitions, tr)
+	}
+	return transitions, gosnmp.NoError, 0, nil
+}
+
+// commit 应用所有修改，回调失败时撤销本次已应用的修改
+func (b *rowBatch) commit() error {
+	t := b.table
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	transitions, _, _, err := b.plan()
+	if err != nil {
+		// 检查之后表格被其他请求修改
+		return err
+	}
+	for _, tr := range transitions {
+		if err := t.transition(tr.index, tr.before, tr.after); err != nil {
+			b.rollbackLocked()
+			t.syncLocked()
+			return err
+		}
+		b.applied = append(b.applied, tr)
+	}
+	t.syncLocked()
+	return nil
+}
+
+// undo 撤销已提交的修改
+func (b *rowBatch) undo() error {
+	t := b.table
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	err := b.rollbackLocked()
+	t.syncLocked()
+	return err
+}
+
+func (b *rowBatch) rollbackLocked() error {
+	var first error
+	for i := len(b.applied) - 1; i >= 0; i-- {
+		tr := b.applied[i]
+		if err := b.table.transition(tr.index, tr.after, tr.before); err != nil && first == nil {
+			first = err
+		}
+	}
+	b.applied = nil
+	return first
+}
+
+// cellChanges 返回已提交的修改中值发生变化的单元格，行被创建或删除时旧值或新值为 nil
+func (b *rowBatch) cellChanges() []ChangeEvent {
+	t := b.table
+	var events []ChangeEvent
+	for _, tr := range b.applied {
+		cell := func(col int, oidType gosnmp.Asn1BER, old, value interface{}) {
+			if old == nil && value == nil || old != nil && value != nil && sameValue(old, value) {
+				return
+			}
+			oid := fmt.Sprintf("%s.%d.%s", t.entryOID, col, tr.index)
+			events = append(events, ChangeEvent{OID: oid, Type: oidType, OldValue: old, NewValue: value})
+		}
+		var oldStatus, newStatus interface{}
+		if tr.before != nil {
+			oldStatus = tr.before.Status
+		}
+		if tr.after != nil {
+			newStatus = tr.after.Status
+		}
+		cell(t.config.StatusColumn, gosnmp.Integer, oldStatus, newStatus)
+		for _, col := range t.config.Columns {
+			var old, value interface{}
+			if tr.before != nil {
+				old = tr.before.Values[col.ID]
+			}
+			if tr.after != nil {
+				value = tr.after.Values[col.ID]
+			}
+			cell(col.ID, col.Type, old, value)
+		}
+	}
+	return events
+}
+
+// transition 调用 from 变为 to 对应的回调，成功后更新行
+func (t *RowTable) transition(index string, from, to *Row) error {
+	call := func(event string, fn func(Row) error, row *Row) error {
+		if fn == nil {
+			return nil
+		}
+		if _, err := t.agent.guard(t.entryOID, func() (interface{}, error) { return nil, fn(*row.clone()) }); err != nil {
+			t.agent.logger.Error("Row callback error", "table", t.entryOID, "index", index, "event", event, "error", err)
+			return err
+		}
+		return nil
+	}
+
+	var err error
+	switch {
+	case from == nil && to == nil:
+	case from == nil:
+		if err = call("create", t.config.OnCreate, to); err == nil && to.Status == RowStatusActive {
+			if err = call("activate", t.config.OnActivate, to); err != nil {
+				call("destroy", t.config.OnDestroy, to)
+			}
+		}
+	case to == nil:
+		err = call("destroy", t.config.OnDestroy, from)
+	case from.Status != RowStatusActive && to.Status == RowStatusActive:
+		err = call("activate", t.config.OnActivate, to)
+	case from.Status == RowStatusActive && to.Status != RowStatusActive:
+		err = call("deactivate", t.config.OnDeactivate, to)
+	case to.Status == RowStatusActive && !maps.EqualFunc(from.Values, to.Values, sameValue):
+		err = call("update", t.config.OnUpdate, to)
+	}
+	if err != nil {
+		return err
+	}
+
+	if to == nil {
+		delete(t.rows, index)
+	} else {
+		t.rows[index] = to
+	}
+	t.agent.logger.Info("Row changed", "table", t.entryOID, "index", index, "status", RowStatusEnum.Format(rowStatusOf(to)))
+	return nil
+}
+
+// rowStatusOf 返回行的状态，行不存在时为 destroy
+func rowStatusOf(row *Row) int {
+	if row == nil {
+		return RowStatusDestroy
+	}
+	return row.Status
+}
+
+func sameValue(a, b interface{}) bool {
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
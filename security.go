@@ -0,0 +1,118 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/slayercat/GoSNMPServer"
+)
+
+// SecurityLevel 请求的安全级别，v1/v2c 请求为 SecurityNoAuthNoPriv
+type SecurityLevel int
+
+const (
+	SecurityNoAuthNoPriv SecurityLevel = iota // 不认证、不加密
+	SecurityAuthNoPriv                        // 认证、不加密
+	SecurityAuthPriv                          // 认证并加密
+
+	securityLevels = iota
+)
+
+func (l SecurityLevel) String() string {
+	switch l {
+	case SecurityNoAuthNoPriv:
+		return "noAuthNoPriv"
+	case SecurityAuthNoPriv:
+		return "authNoPriv"
+	case SecurityAuthPriv:
+		return "authPriv"
+	}
+	return fmt.Sprintf("SecurityLevel(%d)", int(l))
+}
+
+// ParseSecurityLevel 解析安全级别名称（不区分大小写），如 "authPriv"
+func ParseSecurityLevel(name string) (SecurityLevel, error) {
+	for l := SecurityLevel(0); l < securityLevels; l++ {
+		if strings.EqualFold(strings.TrimSpace(name), l.String()) {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown security level: %s", name)
+}
+
+// securityLevelOf 返回请求的安全级别，无法解码的请求视为 SecurityNoAuthNoPriv
+func securityLevelOf(pkt *gosnmp.SnmpPacket) SecurityLevel {
+	if pkt == nil || pkt.Version != gosnmp.Version3 {
+		return SecurityNoAuthNoPriv
+	}
+	switch pkt.MsgFlags & gosnmp.AuthPriv {
+	case gosnmp.AuthPriv:
+		return SecurityAuthPriv
+	case gosnmp.AuthNoPriv:
+		return SecurityAuthNoPriv
+	}
+	return SecurityNoAuthNoPriv
+}
+
+// RequireSecurity 要求访问相对 OID 子树的请求至少达到 level 安全级别
+func (a *Agent) RequireSecurity(relativeOID string, level SecurityLevel) error {
+	return a.RequireSecurityAbsolute(a.oidPrefix+"."+strings.TrimPrefix(relativeOID, "."), level)
+}
+
+// RequireSecurityAbsolute 要求访问绝对 OID 子树的请求至少达到 level 安全级别
+//
+// 安全级别不足的请求看不到子树中的 OID：GET 返回 noSuchObject 或 noSuchInstance，GETNEXT/GETBULK 跳过，SET 返回 noCreation（v1 为 noSuchName），
+// 即使 v1/v2c 或 noAuthNoPriv 用户在其他子树上可用，受保护的数据也不会以这些方式返回。
+// 子树可以在其中的 OID 注册之前设置；嵌套的子树取最严格的要求，level 为 SecurityNoAuthNoPriv 时取消该子树的要求。
+func (a *Agent) RequireSecurityAbsolute(oid string, level SecurityLevel) error {
+	if level < 0 || level >= securityLevels {
+		return fmt.Errorf("invalid security level: %d", level)
+	}
+	root, err := normalizeOID(oid)
+	if err != nil {
+		return err
+	}
+	return a.updateStore(func(s *oidStore) error {
+		if level == SecurityNoAuthNoPriv {
+			delete(s.security, root)
+		} else {
+			s.security[root] = level
+		}
+		a.logger.Info("Set subtree security level", "root", root, "level", level)
+		return nil
+	})
+}
+
+// requiredSecurity 返回访问 oid 所需的最低安全级别
+func (s *oidStore) requiredSecurity(oid string) SecurityLevel {
+	required := SecurityNoAuthNoPriv
+	for p := oid; p != ""; p = parentOID(p) {
+		required = max(required, s.security[p])
+	}
+	return required
+}
+
+// securityViews 按安全级别划分的 OID 列表，下标为 SecurityLevel
+type securityViews [securityLevels][]*GoSNMPServer.PDUValueControlItem
+
+// securityViews 从已排序的 OID 列表生成各安全级别可见的列表，没有安全要求时各级别共用同一列表
+func (s *oidStore) securityViews(items []*GoSNMPServer.PDUValueControlItem) *securityViews {
+	var views securityViews
+	if len(s.security) == 0 {
+		for level := range views {
+			views[level] = items
+		}
+		return &views
+	}
+
+	for level := range views {
+		views[level] = make([]*GoSNMPServer.PDUValueControlItem, 0, len(items))
+	}
+	for _, item := range items {
+		for level := s.requiredSecurity(item.OID); level < securityLevels; level++ {
+			views[level] = append(views[level], item)
+		}
+	}
+	return &views
+}
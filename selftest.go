@@ -0,0 +1,225 @@
+package lzsnmp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// selfTestTimeout SelfTest 单个请求的超时
+const selfTestTimeout = 2 * time.Second
+
+// SelfTestFailure SelfTest 发现的一个问题
+type SelfTestFailure struct {
+	OID    string
+	Type   gosnmp.Asn1BER // 注册时声明的类型
+	Reason string
+}
+
+// SelfTestReport SelfTest 的结果
+type SelfTestReport struct {
+	Target    string // 请求发往的地址
+	Checked   int    // GET 检查的 OID 数
+	Skipped   int    // 要求 SNMPv3 认证、未检查的 OID 数
+	Walked    int    // WALK 返回的变量数
+	WalkError error  // WALK 失败（如 GETNEXT 顺序错误）的原因
+	Failures  []SelfTestFailure
+}
+
+// Passed 没有发现问题时返回 true
+func (r *SelfTestReport) Passed() bool {
+	return r.WalkError == nil && len(r.Failures) == 0
+}
+
+// WriteText 以文本格式输出报告
+func (r *SelfTestReport) WriteText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "target %s, %d checked, %d skipped, %d walked\n", r.Target, r.Checked, r.Skipped, r.Walked); err != nil {
+		return err
+	}
+	if r.WalkError != nil {
+		if _, err := fmt.Fprintf(w, "FAIL walk: %v\n", r.WalkError); err != nil {
+			return err
+		}
+	}
+	for _, f := range r.Failures {
+		if _, err := fmt.Fprintf(w, "FAIL %s (%s): %s\n", f.OID, f.Type, f.Reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SelfTest 在 Start 之后通过回环地址向 Agent 自身发送 SNMPv2c 请求，检查所有已注册的 OID
+//
+// 对每个 OID 执行 GET，记录处理函数返回错误或返回值与声明类型不符的 OID；随后 WALK 整棵树，
+// 检查 GETNEXT 顺序和每个 OID 都能被遍历到。处理函数出错时会在进程内再调用一次以给出具体原因。
+// 要求 SNMPv3 认证的子树被跳过。自检请求计入统计和访问日志，只有无法发送请求时返回错误。
+func (a *Agent) SelfTest(ctx context.Context) (*SelfTestReport, error) {
+	addr := a.Addr()
+	if addr == nil {
+		return nil, fmt.Errorf("self test requires a started agent")
+	}
+	target, err := loopbackTarget(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &gosnmp.GoSNMP{
+		Target:    target.IP.String(),
+		Port:      uint16(target.Port),
+		Community: a.config.Community,
+		Version:   gosnmp.Version2c,
+		Timeout:   selfTestTimeout,
+		Retries:   1,
+		MaxOids:   gosnmp.MaxOids,
+		Context:   ctx,
+	}
+	if err := client.Connect(); err != nil {
+		return nil, fmt.Errorf("self test connect %s: %w", target, err)
+	}
+	defer client.Conn.Close()
+
+	s := a.store.Load()
+	report := &SelfTestReport{Target: target.String()}
+	served := make(map[string]bool)
+	for _, oid := range s.sortedOIDs() {
+		if s.requiredSecurity(oid) > SecurityNoAuthNoPriv {
+			report.Skipped++
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		report.Checked++
+		oidType := s.types[oid]
+		resp, err := client.Get([]string{oid})
+		if err != nil {
+			return nil, fmt.Errorf("self test GET %s: %w", oid, err)
+		}
+		if reason := a.checkSelfTestResponse(ctx, s, oid, oidType, resp); reason != "" {
+			report.Failures = append(report.Failures, SelfTestFailure{OID: oid, Type: oidType, Reason: reason})
+			continue
+		}
+		served[oid] = true
+	}
+
+	// 按前两级分组 WALK，gosnmp 不接受只有一级的根 OID
+	for _, root := range walkRoots(served) {
+		err = client.BulkWalk("."+root, func(pdu gosnmp.SnmpPDU) error {
+			report.Walked++
+			delete(served, strings.TrimPrefix(pdu.Name, "."))
+			return nil
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			report.WalkError = fmt.Errorf("walk %s: %w", root, err)
+			break
+		}
+	}
+	if report.WalkError == nil {
+		for _, oid := range s.sortedOIDs() {
+			if served[oid] {
+				report.Failures = append(report.Failures, SelfTestFailure{OID: oid, Type: s.types[oid], Reason: "served by GET but missing from WALK"})
+			}
+		}
+	}
+
+	if report.Passed() {
+		a.logger.Info("Self test passed", "checked", report.Checked, "skipped", report.Skipped, "walked", report.Walked)
+	} else {
+		a.logger.Warn("Self test failed", "checked", report.Checked, "failures", len(report.Failures), "walkError", report.WalkError)
+	}
+	return report, nil
+}
+
+// checkSelfTestResponse 检查 GET oid 的响应，返回问题描述，没有问题时返回空字符串
+func (a *Agent) checkSelfTestResponse(ctx context.Context, s *oidStore, oid string, oidType gosnmp.Asn1BER, resp *gosnmp.SnmpPacket) string {
+	if resp.Error != gosnmp.NoError {
+		return fmt.Sprintf("%s: %s", resp.Error, a.diagnoseHandler(ctx, s, oid, oidType))
+	}
+	if len(resp.Variables) != 1 {
+		return fmt.Sprintf("expected 1 variable, got %d", len(resp.Variables))
+	}
+	// 处理函数出错时 GoSNMPServer 默认以 "ERROR: ..." 字符串应答，不设置错误状态
+	pdu := resp.Variables[0]
+	switch {
+	case pdu.Type == gosnmp.NoSuchObject || pdu.Type == gosnmp.NoSuchInstance:
+		return fmt.Sprintf("not served (%s)", pdu.Type)
+	case pdu.Type != oidType:
+		return fmt.Sprintf("returned %s: %s", pdu.Type, a.diagnoseHandler(ctx, s, oid, oidType))
+	case oidType == gosnmp.OctetString && isHandlerErrorValue(pdu.Value):
+		if reason := a.diagnoseHandler(ctx, s, oid, oidType); reason != handlerSucceeded {
+			return reason
+		}
+	}
+	return ""
+}
+
+// handlerSucceeded diagnoseHandler 没有发现问题时的结果
+const handlerSucceeded = "handler succeeded when called directly"
+
+// diagnoseHandler 在进程内调用 oid 的处理函数，说明响应出错的原因
+func (a *Agent) diagnoseHandler(ctx context.Context, s *oidStore, oid string, oidType gosnmp.Asn1BER) string {
+	handler, ok := s.handlers[oid]
+	if !ok {
+		return "static value rejected"
+	}
+	if contextHandler, ok := s.contexts[oid]; ok {
+		handler = func() (interface{}, error) { return contextHandler(ctx) }
+	}
+	value, err := a.guard(oid, handler)
+	if err != nil {
+		return fmt.Sprintf("handler error: %v", err)
+	}
+	if _, err := normalizeValue(oidType, value); err != nil {
+		return fmt.Sprintf("value %v (%T) incompatible with %s: %v", value, value, oidType, err)
+	}
+	return handlerSucceeded
+}
+
+// isHandlerErrorValue 判断 OctetString 值是否为 GoSNMPServer 生成的错误文本
+func isHandlerErrorValue(value interface{}) bool {
+	b, ok := value.([]byte)
+	return ok && strings.HasPrefix(string(b), "ERROR: ")
+}
+
+// walkRoots 返回 oids 的前两级 OID，按顺序去重
+func walkRoots(oids map[string]bool) []string {
+	seen := make(map[string]bool)
+	var roots []string
+	for oid := range oids {
+		arcs := strings.SplitN(oid, ".", 3)
+		root := arcs[0] + "." + arcs[1]
+		if !seen[root] {
+			seen[root] = true
+			roots = append(roots, root)
+		}
+	}
+	slices.SortFunc(roots, compareOID)
+	return roots
+}
+
+// loopbackTarget 返回访问监听地址的目标地址，通配地址替换为同一地址族的回环地址
+func loopbackTarget(addr net.Addr) (*net.UDPAddr, error) {
+	udpAddr, ok := addr.(*net.UDPAddr)
+	if !ok {
+		return nil, fmt.Errorf("self test requires a UDP listener, got %s", addr.Network())
+	}
+	target := *udpAddr
+	if target.IP == nil || target.IP.IsUnspecified() {
+		if target.IP == nil || target.IP.To4() != nil {
+			target.IP = net.IPv4(127, 0, 0, 1)
+		} else {
+			target.IP = net.IPv6loopback
+		}
+	}
+	return &target, nil
+}
@@ -0,0 +1,288 @@
+package lzsnmp
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// maxPacketSize UDP 报文最大长度
+const maxPacketSize = 65535
+
+// pooledPacketSize 复用的请求缓冲区大小，覆盖绝大多数请求，更大的报文单独分配
+const pooledPacketSize = 2048
+
+// packetPool 请求报文缓冲区，处理完成后归还
+var packetPool = sync.Pool{New: func() any {
+	b := make([]byte, pooledPacketSize)
+	return &b
+}}
+
+var (
+	errUndecodable = errors.New("undecodable request")
+	errNoResponse  = errors.New("request dropped without response")
+)
+
+// serve 接收所有连接上的请求并分发给 worker，连接全部关闭后等待已排队的请求处理完再返回
+//
+// 某个连接不可用时关闭所有连接，返回该连接的错误；通过 Stop 关闭时返回 nil。
+func (a *Agent) serve(conns []transport) error {
+	jobs := make(chan packetJob, a.config.RequestQueueSize)
+	var wg sync.WaitGroup
+	for _, w := range a.workers {
+		wg.Add(1)
+		go func(w *worker) {
+			defer wg.Done()
+			a.runWorker(w, jobs)
+		}(w)
+	}
+
+	var readers sync.WaitGroup
+	var failOnce sync.Once
+	var failure error
+	for _, conn := range conns {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			if err := a.readPackets(conn, jobs); err != nil {
+				failOnce.Do(func() {
+					failure = err
+					for _, c := range conns {
+						c.Close()
+					}
+				})
+			}
+		}()
+	}
+	readers.Wait()
+	a.logger.Debug("SNMP server loop stopped")
+	close(jobs)
+	wg.Wait()
+	return failure
+}
+
+// readPackets 从 conn 读取请求放入队列，直到连接关闭（返回 nil）或连续 readErrorLimit 次读取失败
+func (a *Agent) readPackets(conn transport, jobs chan<- packetJob) error {
+	buf := make([]byte, maxPacketSize)
+	failures := 0
+	for {
+		n, local, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			failures++
+			if failures >= readErrorLimit {
+				return fmt.Errorf("failed to read request on %s: %w", conn.LocalAddr(), err)
+			}
+			backoff := min(readErrorBackoffMin<<(failures-1), readErrorBackoffMax)
+			a.logger.Error("Failed to read request", "listen", conn.LocalAddr(), "error", err, "backoff", backoff)
+			time.Sleep(backoff)
+			continue
+		}
+		failures = 0
+
+		a.dumpPacket(true, conn, local, addr, buf[:n])
+
+		job := packetJob{conn: conn, local: local, addr: addr}
+		if n <= pooledPacketSize {
+			job.pooled = packetPool.Get().(*[]byte)
+			job.packet = (*job.pooled)[:copy(*job.pooled, buf[:n])]
+		} else {
+			job.packet = append([]byte(nil), buf[:n]...)
+		}
+		a.stats.queued.Add(1)
+		if !a.config.DropWhenBusy {
+			jobs <- job
+			continue
+		}
+		select {
+		case jobs <- job:
+		default:
+			a.stats.queued.Add(-1)
+			job.release()
+			a.stats.countIn(addr)
+			a.stats.overloadDrops.Add(1)
+			a.stats.silentDrops.Add(1)
+			logSampled(a.logLimits.packet, a.logger.Debug, "Request dropped, all workers busy", "from", addr)
+		}
+	}
+}
+
+// handlePacket 处理一个请求报文，并从请求到达的地址（或配置的源地址）发送响应
+//
+// 报文缓冲区在处理完成后归还，延迟发送时由 sendResponse 归还。
+func (a *Agent) handlePacket(w *worker, job packetJob) {
+	addr, packet := job.addr, job.packet
+	id := newRequestID()
+	ctx := context.WithValue(context.Background(), requestIDKey{}, id)
+	var span Span
+	if a.tracing {
+		ctx, span = a.tracer.Start(ctx, "snmp.request", "snmp.request_id", id, "net.peer.addr", addr.String())
+	} else {
+		ctx, span = a.tracer.Start(ctx, "snmp.request")
+	}
+	var result error
+	sending := false // 已交给 sendResponse 结束 span
+	defer func() {
+		if r := recover(); r != nil {
+			a.stats.silentDrops.Add(1)
+			a.logger.Error("Panic while processing request", "request", id, "from", addr, "panic", r, "stack", string(debug.Stack()))
+			result = fmt.Errorf("panic: %v", r)
+		}
+		if !sending {
+			span.End(result)
+			job.release()
+		}
+	}()
+
+	start := time.Now()
+	a.stats.countIn(addr)
+	_, decodeSpan := a.tracer.Start(ctx, "snmp.decode")
+	pkt := a.inspectRequest(w, addr, packet)
+	if pkt == nil {
+		decodeSpan.End(errUndecodable)
+	} else {
+		decodeSpan.End(nil)
+		if a.tracing {
+			span.SetAttributes("snmp.version", pkt.Version.String(), "snmp.pdu_type", pkt.PDUType.String())
+		}
+	}
+	if !isReadRequest(pkt) {
+		// SET 的值和无法解密的 SNMPv3 报文中的值可能引用缓冲区并被处理函数保留，不归还
+		job.pooled = nil
+	}
+	if pkt != nil && pkt.Version != gosnmp.Version3 && pkt.Community == "" {
+		// 空字符串只用于映射 SNMPv3 默认 context，不接受空 community 的 v1/v2c 请求
+		a.stats.silentDrops.Add(1)
+		a.writeAccessLog(start, addr, pkt, nil)
+		result = errNoResponse
+		return
+	}
+	a.syncAccess(w)
+	w.syncOIDs(pkt)
+	w.current = requestContext{id: id, ctx: ctx, source: addr, pkt: pkt}
+	defer func() {
+		w.current = requestContext{}
+		w.markGenErr(false)
+	}()
+
+	if key, ok := requestKeyOf(addr, pkt); ok {
+		if duplicate, slow := a.stats.duplicates.observe(key, start); duplicate {
+			a.stats.inDuplicates.Add(1)
+			if slow {
+				a.stats.inDuplicatesSlow.Add(1)
+			}
+			logSampled(a.logLimits.packet, a.logger.Debug, "Duplicate request", "request", id, "from", addr, "id", key.id, "slow", slow)
+		}
+		defer func() { a.stats.duplicates.complete(key, start, time.Since(start)) }()
+	}
+
+	// 处理函数的 span 是 snmp.process 的子 span
+	var processSpan Span
+	w.current.ctx, processSpan = a.tracer.Start(ctx, "snmp.process")
+	response, err := a.respond(w, packet, pkt)
+	processSpan.End(err)
+	if err != nil {
+		logSampled(a.logLimits.packet, a.logger.Warn, "Failed to process request", "request", id, "from", addr, "error", err)
+	}
+	if len(response) == 0 {
+		a.stats.silentDrops.Add(1)
+		a.writeAccessLog(start, addr, pkt, nil)
+		result = cmp.Or(err, errNoResponse)
+		return
+	}
+
+	if a.sourceIP != nil {
+		job.local = a.sourceIP
+	}
+	sending = true
+	if a.config.ResponseJitter > 0 {
+		// 延迟发送不占用 worker，worker 可以继续处理下一个请求
+		delay := rand.N(a.config.ResponseJitter)
+		time.AfterFunc(delay, func() { a.sendResponse(ctx, span, job, start, pkt, response) })
+		return
+	}
+	a.sendResponse(ctx, span, job, start, pkt, response)
+}
+
+// sendResponse 发送响应，记录计数、访问日志和影子比对，结束请求的 span 并归还报文缓冲区
+func (a *Agent) sendResponse(ctx context.Context, span Span, job packetJob, start time.Time, pkt *gosnmp.SnmpPacket, response []byte) {
+	defer job.release()
+	conn, local, addr, packet := job.conn, job.local, job.addr, job.packet
+	_, sendSpan := a.tracer.Start(ctx, "snmp.send")
+	err := conn.WriteTo(response, local, addr)
+	sendSpan.End(err)
+	span.End(err)
+	if err != nil {
+		a.stats.silentDrops.Add(1)
+		a.logger.Error("Failed to send response", "request", RequestIDFromContext(ctx), "to", addr, "error", err)
+		a.writeAccessLog(start, addr, pkt, nil)
+		return
+	}
+	a.dumpPacket(false, conn, local, addr, response)
+	a.stats.countOut(addr)
+	a.stats.outGetResponses.Add(1)
+	a.writeAccessLog(start, addr, pkt, response)
+	a.shadowRequest(addr, pkt, packet, response)
+}
+
+// inspectRequest 解码请求以更新计数器，返回解码结果，无法解码时返回 nil
+//
+// SNMPv3 加密报文只能解析出报头（版本和用户名）。
+func (a *Agent) inspectRequest(w *worker, addr net.Addr, packet []byte) *gosnmp.SnmpPacket {
+	pkt, err := w.decoder.SnmpDecodePacket(packet)
+
+	switch pkt.Version {
+	case gosnmp.Version1, gosnmp.Version2c:
+	case gosnmp.Version3:
+		if err == nil {
+			a.stats.observeRequest(pkt)
+		}
+		return pkt
+	default:
+		a.stats.inBadVersions.Add(1)
+		return nil
+	}
+
+	if err != nil {
+		a.stats.inASNParseErrs.Add(1)
+		a.publishEvent(Event{Type: EventDecodeError, Source: addr, Err: err})
+		return nil
+	}
+	if !a.knownCommunity(pkt.Community) {
+		a.stats.inBadCommunityNames.Add(1)
+		a.sendAuthFailureTrap()
+		a.publishEvent(Event{Type: EventAuthFailure, Source: addr})
+		return pkt
+	}
+	a.stats.observeRequest(pkt)
+	return pkt
+}
+
+// isReadRequest 判断请求是否为已解码的 GET/GETNEXT/GETBULK
+func isReadRequest(pkt *gosnmp.SnmpPacket) bool {
+	if pkt == nil {
+		return false
+	}
+	switch pkt.PDUType {
+	case gosnmp.GetRequest, gosnmp.GetNextRequest, gosnmp.GetBulkRequest:
+		return true
+	}
+	return false
+}
+
+// knownCommunity 判断 community 是否被接受
+func (a *Agent) knownCommunity(community string) bool {
+	access := a.access.Load()
+	return community != "" && (slices.Contains(access.communities, community) || access.subAgentOf(community) != nil)
+}
@@ -0,0 +1,139 @@
+package lzsnmp_test
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/gosnmp/gosnmp"
+
+	lzsnmp "github.com/liuzhen9320/snmp-go"
+	"github.com/liuzhen9320/snmp-go/testutil"
+)
+
+// benchPEN 基准测试使用的企业号
+const benchPEN = 99999
+
+// maxGetAllocs GET 请求（含内存网络转发报文）的分配次数上限，当前约 80 次，留有余量以免随 gosnmp 版本波动
+const maxGetAllocs = 120
+
+// benchAgent 在内存网络上启动注册了 100 个整数 OID 和一个字符串 OID 的 Agent，返回管理端的连接和 Agent 的地址
+func benchAgent(tb testing.TB) (net.PacketConn, net.Addr) {
+	tb.Helper()
+	agent, err := lzsnmp.NewAgent(lzsnmp.Config{PEN: benchPEN, DisableStartTraps: true, LogLevel: log.ErrorLevel})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for i := 1; i <= 100; i++ {
+		if err := agent.RegisterStatic(fmt.Sprintf("1.%d.0", i), gosnmp.Integer, i); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	if err := agent.RegisterStatic("2.1.0", gosnmp.OctetString, "allocation benchmark"); err != nil {
+		tb.Fatal(err)
+	}
+
+	network := testutil.NewMemNetwork()
+	server, err := network.Listen("agent")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := agent.StartPacketConn(server); err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { agent.Stop() })
+	client, err := network.Listen("")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { client.Close() })
+	return client, server.LocalAddr()
+}
+
+// encodeRequests 预先编码 n 个请求 ID 各不相同的 v2c 请求，避免被当作重传，编码不计入分配
+func encodeRequests(tb testing.TB, n int, pduType gosnmp.PDUType, maxRepetitions uint32, oid string) [][]byte {
+	tb.Helper()
+	packets := make([][]byte, n)
+	for i := range packets {
+		pkt := gosnmp.SnmpPacket{
+			Version:        gosnmp.Version2c,
+			Community:      "public",
+			PDUType:        pduType,
+			RequestID:      uint32(i + 1),
+			MaxRepetitions: maxRepetitions,
+			Variables:      []gosnmp.SnmpPDU{{Name: fmt.Sprintf(".1.3.6.1.4.1.%d%s", benchPEN, oid), Type: gosnmp.Null}},
+		}
+		b, err := pkt.MarshalMsg()
+		if err != nil {
+			tb.Fatal(err)
+		}
+		packets[i] = b
+	}
+	return packets
+}
+
+// roundTrip 发送一个请求并等待响应
+func roundTrip(conn net.PacketConn, agent net.Addr, packet, buf []byte) error {
+	if _, err := conn.WriteTo(packet, agent); err != nil {
+		return err
+	}
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := conn.ReadFrom(buf)
+	return err
+}
+
+func benchmarkRequest(b *testing.B, pduType gosnmp.PDUType, maxRepetitions uint32, oid string) {
+	conn, agent := benchAgent(b)
+	packets := encodeRequests(b, b.N, pduType, maxRepetitions, oid)
+	buf := make([]byte, 65535)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for _, packet := range packets {
+		if err := roundTrip(conn, agent, packet, buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	benchmarkRequest(b, gosnmp.GetRequest, 0, ".2.1.0")
+}
+
+func BenchmarkGetNext(b *testing.B) {
+	benchmarkRequest(b, gosnmp.GetNextRequest, 0, ".1.50.0")
+}
+
+func BenchmarkGetBulk(b *testing.B) {
+	benchmarkRequest(b, gosnmp.GetBulkRequest, 25, ".1")
+}
+
+func TestGetAllocs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("allocation check is skipped in short mode")
+	}
+	conn, agent := benchAgent(t)
+	const runs = 1000
+	// 先预热请求缓冲区池和 worker 的解码器，AllocsPerRun 本身还会多调用一次
+	packets := encodeRequests(t, runs+101, gosnmp.GetRequest, 0, ".2.1.0")
+	buf := make([]byte, 65535)
+	for _, packet := range packets[:100] {
+		if err := roundTrip(conn, agent, packet, buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	next := packets[100:]
+	allocs := testing.AllocsPerRun(runs, func() {
+		if err := roundTrip(conn, agent, next[0], buf); err != nil {
+			t.Fatal(err)
+		}
+		next = next[1:]
+	})
+	t.Logf("GET: %.1f allocs per request", allocs)
+	if allocs > maxGetAllocs {
+		t.Errorf("GET allocates %.1f times per request, want at most %d", allocs, maxGetAllocs)
+	}
+}
@@ -0,0 +1,308 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// SetTransaction 两阶段 SET 的回调
+//
+// 多变量 SET 先对所有变量调用 Test，全部通过后按顺序调用 Commit；某个变量 Commit 失败时，
+// 按相反顺序对已提交的变量调用 Undo，使整个请求要么全部生效，要么全部不生效。
+type SetTransaction struct {
+	Test   func(value interface{}) error // 检查值，不能修改状态；为 nil 时只做类型和文本约定检查
+	Commit func(value interface{}) error // 应用值，必填
+	Undo   func() error                  // 撤销本次 Commit；为 nil 时用提交前读取的旧值再次调用 Commit
+}
+
+// RegisterWritableTx 注册使用两阶段 SET 的相对 OID，见 RegisterWritableTxAbsolute
+func (a *Agent) RegisterWritableTx(relativeOID string, oidType gosnmp.Asn1BER, handler ValueHandler, tx SetTransaction) error {
+	absoluteOID := fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID)
+	return a.RegisterWritableTxAbsolute(absoluteOID, oidType, handler, tx)
+}
+
+// RegisterWritableTxAbsolute 注册使用两阶段 SET 的绝对路径 OID
+//
+// tx.Commit 相当于 RegisterWritable 的 setter，中间件和持久化对它同样生效；Test 和 Undo 直接调用。
+func (a *Agent) RegisterWritableTxAbsolute(oid string, oidType gosnmp.Asn1BER, handler ValueHandler, tx SetTransaction) error {
+	if tx.Commit == nil {
+		return fmt.Errorf("commit is required for writable OID: %s", oid)
+	}
+	if err := a.registerDynamic(oid, oidType, handler, tx.Commit); err != nil {
+		return err
+	}
+	oid, _ = normalizeOID(oid)
+	return a.updateStore(func(s *oidStore) error {
+		if _, ok := s.setters[oid]; !ok {
+			return fmt.Errorf("OID %s was replaced during registration", oid)
+		}
+		txCopy := tx
+		s.txs[oid] = &txCopy
+		return nil
+	})
+}
+
+// setTarget SET 请求写入的 OID
+type setTarget struct {
+	oid     string
+	oidType gosnmp.Asn1BER
+	tc      TextConvention
+	handler ValueHandler // 读取旧值，用于默认的撤销
+	setter  SetHandler
+	tx      *SetTransaction // 通过 RegisterWritableTx 注册时非 nil
+}
+
+// setTarget 返回可写 OID 的 SET 目标，OID 不存在或只读时返回 nil
+func (s *oidStore) setTarget(oid string) *setTarget {
+	setter, ok := s.setters[oid]
+	if !ok {
+		return nil
+	}
+	return &setTarget{oid: oid, oidType: s.types[oid], tc: s.tcs[oid], handler: s.handlers[oid], setter: setter, tx: s.txs[oid]}
+}
+
+// testSet SET 的检查阶段：文本约定、值转换和 SetTransaction.Test，返回转换后的值
+func (a *Agent) testSet(w *worker, t *setTarget, value interface{}) (interface{}, error) {
+	logSampled(a.logLimits.set, a.logger.Info, "SET request", "request", w.current.id, "oid", t.oid, "value", displayValue(t.tc, value))
+	if t.tc != nil {
+		if err := t.tc.Validate(value); err != nil {
+			a.logger.Warn("SET value rejected", "request", w.current.id, "oid", t.oid, "error", err)
+			return nil, err
+		}
+	}
+	value, err := normalizeSetValue(t.oidType, value)
+	if err == nil && t.tx != nil && t.tx.Test != nil {
+		_, err = a.guardRequest(w, t.oid, func() (interface{}, error) {
+			return nil, t.tx.Test(value)
+		})
+	}
+	if err != nil {
+		a.logger.Warn("SET value rejected", "request", w.current.id, "oid", t.oid, "error", err)
+		return nil, err
+	}
+	return value, nil
+}
+
+// commitSet SET 的提交阶段：通过中间件链调用 setter 并记录持久化的值
+func (a *Agent) commitSet(w *worker, t *setTarget, value interface{}) error {
+	start := time.Now()
+	_, err := a.guardRequest(w, t.oid, func() (interface{}, error) {
+		return a.resolve(w, t.oid, value, func(req *RequestInfo) (interface{}, error) {
+			return nil, t.setter(req.Value)
+		})
+	})
+	a.stats.observeHandler(t.oid, time.Since(start), err)
+	if err != nil {
+		a.logger.Error("Setter error", "request", w.current.id, "oid", t.oid, "name", a.metaName(t.oid), "error", err)
+		return err
+	}
+	a.stats.oids.remember(t.oid, value)
+	a.forgetCoalesced(t.oid)
+	a.persist.record(t.oid, t.oidType, value)
+	return nil
+}
+
+// undoSet 撤销已提交的 SET，old 为提交前读取的值
+func (a *Agent) undoSet(w *worker, t *setTarget, old interface{}, hasOld bool) error {
+	_, err := a.guardRequest(w, t.oid, func() (interface{}, error) {
+		if t.tx != nil && t.tx.Undo != nil {
+			return nil, t.tx.Undo()
+		}
+		if !hasOld {
+			return nil, fmt.Errorf("old value is unavailable")
+		}
+		return nil, t.setter(old)
+	})
+	if err != nil {
+		a.logger.Error("SET undo failed", "request", w.current.id, "oid", t.oid, "name", a.metaName(t.oid), "error", err)
+		return err
+	}
+	a.logger.Warn("SET undone", "request", w.current.id, "oid", t.oid)
+	if hasOld {
+		a.forgetCoalesced(t.oid)
+		a.persist.record(t.oid, t.oidType, old)
+	}
+	return nil
+}
+
+// serveSet 按两阶段处理 SET 请求
+//
+// 检查阶段依次检查每个变量：OID 不存在为 noCreation，只读为 notWritable，类型不符为 wrongType，
+// 文本约定、值转换或 Test 失败为 wrongValue（v1 分别为 noSuchName 和 badValue），任何变量失败时
+// 都不调用 setter。提交阶段按顺序调用 setter，失败时撤销已提交的变量并返回 commitFailed，
+// 撤销也失败时返回 undoFailed（v1 均为 genErr）。可创建行的表格中的变量按表格合并为一步提交。
+func (a *Agent) serveSet(w *worker, pkt *gosnmp.SnmpPacket) ([]byte, error) {
+	r := readRequest{agent: a, items: w.server.SubAgents[0].OIDs, v1: pkt.Version == gosnmp.Version1}
+	s := a.store.Load()
+	var steps []setStep
+	var batches []*rowBatch
+
+	for i, v := range pkt.Variables {
+		oid := trimOID(v.Name)
+		if table := s.rowTable(oid); table != nil {
+			// 可创建行的表格不经过 worker 的 OID 列表，需要单独检查子 Agent 的视图
+			if !w.access.inView(pkt, oid) {
+				r.fail(r.status(gosnmp.NoAccess), i)
+				break
+			}
+			if s.requiredSecurity(oid) > securityLevelOf(pkt) {
+				r.fail(r.status(gosnmp.NoCreation), i)
+				break
+			}
+			k := slices.IndexFunc(batches, func(b *rowBatch) bool { return b.table == table })
+			if k < 0 {
+				k = len(batches)
+				batches = append(batches, table.begin())
+				steps = append(steps, setStep{index: i, commit: batches[k].commit, undo: batches[k].undo, changes: batches[k].cellChanges})
+			}
+			b := batches[k]
+			logSampled(a.logLimits.set, a.logger.Info, "SET request", "request", w.current.id, "oid", oid, "value", v.Value)
+			if status, err := b.add(i, oid, v.Type, v.Value); err != nil {
+				a.logger.Warn("SET value rejected", "request", w.current.id, "oid", oid, "error", err)
+				r.fail(r.status(status), i)
+				break
+			}
+			continue
+		}
+
+		j := r.search(oid)
+		if j >= len(r.items) || r.items[j].OID != oid {
+			r.fail(r.status(gosnmp.NoCreation), i)
+			break
+		}
+		t := s.setTarget(oid)
+		if r.items[j].OnSet == nil || t == nil {
+			r.fail(r.status(gosnmp.NotWritable), i)
+			break
+		}
+		if !setTypeMatches(t.oidType, v.Type) {
+			a.logger.Warn("SET value rejected", "request", w.current.id, "oid", oid, "error", fmt.Sprintf("wrong type %v, want %v", v.Type, t.oidType))
+			r.fail(r.status(gosnmp.WrongType), i)
+			break
+		}
+		value, err := a.testSet(w, t, v.Value)
+		if err != nil {
+			r.fail(r.status(gosnmp.WrongValue), i)
+			break
+		}
+		steps = append(steps, a.setStep(w, t, value, i, len(pkt.Variables) > 1))
+	}
+	for _, b := range batches {
+		if r.err != gosnmp.NoError {
+			break
+		}
+		if status, index, err := b.check(); err != nil {
+			a.logger.Warn("SET value rejected", "request", w.current.id, "table", b.table.entryOID, "error", err)
+			r.fail(r.status(status), index)
+		}
+	}
+
+	if r.err == gosnmp.NoError {
+		a.commitAll(w, &r, steps)
+		a.persist.sync()
+	}
+
+	resp := *pkt
+	resp.PDUType = gosnmp.GetResponse
+	resp.Error, resp.ErrorIndex = r.err, r.errIndex
+	out, err := resp.MarshalMsg()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode response: %w", err)
+	}
+	return out, nil
+}
+
+// setStep 提交阶段的一步，index 为对应变量在请求中的位置（从 0 开始）
+type setStep struct {
+	index   int
+	commit  func() error
+	undo    func() error
+	changes func() []ChangeEvent // 提交后的修改，用于 Watch
+}
+
+// setStep 返回提交 t 的一步，needUndo 为 true 且没有 Undo 回调、或有订阅方关注 t 时在提交前读取旧值
+func (a *Agent) setStep(w *worker, t *setTarget, value interface{}, index int, needUndo bool) setStep {
+	var old interface{}
+	var hasOld, watched bool
+	return setStep{
+		index: index,
+		commit: func() error {
+			watched = a.watches.watched(t.oid)
+			if (needUndo && (t.tx == nil || t.tx.Undo == nil)) || watched {
+				old, hasOld = a.readOld(t)
+			}
+			return a.commitSet(w, t, value)
+		},
+		undo: func() error {
+			return a.undoSet(w, t, old, hasOld)
+		},
+		changes: func() []ChangeEvent {
+			if !watched {
+				return nil
+			}
+			return []ChangeEvent{{OID: t.oid, Type: t.oidType, OldValue: old, NewValue: value}}
+		},
+	}
+}
+
+// readOld 读取 t 提交前的值
+func (a *Agent) readOld(t *setTarget) (interface{}, bool) {
+	if t.handler == nil {
+		return nil, false
+	}
+	v, err := a.guard(t.oid, t.handler)
+	if err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// commitAll 按顺序提交，失败时按相反顺序撤销已提交的步骤，全部成功后通知 Watch 的订阅方
+func (a *Agent) commitAll(w *worker, r *readRequest, steps []setStep) {
+	for i, step := range steps {
+		if err := step.commit(); err != nil {
+			status := gosnmp.CommitFailed
+			for k := i - 1; k >= 0; k-- {
+				if err := steps[k].undo(); err != nil {
+					status = gosnmp.UndoFailed
+				}
+			}
+			r.fail(r.status(status), step.index)
+			return
+		}
+	}
+	var events []ChangeEvent
+	for _, step := range steps {
+		events = append(events, step.changes()...)
+	}
+	a.publishChanges(w, events)
+}
+
+// status 将 v2c 的错误状态转换为 v1 能表示的状态（RFC 3584 4.4）
+func (r *readRequest) status(err gosnmp.SNMPError) gosnmp.SNMPError {
+	if !r.v1 {
+		return err
+	}
+	switch err {
+	case gosnmp.NoCreation, gosnmp.NotWritable, gosnmp.NoAccess, gosnmp.AuthorizationError, gosnmp.InconsistentName:
+		return gosnmp.NoSuchName
+	case gosnmp.WrongType, gosnmp.WrongValue, gosnmp.WrongLength, gosnmp.WrongEncoding, gosnmp.InconsistentValue:
+		return gosnmp.BadValue
+	}
+	return gosnmp.GenErr
+}
+
+// setTypeMatches 判断 SET 的值类型是否符合 OID 的类型，浮点 OID 接受 Opaque 封装的 Float 和 Double
+func setTypeMatches(oidType, valueType gosnmp.Asn1BER) bool {
+	if oidType == valueType {
+		return true
+	}
+	switch oidType {
+	case gosnmp.OpaqueFloat, gosnmp.OpaqueDouble:
+		return valueType == gosnmp.Opaque || valueType == gosnmp.OpaqueFloat || valueType == gosnmp.OpaqueDouble
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package lzsnmp
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"reflect"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// shadowQueueSize 等待影子比对的请求数上限，超出时跳过比对
+const shadowQueueSize = 256
+
+// ShadowConfig 影子模式配置
+type ShadowConfig struct {
+	// Staged 待验证的新版本注册表，可以是未启动的 Agent；为 nil 时关闭影子模式
+	Staged *Agent
+	// CompareValues 为 true 时同时比较变量值；默认只比较错误状态、OID 和类型，避免计数器等动态值产生噪音
+	CompareValues bool
+	// OnMismatch 发现差异时调用，为 nil 时记录 Warn 日志
+	OnMismatch func(m *ShadowMismatch)
+}
+
+// ShadowMismatch 线上与新版本注册表对同一请求的响应差异
+type ShadowMismatch struct {
+	Source  net.Addr
+	PDUType gosnmp.PDUType
+	OIDs    []string // 请求中的 OID
+	Detail  string   // 第一处差异
+}
+
+// shadowJob 等待比对的请求及线上响应
+type shadowJob struct {
+	source   net.Addr
+	pkt      *gosnmp.SnmpPacket
+	packet   []byte
+	response []byte
+}
+
+// shadowRunner 在后台用新版本注册表处理请求副本并比较响应
+type shadowRunner struct {
+	cfg  ShadowConfig
+	w    *worker
+	jobs chan shadowJob
+	done chan struct{}
+}
+
+// SetShadow 开启影子模式：GET/GETNEXT/GETBULK 请求在回复后，再交给 cfg.Staged 处理并比较两份响应，
+// 差异写入日志，用于在切换前用真实轮询流量验证大规模的注册树重构
+//
+// 影子处理在后台进行，不影响线上响应延迟；队列满时跳过比对。SET 请求不会转发给新版本，
+// SNMPv3 请求无法解码响应，也不参与比对。cfg.Staged 为 nil 时关闭影子模式。
+func (a *Agent) SetShadow(cfg ShadowConfig) error {
+	var runner *shadowRunner
+	if cfg.Staged != nil {
+		if cfg.Staged == a {
+			return fmt.Errorf("staged agent must differ from the live agent")
+		}
+		if cfg.Staged.workers == nil {
+			if err := cfg.Staged.prepare(); err != nil {
+				return fmt.Errorf("prepare staged agent: %w", err)
+			}
+		}
+		runner = &shadowRunner{
+			cfg:  cfg,
+			w:    cfg.Staged.workers[0],
+			jobs: make(chan shadowJob, shadowQueueSize),
+			done: make(chan struct{}),
+		}
+		go a.runShadow(runner)
+	}
+
+	a.mu.Lock()
+	old := a.shadow
+	a.shadow = runner
+	a.mu.Unlock()
+
+	if old != nil {
+		close(old.done)
+	}
+	if runner == nil {
+		a.logger.Info("Shadow mode disabled")
+	} else {
+		a.logger.Info("Shadow mode enabled", "compareValues", cfg.CompareValues)
+	}
+	return nil
+}
+
+// shadowRequest 将已回复的请求交给影子模式比对
+func (a *Agent) shadowRequest(source net.Addr, pkt *gosnmp.SnmpPacket, packet, response []byte) {
+	a.mu.RLock()
+	runner := a.shadow
+	a.mu.RUnlock()
+	if runner == nil || pkt == nil || pkt.Version == gosnmp.Version3 {
+		return
+	}
+	switch pkt.PDUType {
+	case gosnmp.GetRequest, gosnmp.GetNextRequest, gosnmp.GetBulkRequest:
+	default:
+		return
+	}
+
+	select {
+	// 请求的缓冲区在发送响应后被复用，比对在其他协程中进行，需要复制
+	case runner.jobs <- shadowJob{source: source, pkt: pkt, packet: bytes.Clone(packet), response: response}:
+	default:
+		a.stats.shadowSkipped.Add(1)
+	}
+}
+
+// runShadow 处理影子比对队列，runner 被替换后返回
+func (a *Agent) runShadow(runner *shadowRunner) {
+	for {
+		select {
+		case <-runner.done:
+			return
+		case job := <-runner.jobs:
+			a.compareShadow(runner, job)
+		}
+	}
+}
+
+// compareShadow 用新版本注册表处理请求并与线上响应比较
+func (a *Agent) compareShadow(runner *shadowRunner, job shadowJob) {
+	defer func() {
+		if r := recover(); r != nil {
+			a.logger.Error("Panic in shadow request", "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+
+	w := runner.w
+	a.syncAccess(w)
+	w.syncOIDs(job.pkt)
+	w.current = requestContext{source: job.source, pkt: job.pkt}
+	staged, err := a.respond(w, job.packet, job.pkt)
+	w.current = requestContext{}
+	w.markGenErr(false)
+	a.stats.shadowCompared.Add(1)
+
+	detail := ""
+	if err != nil || len(staged) == 0 {
+		detail = fmt.Sprintf("staged registry sent no response: %v", err)
+	} else {
+		detail = diffResponses(job.response, staged, runner.cfg.CompareValues)
+	}
+	if detail == "" {
+		return
+	}
+
+	a.stats.shadowMismatches.Add(1)
+	m := &ShadowMismatch{Source: job.source, PDUType: job.pkt.PDUType, Detail: detail}
+	for _, v := range job.pkt.Variables {
+		m.OIDs = append(m.OIDs, v.Name)
+	}
+	if runner.cfg.OnMismatch != nil {
+		runner.cfg.OnMismatch(m)
+		return
+	}
+	a.logger.Warn("Shadow response differs", "from", m.Source, "pdu", m.PDUType, "oids", strings.Join(m.OIDs, ","), "diff", m.Detail)
+}
+
+// diffResponses 比较两个响应报文，返回第一处差异，相同时返回空字符串
+func diffResponses(live, staged []byte, compareValues bool) string {
+	decode := func(b []byte) (*gosnmp.SnmpPacket, error) {
+		decoder := gosnmp.GoSNMP{SecurityParameters: &gosnmp.UsmSecurityParameters{}}
+		return decoder.SnmpDecodePacket(b)
+	}
+	l, err := decode(live)
+	if err != nil {
+		return fmt.Sprintf("decode live response: %v", err)
+	}
+	s, err := decode(staged)
+	if err != nil {
+		return fmt.Sprintf("decode staged response: %v", err)
+	}
+
+	if l.Error != s.Error || l.ErrorIndex != s.ErrorIndex {
+		return fmt.Sprintf("error-status live=%s/%d staged=%s/%d", l.Error, l.ErrorIndex, s.Error, s.ErrorIndex)
+	}
+	if len(l.Variables) != len(s.Variables) {
+		return fmt.Sprintf("varbinds live=%d staged=%d", len(l.Variables), len(s.Variables))
+	}
+	for i := range l.Variables {
+		lv, sv := l.Variables[i], s.Variables[i]
+		switch {
+		case lv.Name != sv.Name:
+			return fmt.Sprintf("varbind %d oid live=%s staged=%s", i+1, lv.Name, sv.Name)
+		case lv.Type != sv.Type:
+			return fmt.Sprintf("%s type live=%s staged=%s", lv.Name, lv.Type, sv.Type)
+		case compareValues && !reflect.DeepEqual(lv.Value, sv.Value):
+			return fmt.Sprintf("%s value live=%v staged=%v", lv.Name, lv.Value, sv.Value)
+		}
+	}
+	return ""
+}
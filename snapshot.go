@@ -0,0 +1,113 @@
+package lzsnmp
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// Snapshot 返回所有已注册 OID 及其当前值，可以 JSON 序列化后交给 Restore
+//
+// 与对整个注册表调用 ExportSubtree 相同：动态 OID 调用一次处理函数，出错的 OID 被跳过。
+func (a *Agent) Snapshot() (*Subtree, error) {
+	return a.exportSubtree("")
+}
+
+// Restore 用快照中的值预填充 Agent，返回恢复的 OID 数量，用于蓝绿重启和测试夹具
+//
+// 与 ImportSubtree 不同，Restore 保留已注册的处理函数：可写 OID 用快照中的值调用一次 setter，
+// 静态 OID 替换为快照中的值，未注册的 OID 注册为静态值；只读的动态 OID、类型不一致的 OID
+// 和位于表格等受管子树中的 OID 被跳过。快照中的值格式错误时返回错误且不做任何修改。
+func (a *Agent) Restore(snapshot *Subtree) (int, error) {
+	if snapshot == nil {
+		return 0, fmt.Errorf("snapshot is required")
+	}
+
+	type parsed struct {
+		oid     string
+		oidType gosnmp.Asn1BER
+		value   interface{}
+		meta    OIDMeta
+	}
+	entries := make([]parsed, 0, len(snapshot.Entries))
+	for _, e := range snapshot.Entries {
+		oid, err := normalizeOID(e.OID)
+		if err != nil {
+			return 0, fmt.Errorf("entry %s: %w", e.OID, err)
+		}
+		oidType, err := ParseType(e.Type)
+		if err != nil {
+			return 0, fmt.Errorf("entry %s: %w", e.OID, err)
+		}
+		value, err := parseValueText(oidType, e.Value, e.Hex)
+		if err != nil {
+			return 0, fmt.Errorf("entry %s: %w", e.OID, err)
+		}
+		entries = append(entries, parsed{oid: oid, oidType: oidType, value: value, meta: OIDMeta{Name: e.Name, Description: e.Description, Units: e.Units}})
+	}
+
+	type pendingSet struct {
+		oid     string
+		oidType gosnmp.Asn1BER
+		value   interface{}
+		setter  SetHandler
+	}
+	var sets []pendingSet
+	static, skipped := 0, 0
+	a.updateStore(func(s *oidStore) error {
+		base := a.store.Load()
+		for _, e := range entries {
+			oidType, registered := s.types[e.oid]
+			switch {
+			case registered && oidType != e.oidType:
+				a.logger.Warn("Snapshot type mismatch, skipping", "oid", e.oid, "snapshot", e.oidType, "registered", oidType)
+				skipped++
+			case s.setters[e.oid] != nil:
+				sets = append(sets, pendingSet{oid: e.oid, oidType: e.oidType, value: setValue(e.oidType, e.value), setter: s.setters[e.oid]})
+			case s.handlers[e.oid] != nil:
+				skipped++
+			default:
+				if !registered {
+					var overlap *OverlapError
+					if err := s.checkLeaf(base, e.oid); errors.As(err, &overlap) {
+						a.logger.Debug("Snapshot entry overlaps registered OID, skipping", "oid", e.oid, "existing", overlap.Existing)
+						skipped++
+						continue
+					}
+				}
+				s.putStatic(e.oid, e.oidType, e.value)
+				a.persist.track(e.oid, e.oidType, e.value)
+				if _, ok := a.meta[e.oid]; !ok && e.meta != (OIDMeta{}) {
+					a.meta[e.oid] = e.meta
+				}
+				static++
+			}
+		}
+		return nil
+	})
+
+	// setter 可能注册 OID，不能在 updateStore 中调用
+	restored := static
+	for _, p := range sets {
+		if err := p.setter(p.value); err != nil {
+			a.logger.Warn("Failed to restore writable OID", "oid", p.oid, "error", err)
+			skipped++
+			continue
+		}
+		a.persist.record(p.oid, p.oidType, p.value)
+		restored++
+	}
+	a.persist.sync()
+
+	a.logger.Info("Restored snapshot", "restored", restored, "static", static, "writable", restored-static, "skipped", skipped)
+	return restored, nil
+}
+
+// setValue 将解析出的值转换为 SET 请求传给 setter 的形式，OctetString 为 []byte
+func setValue(oidType gosnmp.Asn1BER, value interface{}) interface{} {
+	if s, ok := value.(string); ok && (oidType == gosnmp.OctetString || oidType == gosnmp.Opaque) {
+		return []byte(s)
+	}
+	return value
+}
@@ -0,0 +1,320 @@
+package lzsnmp
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// snmprecTags snmpsim .snmprec 文件中的 BER 标签，Null 和 noSuchObject 等异常值不在其中，加载时跳过
+var snmprecTags = map[int]gosnmp.Asn1BER{
+	2:  gosnmp.Integer,
+	4:  gosnmp.OctetString,
+	6:  gosnmp.ObjectIdentifier,
+	64: gosnmp.IPAddress,
+	65: gosnmp.Counter32,
+	66: gosnmp.Gauge32,
+	67: gosnmp.TimeTicks,
+	68: gosnmp.Opaque,
+	70: gosnmp.Counter64,
+}
+
+// snmprecSkipped 没有值的标签：Null、noSuchObject、noSuchInstance、endOfMibView
+var snmprecSkipped = map[int]bool{5: true, 128: true, 129: true, 130: true}
+
+// LoadSimulationFile 读取设备录制数据并导入为静态 OID，返回导入的 OID 数量
+//
+// 扩展名为 .snmprec 时按 snmpsim 格式解析，其他按 snmpwalk -On 的输出解析。
+// 与 ImportSubtree 相同，opts.Root 可以把数据导入到其他位置，opts.Replace 先清空目标子树。
+func (a *Agent) LoadSimulationFile(path string, opts ImportOptions) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	parse := ParseSnmpwalk
+	if strings.EqualFold(filepath.Ext(path), ".snmprec") {
+		parse = ParseSnmprec
+	}
+	subtree, err := parse(f)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+	return a.ImportSubtree(subtree, opts)
+}
+
+// ParseSnmprec 解析 snmpsim 的 .snmprec 数据，每行为 OID|标签|值，返回可以 ImportSubtree 的子树
+//
+// 标签带 x 后缀时值为十六进制；Null 和 noSuchObject 等异常值被跳过；
+// 带变化模块（如 2:numeric）的行无法还原为静态值，返回错误。
+func ParseSnmprec(r io.Reader) (*Subtree, error) {
+	var entries []SubtreeEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		entry, ok, err := parseSnmprecLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if ok {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return newParsedSubtree(entries)
+}
+
+// parseSnmprecLine 解析一行 .snmprec 数据，值为异常值时 ok 为 false
+func parseSnmprecLine(text string) (entry SubtreeEntry, ok bool, err error) {
+	fields := strings.SplitN(text, "|", 3)
+	if len(fields) != 3 {
+		return entry, false, fmt.Errorf("expected OID|tag|value, got %q", text)
+	}
+	oid, err := normalizeOID(strings.TrimSpace(fields[0]))
+	if err != nil {
+		return entry, false, err
+	}
+
+	tag, module, _ := strings.Cut(strings.TrimSpace(fields[1]), ":")
+	if module != "" {
+		return entry, false, fmt.Errorf("variation module %q is not supported for OID %s", module, oid)
+	}
+	isHex := strings.HasSuffix(tag, "x")
+	n, err := strconv.Atoi(strings.TrimSuffix(tag, "x"))
+	if err != nil {
+		return entry, false, fmt.Errorf("invalid tag %q for OID %s", fields[1], oid)
+	}
+	if snmprecSkipped[n] {
+		return entry, false, nil
+	}
+	oidType, known := snmprecTags[n]
+	if !known {
+		return entry, false, fmt.Errorf("unsupported tag %d for OID %s", n, oid)
+	}
+
+	value := fields[2]
+	if isHex {
+		switch oidType {
+		case gosnmp.OctetString, gosnmp.Opaque:
+			if _, err := hex.DecodeString(value); err != nil {
+				return entry, false, fmt.Errorf("invalid hex value for OID %s: %w", oid, err)
+			}
+		case gosnmp.IPAddress:
+			b, err := hex.DecodeString(value)
+			if err != nil || len(b) != net.IPv4len {
+				return entry, false, fmt.Errorf("invalid hex IP address %q for OID %s", value, oid)
+			}
+			value, isHex = net.IP(b).String(), false
+		default:
+			return entry, false, fmt.Errorf("hex value is not supported for tag %d, OID %s", n, oid)
+		}
+	}
+	return SubtreeEntry{OID: oid, Type: oidType.String(), Value: value, Hex: isHex}, true, nil
+}
+
+// ParseSnmpwalk 解析 snmpwalk 的输出，返回可以 ImportSubtree 的子树
+//
+// OID 需要是数字形式（snmpwalk -On，或未加载 MIB 时的 iso.3.6.1...），支持跨行的 STRING 和 Hex-STRING；
+// INTEGER 的枚举名（如 up(1)）和 Timeticks 的可读格式取括号中的数值；NULL 和 No Such Object 等被跳过。
+func ParseSnmpwalk(r io.Reader) (*Subtree, error) {
+	type record struct {
+		line int
+		text string
+	}
+	var records []record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if isSnmpwalkLine(text) {
+			records = append(records, record{line, text})
+		} else if len(records) > 0 {
+			// 多行的 STRING 和 Hex-STRING
+			records[len(records)-1].text += "\n" + text
+		} else if strings.TrimSpace(text) != "" {
+			return nil, fmt.Errorf("line %d: expected OID = TYPE: VALUE, got %q", line, text)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	entries := make([]SubtreeEntry, 0, len(records))
+	for _, rec := range records {
+		entry, ok, err := parseSnmpwalkRecord(rec.text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", rec.line, err)
+		}
+		if ok {
+			entries = append(entries, entry)
+		}
+	}
+	return newParsedSubtree(entries)
+}
+
+// isSnmpwalkLine 判断一行是否为新变量的开始，而不是上一个值的续行
+func isSnmpwalkLine(text string) bool {
+	left, _, found := strings.Cut(text, " = ")
+	if !found || left == "" || strings.ContainsAny(left, " \t\"") {
+		return false
+	}
+	return left[0] == '.' || unicode.IsDigit(rune(left[0])) || strings.HasPrefix(left, "iso.") || strings.Contains(left, "::")
+}
+
+// parseSnmpwalkRecord 解析一个变量，值为 NULL 或异常值时 ok 为 false
+func parseSnmpwalkRecord(text string) (entry SubtreeEntry, ok bool, err error) {
+	left, right, _ := strings.Cut(text, " = ")
+	oid, err := numericWalkOID(left)
+	if err != nil {
+		return entry, false, err
+	}
+
+	if strings.TrimSpace(right) == `""` {
+		return SubtreeEntry{OID: oid, Type: gosnmp.OctetString.String()}, true, nil
+	}
+	kind, value, found := strings.Cut(right, ": ")
+	if !found {
+		// NULL、No Such Object available on this agent at this OID 等
+		return entry, false, nil
+	}
+
+	entry = SubtreeEntry{OID: oid}
+	var oidType gosnmp.Asn1BER
+	switch kind {
+	case "STRING":
+		oidType, entry.Value = gosnmp.OctetString, unquoteWalkString(value)
+	case "Hex-STRING", "BITS":
+		oidType, entry.Hex = gosnmp.OctetString, true
+		if entry.Value, err = walkHex(value); err != nil {
+			return entry, false, fmt.Errorf("%s: %w", oid, err)
+		}
+	case "INTEGER":
+		oidType, entry.Value = gosnmp.Integer, walkNumber(value)
+	case "Counter32":
+		oidType, entry.Value = gosnmp.Counter32, walkNumber(value)
+	case "Gauge32", "Unsigned32":
+		oidType, entry.Value = gosnmp.Gauge32, walkNumber(value)
+	case "UInteger32":
+		oidType, entry.Value = gosnmp.Uinteger32, walkNumber(value)
+	case "Counter64":
+		oidType, entry.Value = gosnmp.Counter64, walkNumber(value)
+	case "Timeticks":
+		oidType, entry.Value = gosnmp.TimeTicks, walkNumber(value)
+	case "IpAddress":
+		oidType, entry.Value = gosnmp.IPAddress, strings.TrimSpace(value)
+	case "OID":
+		oidType = gosnmp.ObjectIdentifier
+		if entry.Value, err = numericWalkOID(strings.TrimSpace(value)); err != nil {
+			return entry, false, err
+		}
+	case "Opaque":
+		switch sub, v, _ := strings.Cut(value, ": "); sub {
+		case "Float":
+			oidType, entry.Value = gosnmp.OpaqueFloat, strings.TrimSpace(v)
+		case "Double":
+			oidType, entry.Value = gosnmp.OpaqueDouble, strings.TrimSpace(v)
+		default:
+			oidType, entry.Hex = gosnmp.Opaque, true
+			if entry.Value, err = walkHex(value); err != nil {
+				return entry, false, fmt.Errorf("%s: %w", oid, err)
+			}
+		}
+	default:
+		return entry, false, fmt.Errorf("unsupported type %q for OID %s", kind, oid)
+	}
+	entry.Type = oidType.String()
+	return entry, true, nil
+}
+
+// numericWalkOID 将 snmpwalk 输出的 OID 转换为数字形式，iso 开头的视为 1
+func numericWalkOID(text string) (string, error) {
+	if rest, ok := strings.CutPrefix(text, "iso."); ok {
+		text = "1." + rest
+	}
+	oid, err := normalizeOID(text)
+	if err != nil {
+		return "", fmt.Errorf("OID %q is not numeric, use snmpwalk -On: %w", text, err)
+	}
+	return oid, nil
+}
+
+// unquoteWalkString 去掉 STRING 值两端的引号并还原转义的引号和反斜杠
+func unquoteWalkString(value string) string {
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		value = value[1 : len(value)-1]
+		value = strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(value)
+	}
+	return value
+}
+
+// walkHex 将空格分隔的十六进制字节（可跨行）转换为连续的十六进制文本，忽略 BITS 值后面的位名称
+func walkHex(value string) (string, error) {
+	var b strings.Builder
+	for _, field := range strings.Fields(value) {
+		if len(field) != 2 {
+			break
+		}
+		if _, err := hex.DecodeString(field); err != nil {
+			break
+		}
+		b.WriteString(strings.ToLower(field))
+	}
+	if b.Len() == 0 && strings.TrimSpace(value) != "" {
+		return "", fmt.Errorf("invalid hex value %q", value)
+	}
+	return b.String(), nil
+}
+
+// walkNumber 取出数值：枚举 up(1) 和 Timeticks (12345) 0:02:03.45 取括号中的值，"42 seconds" 取第一个字段
+func walkNumber(value string) string {
+	value = strings.TrimSpace(value)
+	if open := strings.IndexByte(value, '('); open >= 0 {
+		if end := strings.IndexByte(value[open:], ')'); end > 0 {
+			return value[open+1 : open+end]
+		}
+	}
+	if fields := strings.Fields(value); len(fields) > 0 {
+		return fields[0]
+	}
+	return value
+}
+
+// newParsedSubtree 由解析出的变量创建子树，根为所有 OID 的最长公共前缀
+func newParsedSubtree(entries []SubtreeEntry) (*Subtree, error) {
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no variables found")
+	}
+	root := strings.Split(entries[0].OID, ".")
+	for _, e := range entries {
+		arcs := strings.Split(e.OID, ".")
+		// 根需要比每个 OID 都短，ImportOptions.Root 才能重新定位
+		n := min(len(root), len(arcs)-1)
+		for i := 0; i < n; i++ {
+			if root[i] != arcs[i] {
+				n = i
+				break
+			}
+		}
+		root = root[:n]
+	}
+	if len(root) == 0 {
+		return nil, fmt.Errorf("variables do not share a common root OID")
+	}
+	return &Subtree{Root: strings.Join(root, "."), Entries: entries}, nil
+}
@@ -0,0 +1,292 @@
+//go:build !lzsnmp_nosql
+
+package lzsnmp
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// SQLColumn SQL 表格的一列
+type SQLColumn struct {
+	ID   int            // 列号
+	Name string         // 结果集中的列名（不区分大小写）
+	Type gosnmp.Asn1BER // 单元格按该类型转换
+}
+
+// SQLTable SQL 查询结果到 SNMP 表格的映射
+type SQLTable struct {
+	Query    string        // 查询语句
+	Args     []interface{} // 查询参数
+	Index    []string      // 组成行索引的列名，按顺序编码；为空时使用行号（从 1 开始）
+	Columns  []SQLColumn
+	Interval time.Duration // 刷新间隔，默认 30 秒
+	Timeout  time.Duration // 单次查询超时，默认 10 秒
+}
+
+// SQLProvider 由 RegisterSQLTable 创建的表格
+type SQLProvider struct {
+	agent    *Agent
+	entryOID string
+	db       *sql.DB
+	table    SQLTable
+
+	mu        sync.Mutex
+	values    map[string]interface{}
+	instances []string
+	stop      chan struct{}
+}
+
+// RegisterSQLTable 按 table.Interval 定期执行 table.Query，并将结果映射为相对 OID 下的表格
+//
+// 实例 OID 为 {relativeOID}.1.{列号}.{索引}。索引列的整数值直接作为 OID 分量，字符串按 SMI 规则编码（长度 + 字节）。
+// GET 返回最近一次查询的结果，不会触发查询；查询失败时保留上一次的结果。NULL 单元格不导出。
+func (a *Agent) RegisterSQLTable(relativeOID string, db *sql.DB, table SQLTable) (*SQLProvider, error) {
+	if db == nil {
+		return nil, fmt.Errorf("sql db is required")
+	}
+	if table.Query == "" {
+		return nil, fmt.Errorf("sql query is required")
+	}
+	if len(table.Columns) == 0 {
+		return nil, fmt.Errorf("sql table needs at least one column")
+	}
+	seen := make(map[int]bool, len(table.Columns))
+	for _, col := range table.Columns {
+		if col.ID <= 0 || seen[col.ID] {
+			return nil, fmt.Errorf("invalid or duplicate sql column id: %d", col.ID)
+		}
+		seen[col.ID] = true
+		if col.Name == "" {
+			return nil, fmt.Errorf("sql column %d needs a name", col.ID)
+		}
+	}
+	if table.Interval <= 0 {
+		table.Interval = 30 * time.Second
+	}
+	if table.Timeout <= 0 {
+		table.Timeout = 10 * time.Second
+	}
+
+	p := &SQLProvider{
+		agent:    a,
+		entryOID: fmt.Sprintf("%s.%s.1", a.oidPrefix, strings.Trim(relativeOID, ".")),
+		db:       db,
+		table:    table,
+		stop:     make(chan struct{}),
+	}
+	if err := a.claimSubtree(parentOID(p.entryOID), "sql table"); err != nil {
+		return nil, err
+	}
+	if err := p.Refresh(); err != nil {
+		return nil, err
+	}
+	go p.refreshLoop()
+
+	a.logger.Info("Registered sql table", "oid", p.entryOID, "instances", len(p.instances), "interval", table.Interval)
+	return p, nil
+}
+
+// Refresh 立即执行一次查询并在实例集合变化时重建实例 OID
+func (p *SQLProvider) Refresh() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.refreshLocked()
+}
+
+// Close 停止定期查询并注销表格中的实例，不关闭 db
+func (p *SQLProvider) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stop == nil {
+		return
+	}
+	close(p.stop)
+	p.stop = nil
+
+	p.agent.replaceDynamic(p.instances, nil)
+	p.instances = nil
+}
+
+func (p *SQLProvider) refreshLoop() {
+	p.mu.Lock()
+	stop := p.stop
+	p.mu.Unlock()
+
+	ticker := time.NewTicker(p.table.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			var err error
+			if p.stop != nil {
+				err = p.refreshLocked()
+			}
+			p.mu.Unlock()
+			if err != nil {
+				p.agent.logger.Error("SQL table refresh failed, keeping previous rows", "oid", p.entryOID, "error", err)
+			}
+		}
+	}
+}
+
+func (p *SQLProvider) refreshLocked() error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.table.Timeout)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, p.table.Query, p.table.Args...)
+	if err != nil {
+		return fmt.Errorf("sql query: %w", err)
+	}
+	defer rows.Close()
+
+	names, err := rows.Columns()
+	if err != nil {
+		return fmt.Errorf("sql query: %w", err)
+	}
+	position := func(name string) (int, error) {
+		for i, n := range names {
+			if strings.EqualFold(n, name) {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("sql result has no column %q", name)
+	}
+	indexPos := make([]int, len(p.table.Index))
+	for i, name := range p.table.Index {
+		if indexPos[i], err = position(name); err != nil {
+			return err
+		}
+	}
+	columnPos := make([]int, len(p.table.Columns))
+	for i, col := range p.table.Columns {
+		if columnPos[i], err = position(col.Name); err != nil {
+			return err
+		}
+	}
+
+	values := make(map[string]interface{})
+	types := make(map[string]gosnmp.Asn1BER)
+	cells := make([]interface{}, len(names))
+	dest := make([]interface{}, len(names))
+	for i := range cells {
+		dest[i] = &cells[i]
+	}
+	seen := make(map[string]bool)
+	for n := 0; rows.Next(); n++ {
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("sql scan: %w", err)
+		}
+		index, err := p.rowIndex(cells, indexPos, n)
+		if err != nil {
+			p.agent.logger.Warn("Skipping sql row", "oid", p.entryOID, "row", n+1, "error", err)
+			continue
+		}
+		if seen[index] {
+			return fmt.Errorf("sql table %s: duplicate row index %s", p.entryOID, index)
+		}
+		seen[index] = true
+
+		for i, col := range p.table.Columns {
+			cell := cells[columnPos[i]]
+			if cell == nil {
+				continue
+			}
+			value, err := sqlCellValue(col.Type, cell)
+			if err != nil {
+				p.agent.logger.Debug("Skipping sql cell", "column", col.Name, "row", n+1, "error", err)
+				continue
+			}
+			oid := fmt.Sprintf("%s.%d.%s", p.entryOID, col.ID, index)
+			values[oid], types[oid] = value, col.Type
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("sql rows: %w", err)
+	}
+
+	p.values = values
+
+	instances := make([]string, 0, len(values))
+	for oid := range values {
+		instances = append(instances, oid)
+	}
+	sort.Strings(instances)
+	if slices.Equal(p.instances, instances) {
+		return nil
+	}
+
+	add := make(map[string]dynamicOID, len(instances))
+	for _, oid := range instances {
+		add[oid] = dynamicOID{Type: types[oid], Handler: p.getter(oid)}
+	}
+	p.agent.replaceDynamic(p.instances, add)
+	p.instances = instances
+	return nil
+}
+
+// rowIndex 返回行的实例索引
+func (p *SQLProvider) rowIndex(cells []interface{}, indexPos []int, n int) (string, error) {
+	if len(indexPos) == 0 {
+		return strconv.Itoa(n + 1), nil
+	}
+	parts := make([]string, 0, len(indexPos))
+	for i, pos := range indexPos {
+		cell := cells[pos]
+		if cell == nil {
+			return "", fmt.Errorf("index column %s is NULL", p.table.Index[i])
+		}
+		part, err := encodeIndexValue(reflect.ValueOf(cell), false)
+		if err != nil {
+			return "", fmt.Errorf("index column %s: %w", p.table.Index[i], err)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "."), nil
+}
+
+func (p *SQLProvider) getter(oid string) ValueHandler {
+	return func() (interface{}, error) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		value, ok := p.values[oid]
+		if !ok {
+			return nil, fmt.Errorf("sql row %s no longer exists", oid)
+		}
+		return value, nil
+	}
+}
+
+// sqlCellValue 将驱动返回的单元格转换为 oidType 对应的值
+func sqlCellValue(oidType gosnmp.Asn1BER, cell interface{}) (interface{}, error) {
+	switch v := cell.(type) {
+	case []byte:
+		if oidType == gosnmp.OctetString || oidType == gosnmp.Opaque {
+			return slices.Clone(v), nil
+		}
+		return parseValueText(oidType, string(v), false)
+	case string:
+		return parseValueText(oidType, v, false)
+	case bool:
+		return TruthValue(v), nil
+	case time.Time:
+		if oidType == gosnmp.OctetString {
+			return v.Format(time.RFC3339), nil
+		}
+		return nil, fmt.Errorf("time value cannot be converted to %s", oidType)
+	}
+	return normalizeValue(oidType, cell)
+}
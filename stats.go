@@ -0,0 +1,328 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// latencyWindowSize 计算处理耗时分位数时保留的最近样本数
+const latencyWindowSize = 1024
+
+// snmpGroupOID SNMPv2-MIB snmp 组
+const snmpGroupOID = "1.3.6.1.2.1.11"
+
+// Stats Agent 内部计数器快照
+type Stats struct {
+	InPkts              uint64 // 收到的报文
+	OutPkts             uint64 // 发出的报文
+	InPktsIPv4          uint64 // 来自 IPv4 地址（含双栈 socket 上的 IPv4 映射地址）的报文
+	InPktsIPv6          uint64 // 来自 IPv6 地址的报文
+	OutPktsIPv4         uint64
+	OutPktsIPv6         uint64
+	InBadVersions       uint64 // 不支持的 SNMP 版本
+	InBadCommunityNames uint64 // 未知 community（认证失败）
+	InASNParseErrs      uint64 // 报文解码失败
+	InGetRequests       uint64
+	InGetNexts          uint64
+	InGetBulks          uint64
+	InSetRequests       uint64
+	InTotalReqVars      uint64 // GET/GETNEXT/GETBULK 请求中的变量数
+	InTotalSetVars      uint64 // SET 请求中的变量数
+	OutGetResponses     uint64
+	SilentDrops         uint64 // 未能回复的请求
+	InDuplicates        uint64 // 管理端重传的请求（同一来源的相同请求 ID）
+	InDuplicatesSlow    uint64 // 原请求处理超过 1 秒的重传，其余重传通常由网络丢包导致
+	HandlerCalls        uint64 // 处理函数调用次数
+	HandlerErrors       uint64 // 处理函数返回错误的次数（含 panic）
+	HandlerPanics       uint64 // 处理函数 panic 的次数
+	HandlerCoalesced    uint64 // 合并到其他请求、未调用处理函数的 GET（Coalesce）
+	InOverloadDrops     uint64 // 所有 worker 繁忙且队列已满时丢弃的请求（DropWhenBusy）
+	RequestsQueued      int64  // 等待 worker 处理的请求数
+	RequestsInFlight    int64  // worker 正在处理的请求数
+	ShadowCompared      uint64 // 影子模式比对的请求数
+	ShadowMismatches    uint64 // 影子模式中响应不一致的请求数
+	ShadowSkipped       uint64 // 影子模式队列满而跳过比对的请求数
+	HandlerTime         time.Duration
+	HandlerLatencyP50   time.Duration // 最近 1024 次调用的耗时分位数
+	HandlerLatencyP90   time.Duration
+	HandlerLatencyP99   time.Duration
+	OIDs                []OIDStats // 每个 OID 的处理函数调用统计，按累计耗时从大到小排序
+}
+
+// agentStats Agent 内部计数器
+type agentStats struct {
+	inPkts              atomic.Uint64
+	outPkts             atomic.Uint64
+	inPktsIPv4          atomic.Uint64
+	inPktsIPv6          atomic.Uint64
+	outPktsIPv4         atomic.Uint64
+	outPktsIPv6         atomic.Uint64
+	inBadVersions       atomic.Uint64
+	inBadCommunityNames atomic.Uint64
+	inASNParseErrs      atomic.Uint64
+	inGetRequests       atomic.Uint64
+	inGetNexts          atomic.Uint64
+	inGetBulks          atomic.Uint64
+	inSetRequests       atomic.Uint64
+	inTotalReqVars      atomic.Uint64
+	inTotalSetVars      atomic.Uint64
+	outGetResponses     atomic.Uint64
+	silentDrops         atomic.Uint64
+	inDuplicates        atomic.Uint64
+	inDuplicatesSlow    atomic.Uint64
+	handlerCalls        atomic.Uint64
+	handlerErrors       atomic.Uint64
+	handlerPanics       atomic.Uint64
+	coalescedCalls      atomic.Uint64
+	handlerNanos        atomic.Uint64
+	overloadDrops       atomic.Uint64
+	queued              atomic.Int64
+	inFlight            atomic.Int64
+	shadowCompared      atomic.Uint64
+	shadowMismatches    atomic.Uint64
+	shadowSkipped       atomic.Uint64
+
+	duplicates duplicateTracker
+	oids       oidMetricsMap
+
+	mu      sync.Mutex
+	latency [latencyWindowSize]time.Duration
+	next    int
+	filled  bool
+}
+
+// countIn 记录一个来自 addr 的报文
+func (s *agentStats) countIn(addr net.Addr) {
+	s.inPkts.Add(1)
+	switch addressFamily(addr) {
+	case 4:
+		s.inPktsIPv4.Add(1)
+	case 6:
+		s.inPktsIPv6.Add(1)
+	}
+}
+
+// countOut 记录一个发往 addr 的报文
+func (s *agentStats) countOut(addr net.Addr) {
+	s.outPkts.Add(1)
+	switch addressFamily(addr) {
+	case 4:
+		s.outPktsIPv4.Add(1)
+	case 6:
+		s.outPktsIPv6.Add(1)
+	}
+}
+
+// addressFamily 返回 UDP 地址的协议族（4 或 6），IPv4 映射地址按 IPv4 计，非 IP 地址返回 0
+func addressFamily(addr net.Addr) int {
+	udp, ok := addr.(*net.UDPAddr)
+	switch {
+	case !ok || udp.IP == nil:
+		return 0
+	case udp.IP.To4() != nil:
+		return 4
+	}
+	return 6
+}
+
+// observeRequest 按解码后的请求更新计数器
+func (s *agentStats) observeRequest(pkt *gosnmp.SnmpPacket) {
+	vars := uint64(len(pkt.Variables))
+	switch pkt.PDUType {
+	case gosnmp.GetRequest:
+		s.inGetRequests.Add(1)
+		s.inTotalReqVars.Add(vars)
+	case gosnmp.GetNextRequest:
+		s.inGetNexts.Add(1)
+		s.inTotalReqVars.Add(vars)
+	case gosnmp.GetBulkRequest:
+		s.inGetBulks.Add(1)
+		s.inTotalReqVars.Add(vars)
+	case gosnmp.SetRequest:
+		s.inSetRequests.Add(1)
+		s.inTotalSetVars.Add(vars)
+	}
+}
+
+// observeHandler 记录 oid 的一次处理函数调用
+func (s *agentStats) observeHandler(oid string, elapsed time.Duration, err error) {
+	s.oids.observe(oid, elapsed, err)
+	s.handlerCalls.Add(1)
+	s.handlerNanos.Add(uint64(elapsed))
+	if err != nil {
+		s.handlerErrors.Add(1)
+	}
+
+	s.mu.Lock()
+	s.latency[s.next] = elapsed
+	s.next = (s.next + 1) % latencyWindowSize
+	if s.next == 0 {
+		s.filled = true
+	}
+	s.mu.Unlock()
+}
+
+// quantiles 返回最近样本的耗时分位数
+func (s *agentStats) quantiles(qs ...float64) []time.Duration {
+	s.mu.Lock()
+	n := s.next
+	if s.filled {
+		n = latencyWindowSize
+	}
+	samples := make([]time.Duration, n)
+	copy(samples, s.latency[:n])
+	s.mu.Unlock()
+
+	out := make([]time.Duration, len(qs))
+	if n == 0 {
+		return out
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	for i, q := range qs {
+		out[i] = samples[int(q*float64(n-1)+0.5)]
+	}
+	return out
+}
+
+func (s *agentStats) snapshot() Stats {
+	q := s.quantiles(0.5, 0.9, 0.99)
+	return Stats{
+		InPkts:              s.inPkts.Load(),
+		OutPkts:             s.outPkts.Load(),
+		InPktsIPv4:          s.inPktsIPv4.Load(),
+		InPktsIPv6:          s.inPktsIPv6.Load(),
+		OutPktsIPv4:         s.outPktsIPv4.Load(),
+		OutPktsIPv6:         s.outPktsIPv6.Load(),
+		InBadVersions:       s.inBadVersions.Load(),
+		InBadCommunityNames: s.inBadCommunityNames.Load(),
+		InASNParseErrs:      s.inASNParseErrs.Load(),
+		InGetRequests:       s.inGetRequests.Load(),
+		InGetNexts:          s.inGetNexts.Load(),
+		InGetBulks:          s.inGetBulks.Load(),
+		InSetRequests:       s.inSetRequests.Load(),
+		InTotalReqVars:      s.inTotalReqVars.Load(),
+		InTotalSetVars:      s.inTotalSetVars.Load(),
+		OutGetResponses:     s.outGetResponses.Load(),
+		SilentDrops:         s.silentDrops.Load(),
+		InDuplicates:        s.inDuplicates.Load(),
+		InDuplicatesSlow:    s.inDuplicatesSlow.Load(),
+		HandlerCalls:        s.handlerCalls.Load(),
+		HandlerErrors:       s.handlerErrors.Load(),
+		HandlerPanics:       s.handlerPanics.Load(),
+		HandlerCoalesced:    s.coalescedCalls.Load(),
+		InOverloadDrops:     s.overloadDrops.Load(),
+		RequestsQueued:      s.queued.Load(),
+		RequestsInFlight:    s.inFlight.Load(),
+		ShadowCompared:      s.shadowCompared.Load(),
+		ShadowMismatches:    s.shadowMismatches.Load(),
+		ShadowSkipped:       s.shadowSkipped.Load(),
+		HandlerTime:         time.Duration(s.handlerNanos.Load()),
+		HandlerLatencyP50:   q[0],
+		HandlerLatencyP90:   q[1],
+		HandlerLatencyP99:   q[2],
+	}
+}
+
+// Stats 返回 Agent 内部计数器快照，包括每个 OID 的调用统计
+func (a *Agent) Stats() Stats {
+	s := a.stats.snapshot()
+	s.OIDs = a.stats.oids.snapshot(a.store.Load())
+	return s
+}
+
+// statsOID 一个计数器 OID
+type statsOID struct {
+	oid     string
+	name    string
+	oidType gosnmp.Asn1BER
+	value   func(Stats) interface{}
+}
+
+// RegisterStats 将内部计数器注册为 OID
+//
+// 标准计数器注册在 SNMPv2-MIB snmp 组（1.3.6.1.2.1.11）下，均为 Counter32；
+// SNMPv2-MIB 没有定义的计数器注册在相对 OID prefix 下：
+//   - prefix.1.0: GETBULK 请求数 (Counter64)
+//   - prefix.2.0: 处理函数调用次数 (Counter64)
+//   - prefix.3.0: 处理函数错误次数 (Counter64)
+//   - prefix.4.0 / 5.0 / 6.0: 处理耗时 P50 / P90 / P99，单位微秒 (Gauge32)
+//   - prefix.7.0: 重传请求数 (Counter64)
+//   - prefix.8.0: 原请求处理过慢导致的重传数 (Counter64)
+//   - prefix.9.0: 处理函数 panic 次数 (Counter64)
+//   - prefix.10.0: 过载丢弃的请求数 (Counter64)
+//   - prefix.11.0 / 12.0: 排队 / 正在处理的请求数 (Gauge32)
+func (a *Agent) RegisterStats(prefix string) error {
+	if prefix == "" {
+		return fmt.Errorf("stats prefix is required")
+	}
+	root := fmt.Sprintf("%s.%s", a.oidPrefix, prefix)
+
+	counter32 := func(f func(Stats) uint64) func(Stats) interface{} {
+		return func(s Stats) interface{} { return uint(uint32(f(s))) }
+	}
+	counter64 := func(f func(Stats) uint64) func(Stats) interface{} {
+		return func(s Stats) interface{} { return f(s) }
+	}
+	gauge := func(f func(Stats) int64) func(Stats) interface{} {
+		return func(s Stats) interface{} { return uint(clampUint32(float64(f(s)))) }
+	}
+	micros := func(f func(Stats) time.Duration) func(Stats) interface{} {
+		return func(s Stats) interface{} { return uint(clampUint32(float64(f(s).Microseconds()))) }
+	}
+
+	objects := []statsOID{
+		{snmpGroupOID + ".1.0", "snmpInPkts", gosnmp.Counter32, counter32(func(s Stats) uint64 { return s.InPkts })},
+		{snmpGroupOID + ".2.0", "snmpOutPkts", gosnmp.Counter32, counter32(func(s Stats) uint64 { return s.OutPkts })},
+		{snmpGroupOID + ".3.0", "snmpInBadVersions", gosnmp.Counter32, counter32(func(s Stats) uint64 { return s.InBadVersions })},
+		{snmpGroupOID + ".4.0", "snmpInBadCommunityNames", gosnmp.Counter32, counter32(func(s Stats) uint64 { return s.InBadCommunityNames })},
+		{snmpGroupOID + ".6.0", "snmpInASNParseErrs", gosnmp.Counter32, counter32(func(s Stats) uint64 { return s.InASNParseErrs })},
+		{snmpGroupOID + ".13.0", "snmpInTotalReqVars", gosnmp.Counter32, counter32(func(s Stats) uint64 { return s.InTotalReqVars })},
+		{snmpGroupOID + ".14.0", "snmpInTotalSetVars", gosnmp.Counter32, counter32(func(s Stats) uint64 { return s.InTotalSetVars })},
+		{snmpGroupOID + ".15.0", "snmpInGetRequests", gosnmp.Counter32, counter32(func(s Stats) uint64 { return s.InGetRequests })},
+		{snmpGroupOID + ".16.0", "snmpInGetNexts", gosnmp.Counter32, counter32(func(s Stats) uint64 { return s.InGetNexts })},
+		{snmpGroupOID + ".17.0", "snmpInSetRequests", gosnmp.Counter32, counter32(func(s Stats) uint64 { return s.InSetRequests })},
+		{snmpGroupOID + ".28.0", "snmpOutGetResponses", gosnmp.Counter32, counter32(func(s Stats) uint64 { return s.OutGetResponses })},
+		{snmpGroupOID + ".30.0", "snmpEnableAuthenTraps", gosnmp.Integer, func(Stats) interface{} { return TruthValue(a.authenTraps.Load()) }},
+		{snmpGroupOID + ".31.0", "snmpSilentDrops", gosnmp.Counter32, counter32(func(s Stats) uint64 { return s.SilentDrops })},
+		{root + ".1.0", "agentInGetBulks", gosnmp.Counter64, counter64(func(s Stats) uint64 { return s.InGetBulks })},
+		{root + ".2.0", "agentHandlerCalls", gosnmp.Counter64, counter64(func(s Stats) uint64 { return s.HandlerCalls })},
+		{root + ".3.0", "agentHandlerErrors", gosnmp.Counter64, counter64(func(s Stats) uint64 { return s.HandlerErrors })},
+		{root + ".4.0", "agentHandlerLatencyP50", gosnmp.Gauge32, micros(func(s Stats) time.Duration { return s.HandlerLatencyP50 })},
+		{root + ".5.0", "agentHandlerLatencyP90", gosnmp.Gauge32, micros(func(s Stats) time.Duration { return s.HandlerLatencyP90 })},
+		{root + ".6.0", "agentHandlerLatencyP99", gosnmp.Gauge32, micros(func(s Stats) time.Duration { return s.HandlerLatencyP99 })},
+		{root + ".7.0", "agentInDuplicates", gosnmp.Counter64, counter64(func(s Stats) uint64 { return s.InDuplicates })},
+		{root + ".8.0", "agentInDuplicatesSlow", gosnmp.Counter64, counter64(func(s Stats) uint64 { return s.InDuplicatesSlow })},
+		{root + ".9.0", "agentHandlerPanics", gosnmp.Counter64, counter64(func(s Stats) uint64 { return s.HandlerPanics })},
+		{root + ".10.0", "agentInOverloadDrops", gosnmp.Counter64, counter64(func(s Stats) uint64 { return s.InOverloadDrops })},
+		{root + ".11.0", "agentRequestsQueued", gosnmp.Gauge32, gauge(func(s Stats) int64 { return s.RequestsQueued })},
+		{root + ".12.0", "agentRequestsInFlight", gosnmp.Gauge32, gauge(func(s Stats) int64 { return s.RequestsInFlight })},
+		{root + ".13.0", "agentInPktsIPv4", gosnmp.Counter64, counter64(func(s Stats) uint64 { return s.InPktsIPv4 })},
+		{root + ".14.0", "agentInPktsIPv6", gosnmp.Counter64, counter64(func(s Stats) uint64 { return s.InPktsIPv6 })},
+		{root + ".15.0", "agentOutPktsIPv4", gosnmp.Counter64, counter64(func(s Stats) uint64 { return s.OutPktsIPv4 })},
+		{root + ".16.0", "agentOutPktsIPv6", gosnmp.Counter64, counter64(func(s Stats) uint64 { return s.OutPktsIPv6 })},
+		{root + ".17.0", "agentHandlerCoalesced", gosnmp.Counter64, counter64(func(s Stats) uint64 { return s.HandlerCoalesced })},
+	}
+
+	add := make(map[string]dynamicOID, len(objects))
+	for _, obj := range objects {
+		value := obj.value
+		add[obj.oid] = dynamicOID{
+			Type:    obj.oidType,
+			Handler: func() (interface{}, error) { return value(a.stats.snapshot()), nil },
+		}
+		if err := a.AnnotateAbsolute(obj.oid, OIDMeta{Name: obj.name}); err != nil {
+			return err
+		}
+	}
+	a.replaceDynamic(nil, add)
+	a.addSysOR(snmpMIBOID, "The MIB module for SNMP entities", "")
+
+	a.logger.Info("Registered agent stats", "oid", root, "objects", len(objects))
+	return nil
+}
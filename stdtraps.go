@@ -0,0 +1,45 @@
+package lzsnmp
+
+// SNMPv2-MIB 中的标准通知
+const (
+	coldStartOID             = snmpTrapsOID + ".1"
+	warmStartOID             = snmpTrapsOID + ".2"
+	authenticationFailureOID = snmpTrapsOID + ".5"
+)
+
+// sendStartTrap 发送启动通知：第一次 Start 为 coldStart，Stop 后再次 Start 为 warmStart
+//
+// 配置了 Cluster 时由 SendTrap 询问 ShouldSendTrap，这里不再重复询问，避免共享去重存储把同一通知记录两次。
+func (a *Agent) sendStartTrap() {
+	if a.config.DisableStartTraps {
+		return
+	}
+	trapOID := coldStartOID
+	if a.restarted.Swap(true) {
+		trapOID = warmStartOID
+	}
+	go a.SendTrap(trapOID, nil)
+}
+
+// sendAuthFailureTrap 在启用 snmpEnableAuthenTraps 时发送 authenticationFailure
+//
+// 同一时刻最多发送一个，发送期间的认证失败不再产生通知，避免错误 community 的请求洪泛放大为通知洪泛。
+func (a *Agent) sendAuthFailureTrap() {
+	if !a.authenTraps.Load() || !a.authTrapBusy.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer a.authTrapBusy.Store(false)
+		a.SendTrap(authenticationFailureOID, nil)
+	}()
+}
+
+// AuthenTrapsEnabled 返回是否在认证失败时发送 authenticationFailure 通知（snmpEnableAuthenTraps）
+func (a *Agent) AuthenTrapsEnabled() bool {
+	return a.authenTraps.Load()
+}
+
+// SetAuthenTraps 运行时开启或关闭 authenticationFailure 通知，Reload 时按 Config.EnableAuthenTraps 重新设置
+func (a *Agent) SetAuthenTraps(enabled bool) {
+	a.authenTraps.Store(enabled)
+}
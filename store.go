@@ -0,0 +1,162 @@
+package lzsnmp
+
+import (
+	"maps"
+	"sync"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/slayercat/GoSNMPServer"
+)
+
+// oidStore 已注册 OID 的快照
+//
+// 快照发布后不再修改：写操作复制当前快照、修改后原子替换，
+// 读操作直接加载快照，不与注册竞争锁。
+type oidStore struct {
+	handlers   map[string]ValueHandler
+	contexts   map[string]ContextHandler // 通过 RegisterContext 注册的处理函数，handlers 中为其无 context 的包装
+	staticVals map[string]interface{}
+	setters    map[string]SetHandler
+	types      map[string]gosnmp.Asn1BER
+	subtrees   map[string]string          // 由表格、桥接等管理的子树根 → 注册方
+	overrides  map[string]bool            // 通过 Override 注册、覆盖子树实例的 OID
+	security   map[string]SecurityLevel   // 子树根 → 访问所需的最低安全级别
+	exprs      map[string]*expression     // 通过 RegisterExpression 注册的 OID，用于检测循环引用
+	tcs        map[string]TextConvention  // 通过 SetTextConvention 关联的文本约定
+	txs        map[string]*SetTransaction // 通过 RegisterWritableTx 注册的两阶段 SET 回调
+	rowTables  map[string]*RowTable       // 可创建行的表格 entry OID → 表格
+
+	sortOnce sync.Once
+	sorted   []string
+}
+
+func newOIDStore() *oidStore {
+	return &oidStore{
+		handlers:   make(map[string]ValueHandler),
+		contexts:   make(map[string]ContextHandler),
+		staticVals: make(map[string]interface{}),
+		setters:    make(map[string]SetHandler),
+		types:      make(map[string]gosnmp.Asn1BER),
+		subtrees:   make(map[string]string),
+		overrides:  make(map[string]bool),
+		security:   make(map[string]SecurityLevel),
+		exprs:      make(map[string]*expression),
+		tcs:        make(map[string]TextConvention),
+		txs:        make(map[string]*SetTransaction),
+		rowTables:  make(map[string]*RowTable),
+	}
+}
+
+// clone 复制快照，用于在发布前修改
+func (s *oidStore) clone() *oidStore {
+	return &oidStore{
+		handlers:   maps.Clone(s.handlers),
+		contexts:   maps.Clone(s.contexts),
+		staticVals: maps.Clone(s.staticVals),
+		setters:    maps.Clone(s.setters),
+		types:      maps.Clone(s.types),
+		subtrees:   maps.Clone(s.subtrees),
+		overrides:  maps.Clone(s.overrides),
+		security:   maps.Clone(s.security),
+		exprs:      maps.Clone(s.exprs),
+		tcs:        maps.Clone(s.tcs),
+		txs:        maps.Clone(s.txs),
+		rowTables:  maps.Clone(s.rowTables),
+	}
+}
+
+// putDynamic 添加或替换动态 OID，setter 为 nil 时为只读
+func (s *oidStore) putDynamic(oid string, oidType gosnmp.Asn1BER, handler ValueHandler, setter SetHandler) {
+	delete(s.staticVals, oid)
+	delete(s.setters, oid)
+	delete(s.contexts, oid)
+	delete(s.exprs, oid)
+	delete(s.txs, oid)
+	s.handlers[oid] = handler
+	s.types[oid] = oidType
+	if setter != nil {
+		s.setters[oid] = setter
+	}
+}
+
+// putStatic 添加或替换静态 OID
+func (s *oidStore) putStatic(oid string, oidType gosnmp.Asn1BER, value interface{}) {
+	delete(s.handlers, oid)
+	delete(s.contexts, oid)
+	delete(s.setters, oid)
+	delete(s.exprs, oid)
+	delete(s.txs, oid)
+	s.staticVals[oid] = value
+	s.types[oid] = oidType
+}
+
+// remove 删除 OID，返回 OID 是否存在
+func (s *oidStore) remove(oid string) bool {
+	_, exists := s.types[oid]
+	delete(s.handlers, oid)
+	delete(s.contexts, oid)
+	delete(s.staticVals, oid)
+	delete(s.setters, oid)
+	delete(s.types, oid)
+	delete(s.overrides, oid)
+	delete(s.exprs, oid)
+	delete(s.txs, oid)
+	return exists
+}
+
+// updateStore 复制当前快照交给 fn 修改，fn 返回 nil 时发布修改后的快照并更新 worker
+//
+// fn 在持有 a.mu 写锁时调用，可以同时修改 a.meta 等其他字段。
+func (a *Agent) updateStore(fn func(s *oidStore) error) error {
+	a.mu.Lock()
+	before := a.store.Load()
+	s := before.clone()
+	if err := fn(s); err != nil {
+		a.mu.Unlock()
+		return err
+	}
+	a.store.Store(s)
+	a.mu.Unlock()
+
+	a.syncWorkers()
+	a.persist.sync()
+	if a.events.subscribed() {
+		a.publishRegistrations(before, s)
+	}
+	return nil
+}
+
+// syncWorkers 按最新快照为每个 worker 重建 OID 列表，Agent 未启动时不做任何事
+//
+// 重建不持有 a.mu，不阻塞请求处理；a.syncMu 保证并发注册时最后安装的总是最新快照。
+func (a *Agent) syncWorkers() {
+	a.mu.RLock()
+	workers := a.workers
+	a.mu.RUnlock()
+	if workers == nil {
+		return
+	}
+
+	a.syncMu.Lock()
+	defer a.syncMu.Unlock()
+
+	s := a.store.Load()
+	for _, w := range workers {
+		a.registerWorkerHandlers(w, s)
+	}
+
+	a.logger.Debug("Handlers registered",
+		"dynamic", len(s.handlers),
+		"static", len(s.staticVals),
+		"workers", len(workers))
+}
+
+// sortOIDs 按 OID 排序并检查重复，GETNEXT/WALK 依赖有序列表
+func sortOIDs(template *GoSNMPServer.SubAgent, items []*GoSNMPServer.PDUValueControlItem) error {
+	sorter := &GoSNMPServer.SubAgent{
+		CommunityIDs: template.CommunityIDs,
+		OIDs:         items,
+		Logger:       template.Logger,
+	}
+	return sorter.SyncConfig()
+}
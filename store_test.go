@@ -0,0 +1,114 @@
+package lzsnmp_test
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	"github.com/gosnmp/gosnmp"
+
+	lzsnmp "github.com/liuzhen9320/snmp-go"
+	"github.com/liuzhen9320/snmp-go/testutil"
+)
+
+// BenchmarkConcurrentGet 多个管理端并发 GET，registering 同时由另一个协程不断注册和注销 OID
+//
+// 读请求只读取快照，注册只替换快照，两者的 ns/op 应相近（单核时注册协程与请求争用 CPU）；
+// registers/op 为每个 GET 期间完成的注册和注销次数。
+func BenchmarkConcurrentGet(b *testing.B) {
+	b.Run("idle", func(b *testing.B) { benchmarkConcurrentGet(b, false) })
+	b.Run("registering", func(b *testing.B) { benchmarkConcurrentGet(b, true) })
+}
+
+func benchmarkConcurrentGet(b *testing.B, register bool) {
+	workers := runtime.GOMAXPROCS(0)
+	agent, err := lzsnmp.NewAgent(lzsnmp.Config{
+		PEN:                   benchPEN,
+		DisableStartTraps:     true,
+		LogLevel:              log.ErrorLevel,
+		MaxConcurrentRequests: workers,
+		RequestQueueSize:      4 * workers,
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 1; i <= 1000; i++ {
+		if err := agent.RegisterStatic(fmt.Sprintf("1.%d.0", i), gosnmp.Integer, i); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	network := testutil.NewMemNetwork()
+	server, err := network.Listen("agent")
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := agent.StartPacketConn(server); err != nil {
+		b.Fatal(err)
+	}
+	defer agent.Stop()
+
+	stop := make(chan struct{})
+	var registers atomic.Int64
+	var wg sync.WaitGroup
+	if register {
+		wg.Add(1)
+		go registerLoop(b, agent, stop, &registers, &wg)
+	}
+
+	var next atomic.Int64
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		conn, err := network.Listen("")
+		if err != nil {
+			b.Error(err)
+			return
+		}
+		client := testutil.NewClient(conn, server.LocalAddr())
+		defer client.Close()
+		for pb.Next() {
+			oid := fmt.Sprintf(".1.3.6.1.4.1.%d.1.%d.0", benchPEN, next.Add(1)%1000+1)
+			resp, err := client.Get(oid)
+			if err != nil {
+				b.Error(err)
+				return
+			}
+			if resp.Error != gosnmp.NoError || resp.Variables[0].Type != gosnmp.Integer {
+				b.Errorf("GET %s: %s, %s", oid, resp.Error, resp.Variables[0].Type)
+				return
+			}
+		}
+	})
+	b.StopTimer()
+	close(stop)
+	wg.Wait()
+	if register {
+		b.ReportMetric(float64(registers.Load())/float64(b.N), "registers/op")
+	}
+}
+
+// registerLoop 不断注册和注销 OID 直到 stop 关闭
+func registerLoop(b *testing.B, agent *lzsnmp.Agent, stop <-chan struct{}, registers *atomic.Int64, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for i := 0; ; i++ {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		oid := fmt.Sprintf("2.%d.0", i%100)
+		if err := agent.RegisterStatic(oid, gosnmp.Integer, i); err != nil {
+			b.Error(err)
+			return
+		}
+		if err := agent.Unregister(oid); err != nil {
+			b.Error(err)
+			return
+		}
+		registers.Add(2)
+	}
+}
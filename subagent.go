@@ -0,0 +1,180 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/slayercat/GoSNMPServer"
+)
+
+// SubAgentConfig 共用监听端口、按 community 隔离的子 Agent
+type SubAgentConfig struct {
+	Name        string   // 子 Agent 名称，用于日志和 RemoveSubAgent
+	Communities []string // 访问该子 Agent 的 community，不能与 Config.Community、Communities 或其他子 Agent 重复
+	View        []string // 可见的 OID 子树（绝对路径），如 "1.3.6.1.4.1.12345.10"；为空时看不到任何 OID
+}
+
+// contains 判断 oid 是否在子 Agent 的视图中
+func (c *SubAgentConfig) contains(oid string) bool {
+	return slices.ContainsFunc(c.View, func(root string) bool { return hasOIDPrefix(oid, root) })
+}
+
+// AddSubAgent 添加子 Agent，使用其 community 的 v1/v2c 请求和以其 community 为 contextName 的 SNMPv3 请求
+// 只能读写视图中的 OID
+//
+// 所有子 Agent 共用 Agent 的 OID 注册、中间件和安全级别要求（RequireSecurity），各自的视图互相独立，
+// 可以重叠。默认 context（空 contextName）的 SNMPv3 请求使用 Agent 的完整视图。Reload 保留已添加的子 Agent。
+func (a *Agent) AddSubAgent(cfg SubAgentConfig) error {
+	if cfg.Name == "" {
+		return fmt.Errorf("sub-agent name is required")
+	}
+	if len(cfg.Communities) == 0 {
+		return fmt.Errorf("sub-agent %s: at least one community is required", cfg.Name)
+	}
+	view := make([]string, 0, len(cfg.View))
+	for _, root := range cfg.View {
+		oid, err := normalizeOID(root)
+		if err != nil {
+			return fmt.Errorf("sub-agent %s: %w", cfg.Name, err)
+		}
+		view = append(view, oid)
+	}
+	sub := SubAgentConfig{Name: cfg.Name, Communities: slices.Clone(cfg.Communities), View: view}
+
+	a.accessMu.Lock()
+	current := a.access.Load()
+	if slices.ContainsFunc(current.subAgents, func(s SubAgentConfig) bool { return s.Name == cfg.Name }) {
+		a.accessMu.Unlock()
+		return fmt.Errorf("sub-agent %s already exists", cfg.Name)
+	}
+	next := *current
+	next.subAgents = append(slices.Clone(current.subAgents), sub)
+	if err := next.checkCommunities(); err != nil {
+		a.accessMu.Unlock()
+		return fmt.Errorf("sub-agent %s: %w", cfg.Name, err)
+	}
+	// worker 重建视图前，子 Agent 的 community 看到空视图，见 oidViews.viewOf
+	a.access.Store(&next)
+	a.accessMu.Unlock()
+	a.syncWorkers()
+
+	a.logger.Info("Added sub-agent", "name", cfg.Name, "communities", len(cfg.Communities), "view", view)
+	return nil
+}
+
+// RemoveSubAgent 删除子 Agent，之后其 community 不再被接受
+func (a *Agent) RemoveSubAgent(name string) error {
+	a.accessMu.Lock()
+	current := a.access.Load()
+	i := slices.IndexFunc(current.subAgents, func(s SubAgentConfig) bool { return s.Name == name })
+	if i < 0 {
+		a.accessMu.Unlock()
+		return fmt.Errorf("sub-agent %s does not exist", name)
+	}
+	next := *current
+	next.subAgents = slices.Delete(slices.Clone(current.subAgents), i, i+1)
+	a.access.Store(&next)
+	a.accessMu.Unlock()
+	a.syncWorkers()
+
+	a.logger.Info("Removed sub-agent", "name", name)
+	return nil
+}
+
+// SubAgents 返回已添加的子 Agent
+func (a *Agent) SubAgents() []SubAgentConfig {
+	subs := a.access.Load().subAgents
+	result := make([]SubAgentConfig, len(subs))
+	for i, s := range subs {
+		result[i] = SubAgentConfig{Name: s.Name, Communities: slices.Clone(s.Communities), View: slices.Clone(s.View)}
+	}
+	return result
+}
+
+// subAgentOf 返回使用 community 的子 Agent，没有时返回 nil
+func (c *accessConfig) subAgentOf(community string) *SubAgentConfig {
+	for i := range c.subAgents {
+		if slices.Contains(c.subAgents[i].Communities, community) {
+			return &c.subAgents[i]
+		}
+	}
+	return nil
+}
+
+// subAgentFor 返回请求访问的子 Agent：v1/v2c 按 community，SNMPv3 按 contextName，没有时返回 nil
+func (c *accessConfig) subAgentFor(pkt *gosnmp.SnmpPacket) *SubAgentConfig {
+	if pkt.Version == gosnmp.Version3 {
+		if pkt.ContextName == "" {
+			return nil
+		}
+		return c.subAgentOf(pkt.ContextName)
+	}
+	return c.subAgentOf(pkt.Community)
+}
+
+// inView 判断请求能否访问 oid：访问子 Agent 的请求只能访问其视图中的 OID
+func (c *accessConfig) inView(pkt *gosnmp.SnmpPacket, oid string) bool {
+	sub := c.subAgentFor(pkt)
+	return sub == nil || sub.contains(oid)
+}
+
+// checkCommunities 检查 Agent 和子 Agent 的 community 没有重复
+func (c *accessConfig) checkCommunities() error {
+	seen := make(map[string]bool, len(c.communities))
+	for _, community := range c.communities {
+		seen[community] = true
+	}
+	for _, s := range c.subAgents {
+		for _, community := range s.Communities {
+			if community == "" || seen[community] {
+				return fmt.Errorf("invalid or duplicate community: %q", community)
+			}
+			seen[community] = true
+		}
+	}
+	return nil
+}
+
+// oidViews worker 使用的 OID 列表：Agent 的完整视图和每个子 Agent 的视图
+type oidViews struct {
+	all  *securityViews
+	subs map[string]*securityViews // 按子 Agent 名称
+}
+
+// oidViews 从已排序的 OID 列表生成 Agent 和每个子 Agent 的视图
+func (s *oidStore) oidViews(items []*GoSNMPServer.PDUValueControlItem, subAgents []SubAgentConfig) *oidViews {
+	views := &oidViews{all: s.securityViews(items)}
+	if len(subAgents) == 0 {
+		return views
+	}
+	views.subs = make(map[string]*securityViews, len(subAgents))
+	for i := range subAgents {
+		sub := &subAgents[i]
+		visible := make([]*GoSNMPServer.PDUValueControlItem, 0)
+		for _, item := range items {
+			if sub.contains(item.OID) {
+				visible = append(visible, item)
+			}
+		}
+		views.subs[sub.Name] = s.securityViews(visible)
+	}
+	return views
+}
+
+// viewOf 返回请求可见的 OID 列表，子 Agent 的视图尚未生成时为空
+//
+// SNMPv3 请求解密前 contextName 未知，decodeV3 之后 respond 按解密的请求重新选择。
+func (v *oidViews) viewOf(access *accessConfig, pkt *gosnmp.SnmpPacket) *securityViews {
+	if pkt == nil {
+		return v.all
+	}
+	sub := access.subAgentFor(pkt)
+	if sub == nil {
+		return v.all
+	}
+	if views, ok := v.subs[sub.Name]; ok {
+		return views
+	}
+	return &securityViews{}
+}
@@ -0,0 +1,190 @@
+package lzsnmp
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// SubtreeEntry 导出的一个 OID 注册项
+type SubtreeEntry struct {
+	OID         string `json:"oid"`
+	Type        string `json:"type"`
+	Value       string `json:"value"`
+	Hex         bool   `json:"hex,omitempty"`     // Value 为十六进制编码的 OctetString
+	Dynamic     bool   `json:"dynamic,omitempty"` // 导出时由处理函数生成的值
+	Writable    bool   `json:"writable,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Units       string `json:"units,omitempty"`
+	// Base 只用于 PersistConfig 的存储：值被修改时代码注册的原值的摘要，代码中的值改变后保存的修改不再恢复
+	Base string `json:"base,omitempty"`
+}
+
+// Subtree 导出的子树，可以 JSON 序列化后传给另一个 Agent
+type Subtree struct {
+	Root       string         `json:"root"`
+	ExportedAt time.Time      `json:"exportedAt"`
+	Entries    []SubtreeEntry `json:"entries"`
+}
+
+// ImportOptions 导入子树的选项
+type ImportOptions struct {
+	Root    string // 导入到新的根 OID（绝对路径），为空时保持原 OID
+	Replace bool   // 导入前注销目标子树中已有的所有 OID
+}
+
+// ExportSubtree 导出绝对路径 OID 子树中的所有注册项
+//
+// 静态值原样导出；动态 OID 调用一次处理函数并导出当前值，处理函数出错的 OID 会被跳过。
+func (a *Agent) ExportSubtree(root string) (*Subtree, error) {
+	root = strings.Trim(root, ".")
+	if root == "" {
+		return nil, fmt.Errorf("subtree root is required")
+	}
+	return a.exportSubtree(root)
+}
+
+// exportSubtree 导出 root 子树，root 为空时导出所有 OID
+func (a *Agent) exportSubtree(root string) (*Subtree, error) {
+	type pending struct {
+		entry   SubtreeEntry
+		oidType gosnmp.Asn1BER
+		value   interface{}
+		handler ValueHandler
+	}
+
+	a.mu.RLock()
+	s := a.store.Load()
+	var items []pending
+	for oid, oidType := range s.types {
+		if root != "" && !hasOIDPrefix(oid, root) {
+			continue
+		}
+		meta := a.meta[oid]
+		_, writable := s.setters[oid]
+		item := pending{
+			entry: SubtreeEntry{
+				OID:         oid,
+				Type:        oidType.String(),
+				Writable:    writable,
+				Name:        meta.Name,
+				Description: meta.Description,
+				Units:       meta.Units,
+			},
+			oidType: oidType,
+		}
+		if handler, ok := s.handlers[oid]; ok {
+			item.entry.Dynamic = true
+			item.handler = handler
+		} else {
+			item.value = s.staticVals[oid]
+		}
+		items = append(items, item)
+	}
+	a.mu.RUnlock()
+
+	// 处理函数可能会注册 OID，不能在持有锁时调用
+	subtree := &Subtree{Root: root, ExportedAt: time.Now()}
+	for _, item := range items {
+		value := item.value
+		if item.handler != nil {
+			v, err := item.handler()
+			if errors.Is(err, ErrNoSuchInstance) {
+				continue
+			}
+			if err != nil {
+				a.logger.Warn("Skipping OID in subtree export", "oid", item.entry.OID, "error", err)
+				continue
+			}
+			value = v
+		}
+
+		text, isHex, err := formatValueText(item.oidType, value)
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", item.entry.OID, err)
+		}
+		item.entry.Value, item.entry.Hex = text, isHex
+		subtree.Entries = append(subtree.Entries, item.entry)
+	}
+	sort.Slice(subtree.Entries, func(i, j int) bool {
+		return compareOID(subtree.Entries[i].OID, subtree.Entries[j].OID) < 0
+	})
+
+	a.logger.Info("Exported subtree", "root", root, "entries", len(subtree.Entries))
+	return subtree, nil
+}
+
+// ImportSubtree 将导出的子树注册为静态值，返回导入的 OID 数量
+//
+// 处理函数无法跨进程迁移，动态 OID 以导出时的值注册为静态值，
+// 需要在目标 Agent 上重新注册处理函数以恢复动态行为。
+func (a *Agent) ImportSubtree(subtree *Subtree, opts ImportOptions) (int, error) {
+	if subtree == nil {
+		return 0, fmt.Errorf("subtree is required")
+	}
+	from := strings.Trim(subtree.Root, ".")
+	to := strings.Trim(opts.Root, ".")
+	if to == "" {
+		to = from
+	}
+
+	type parsed struct {
+		oidType gosnmp.Asn1BER
+		value   interface{}
+		meta    OIDMeta
+	}
+
+	entries := make(map[string]parsed, len(subtree.Entries))
+	for _, e := range subtree.Entries {
+		oid := strings.Trim(e.OID, ".")
+		if !hasOIDPrefix(oid, from) {
+			return 0, fmt.Errorf("entry %s is outside subtree %s", e.OID, from)
+		}
+		oid, err := normalizeOID(to + strings.TrimPrefix(oid, from))
+		if err != nil {
+			return 0, fmt.Errorf("entry %s: %w", e.OID, err)
+		}
+
+		oidType, err := ParseType(e.Type)
+		if err != nil {
+			return 0, fmt.Errorf("entry %s: %w", e.OID, err)
+		}
+		value, err := parseValueText(oidType, e.Value, e.Hex)
+		if err != nil {
+			return 0, fmt.Errorf("entry %s: %w", e.OID, err)
+		}
+		entries[oid] = parsed{oidType: oidType, value: value, meta: OIDMeta{Name: e.Name, Description: e.Description, Units: e.Units}}
+	}
+
+	removed := 0
+	a.updateStore(func(s *oidStore) error {
+		if opts.Replace {
+			for oid := range s.types {
+				if hasOIDPrefix(oid, to) {
+					s.remove(oid)
+					a.persist.forget(oid)
+					a.forgetCoalesced(oid)
+					removed++
+				}
+			}
+		}
+
+		for oid, e := range entries {
+			s.putStatic(oid, e.oidType, e.value)
+			a.persist.track(oid, e.oidType, e.value)
+			if e.meta != (OIDMeta{}) {
+				a.meta[oid] = e.meta
+			}
+		}
+
+		a.logger.Info("Imported subtree", "from", from, "to", to, "entries", len(entries), "removed", removed)
+		return nil
+	})
+
+	return len(entries), nil
+}
@@ -0,0 +1,169 @@
+package lzsnmp
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// 模块 Run 重启的退避间隔，每次失败翻倍，稳定运行超过 moduleRestartMax 后重新从最短间隔开始
+const (
+	moduleRestartMin = time.Second
+	moduleRestartMax = time.Minute
+)
+
+// callModule 调用模块函数，将 panic 转换为错误，避免一个模块拖垮整个进程
+func (a *Agent) callModule(name string, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			a.logger.Error("Module panicked", "name", name, "panic", r, "stack", string(debug.Stack()))
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn()
+}
+
+// startModuleTask 在独立 goroutine 中运行并监督模块的 Run
+func (a *Agent) startModuleTask(e *moduleEntry) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	r := &a.modules
+	r.mu.Lock()
+	e.cancel, e.done = cancel, done
+	r.mu.Unlock()
+
+	go a.superviseModule(ctx, e, done)
+}
+
+// superviseModule 运行 Run，失败时按指数退避重启，直到 Run 返回 nil 或 ctx 取消
+func (a *Agent) superviseModule(ctx context.Context, e *moduleEntry, done chan struct{}) {
+	defer close(done)
+
+	r := &a.modules
+	backoff := moduleRestartMin
+	for {
+		start := time.Now()
+		err := a.callModule(e.Name, func() error { return e.Run(ctx, a) })
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			a.logger.Info("Module task finished", "name", e.Name)
+			return
+		}
+
+		if time.Since(start) > moduleRestartMax {
+			backoff = moduleRestartMin
+		}
+		r.mu.Lock()
+		e.state, e.lastErr = ModuleRestarting, err
+		r.mu.Unlock()
+		a.logger.Error("Module task failed, restarting", "name", e.Name, "error", err, "backoff", backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, moduleRestartMax)
+
+		r.mu.Lock()
+		e.state = ModuleRunning
+		e.restarts++
+		r.mu.Unlock()
+	}
+}
+
+// stopModuleTask 取消模块的 Run 并等待其退出
+func (a *Agent) stopModuleTask(e *moduleEntry) {
+	r := &a.modules
+	r.mu.Lock()
+	cancel, done := e.cancel, e.done
+	e.cancel, e.done = nil, nil
+	r.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// stopModuleTasks 取消所有模块的 Run 并等待退出
+func (a *Agent) stopModuleTasks() {
+	r := &a.modules
+	r.mu.Lock()
+	entries := append([]*moduleEntry(nil), r.entries...)
+	r.mu.Unlock()
+
+	for _, e := range entries {
+		a.stopModuleTask(e)
+	}
+}
+
+// moduleStateCodes 模块状态表中 state 列的取值
+var moduleStateCodes = map[ModuleState]int{
+	ModulePending:    1,
+	ModuleRunning:    2,
+	ModuleFailed:     3,
+	ModuleSkipped:    4,
+	ModuleDisabled:   5,
+	ModuleRestarting: 6,
+	ModuleStarting:   7,
+}
+
+// moduleHealthRow 模块状态表的一行
+type moduleHealthRow struct {
+	Name      string `snmp:"1,octetstring" snmpdesc:"Module name"`
+	State     int    `snmp:"2,integer" snmpdesc:"pending(1), running(2), failed(3), skipped(4), disabled(5), restarting(6), starting(7)"`
+	Restarts  uint   `snmp:"3,counter32" snmpdesc:"Number of times the module task was restarted"`
+	LastError string `snmp:"4,octetstring" snmpdesc:"Last error of the module Init or task"`
+}
+
+// RegisterModuleHealth 将模块状态注册为表格，行号按添加顺序从 1 开始
+//
+// 实例 OID 为 {relativeOID}.1.{列号}.{行号}，列为 1: 名称、2: 状态、3: 重启次数、4: 最近错误，
+// 状态取值为 pending(1)、running(2)、failed(3)、skipped(4)、disabled(5)、restarting(6)、starting(7)。
+func (a *Agent) RegisterModuleHealth(relativeOID string) error {
+	table, err := a.BindTable(relativeOID, func() []moduleHealthRow {
+		statuses := a.Modules()
+		rows := make([]moduleHealthRow, len(statuses))
+		for i, s := range statuses {
+			lastErr := s.LastError
+			if lastErr == "" {
+				lastErr = s.Error
+			}
+			rows[i] = moduleHealthRow{
+				Name:      s.Name,
+				State:     moduleStateCodes[s.State],
+				Restarts:  uint(s.Restarts),
+				LastError: lastErr,
+			}
+		}
+		return rows
+	})
+	if err != nil {
+		return err
+	}
+
+	r := &a.modules
+	r.mu.Lock()
+	r.health = table
+	r.mu.Unlock()
+	return nil
+}
+
+// refreshModuleHealth 添加模块后立即更新模块状态表的行
+func (a *Agent) refreshModuleHealth() {
+	r := &a.modules
+	r.mu.Lock()
+	table := r.health
+	r.mu.Unlock()
+
+	if table != nil {
+		if err := table.Refresh(); err != nil {
+			a.logger.Warn("Failed to refresh module health table", "error", err)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// SNMPv2-MIB sysORLastChange.0 和 sysOREntry
+const (
+	sysORLastChangeOID = "1.3.6.1.2.1.1.8.0"
+	sysOREntryOID      = "1.3.6.1.2.1.1.9.1"
+)
+
+// 内置功能对应的 MIB 模块
+const (
+	snmpMIBOID    = "1.3.6.1.6.3.1"  // SNMPv2-MIB，RegisterStats
+	snmpUsmMIBOID = "1.3.6.1.6.3.15" // SNMP-USER-BASED-SM-MIB，配置了 SNMPv3 用户时
+)
+
+// SysOREntry sysORTable 中的一行
+type SysOREntry struct {
+	Index       int
+	ID          string        // 实现的 MIB 模块或 AGENT-CAPABILITIES 的 OID
+	Description string        // sysORDescr
+	UpTime      time.Duration // 添加时的 sysUpTime
+	Module      string        // 由模块添加时为模块名
+}
+
+// sysORRegistry sysORTable 的内容，未调用 RegisterSysORTable 时也记录，注册后立即可见
+type sysORRegistry struct {
+	mu         sync.Mutex
+	entries    []SysOREntry // 按 Index 排序
+	next       int
+	lastChange time.Duration
+	registered bool
+	instances  []string
+}
+
+// AddSysOR 向 sysORTable 添加一行，声明 Agent 实现了 capabilityOID 对应的 MIB 模块，返回行号
+//
+// 同一 OID 已存在时返回已有的行号。模块设置了 Module.Capability 时，初始化成功后自动添加、停用时删除。
+func (a *Agent) AddSysOR(capabilityOID, description string) (int, error) {
+	return a.addSysOR(capabilityOID, description, "")
+}
+
+// RemoveSysOR 删除 sysORTable 中的一行
+func (a *Agent) RemoveSysOR(index int) error {
+	r := &a.sysOR
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := slices.IndexFunc(r.entries, func(e SysOREntry) bool { return e.Index == index })
+	if i < 0 {
+		return fmt.Errorf("sysORTable row %d does not exist", index)
+	}
+	a.logger.Info("Removed sysORTable entry", "index", index, "id", r.entries[i].ID)
+	r.entries = slices.Delete(r.entries, i, i+1)
+	r.lastChange = a.Uptime()
+	a.syncSysORLocked()
+	return nil
+}
+
+// SysOR 返回 sysORTable 的所有行
+func (a *Agent) SysOR() []SysOREntry {
+	r := &a.sysOR
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.entries)
+}
+
+func (a *Agent) addSysOR(capabilityOID, description, module string) (int, error) {
+	oid, err := normalizeOID(capabilityOID)
+	if err != nil {
+		return 0, err
+	}
+	r := &a.sysOR
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i := slices.IndexFunc(r.entries, func(e SysOREntry) bool { return e.ID == oid }); i >= 0 {
+		return r.entries[i].Index, nil
+	}
+	r.next++
+	uptime := a.Uptime()
+	r.entries = append(r.entries, SysOREntry{Index: r.next, ID: oid, Description: description, UpTime: uptime, Module: module})
+	r.lastChange = uptime
+	a.logger.Info("Added sysORTable entry", "index", r.next, "id", oid, "module", module)
+	a.syncSysORLocked()
+	return r.next, nil
+}
+
+// removeModuleSysOR 删除模块添加的行
+func (a *Agent) removeModuleSysOR(module string) {
+	r := &a.sysOR
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := len(r.entries)
+	r.entries = slices.DeleteFunc(r.entries, func(e SysOREntry) bool { return e.Module == module })
+	if len(r.entries) != n {
+		r.lastChange = a.Uptime()
+		a.syncSysORLocked()
+	}
+}
+
+// RegisterSysORTable 注册 SNMPv2-MIB sysORLastChange.0 和 sysORTable
+//
+// 列为 2: sysORID、3: sysORDescr、4: sysORUpTime（添加时的 sysUpTime），sysORIndex 不可访问。
+// 行来自 AddSysOR、设置了 Capability 的模块和内置功能：RegisterStats 添加 SNMPv2-MIB，
+// Start 时配置了 SNMPv3 用户则添加 SNMP-USER-BASED-SM-MIB。
+func (a *Agent) RegisterSysORTable() error {
+	if err := a.claimSubtree(parentOID(sysOREntryOID), "sysORTable"); err != nil {
+		return err
+	}
+	if err := a.RegisterAbsolute(sysORLastChangeOID, gosnmp.TimeTicks, func() (interface{}, error) {
+		a.sysOR.mu.Lock()
+		defer a.sysOR.mu.Unlock()
+		return uint32(a.sysOR.lastChange / (10 * time.Millisecond)), nil
+	}); err != nil {
+		return err
+	}
+
+	r := &a.sysOR
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.registered = true
+	a.syncSysORLocked()
+	return nil
+}
+
+// syncSysORLocked 按当前的行重建 sysORTable 实例，调用方需持有 a.sysOR.mu
+func (a *Agent) syncSysORLocked() {
+	r := &a.sysOR
+	if !r.registered {
+		return
+	}
+	add := make(map[string]dynamicOID, len(r.entries)*3)
+	instances := make([]string, 0, len(r.entries)*3)
+	for _, e := range r.entries {
+		cell := func(col int, oidType gosnmp.Asn1BER, value interface{}) {
+			oid := fmt.Sprintf("%s.%d.%d", sysOREntryOID, col, e.Index)
+			add[oid] = dynamicOID{Type: oidType, Handler: func() (interface{}, error) { return value, nil }}
+			instances = append(instances, oid)
+		}
+		cell(2, gosnmp.ObjectIdentifier, e.ID)
+		cell(3, gosnmp.OctetString, e.Description)
+		cell(4, gosnmp.TimeTicks, uint32(e.UpTime/(10*time.Millisecond)))
+	}
+	a.replaceDynamic(r.instances, add)
+	r.instances = instances
+}
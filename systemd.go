@@ -0,0 +1,131 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SystemdListenAddr 作为 ListenAddr 时使用 systemd socket 激活传入的 UDP socket（LISTEN_FDS），
+// 写作 "systemd:名称" 时选择 FileDescriptorName 为该名称的 socket
+const SystemdListenAddr = "systemd"
+
+// sdListenFDsStart systemd 传入的第一个文件描述符
+const sdListenFDsStart = 3
+
+// systemdFile systemd 传入的一个 socket
+type systemdFile struct {
+	name  string
+	file  *os.File
+	taken bool
+}
+
+var (
+	systemdOnce  sync.Once
+	systemdMu    sync.Mutex
+	systemdFiles []*systemdFile
+)
+
+// loadSystemdFiles 读取 LISTEN_PID / LISTEN_FDS / LISTEN_FDNAMES，随后清除这些环境变量，
+// 避免 RegisterExec 等启动的子进程误认为 socket 是传给自己的
+func loadSystemdFiles() {
+	systemdOnce.Do(func() {
+		defer func() {
+			os.Unsetenv("LISTEN_PID")
+			os.Unsetenv("LISTEN_FDS")
+			os.Unsetenv("LISTEN_FDNAMES")
+		}()
+		pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+		if err != nil || pid != os.Getpid() {
+			return
+		}
+		n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+		if err != nil || n <= 0 {
+			return
+		}
+		names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+		for i := 0; i < n; i++ {
+			name := "unknown"
+			if i < len(names) && names[i] != "" {
+				name = names[i]
+			}
+			fd := uintptr(sdListenFDsStart + i)
+			systemdFiles = append(systemdFiles, &systemdFile{name: name, file: os.NewFile(fd, name)})
+		}
+	})
+}
+
+// isSystemdAddr 判断监听地址是否要求使用 systemd 传入的 socket
+func isSystemdAddr(addr string) bool {
+	return addr == SystemdListenAddr || strings.HasPrefix(addr, SystemdListenAddr+":")
+}
+
+// systemdPacketConn 取出 systemd 传入的 UDP socket，addr 为 "systemd" 时取第一个未使用的 UDP socket，
+// 为 "systemd:名称" 时按名称选择；每个 socket 只能取出一次
+func systemdPacketConn(addr string) (*net.UDPConn, error) {
+	loadSystemdFiles()
+	name := strings.TrimPrefix(strings.TrimPrefix(addr, SystemdListenAddr), ":")
+
+	systemdMu.Lock()
+	defer systemdMu.Unlock()
+	if len(systemdFiles) == 0 {
+		return nil, fmt.Errorf("no sockets passed by systemd (LISTEN_FDS not set for this process)")
+	}
+	for _, f := range systemdFiles {
+		if f.taken || (name != "" && f.name != name) {
+			continue
+		}
+		conn, err := net.FilePacketConn(f.file)
+		if err != nil {
+			// 流式 socket 等，按名称指定时报告错误，否则继续查找
+			if name != "" {
+				return nil, fmt.Errorf("systemd socket %q: %w", name, err)
+			}
+			continue
+		}
+		udp, ok := conn.(*net.UDPConn)
+		if !ok {
+			conn.Close()
+			if name != "" {
+				return nil, fmt.Errorf("systemd socket %q is not a UDP socket", name)
+			}
+			continue
+		}
+		// FilePacketConn 复制了描述符，关闭原描述符
+		f.file.Close()
+		f.taken = true
+		return udp, nil
+	}
+	if name != "" {
+		return nil, fmt.Errorf("no unused systemd socket named %q", name)
+	}
+	return nil, fmt.Errorf("no unused UDP socket passed by systemd")
+}
+
+// sdNotify 向 systemd 发送状态通知（Type=notify），未设置 NOTIFY_SOCKET 时不做任何事
+func sdNotify(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+	// 以 @ 开头的抽象命名空间地址由 net 包处理
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("sd_notify: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("sd_notify: %w", err)
+	}
+	return nil
+}
+
+// notifySystemd 发送通知，失败时只记录日志
+func (a *Agent) notifySystemd(state string) {
+	if err := sdNotify(state); err != nil {
+		a.logger.Warn("Failed to notify systemd", "state", strings.ReplaceAll(state, "\n", " "), "error", err)
+	}
+}
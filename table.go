@@ -0,0 +1,342 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// tableRefreshInterval 表格数据缓存时间，同一次 WALK 内的请求共用一份快照
+const tableRefreshInterval = time.Second
+
+// tableColumn 表格列定义
+type tableColumn struct {
+	id       int
+	name     string
+	desc     string
+	oidType  gosnmp.Asn1BER
+	fieldIdx int
+	index    bool
+	implied  bool
+}
+
+// TableBinding 由 BindTable 创建的表格绑定
+type TableBinding struct {
+	agent    *Agent
+	entryOID string
+	provider reflect.Value
+	columns  []tableColumn
+	indices  []tableColumn
+
+	mu        sync.Mutex
+	rows      map[string]reflect.Value
+	instances []string
+	fetchedAt time.Time
+}
+
+// BindTable 将结构体切片绑定为 SNMP 表格
+//
+// provider 必须是 func() []T 或 func() []*T，T 为带 snmp 标签的结构体。
+// 标签格式为 `snmp:"<列号>[,<类型>][,index][,implied]"`，例如 `snmp:"1,integer,index"`。
+// 带 index 的字段按声明顺序组成行索引；没有索引字段时使用行号（从 1 开始）。
+// 实例 OID 为 {relativeOID}.1.{列号}.{索引}。字段名和 snmpdesc 标签用于 ExportDocs。
+// 非索引字段可以是指针，为 nil 时该单元格是空洞：GET 返回 noSuchInstance，WALK 跳过。
+//
+// 每次 GET 时最多每秒调用一次 provider，行集合变化时自动重建实例 OID。
+// 表格为空时没有实例可供访问，数据变化后请调用 Refresh。
+func (a *Agent) BindTable(relativeOID string, provider interface{}) (*TableBinding, error) {
+	pv := reflect.ValueOf(provider)
+	pt := pv.Type()
+	if pt.Kind() != reflect.Func || pv.IsNil() || pt.NumIn() != 0 || pt.NumOut() != 1 || pt.Out(0).Kind() != reflect.Slice {
+		return nil, fmt.Errorf("table provider must be func() []T, got %T", provider)
+	}
+
+	rowType := pt.Out(0).Elem()
+	if rowType.Kind() == reflect.Ptr {
+		rowType = rowType.Elem()
+	}
+	if rowType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("table row must be a struct, got %s", rowType)
+	}
+
+	columns, err := parseTableColumns(rowType)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &TableBinding{
+		agent:    a,
+		entryOID: fmt.Sprintf("%s.%s.1", a.oidPrefix, strings.Trim(relativeOID, ".")),
+		provider: pv,
+		columns:  columns,
+	}
+	for _, col := range columns {
+		if col.index {
+			t.indices = append(t.indices, col)
+		}
+	}
+	sort.Slice(t.indices, func(i, j int) bool { return t.indices[i].fieldIdx < t.indices[j].fieldIdx })
+
+	if err := a.claimSubtree(parentOID(t.entryOID), "table"); err != nil {
+		return nil, err
+	}
+	for _, col := range columns {
+		a.annotateGroup(fmt.Sprintf("%s.%d", t.entryOID, col.id), OIDMeta{Name: col.name, Description: col.desc})
+	}
+
+	if err := t.Refresh(); err != nil {
+		return nil, err
+	}
+
+	a.logger.Info("Bound table", "oid", t.entryOID, "row", rowType.String(), "columns", len(columns))
+	return t, nil
+}
+
+// parseTableColumns 解析行结构体的列定义
+func parseTableColumns(rowType reflect.Type) ([]tableColumn, error) {
+	var columns []tableColumn
+	seen := make(map[int]string)
+
+	for i := 0; i < rowType.NumField(); i++ {
+		sf := rowType.Field(i)
+		tag, ok := sf.Tag.Lookup("snmp")
+		if !ok || tag == "" || tag == "-" {
+			continue
+		}
+		if !sf.IsExported() {
+			return nil, fmt.Errorf("field %s.%s: snmp tag on unexported field", rowType.Name(), sf.Name)
+		}
+
+		parts := strings.Split(tag, ",")
+		id, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("field %s.%s: invalid column number %q", rowType.Name(), sf.Name, parts[0])
+		}
+		if other, dup := seen[id]; dup {
+			return nil, fmt.Errorf("field %s.%s: column %d already used by %s", rowType.Name(), sf.Name, id, other)
+		}
+		seen[id] = sf.Name
+
+		col := tableColumn{id: id, name: sf.Name, desc: sf.Tag.Get("snmpdesc"), fieldIdx: i}
+		typeSet := false
+		for _, opt := range parts[1:] {
+			opt = strings.TrimSpace(opt)
+			switch opt {
+			case "":
+			case "index":
+				col.index = true
+			case "implied":
+				col.implied = true
+			case "rw":
+				return nil, fmt.Errorf("field %s.%s: table columns are read-only", rowType.Name(), sf.Name)
+			default:
+				t, err := ParseType(opt)
+				if err != nil {
+					return nil, fmt.Errorf("field %s.%s: %w", rowType.Name(), sf.Name, err)
+				}
+				col.oidType = t
+				typeSet = true
+			}
+		}
+		if col.implied && (!col.index || (sf.Type.Kind() != reflect.String && sf.Type.Kind() != reflect.Slice)) {
+			return nil, fmt.Errorf("field %s.%s: implied requires a string index", rowType.Name(), sf.Name)
+		}
+
+		fieldType := sf.Type
+		if fieldType.Kind() == reflect.Ptr {
+			// 指针字段为 nil 时该单元格是空洞
+			if col.index {
+				return nil, fmt.Errorf("field %s.%s: index fields cannot be pointers", rowType.Name(), sf.Name)
+			}
+			fieldType = fieldType.Elem()
+		}
+		if !typeSet {
+			t, err := inferType(fieldType)
+			if err != nil {
+				return nil, fmt.Errorf("field %s.%s: %w", rowType.Name(), sf.Name, err)
+			}
+			col.oidType = t
+		}
+
+		columns = append(columns, col)
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no snmp tagged fields in %s", rowType)
+	}
+
+	sort.Slice(columns, func(i, j int) bool { return columns[i].id < columns[j].id })
+	return columns, nil
+}
+
+// Refresh 立即调用 provider 并在行集合变化时重建实例 OID
+func (t *TableBinding) Refresh() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.refreshLocked()
+}
+
+// OID 返回表格 entry 的绝对 OID
+func (t *TableBinding) OID() string {
+	return t.entryOID
+}
+
+func (t *TableBinding) refreshLocked() error {
+	slice := t.provider.Call(nil)[0]
+
+	rows := make(map[string]reflect.Value, slice.Len())
+	for i := 0; i < slice.Len(); i++ {
+		row := slice.Index(i)
+		if row.Kind() == reflect.Ptr {
+			if row.IsNil() {
+				continue
+			}
+			row = row.Elem()
+		}
+
+		index, err := t.rowIndex(row, i)
+		if err != nil {
+			return err
+		}
+		if _, dup := rows[index]; dup {
+			return fmt.Errorf("table %s: duplicate row index %s", t.entryOID, index)
+		}
+		rows[index] = row
+	}
+
+	t.fetchedAt = time.Now()
+
+	if sameIndexSet(t.rows, rows) {
+		t.rows = rows
+		return nil
+	}
+	t.rows = rows
+
+	add := make(map[string]dynamicOID, len(rows)*len(t.columns))
+	instances := make([]string, 0, len(rows)*len(t.columns))
+	for index := range rows {
+		for _, col := range t.columns {
+			oid := fmt.Sprintf("%s.%d.%s", t.entryOID, col.id, index)
+			add[oid] = dynamicOID{Type: col.oidType, Handler: t.cellGetter(col, index)}
+			instances = append(instances, oid)
+		}
+	}
+
+	t.agent.replaceDynamic(t.instances, add)
+	t.instances = instances
+	return nil
+}
+
+// rowIndex 计算行索引 OID 后缀
+func (t *TableBinding) rowIndex(row reflect.Value, pos int) (string, error) {
+	if len(t.indices) == 0 {
+		return strconv.Itoa(pos + 1), nil
+	}
+
+	parts := make([]string, 0, len(t.indices))
+	for _, col := range t.indices {
+		part, err := encodeIndexValue(row.Field(col.fieldIdx), col.implied)
+		if err != nil {
+			return "", fmt.Errorf("table %s column %d: %w", t.entryOID, col.id, err)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "."), nil
+}
+
+func (t *TableBinding) cellGetter(col tableColumn, index string) ValueHandler {
+	return func() (interface{}, error) {
+		t.mu.Lock()
+		if time.Since(t.fetchedAt) > tableRefreshInterval {
+			if err := t.refreshLocked(); err != nil {
+				t.mu.Unlock()
+				return nil, err
+			}
+		}
+		row, ok := t.rows[index]
+		t.mu.Unlock()
+
+		if !ok {
+			return nil, fmt.Errorf("table %s: row %s no longer exists: %w", t.entryOID, index, ErrNoSuchInstance)
+		}
+
+		field := row.Field(col.fieldIdx)
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				return nil, ErrNoSuchInstance
+			}
+			field = field.Elem()
+		}
+		value := field.Interface()
+		switch v := value.(type) {
+		case net.IP:
+			return v.String(), nil
+		case bool:
+			return TruthValue(v), nil
+		}
+		return value, nil
+	}
+}
+
+// encodeIndexValue 按 SMI 规则将索引字段编码为 OID 后缀
+func encodeIndexValue(v reflect.Value, implied bool) (string, error) {
+	if v.Type() == ipType {
+		ip := v.Interface().(net.IP).To4()
+		if ip == nil {
+			return "", fmt.Errorf("IpAddress index must be IPv4")
+		}
+		return fmt.Sprintf("%d.%d.%d.%d", ip[0], ip[1], ip[2], ip[3]), nil
+	}
+
+	if implied && v.Len() == 0 {
+		return "", fmt.Errorf("implied index must not be empty")
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 {
+			return "", fmt.Errorf("negative integer index %d", v.Int())
+		}
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.String:
+		return encodeOctetsIndex([]byte(v.String()), implied), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return encodeOctetsIndex(v.Bytes(), implied), nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported index type %s", v.Type())
+}
+
+func encodeOctetsIndex(b []byte, implied bool) string {
+	parts := make([]string, 0, len(b)+1)
+	if !implied {
+		parts = append(parts, strconv.Itoa(len(b)))
+	}
+	for _, c := range b {
+		parts = append(parts, strconv.Itoa(int(c)))
+	}
+	return strings.Join(parts, ".")
+}
+
+func sameIndexSet(a, b map[string]reflect.Value) bool {
+	if a == nil || len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,453 @@
+package lzsnmp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/slayercat/GoSNMPServer"
+)
+
+// snmpTrapOID SNMPv2-MIB snmpTrapOID.0
+const snmpTrapOID = "1.3.6.1.6.3.1.1.4.1.0"
+
+// snmpTrapsOID SNMPv2-MIB snmpTraps，标准 trap（coldStart 等）的父节点
+const snmpTrapsOID = "1.3.6.1.6.3.1.1.5"
+
+// 通知目标表的行状态（RowStatus）
+const (
+	targetRowActive  = 1
+	targetRowDestroy = 6
+)
+
+// NotifyTarget 通知（trap/inform）的接收方，对应 SNMP-TARGET-MIB 的 snmpTargetAddrEntry
+type NotifyTarget struct {
+	Name      string             // 目标名称，唯一
+	Address   string             // 接收方地址，如 "10.0.0.5:162"，省略端口时为 162
+	Version   gosnmp.SnmpVersion // 与 gosnmp 相同，零值为 Version1；设置 User 时为 Version3
+	Community string             // v1/v2c 的 community，默认 "public"
+	User      *User              // Version3 使用的 USM 用户
+	Inform    bool               // 发送需要确认的 inform（v2c/v3），未确认时按 Timeout、Retries 重发
+	Timeout   time.Duration      // inform 等待确认的时间，默认 1.5 秒
+	Retries   int                // inform 的重发次数
+	Tags      []string           // 标签，SendTrap 指定标签时只发送给带有任一标签的目标
+}
+
+// TargetManager 通知目标表，通过 Agent.Targets 获取
+type TargetManager struct {
+	agent *Agent
+
+	mu        sync.Mutex
+	targets   map[string]*notifyTarget
+	entryOID  string // RegisterTable 注册的表格 entry OID，未注册时为空
+	instances []string
+}
+
+// notifyTarget 目标配置及其连接，sendMu 串行化同一目标的发送（gosnmp 客户端不能并发使用）
+type notifyTarget struct {
+	config NotifyTarget
+	sendMu sync.Mutex
+	client *gosnmp.GoSNMP
+}
+
+// Targets 返回 Agent 的通知目标表
+func (a *Agent) Targets() *TargetManager {
+	return &a.targets
+}
+
+// AddTarget 添加通知目标，名称已存在时返回错误
+func (m *TargetManager) AddTarget(t NotifyTarget) error {
+	if t.Name == "" {
+		return fmt.Errorf("target name is required")
+	}
+	t.Tags = slices.Clone(t.Tags)
+	client, err := m.agent.newTrapClient(t)
+	if err != nil {
+		return fmt.Errorf("target %s: %w", t.Name, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.targets == nil {
+		m.targets = make(map[string]*notifyTarget)
+	}
+	if _, exists := m.targets[t.Name]; exists {
+		client.Conn.Close()
+		return fmt.Errorf("target %s already exists", t.Name)
+	}
+	m.targets[t.Name] = &notifyTarget{config: t, client: client}
+	m.syncTableLocked()
+
+	m.agent.logger.Info("Added notification target", "name", t.Name, "address", t.Address, "version", t.Version, "tags", t.Tags)
+	return nil
+}
+
+// RemoveTarget 删除通知目标
+func (m *TargetManager) RemoveTarget(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.removeLocked(name); err != nil {
+		return err
+	}
+	m.syncTableLocked()
+	return nil
+}
+
+func (m *TargetManager) removeLocked(name string) error {
+	t, ok := m.targets[name]
+	if !ok {
+		return fmt.Errorf("target %s not found", name)
+	}
+	delete(m.targets, name)
+
+	t.sendMu.Lock()
+	t.client.Conn.Close()
+	t.sendMu.Unlock()
+	m.agent.logger.Info("Removed notification target", "name", name)
+	return nil
+}
+
+// List 返回所有通知目标，按名称排序
+func (m *TargetManager) List() []NotifyTarget {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := make([]NotifyTarget, 0, len(m.targets))
+	for _, t := range m.targets {
+		c := t.config
+		c.Tags = slices.Clone(c.Tags)
+		list = append(list, c)
+	}
+	slices.SortFunc(list, func(a, b NotifyTarget) int { return strings.Compare(a.Name, b.Name) })
+	return list
+}
+
+// matching 返回带有任一标签的目标，tags 为空时返回所有目标
+func (m *TargetManager) matching(tags []string) []*notifyTarget {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var targets []*notifyTarget
+	for _, t := range m.targets {
+		if len(tags) == 0 || slices.ContainsFunc(t.config.Tags, func(tag string) bool { return slices.Contains(tags, tag) }) {
+			targets = append(targets, t)
+		}
+	}
+	return targets
+}
+
+// SendTrap 发送通知 trapOID 及变量 vars（不含 sysUpTime.0 和 snmpTrapOID.0，由 SendTrap 添加）
+//
+// tags 为空时发送给所有目标，否则只发送给带有任一标签的目标；各目标并行发送，
+// 返回所有失败目标的错误。v1 目标按 RFC 3584 转换为 Trap-PDU。通知同时分发给 SubscribeNotifications 的订阅方。
+// 配置了 Cluster.ShouldSendTrap 且其返回 false 时不向目标发送，返回 nil。
+func (a *Agent) SendTrap(trapOID string, vars []gosnmp.SnmpPDU, tags ...string) error {
+	trapOID, err := normalizeOID(trapOID)
+	if err != nil {
+		return fmt.Errorf("invalid trap OID: %w", err)
+	}
+	uptime := uint32(a.Uptime() / (10 * time.Millisecond))
+	n := Notification{TrapOID: trapOID, Variables: slices.Clone(vars), Time: time.Now()}
+	a.publishNotification(n)
+	if c := a.config.Cluster; c != nil && c.ShouldSendTrap != nil && !c.ShouldSendTrap(n) {
+		a.logger.Debug("Notification left to another cluster instance", "trap", trapOID)
+		return nil
+	}
+
+	targets := a.targets.matching(tags)
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = t.send(trapOID, uptime, vars)
+		}()
+	}
+	wg.Wait()
+
+	err = errors.Join(errs...)
+	if err != nil {
+		a.logger.Warn("Failed to deliver notification", "trap", trapOID, "error", err)
+	} else {
+		a.logger.Debug("Notification sent", "trap", trapOID, "targets", len(targets))
+	}
+	return err
+}
+
+// send 向目标发送一个通知
+func (t *notifyTarget) send(trapOID string, uptime uint32, vars []gosnmp.SnmpPDU) error {
+	t.sendMu.Lock()
+	defer t.sendMu.Unlock()
+
+	var trap gosnmp.SnmpTrap
+	if t.client.Version == gosnmp.Version1 {
+		trap = v1Trap(trapOID, uptime, vars)
+		if addr, ok := t.client.Conn.LocalAddr().(*net.UDPAddr); ok && addr.IP.To4() != nil {
+			trap.AgentAddress = addr.IP.String()
+		}
+	} else {
+		trap.Variables = append([]gosnmp.SnmpPDU{
+			{Name: "." + sysUpTimeOID, Type: gosnmp.TimeTicks, Value: uptime},
+			{Name: "." + snmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: "." + trapOID},
+		}, vars...)
+		trap.IsInform = t.config.Inform
+	}
+
+	if _, err := t.client.SendTrap(trap); err != nil {
+		return fmt.Errorf("target %s: %w", t.config.Name, err)
+	}
+	return nil
+}
+
+// v1Trap 按 RFC 3584 第 3.2 节将 SNMPv2 通知转换为 SNMPv1 Trap-PDU
+func v1Trap(trapOID string, uptime uint32, vars []gosnmp.SnmpPDU) gosnmp.SnmpTrap {
+	trap := gosnmp.SnmpTrap{Variables: vars, Timestamp: uint(uptime), AgentAddress: "0.0.0.0"}
+
+	parent, last := parentOID(trapOID), trapOID[strings.LastIndex(trapOID, ".")+1:]
+	n, _ := strconv.Atoi(last)
+	switch {
+	case parent == snmpTrapsOID && n >= 1 && n <= 6:
+		// 标准 trap：coldStart(1) → genericTrap 0，依此类推
+		trap.Enterprise = "." + snmpTrapsOID
+		trap.GenericTrap = n - 1
+	case strings.HasSuffix(parent, ".0"):
+		trap.Enterprise = "." + parentOID(parent)
+		trap.GenericTrap, trap.SpecificTrap = 6, n
+	default:
+		trap.Enterprise = "." + parent
+		trap.GenericTrap, trap.SpecificTrap = 6, n
+	}
+	return trap
+}
+
+// newTrapClient 按目标配置创建并连接 gosnmp 客户端
+func (a *Agent) newTrapClient(t NotifyTarget) (*gosnmp.GoSNMP, error) {
+	host, portText, err := net.SplitHostPort(t.Address)
+	if err != nil {
+		host, portText = t.Address, "162"
+	}
+	port, err := strconv.ParseUint(portText, 10, 16)
+	if host == "" || err != nil {
+		return nil, fmt.Errorf("invalid target address: %s", t.Address)
+	}
+
+	client := &gosnmp.GoSNMP{
+		Target:    host,
+		Port:      uint16(port),
+		Transport: "udp",
+		Version:   t.Version,
+		Community: t.Community,
+		Timeout:   t.Timeout,
+		Retries:   t.Retries,
+		MaxOids:   gosnmp.MaxOids,
+	}
+	if client.Community == "" {
+		client.Community = "public"
+	}
+	if client.Timeout <= 0 {
+		client.Timeout = 1500 * time.Millisecond
+	}
+	if t.Inform && client.Version == gosnmp.Version1 && t.User == nil {
+		return nil, fmt.Errorf("SNMPv1 does not support informs")
+	}
+
+	if client.Version == gosnmp.Version3 || t.User != nil {
+		if t.User == nil {
+			return nil, fmt.Errorf("SNMPv3 target requires a user")
+		}
+		user := *t.User
+		if err := user.validate(); err != nil {
+			return nil, err
+		}
+		usm := user.usm()
+		if !t.Inform {
+			// trap 的发送方是权威引擎，使用 Agent 自己的引擎参数
+			usm.AuthoritativeEngineID, usm.AuthoritativeEngineBoots, usm.AuthoritativeEngineTime = a.engineParams()
+		}
+		client.Version = gosnmp.Version3
+		client.SecurityModel = gosnmp.UserSecurityModel
+		client.SecurityParameters = &usm
+		switch {
+		case user.PrivProtocol != gosnmp.NoPriv:
+			client.MsgFlags = gosnmp.AuthPriv
+		case user.AuthProtocol != gosnmp.NoAuth:
+			client.MsgFlags = gosnmp.AuthNoPriv
+		default:
+			client.MsgFlags = gosnmp.NoAuthNoPriv
+		}
+	}
+
+	if err := client.Connect(); err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", t.Address, err)
+	}
+	return client, nil
+}
+
+// engineParams 返回 Agent 的 snmpEngineID、snmpEngineBoots 和 snmpEngineTime，与 MasterAgent 使用的一致
+func (a *Agent) engineParams() (string, uint32, uint32) {
+	e := a.engine.Load()
+	id := GoSNMPServer.SNMPEngineID{EngineIDData: e.id}
+	return string(id.Marshal()), e.boots, e.time()
+}
+
+// targetRow 通知目标表的一行
+type targetRow struct {
+	index  string
+	config NotifyTarget
+}
+
+// RegisterTable 将通知目标注册为相对 OID 下的表格，索引为目标名称（IMPLIED 字符串）
+//
+// 实例 OID 为 {relativeOID}.1.{列号}.{索引}，列为 1: 名称、2: 地址、3: 版本（1/2/3）、4: inform（TruthValue）、
+// 5: 超时（百分之一秒）、6: 重发次数、7: 标签（空格分隔）、8: 行状态。
+// 地址、超时、重发次数和标签可以通过 SET 修改，行状态写入 destroy(6) 删除目标；新目标只能通过 AddTarget 添加。
+func (m *TargetManager) RegisterTable(relativeOID string) error {
+	entryOID := fmt.Sprintf("%s.%s.1", m.agent.oidPrefix, strings.Trim(relativeOID, "."))
+	if err := m.agent.claimSubtree(parentOID(entryOID), "notification target table"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entryOID = entryOID
+	m.syncTableLocked()
+	return nil
+}
+
+// syncTableLocked 按当前目标重建表格实例
+func (m *TargetManager) syncTableLocked() {
+	if m.entryOID == "" {
+		return
+	}
+
+	add := make(map[string]dynamicOID)
+	instances := make([]string, 0, len(m.targets)*8)
+	for name := range m.targets {
+		index := encodeOctetsIndex([]byte(name), true)
+		cell := func(col int, oidType gosnmp.Asn1BER, get func(NotifyTarget) interface{}, set SetHandler) {
+			oid := fmt.Sprintf("%s.%d.%s", m.entryOID, col, index)
+			add[oid] = dynamicOID{Type: oidType, Handler: m.targetGetter(name, get), Setter: set}
+			instances = append(instances, oid)
+		}
+		cell(1, gosnmp.OctetString, func(t NotifyTarget) interface{} { return t.Name }, nil)
+		cell(2, gosnmp.OctetString, func(t NotifyTarget) interface{} { return t.Address }, m.targetSetter(name, func(t *NotifyTarget, v interface{}) error {
+			t.Address = fmt.Sprint(v)
+			return nil
+		}))
+		cell(3, gosnmp.Integer, func(t NotifyTarget) interface{} { return targetVersionCode(t) }, nil)
+		cell(4, gosnmp.Integer, func(t NotifyTarget) interface{} { return TruthValue(t.Inform) }, nil)
+		cell(5, gosnmp.Integer, func(t NotifyTarget) interface{} { return int(t.Timeout / (10 * time.Millisecond)) }, m.targetSetter(name, func(t *NotifyTarget, v interface{}) error {
+			n, err := toInt64(v)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid timeout: %v", v)
+			}
+			t.Timeout = time.Duration(n) * 10 * time.Millisecond
+			return nil
+		}))
+		cell(6, gosnmp.Integer, func(t NotifyTarget) interface{} { return t.Retries }, m.targetSetter(name, func(t *NotifyTarget, v interface{}) error {
+			n, err := toInt64(v)
+			if err != nil || n < 0 || n > 255 {
+				return fmt.Errorf("invalid retry count: %v", v)
+			}
+			t.Retries = int(n)
+			return nil
+		}))
+		cell(7, gosnmp.OctetString, func(t NotifyTarget) interface{} { return strings.Join(t.Tags, " ") }, m.targetSetter(name, func(t *NotifyTarget, v interface{}) error {
+			t.Tags = strings.Fields(fmt.Sprint(v))
+			return nil
+		}))
+		cell(8, gosnmp.Integer, func(NotifyTarget) interface{} { return targetRowActive }, m.targetStatusSetter(name))
+	}
+
+	m.agent.replaceDynamic(m.instances, add)
+	m.instances = instances
+}
+
+// targetVersionCode 表格中版本列的取值
+func targetVersionCode(t NotifyTarget) int {
+	switch t.Version {
+	case gosnmp.Version1:
+		return 1
+	case gosnmp.Version3:
+		return 3
+	}
+	if t.User != nil {
+		return 3
+	}
+	return 2
+}
+
+func (m *TargetManager) targetGetter(name string, get func(NotifyTarget) interface{}) ValueHandler {
+	return func() (interface{}, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		t, ok := m.targets[name]
+		if !ok {
+			return nil, fmt.Errorf("target %s no longer exists", name)
+		}
+		return get(t.config), nil
+	}
+}
+
+// targetSetter 修改目标配置并重建连接
+func (m *TargetManager) targetSetter(name string, set func(*NotifyTarget, interface{}) error) SetHandler {
+	return func(value interface{}) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		t, ok := m.targets[name]
+		if !ok {
+			return fmt.Errorf("target %s no longer exists", name)
+		}
+
+		config := t.config
+		config.Tags = slices.Clone(config.Tags)
+		if value, ok := value.([]byte); ok {
+			if err := set(&config, string(value)); err != nil {
+				return err
+			}
+		} else if err := set(&config, value); err != nil {
+			return err
+		}
+		client, err := m.agent.newTrapClient(config)
+		if err != nil {
+			return err
+		}
+
+		t.sendMu.Lock()
+		t.client.Conn.Close()
+		t.config, t.client = config, client
+		t.sendMu.Unlock()
+		m.agent.logger.Info("Updated notification target over SNMP", "name", name, "address", config.Address)
+		return nil
+	}
+}
+
+// targetStatusSetter 行状态列：写入 active(1) 不做任何事，写入 destroy(6) 删除目标
+func (m *TargetManager) targetStatusSetter(name string) SetHandler {
+	return func(value interface{}) error {
+		status, err := toInt64(value)
+		if err != nil {
+			return err
+		}
+		switch status {
+		case targetRowActive:
+			return nil
+		case targetRowDestroy:
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			if err := m.removeLocked(name); err != nil {
+				return err
+			}
+			m.syncTableLocked()
+			return nil
+		}
+		return fmt.Errorf("unsupported row status %d for target %s", status, name)
+	}
+}
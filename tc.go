@@ -0,0 +1,451 @@
+package lzsnmp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 常用文本约定（Textual Convention）辅助函数，表格实现应统一使用这些编码
+
+// TruthValue 取值（SNMPv2-TC）
+const (
+	TruthTrue  = 1
+	TruthFalse = 2
+)
+
+// AdminStatus 取值（IF-MIB ifAdminStatus）
+const (
+	AdminStatusUp      = 1
+	AdminStatusDown    = 2
+	AdminStatusTesting = 3
+)
+
+// OperStatus 取值（IF-MIB ifOperStatus）
+const (
+	OperStatusUp             = 1
+	OperStatusDown           = 2
+	OperStatusTesting        = 3
+	OperStatusUnknown        = 4
+	OperStatusDormant        = 5
+	OperStatusNotPresent     = 6
+	OperStatusLowerLayerDown = 7
+)
+
+// ZeroDotZero 空 RowPointer（SNMPv2-SMI zeroDotZero）
+const ZeroDotZero = "0.0"
+
+// TruthValue 将 bool 编码为 TruthValue
+func TruthValue(b bool) int {
+	if b {
+		return TruthTrue
+	}
+	return TruthFalse
+}
+
+// ParseTruthValue 解析 TruthValue，仅接受 1 (true) 和 2 (false)
+func ParseTruthValue(value interface{}) (bool, error) {
+	n, err := toInt64(value)
+	if err != nil {
+		return false, err
+	}
+	switch n {
+	case TruthTrue:
+		return true, nil
+	case TruthFalse:
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid TruthValue: %d", n)
+}
+
+// RowPointer 生成指向表格行的 RowPointer，columnOID 为行内首列 OID
+func RowPointer(columnOID, index string) string {
+	columnOID = strings.Trim(columnOID, ".")
+	index = strings.Trim(index, ".")
+	if columnOID == "" {
+		return ZeroDotZero
+	}
+	if index == "" {
+		return columnOID
+	}
+	return columnOID + "." + index
+}
+
+// InetAddressType 地址类型（INET-ADDRESS-MIB, RFC 4001）
+type InetAddressType int
+
+// InetAddressType 取值
+const (
+	InetAddressUnknown InetAddressType = 0
+	InetAddressIPv4    InetAddressType = 1
+	InetAddressIPv6    InetAddressType = 2
+	InetAddressIPv4z   InetAddressType = 3
+	InetAddressIPv6z   InetAddressType = 4
+	InetAddressDNS     InetAddressType = 16
+)
+
+// String 返回 MIB 中的枚举名称
+func (t InetAddressType) String() string {
+	switch t {
+	case InetAddressUnknown:
+		return "unknown"
+	case InetAddressIPv4:
+		return "ipv4"
+	case InetAddressIPv6:
+		return "ipv6"
+	case InetAddressIPv4z:
+		return "ipv4z"
+	case InetAddressIPv6z:
+		return "ipv6z"
+	case InetAddressDNS:
+		return "dns"
+	}
+	return "InetAddressType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// EncodeInetAddress 将地址编码为 InetAddressType 和 InetAddress 字节串
+//
+// 带 zone 的地址编码为 ipv4z/ipv6z，zone 为数字或本机网卡名称。
+// 无效地址返回 unknown 和空字节串。
+func EncodeInetAddress(addr netip.Addr) (InetAddressType, []byte, error) {
+	if !addr.IsValid() {
+		return InetAddressUnknown, []byte{}, nil
+	}
+
+	zone := addr.Zone()
+	addr = addr.WithZone("")
+
+	var (
+		addrType InetAddressType
+		b        []byte
+	)
+	if addr.Is4() || addr.Is4In6() {
+		v4 := addr.Unmap().As4()
+		addrType, b = InetAddressIPv4, v4[:]
+	} else {
+		v6 := addr.As16()
+		addrType, b = InetAddressIPv6, v6[:]
+	}
+
+	if zone == "" {
+		return addrType, b, nil
+	}
+
+	zoneIndex, err := zoneToIndex(zone)
+	if err != nil {
+		return InetAddressUnknown, nil, err
+	}
+	b = binary.BigEndian.AppendUint32(b, zoneIndex)
+	if addrType == InetAddressIPv4 {
+		return InetAddressIPv4z, b, nil
+	}
+	return InetAddressIPv6z, b, nil
+}
+
+// EncodeInetAddressIP 与 EncodeInetAddress 相同，接受 net.IP
+func EncodeInetAddressIP(ip net.IP) (InetAddressType, []byte) {
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return InetAddressUnknown, []byte{}
+	}
+	// 无 zone 时不会出错
+	addrType, b, _ := EncodeInetAddress(addr)
+	return addrType, b
+}
+
+// DecodeInetAddress 解码 InetAddressType 和 InetAddress 字节串
+//
+// dns 类型请直接将字节串作为主机名使用，这里返回错误。
+func DecodeInetAddress(addrType InetAddressType, b []byte) (netip.Addr, error) {
+	switch addrType {
+	case InetAddressUnknown:
+		if len(b) != 0 {
+			return netip.Addr{}, fmt.Errorf("unknown InetAddress must be empty, got %d bytes", len(b))
+		}
+		return netip.Addr{}, nil
+	case InetAddressIPv4:
+		if len(b) != 4 {
+			return netip.Addr{}, fmt.Errorf("invalid ipv4 InetAddress length: %d", len(b))
+		}
+		return netip.AddrFrom4([4]byte(b)), nil
+	case InetAddressIPv6:
+		if len(b) != 16 {
+			return netip.Addr{}, fmt.Errorf("invalid ipv6 InetAddress length: %d", len(b))
+		}
+		return netip.AddrFrom16([16]byte(b)), nil
+	case InetAddressIPv4z:
+		if len(b) != 8 {
+			return netip.Addr{}, fmt.Errorf("invalid ipv4z InetAddress length: %d", len(b))
+		}
+		zone := strconv.FormatUint(uint64(binary.BigEndian.Uint32(b[4:])), 10)
+		return netip.AddrFrom4([4]byte(b[:4])).WithZone(zone), nil
+	case InetAddressIPv6z:
+		if len(b) != 20 {
+			return netip.Addr{}, fmt.Errorf("invalid ipv6z InetAddress length: %d", len(b))
+		}
+		zone := strconv.FormatUint(uint64(binary.BigEndian.Uint32(b[16:])), 10)
+		return netip.AddrFrom16([16]byte(b[:16])).WithZone(zone), nil
+	}
+	return netip.Addr{}, fmt.Errorf("unsupported InetAddressType: %v", addrType)
+}
+
+// zoneToIndex 将 zone 转换为 RFC 4001 要求的数字索引
+func zoneToIndex(zone string) (uint32, error) {
+	if n, err := strconv.ParseUint(zone, 10, 32); err == nil {
+		return uint32(n), nil
+	}
+	iface, err := net.InterfaceByName(zone)
+	if err != nil {
+		return 0, fmt.Errorf("invalid address zone %q: %w", zone, err)
+	}
+	return uint32(iface.Index), nil
+}
+
+// TextConvention 文本约定，通过 SetTextConvention 关联到 OID 后校验 SET 的值，并在日志中以可读形式显示值
+type TextConvention interface {
+	// Validate 检查值是否符合约定
+	Validate(value interface{}) error
+	// Format 将值格式化为日志中显示的文本，不符合约定的值也应返回可读的文本
+	Format(value interface{}) string
+}
+
+// Enum INTEGER 枚举，键为取值，值为 MIB 中的名称
+type Enum map[int]string
+
+// 常用的枚举
+var (
+	TruthValueEnum      = Enum{TruthTrue: "true", TruthFalse: "false"}
+	AdminStatusEnum     = Enum{AdminStatusUp: "up", AdminStatusDown: "down", AdminStatusTesting: "testing"}
+	OperStatusEnum      = Enum{OperStatusUp: "up", OperStatusDown: "down", OperStatusTesting: "testing", OperStatusUnknown: "unknown", OperStatusDormant: "dormant", OperStatusNotPresent: "notPresent", OperStatusLowerLayerDown: "lowerLayerDown"}
+	InetAddressTypeEnum = Enum{int(InetAddressUnknown): "unknown", int(InetAddressIPv4): "ipv4", int(InetAddressIPv6): "ipv6", int(InetAddressIPv4z): "ipv4z", int(InetAddressIPv6z): "ipv6z", int(InetAddressDNS): "dns"}
+)
+
+// Validate 检查值是否为枚举的取值之一
+func (e Enum) Validate(value interface{}) error {
+	n, err := toInt64(value)
+	if err != nil {
+		return err
+	}
+	if _, ok := e[int(n)]; !ok {
+		return fmt.Errorf("invalid enumeration value: %d", n)
+	}
+	return nil
+}
+
+// Format 按 net-snmp 的格式显示枚举值，如 "up(1)"，未定义的取值只显示数字
+func (e Enum) Format(value interface{}) string {
+	n, err := toInt64(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	if name, ok := e[int(n)]; ok {
+		return name + "(" + strconv.FormatInt(n, 10) + ")"
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// Parse 将名称解析为取值，接受 "up"、"up(1)" 和 "1" 三种形式
+func (e Enum) Parse(text string) (int, error) {
+	text = strings.TrimSpace(text)
+	if name, num, ok := strings.Cut(strings.TrimSuffix(text, ")"), "("); ok && strings.HasSuffix(text, ")") {
+		n, err := strconv.Atoi(num)
+		if err != nil || e[n] != name {
+			return 0, fmt.Errorf("invalid enumeration value: %s", text)
+		}
+		return n, nil
+	}
+	if n, err := strconv.Atoi(text); err == nil {
+		if _, ok := e[n]; ok {
+			return n, nil
+		}
+	}
+	for n, name := range e {
+		if name == text {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid enumeration value: %s", text)
+}
+
+// octets 取出 OctetString 值的字节
+func octets(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	}
+	return nil, fmt.Errorf("cannot use %T as OctetString", value)
+}
+
+// DateAndTime 日期时间（SNMPv2-TC），编码为 OctetString
+var DateAndTime TextConvention = dateAndTimeTC{}
+
+// MacAddress MAC 地址（SNMPv2-TC），编码为 6 字节的 OctetString
+var MacAddress TextConvention = macAddressTC{}
+
+type dateAndTimeTC struct{}
+
+func (dateAndTimeTC) Validate(value interface{}) error {
+	b, err := octets(value)
+	if err != nil {
+		return err
+	}
+	_, err = DecodeDateAndTime(b)
+	return err
+}
+
+// Format 按 MIB 的 DISPLAY-HINT 显示，如 "2024-5-17,13:30:15.0,+8:0"
+func (dateAndTimeTC) Format(value interface{}) string {
+	b, err := octets(value)
+	if err != nil || (len(b) != 8 && len(b) != 11) {
+		return fmt.Sprintf("%x", value)
+	}
+	text := fmt.Sprintf("%d-%d-%d,%d:%d:%d.%d", binary.BigEndian.Uint16(b), b[2], b[3], b[4], b[5], b[6], b[7])
+	if len(b) == 11 {
+		text += fmt.Sprintf(",%c%d:%d", b[8], b[9], b[10])
+	}
+	return text
+}
+
+// EncodeDateAndTime 将时间编码为 11 字节的 DateAndTime，包含 t 所在时区的 UTC 偏移，精度为 0.1 秒
+func EncodeDateAndTime(t time.Time) []byte {
+	_, offset := t.Zone()
+	direction := byte('+')
+	if offset < 0 {
+		direction, offset = '-', -offset
+	}
+	b := binary.BigEndian.AppendUint16(make([]byte, 0, 11), uint16(t.Year()))
+	return append(b,
+		byte(t.Month()), byte(t.Day()), byte(t.Hour()), byte(t.Minute()), byte(t.Second()), byte(t.Nanosecond()/int(100*time.Millisecond)),
+		direction, byte(offset/3600), byte(offset%3600/60))
+}
+
+// DecodeDateAndTime 解码 8 或 11 字节的 DateAndTime，8 字节时没有时区信息，按 UTC 处理
+//
+// 时区偏移的小时允许 0~14（RFC 2579 为 0~13，兼容 UTC+14 时区）；秒为 60（闰秒）时按下一分钟处理。
+func DecodeDateAndTime(b []byte) (time.Time, error) {
+	if len(b) != 8 && len(b) != 11 {
+		return time.Time{}, fmt.Errorf("invalid DateAndTime length: %d", len(b))
+	}
+	year, month, day := int(binary.BigEndian.Uint16(b)), int(b[2]), int(b[3])
+	hour, minute, sec, deci := int(b[4]), int(b[5]), int(b[6]), int(b[7])
+	if month < 1 || month > 12 || day < 1 || day > 31 || hour > 23 || minute > 59 || sec > 60 || deci > 9 {
+		return time.Time{}, fmt.Errorf("invalid DateAndTime: %s", DateAndTime.Format(b))
+	}
+
+	loc := time.UTC
+	if len(b) == 11 {
+		if (b[8] != '+' && b[8] != '-') || b[9] > 14 || b[10] > 59 {
+			return time.Time{}, fmt.Errorf("invalid DateAndTime time zone: %s", DateAndTime.Format(b))
+		}
+		offset := int(b[9])*3600 + int(b[10])*60
+		if b[8] == '-' {
+			offset = -offset
+		}
+		if offset != 0 {
+			loc = time.FixedZone("", offset)
+		}
+	}
+	return time.Date(year, time.Month(month), day, hour, minute, sec, deci*int(100*time.Millisecond), loc), nil
+}
+
+type macAddressTC struct{}
+
+func (macAddressTC) Validate(value interface{}) error {
+	b, err := octets(value)
+	if err != nil {
+		return err
+	}
+	if len(b) != 6 {
+		return fmt.Errorf("invalid MacAddress length: %d", len(b))
+	}
+	return nil
+}
+
+// Format 显示为冒号分隔的十六进制，如 "00:1a:2b:3c:4d:5e"
+func (macAddressTC) Format(value interface{}) string {
+	b, err := octets(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return net.HardwareAddr(b).String()
+}
+
+// ParseMacAddress 解析 MAC 地址文本（如 "00:1a:2b:3c:4d:5e"）为 6 字节的 MacAddress
+func ParseMacAddress(text string) ([]byte, error) {
+	mac, err := net.ParseMAC(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(mac) != 6 {
+		return nil, fmt.Errorf("invalid MacAddress length: %d", len(mac))
+	}
+	return mac, nil
+}
+
+// ValidateInetAddress 检查 InetAddress 字节串与 InetAddressType 是否匹配
+//
+// dns 类型要求 1~255 字节的主机名。
+func ValidateInetAddress(addrType InetAddressType, b []byte) error {
+	if addrType == InetAddressDNS {
+		if len(b) == 0 || len(b) > 255 {
+			return fmt.Errorf("invalid dns InetAddress length: %d", len(b))
+		}
+		return nil
+	}
+	_, err := DecodeInetAddress(addrType, b)
+	return err
+}
+
+// FormatInetAddress 将 InetAddressType 和 InetAddress 格式化为日志中显示的文本，如 "ipv6:fe80::1%2"
+func FormatInetAddress(addrType InetAddressType, b []byte) string {
+	if addrType == InetAddressDNS {
+		return "dns:" + string(b)
+	}
+	addr, err := DecodeInetAddress(addrType, b)
+	if err != nil {
+		return fmt.Sprintf("%v:%x", addrType, b)
+	}
+	if !addr.IsValid() {
+		return addrType.String()
+	}
+	return addrType.String() + ":" + addr.String()
+}
+
+// SetTextConvention 为相对 OID 关联文本约定，见 SetTextConventionAbsolute
+func (a *Agent) SetTextConvention(relativeOID string, tc TextConvention) error {
+	absoluteOID := fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID)
+	return a.SetTextConventionAbsolute(absoluteOID, tc)
+}
+
+// SetTextConventionAbsolute 为绝对路径 OID 关联文本约定，tc 为 nil 时取消
+//
+// 关联后不符合约定的 SET 值在调用 setter 前被拒绝，SET 和 GET 日志中的值按 tc.Format 显示。
+// 关联与 OID 是否已注册无关，重新注册 OID 后仍然有效。
+func (a *Agent) SetTextConventionAbsolute(oid string, tc TextConvention) error {
+	oid, err := normalizeOID(oid)
+	if err != nil {
+		return err
+	}
+	return a.updateStore(func(s *oidStore) error {
+		if tc == nil {
+			delete(s.tcs, oid)
+		} else {
+			s.tcs[oid] = tc
+		}
+		return nil
+	})
+}
+
+// displayValue 返回日志中显示的值，未关联文本约定时原样返回
+func displayValue(tc TextConvention, value interface{}) interface{} {
+	if tc == nil {
+		return value
+	}
+	return tc.Format(value)
+}
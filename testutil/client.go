@@ -0,0 +1,51 @@
+package testutil
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// Walk 对 target（host:port）执行 SNMPv2c WALK，返回 rootOID 下的所有变量
+func Walk(target, community, rootOID string) ([]gosnmp.SnmpPDU, error) {
+	client := &gosnmp.GoSNMP{
+		Community: community,
+		Version:   gosnmp.Version2c,
+		Timeout:   2 * time.Second,
+		Retries:   1,
+	}
+
+	host, port, err := splitTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	client.Target = host
+	client.Port = port
+
+	if err := client.Connect(); err != nil {
+		return nil, fmt.Errorf("connect %s: %w", target, err)
+	}
+	defer client.Conn.Close()
+
+	pdus, err := client.WalkAll(rootOID)
+	if err != nil {
+		return nil, fmt.Errorf("walk %s: %w", rootOID, err)
+	}
+	return pdus, nil
+}
+
+// splitTarget 解析 host:port，端口缺省为 161
+func splitTarget(target string) (string, uint16, error) {
+	host, portStr, err := net.SplitHostPort(target)
+	if err != nil {
+		return target, 161, nil
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port in %q: %w", target, err)
+	}
+	return host, uint16(port), nil
+}
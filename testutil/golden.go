@@ -0,0 +1,152 @@
+// Package testutil 提供基于 SNMP WALK 的契约测试工具和不占用端口的内存传输
+//
+// 典型用法：在测试中启动 Agent，调用 Walk 获取整棵树，再用 AssertGoldenWalk
+// 与仓库中的 golden 文件比对。设置环境变量 SNMP_UPDATE_GOLDEN=1 时会重写 golden 文件。
+package testutil
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// UpdateGoldenEnv 设置为非空时，AssertGoldenWalk 会用当前结果覆盖 golden 文件
+const UpdateGoldenEnv = "SNMP_UPDATE_GOLDEN"
+
+// dynamicPlaceholder 动态 OID 在 golden 文件中的值占位符
+const dynamicPlaceholder = "<dynamic>"
+
+// FormatWalk 将 WALK 结果格式化为 golden 文本，每行一个变量
+//
+// dynamic 中的 OID 及其子树只保留类型，值替换为占位符，
+// 用于运行时间、计数器等每次都会变化的值。
+func FormatWalk(pdus []gosnmp.SnmpPDU, dynamic ...string) string {
+	var buf bytes.Buffer
+	for _, pdu := range pdus {
+		oid := strings.TrimPrefix(pdu.Name, ".")
+		value := dynamicPlaceholder
+		if !matchesAny(oid, dynamic) {
+			value = formatValue(pdu)
+		}
+		fmt.Fprintf(&buf, "%s = %s: %s\n", oid, pdu.Type, value)
+	}
+	return buf.String()
+}
+
+// AssertGoldenWalk 比对 WALK 结果与 golden 文件，不一致时报告差异
+func AssertGoldenWalk(tb testing.TB, goldenPath string, pdus []gosnmp.SnmpPDU, dynamic ...string) {
+	tb.Helper()
+
+	got := FormatWalk(pdus, dynamic...)
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0o755); err != nil {
+			tb.Fatalf("create golden dir: %v", err)
+		}
+		if err := os.WriteFile(goldenPath, []byte(got), 0o644); err != nil {
+			tb.Fatalf("write golden file: %v", err)
+		}
+		tb.Logf("updated golden file %s", goldenPath)
+		return
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		tb.Fatalf("read golden file (run with %s=1 to create it): %v", UpdateGoldenEnv, err)
+	}
+
+	if diff := diffLines(string(want), got); diff != "" {
+		tb.Errorf("walk does not match %s:\n%s", goldenPath, diff)
+	}
+}
+
+// diffLines 按 OID 行比较，返回缺失、多出和变化的行
+func diffLines(want, got string) string {
+	wantLines := splitLines(want)
+	gotLines := splitLines(got)
+
+	wantByOID := make(map[string]string, len(wantLines))
+	for _, line := range wantLines {
+		wantByOID[lineOID(line)] = line
+	}
+	gotByOID := make(map[string]string, len(gotLines))
+	for _, line := range gotLines {
+		gotByOID[lineOID(line)] = line
+	}
+
+	var buf bytes.Buffer
+	for _, line := range wantLines {
+		g, ok := gotByOID[lineOID(line)]
+		switch {
+		case !ok:
+			fmt.Fprintf(&buf, "- %s\n", line)
+		case g != line:
+			fmt.Fprintf(&buf, "- %s\n+ %s\n", line, g)
+		}
+	}
+	for _, line := range gotLines {
+		if _, ok := wantByOID[lineOID(line)]; !ok {
+			fmt.Fprintf(&buf, "+ %s\n", line)
+		}
+	}
+
+	if buf.Len() == 0 && strings.Join(wantLines, "\n") != strings.Join(gotLines, "\n") {
+		buf.WriteString("walk order changed\n")
+	}
+	return buf.String()
+}
+
+func splitLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func lineOID(line string) string {
+	oid, _, _ := strings.Cut(line, " = ")
+	return oid
+}
+
+func matchesAny(oid string, prefixes []string) bool {
+	for _, p := range prefixes {
+		p = strings.Trim(p, ".")
+		if oid == p || strings.HasPrefix(oid, p+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func formatValue(pdu gosnmp.SnmpPDU) string {
+	switch v := pdu.Value.(type) {
+	case []byte:
+		if isPrintable(v) {
+			return fmt.Sprintf("%q", v)
+		}
+		return fmt.Sprintf("0x%X", v)
+	case string:
+		return fmt.Sprintf("%q", v)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(pdu.Value)
+}
+
+func isPrintable(b []byte) bool {
+	for _, r := range string(b) {
+		if r == unicode.ReplacementChar || !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,177 @@
+package testutil
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	lzsnmp "github.com/liuzhen9320/snmp-go"
+)
+
+// Client 通过任意 net.PacketConn 访问 Agent 的 SNMPv1/v2c 测试客户端
+//
+// 与 Walk 不同，Client 不需要 UDP，并返回完整的响应报文，测试可以检查 Error 和 ErrorIndex。
+type Client struct {
+	Community string             // 默认 public
+	Version   gosnmp.SnmpVersion // 默认 v2c，只支持 v1 和 v2c
+	Timeout   time.Duration      // 等待响应的时长，默认 2 秒
+
+	conn  net.PacketConn
+	agent net.Addr
+
+	mu        sync.Mutex
+	requestID uint32
+	buf       []byte
+}
+
+// NewClient 创建通过 conn 向 agent 地址发送请求的客户端
+func NewClient(conn net.PacketConn, agent net.Addr) *Client {
+	return &Client{
+		Community: "public",
+		Version:   gosnmp.Version2c,
+		Timeout:   2 * time.Second,
+		conn:      conn,
+		agent:     agent,
+		buf:       make([]byte, 65535),
+	}
+}
+
+// StartInMemory 在新的 MemNetwork 上启动 agent，返回连接到它的客户端
+//
+// 测试结束时调用 agent.Stop 和 Client.Close。
+func StartInMemory(agent *lzsnmp.Agent) (*Client, error) {
+	network := NewMemNetwork()
+	server, err := network.Listen("agent")
+	if err != nil {
+		return nil, err
+	}
+	if err := agent.StartPacketConn(server); err != nil {
+		server.Close()
+		return nil, err
+	}
+	conn, err := network.Listen("")
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn, server.LocalAddr()), nil
+}
+
+// Close 关闭客户端的连接
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Get 发送 GetRequest
+func (c *Client) Get(oids ...string) (*gosnmp.SnmpPacket, error) {
+	return c.request(gosnmp.GetRequest, 0, 0, nullVariables(oids))
+}
+
+// GetNext 发送 GetNextRequest
+func (c *Client) GetNext(oids ...string) (*gosnmp.SnmpPacket, error) {
+	return c.request(gosnmp.GetNextRequest, 0, 0, nullVariables(oids))
+}
+
+// GetBulk 发送 GetBulkRequest，只支持 v2c
+func (c *Client) GetBulk(nonRepeaters uint8, maxRepetitions uint32, oids ...string) (*gosnmp.SnmpPacket, error) {
+	if c.Version == gosnmp.Version1 {
+		return nil, fmt.Errorf("GetBulk is not supported in SNMPv1")
+	}
+	return c.request(gosnmp.GetBulkRequest, nonRepeaters, maxRepetitions, nullVariables(oids))
+}
+
+// Set 发送 SetRequest
+func (c *Client) Set(pdus ...gosnmp.SnmpPDU) (*gosnmp.SnmpPacket, error) {
+	return c.request(gosnmp.SetRequest, 0, 0, pdus)
+}
+
+// Walk 用 GetNext 遍历 rootOID 下的所有变量，响应带错误状态时返回错误（v1 的 noSuchName 表示遍历结束）
+func (c *Client) Walk(rootOID string) ([]gosnmp.SnmpPDU, error) {
+	root := "." + strings.Trim(rootOID, ".")
+	var out []gosnmp.SnmpPDU
+	for oid := root; ; {
+		resp, err := c.GetNext(oid)
+		if err != nil {
+			return out, fmt.Errorf("walk %s: %w", rootOID, err)
+		}
+		if resp.Error == gosnmp.NoSuchName && c.Version == gosnmp.Version1 {
+			return out, nil
+		}
+		if resp.Error != gosnmp.NoError {
+			return out, fmt.Errorf("walk %s: %s at %s", rootOID, resp.Error, oid)
+		}
+		if len(resp.Variables) != 1 {
+			return out, fmt.Errorf("walk %s: expected 1 variable, got %d", rootOID, len(resp.Variables))
+		}
+		pdu := resp.Variables[0]
+		if pdu.Type == gosnmp.EndOfMibView || !strings.HasPrefix(pdu.Name, root+".") {
+			return out, nil
+		}
+		out = append(out, pdu)
+		oid = pdu.Name
+	}
+}
+
+// request 发送请求并等待请求 ID 相同的响应，忽略其他报文
+func (c *Client) request(pduType gosnmp.PDUType, nonRepeaters uint8, maxRepetitions uint32, pdus []gosnmp.SnmpPDU) (*gosnmp.SnmpPacket, error) {
+	if c.Version != gosnmp.Version1 && c.Version != gosnmp.Version2c {
+		return nil, fmt.Errorf("unsupported SNMP version: %s", c.Version)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requestID++
+	req := &gosnmp.SnmpPacket{
+		Version:        c.Version,
+		Community:      c.Community,
+		PDUType:        pduType,
+		RequestID:      c.requestID,
+		NonRepeaters:   nonRepeaters,
+		MaxRepetitions: maxRepetitions,
+		Variables:      pdus,
+	}
+	out, err := req.MarshalMsg()
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s: %w", pduType, err)
+	}
+	if _, err := c.conn.WriteTo(out, c.agent); err != nil {
+		return nil, fmt.Errorf("send %s: %w", pduType, err)
+	}
+
+	if err := c.conn.SetReadDeadline(time.Now().Add(c.Timeout)); err != nil {
+		return nil, err
+	}
+	defer c.conn.SetReadDeadline(time.Time{})
+
+	decoder := &gosnmp.GoSNMP{Version: c.Version, SecurityParameters: &gosnmp.UsmSecurityParameters{}}
+	for {
+		n, _, err := c.conn.ReadFrom(c.buf)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return nil, fmt.Errorf("%s: no response within %s", pduType, c.Timeout)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("receive %s: %w", pduType, err)
+		}
+		// 解码后的值引用输入的字节，不能直接使用复用的缓冲区
+		resp, err := decoder.SnmpDecodePacket(append([]byte(nil), c.buf[:n]...))
+		if err != nil || resp.RequestID != c.requestID {
+			continue
+		}
+		return resp, nil
+	}
+}
+
+// nullVariables 构造只含 OID 的变量列表，用于 GET 类请求
+func nullVariables(oids []string) []gosnmp.SnmpPDU {
+	pdus := make([]gosnmp.SnmpPDU, len(oids))
+	for i, oid := range oids {
+		pdus[i] = gosnmp.SnmpPDU{Name: oid, Type: gosnmp.Null}
+	}
+	return pdus
+}
@@ -0,0 +1,174 @@
+package testutil
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// memQueueSize 每个端点等待读取的报文数上限，超出时丢弃新报文（与 UDP 相同）
+const memQueueSize = 256
+
+// MemNetwork 内存中的数据报网络，按地址在端点之间投递报文
+//
+// 端点实现 net.PacketConn，Agent 通过 StartPacketConn 在其上提供服务，
+// 测试不占用 UDP 端口，也不会因为 CI 中的端口冲突而失败。
+type MemNetwork struct {
+	mu    sync.Mutex
+	conns map[string]*memConn
+	next  int
+}
+
+// NewMemNetwork 创建空的内存网络
+func NewMemNetwork() *MemNetwork {
+	return &MemNetwork{conns: make(map[string]*memConn)}
+}
+
+// Listen 创建地址为 address 的端点，address 为空时自动分配，地址已被占用时返回错误
+func (n *MemNetwork) Listen(address string) (net.PacketConn, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if address == "" {
+		n.next++
+		address = fmt.Sprintf("mem-%d", n.next)
+	}
+	if _, exists := n.conns[address]; exists {
+		return nil, fmt.Errorf("address already in use: %s", address)
+	}
+	c := &memConn{
+		network:  n,
+		addr:     memAddr(address),
+		queue:    make(chan memPacket, memQueueSize),
+		closed:   make(chan struct{}),
+		deadline: make(chan struct{}),
+	}
+	n.conns[address] = c
+	return c, nil
+}
+
+// lookup 返回地址为 address 的端点
+func (n *MemNetwork) lookup(address string) *memConn {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.conns[address]
+}
+
+func (n *MemNetwork) remove(c *memConn) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.conns[string(c.addr)] == c {
+		delete(n.conns, string(c.addr))
+	}
+}
+
+// memAddr 内存网络中的地址
+type memAddr string
+
+func (a memAddr) Network() string { return "mem" }
+func (a memAddr) String() string  { return string(a) }
+
+// memPacket 投递到端点的报文
+type memPacket struct {
+	data []byte
+	from memAddr
+}
+
+// memConn 内存网络中的端点
+type memConn struct {
+	network *MemNetwork
+	addr    memAddr
+	queue   chan memPacket
+
+	closeOnce sync.Once
+	closed    chan struct{}
+
+	mu           sync.Mutex
+	readDeadline time.Time
+	deadline     chan struct{} // 修改读超时时关闭，唤醒正在等待的 ReadFrom
+}
+
+func (c *memConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	for {
+		c.mu.Lock()
+		deadline, changed := c.readDeadline, c.deadline
+		c.mu.Unlock()
+
+		var timeout <-chan time.Time
+		if !deadline.IsZero() {
+			d := time.Until(deadline)
+			if d <= 0 {
+				return 0, nil, c.opError("read", os.ErrDeadlineExceeded)
+			}
+			timer := time.NewTimer(d)
+			timeout = timer.C
+			defer timer.Stop()
+		}
+
+		select {
+		case p := <-c.queue:
+			return copy(b, p.data), p.from, nil
+		case <-c.closed:
+			return 0, nil, c.opError("read", net.ErrClosed)
+		case <-timeout:
+			return 0, nil, c.opError("read", os.ErrDeadlineExceeded)
+		case <-changed:
+			// 读超时被修改，按新的超时重新等待
+		}
+	}
+}
+
+// WriteTo 将报文投递到 addr 的端点，端点不存在或队列已满时与 UDP 一样静默丢弃
+func (c *memConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	select {
+	case <-c.closed:
+		return 0, c.opError("write", net.ErrClosed)
+	default:
+	}
+
+	dst := c.network.lookup(addr.String())
+	if dst == nil {
+		return len(b), nil
+	}
+	select {
+	case dst.queue <- memPacket{data: append([]byte(nil), b...), from: c.addr}:
+	default:
+	}
+	return len(b), nil
+}
+
+func (c *memConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.network.remove(c)
+	})
+	return nil
+}
+
+func (c *memConn) LocalAddr() net.Addr {
+	return c.addr
+}
+
+func (c *memConn) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+func (c *memConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readDeadline = t
+	close(c.deadline)
+	c.deadline = make(chan struct{})
+	return nil
+}
+
+// SetWriteDeadline 写入不会阻塞，忽略写超时
+func (c *memConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+func (c *memConn) opError(op string, err error) error {
+	return &net.OpError{Op: op, Net: "mem", Addr: c.addr, Err: err}
+}
@@ -0,0 +1,60 @@
+package lzsnmp
+
+import (
+	"context"
+	"math/rand/v2"
+)
+
+// Tracer 为请求处理的各阶段创建追踪 span，keyvals 为交替的属性键和值
+//
+// otelsnmp.NewTracer 提供 OpenTelemetry 实现。每个请求创建以下 span：
+//
+//	snmp.request  整个请求，从收到报文到发送响应
+//	snmp.decode   解码报文、检查版本和 community
+//	snmp.process  GoSNMPServer 完成 v3 认证和解密、分发变量绑定并编码响应（三者在同一次调用中完成，无法分开计时）
+//	snmp.handler  每个变量绑定的中间件和处理函数，是 snmp.process 的子 span
+//	snmp.send     发送响应，配置了 ResponseJitter 时不包含随机延迟
+type Tracer interface {
+	Start(ctx context.Context, name string, keyvals ...interface{}) (context.Context, Span)
+}
+
+// Span 一个追踪阶段
+type Span interface {
+	SetAttributes(keyvals ...interface{})
+	End(err error) // 结束 span，err 不为 nil 时标记为失败
+}
+
+// noopTracer 未配置 Tracer 时使用，不创建 span
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string, _ ...interface{}) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...interface{}) {}
+func (noopSpan) End(error)                    {}
+
+// requestIDKey 请求 ID 在 context 中的键
+type requestIDKey struct{}
+
+// RequestIDFromContext 返回 RequestInfo.Context 中的请求 ID，与该请求的日志中 "request" 键的值相同
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// newRequestID 生成 16 位十六进制的请求 ID
+func newRequestID() string {
+	var b [16]byte
+	v := rand.Uint64()
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = hexDigits[v&0xf]
+		v >>= 4
+	}
+	return string(b[:])
+}
+
+// hexDigits 请求 ID 使用的十六进制数字
+const hexDigits = "0123456789abcdef"
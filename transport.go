@@ -0,0 +1,132 @@
+package lzsnmp
+
+import (
+	"context"
+	"net"
+	"net/netip"
+)
+
+// transport 收发 SNMP 报文的连接
+//
+// ReadFrom 返回请求到达的本地地址（未知时为 nil），WriteTo 使用 local 作为响应源地址（nil 时由内核选择）。
+type transport interface {
+	ReadFrom(b []byte) (n int, local net.IP, remote net.Addr, err error)
+	WriteTo(b []byte, local net.IP, remote net.Addr) error
+	LocalAddr() net.Addr
+	Close() error
+}
+
+// udpTransport 基于 IP_PKTINFO / IPV6_PKTINFO 的 UDP 连接，
+// 监听通配地址时可以获取请求的目的地址并以其作为响应源地址
+type udpTransport struct {
+	conn *net.UDPConn
+	pkt  *pktinfo // 未启用 PKTINFO 时为 nil
+}
+
+// listenOptions 创建监听 socket 的选项
+type listenOptions struct {
+	iface  string // 绑定的网卡，为空时不绑定
+	v6only bool   // IPv6 地址只接收 IPv6 请求，与同端口的 IPv4 socket 共存
+
+	reusePort bool // 设置 SO_REUSEPORT，多个 socket 监听同一地址
+}
+
+// listenUDP 监听 UDP 地址，平台不支持 PKTINFO 时退化为普通 UDP 连接
+//
+// IPv4 地址（如 0.0.0.0）使用 IPv4 socket；空地址或 :: 为双栈 socket，
+// 此时只有 IPv6 请求可以指定响应源地址（IPV6_PKTINFO 不支持 IPv4 映射地址）。
+// 链路本地地址可以带 zone（如 "[fe80::1%eth0]:161"）。
+// addr 为 "systemd" 或 "systemd:名称" 时使用 systemd socket 激活传入的 socket，忽略 opts。
+func listenUDP(addr string, opts listenOptions) (*udpTransport, error) {
+	if isSystemdAddr(addr) {
+		conn, err := systemdPacketConn(addr)
+		if err != nil {
+			return nil, err
+		}
+		return newUDPTransport(conn), nil
+	}
+
+	network := "udp"
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		if ip, err := netip.ParseAddr(host); err == nil {
+			switch {
+			case ip.Is4():
+				network = "udp4"
+			case opts.v6only:
+				network = "udp6"
+			}
+		}
+	}
+
+	var lc net.ListenConfig
+	if opts.iface != "" || opts.reusePort {
+		lc.Control = socketControl(opts)
+	}
+	conn, err := lc.ListenPacket(context.Background(), network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return newUDPTransport(conn.(*net.UDPConn)), nil
+}
+
+// newUDPTransport 包装已绑定的 UDP 连接，绑定通配地址时启用 PKTINFO
+func newUDPTransport(conn *net.UDPConn) *udpTransport {
+	t := &udpTransport{conn: conn}
+	if local := t.conn.LocalAddr().(*net.UDPAddr); local.IP.IsUnspecified() {
+		// 绑定了具体地址时内核总是使用该地址作为源地址，不需要 PKTINFO
+		t.pkt = enablePktinfo(conn, local.IP.To4() != nil)
+	}
+	return t
+}
+
+func (t *udpTransport) ReadFrom(b []byte) (int, net.IP, net.Addr, error) {
+	if t.pkt != nil {
+		return t.pkt.readFrom(b)
+	}
+	n, remote, err := t.conn.ReadFrom(b)
+	return n, nil, remote, err
+}
+
+func (t *udpTransport) WriteTo(b []byte, local net.IP, remote net.Addr) error {
+	// 组播和广播请求的目的地址不能作为源地址
+	if local != nil && (local.IsMulticast() || local.Equal(net.IPv4bcast)) {
+		local = nil
+	}
+
+	if t.pkt != nil && local != nil {
+		return t.pkt.writeTo(b, local, remote)
+	}
+	_, err := t.conn.WriteTo(b, remote)
+	return err
+}
+
+func (t *udpTransport) LocalAddr() net.Addr {
+	return t.conn.LocalAddr()
+}
+
+func (t *udpTransport) Close() error {
+	return t.conn.Close()
+}
+
+// packetConnTransport 基于调用方提供的 net.PacketConn，不支持指定响应源地址
+type packetConnTransport struct {
+	conn net.PacketConn
+}
+
+func (t packetConnTransport) ReadFrom(b []byte) (int, net.IP, net.Addr, error) {
+	n, remote, err := t.conn.ReadFrom(b)
+	return n, nil, remote, err
+}
+
+func (t packetConnTransport) WriteTo(b []byte, _ net.IP, remote net.Addr) error {
+	_, err := t.conn.WriteTo(b, remote)
+	return err
+}
+
+func (t packetConnTransport) LocalAddr() net.Addr {
+	return t.conn.LocalAddr()
+}
+
+func (t packetConnTransport) Close() error {
+	return t.conn.Close()
+}
@@ -0,0 +1,230 @@
+package lzsnmp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// TrapStore 接收到的通知的存储，由 Receiver.SetStore 使用，实现需要支持并发调用
+type TrapStore interface {
+	Add(t *ReceivedTrap) error
+	// Query 返回符合条件的通知，按接收时间从新到旧排列
+	Query(q TrapQuery) ([]*ReceivedTrap, error)
+	// Prune 删除 before 之前接收的通知，maxCount 大于 0 时只保留最新的 maxCount 条，返回删除的条数
+	Prune(before time.Time, maxCount int) (int, error)
+	Close() error
+}
+
+// TrapQuery 通知查询条件，零值字段不参与过滤
+type TrapQuery struct {
+	Since   time.Time // 接收时间不早于 Since
+	Until   time.Time // 接收时间早于 Until
+	Source  string    // 发送方 IP 地址，不含端口
+	TrapOID string    // snmpTrapOID 位于该 OID 子树下
+	Limit   int       // 最多返回的条数，0 表示不限制
+}
+
+// Match 判断通知是否符合查询条件（不考虑 Limit），供 TrapStore 实现使用
+func (q TrapQuery) Match(t *ReceivedTrap) bool {
+	if !q.Since.IsZero() && t.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !t.Time.Before(q.Until) {
+		return false
+	}
+	if q.Source != "" && t.SourceIP() != q.Source {
+		return false
+	}
+	if q.TrapOID != "" && !hasOIDPrefix(t.TrapOID, strings.Trim(q.TrapOID, ".")) {
+		return false
+	}
+	return true
+}
+
+// TrapRetention 存储的保留策略，零值字段表示不限制
+type TrapRetention struct {
+	MaxAge   time.Duration // 删除早于 MaxAge 之前接收的通知
+	MaxCount int           // 只保留最新的 MaxCount 条
+}
+
+// trapPruneInterval 按保留策略清理存储的间隔
+const trapPruneInterval = time.Minute
+
+// SourceIP 返回发送方的 IP 地址，不含端口
+func (t *ReceivedTrap) SourceIP() string {
+	if t.Source == nil {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(t.Source.String()); err == nil {
+		return host
+	}
+	return t.Source.String()
+}
+
+// receivedTrapJSON ReceivedTrap 的 JSON 形式，变量与 ExportSubtree 使用相同的文本格式
+type receivedTrapJSON struct {
+	Time         time.Time      `json:"time"`
+	Source       string         `json:"source,omitempty"`
+	Version      string         `json:"version"`
+	Community    string         `json:"community,omitempty"`
+	SecurityName string         `json:"securityName,omitempty"`
+	Inform       bool           `json:"inform,omitempty"`
+	TrapOID      string         `json:"trapOID"`
+	Uptime       uint32         `json:"uptime"`
+	AgentAddress string         `json:"agentAddress,omitempty"`
+	Variables    []SubtreeEntry `json:"variables,omitempty"`
+}
+
+// snmpVersionNames 版本名称，与 gosnmp.SnmpVersion.String 一致
+var snmpVersionNames = map[string]gosnmp.SnmpVersion{
+	gosnmp.Version1.String():  gosnmp.Version1,
+	gosnmp.Version2c.String(): gosnmp.Version2c,
+	gosnmp.Version3.String():  gosnmp.Version3,
+}
+
+// MarshalJSON 将通知编码为 JSON，TrapStore 实现可以用它持久化通知
+func (t *ReceivedTrap) MarshalJSON() ([]byte, error) {
+	doc := receivedTrapJSON{
+		Time:         t.Time,
+		Version:      t.Version.String(),
+		Community:    t.Community,
+		SecurityName: t.SecurityName,
+		Inform:       t.Inform,
+		TrapOID:      t.TrapOID,
+		Uptime:       t.Uptime,
+		AgentAddress: t.AgentAddress,
+	}
+	if t.Source != nil {
+		doc.Source = t.Source.String()
+	}
+	for _, v := range t.Variables {
+		entry := SubtreeEntry{OID: strings.TrimPrefix(v.Name, "."), Type: v.Type.String()}
+		// Null、noSuchObject 等没有值的类型只保存类型
+		if text, isHex, err := formatValueText(v.Type, v.Value); err == nil {
+			entry.Value, entry.Hex = text, isHex
+		}
+		doc.Variables = append(doc.Variables, entry)
+	}
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON 从 MarshalJSON 的结果还原通知，Source 还原为 *net.UDPAddr
+func (t *ReceivedTrap) UnmarshalJSON(data []byte) error {
+	var doc receivedTrapJSON
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	version, ok := snmpVersionNames[doc.Version]
+	if !ok {
+		return fmt.Errorf("unknown SNMP version: %q", doc.Version)
+	}
+
+	*t = ReceivedTrap{
+		Version:      version,
+		Community:    doc.Community,
+		SecurityName: doc.SecurityName,
+		Inform:       doc.Inform,
+		TrapOID:      doc.TrapOID,
+		Uptime:       doc.Uptime,
+		AgentAddress: doc.AgentAddress,
+		Time:         doc.Time,
+	}
+	if doc.Source != "" {
+		addr, err := net.ResolveUDPAddr("udp", doc.Source)
+		if err != nil {
+			return fmt.Errorf("invalid source %q: %w", doc.Source, err)
+		}
+		t.Source = addr
+	}
+	for _, entry := range doc.Variables {
+		pdu := gosnmp.SnmpPDU{Name: "." + entry.OID}
+		if oidType, err := ParseType(entry.Type); err == nil {
+			value, err := parseValueText(oidType, entry.Value, entry.Hex)
+			if err != nil {
+				return fmt.Errorf("variable %s: %w", entry.OID, err)
+			}
+			pdu.Type, pdu.Value = oidType, value
+		} else {
+			pdu.Type = gosnmp.Null
+		}
+		t.Variables = append(t.Variables, pdu)
+	}
+	return nil
+}
+
+// memoryTrapStore 固定容量的环形缓冲区
+type memoryTrapStore struct {
+	mu    sync.Mutex
+	buf   []*ReceivedTrap
+	next  int
+	count int
+}
+
+// NewMemoryTrapStore 创建最多保存 capacity 条通知的内存存储，满后覆盖最旧的通知
+func NewMemoryTrapStore(capacity int) (TrapStore, error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("trap store capacity must be positive")
+	}
+	return &memoryTrapStore{buf: make([]*ReceivedTrap, capacity)}, nil
+}
+
+func (s *memoryTrapStore) Add(t *ReceivedTrap) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf[s.next] = t
+	s.next = (s.next + 1) % len(s.buf)
+	s.count = min(s.count+1, len(s.buf))
+	return nil
+}
+
+// at 返回从新到旧第 i 条通知
+func (s *memoryTrapStore) at(i int) *ReceivedTrap {
+	return s.buf[(s.next-1-i+2*len(s.buf))%len(s.buf)]
+}
+
+func (s *memoryTrapStore) Query(q TrapQuery) ([]*ReceivedTrap, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []*ReceivedTrap
+	for i := 0; i < s.count; i++ {
+		if q.Limit > 0 && len(result) >= q.Limit {
+			break
+		}
+		if t := s.at(i); q.Match(t) {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+func (s *memoryTrapStore) Prune(before time.Time, maxCount int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keep := s.count
+	if maxCount > 0 {
+		keep = min(keep, maxCount)
+	}
+	if !before.IsZero() {
+		for keep > 0 && s.at(keep-1).Time.Before(before) {
+			keep--
+		}
+	}
+	removed := s.count - keep
+	for i := keep; i < s.count; i++ {
+		s.buf[(s.next-1-i+2*len(s.buf))%len(s.buf)] = nil
+	}
+	s.count = keep
+	return removed, nil
+}
+
+func (s *memoryTrapStore) Close() error {
+	return nil
+}
@@ -0,0 +1,134 @@
+//go:build !lzsnmp_nosql
+
+package lzsnmp
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// sqlIdentifier 允许的 SQL 表名
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// sqlTrapStore 保存在 SQL 表中的通知
+type sqlTrapStore struct {
+	db    *sql.DB
+	table string
+}
+
+// NewSQLTrapStore 使用 db 中的 table 表保存通知，表不存在时创建
+//
+// 语句使用 SQLite 语法和 ? 占位符（如 modernc.org/sqlite、mattn/go-sqlite3），其他数据库可以自行实现 TrapStore。
+// 每条通知一行：接收时间（Unix 纳秒）、发送方 IP、通知 OID 和 JSON 编码的完整内容。Close 不关闭 db。
+func NewSQLTrapStore(db *sql.DB, table string) (TrapStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("sql db is required")
+	}
+	if !sqlIdentifier.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name: %q", table)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	stmts := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			id INTEGER PRIMARY KEY,
+			time INTEGER NOT NULL,
+			source TEXT NOT NULL,
+			trap_oid TEXT NOT NULL,
+			data TEXT NOT NULL
+		)`, table),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_time ON %s (time)`, table, table),
+	}
+	for _, stmt := range stmts {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return nil, fmt.Errorf("create trap table %s: %w", table, err)
+		}
+	}
+	return &sqlTrapStore{db: db, table: table}, nil
+}
+
+func (s *sqlTrapStore) Add(t *ReceivedTrap) error {
+	data, err := t.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(fmt.Sprintf(`INSERT INTO %s (time, source, trap_oid, data) VALUES (?, ?, ?, ?)`, s.table),
+		t.Time.UnixNano(), t.SourceIP(), t.TrapOID, string(data))
+	return err
+}
+
+func (s *sqlTrapStore) Query(q TrapQuery) ([]*ReceivedTrap, error) {
+	var where []string
+	var args []interface{}
+	if !q.Since.IsZero() {
+		where, args = append(where, "time >= ?"), append(args, q.Since.UnixNano())
+	}
+	if !q.Until.IsZero() {
+		where, args = append(where, "time < ?"), append(args, q.Until.UnixNano())
+	}
+	if q.Source != "" {
+		where, args = append(where, "source = ?"), append(args, q.Source)
+	}
+	if q.TrapOID != "" {
+		oid := strings.Trim(q.TrapOID, ".")
+		where, args = append(where, "(trap_oid = ? OR substr(trap_oid, 1, ?) = ?)"), append(args, oid, len(oid)+1, oid+".")
+	}
+	query := fmt.Sprintf(`SELECT data FROM %s`, s.table)
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY time DESC, id DESC"
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", q.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*ReceivedTrap
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		t := &ReceivedTrap{}
+		if err := t.UnmarshalJSON([]byte(data)); err != nil {
+			return nil, err
+		}
+		result = append(result, t)
+	}
+	return result, rows.Err()
+}
+
+func (s *sqlTrapStore) Prune(before time.Time, maxCount int) (int, error) {
+	var removed int64
+	if !before.IsZero() {
+		res, err := s.db.Exec(fmt.Sprintf(`DELETE FROM %s WHERE time < ?`, s.table), before.UnixNano())
+		if err != nil {
+			return 0, err
+		}
+		n, _ := res.RowsAffected()
+		removed += n
+	}
+	if maxCount > 0 {
+		res, err := s.db.Exec(fmt.Sprintf(`DELETE FROM %[1]s WHERE id NOT IN (SELECT id FROM %[1]s ORDER BY time DESC, id DESC LIMIT ?)`, s.table), maxCount)
+		if err != nil {
+			return int(removed), err
+		}
+		n, _ := res.RowsAffected()
+		removed += n
+	}
+	return int(removed), nil
+}
+
+func (s *sqlTrapStore) Close() error {
+	return nil
+}
@@ -0,0 +1,98 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// minPassphraseLen RFC 3414 要求的口令最小长度
+const minPassphraseLen = 8
+
+// User SNMPv3 USM 用户
+type User struct {
+	Name           string
+	AuthProtocol   gosnmp.SnmpV3AuthProtocol // 0 或 NoAuth 表示不认证
+	AuthPassphrase string
+	PrivProtocol   gosnmp.SnmpV3PrivProtocol // 0 或 NoPriv 表示不加密，加密要求同时认证
+	PrivPassphrase string
+}
+
+// authProtocolNames 认证协议名称，用于配置文件
+var authProtocolNames = map[string]gosnmp.SnmpV3AuthProtocol{
+	"":       gosnmp.NoAuth,
+	"none":   gosnmp.NoAuth,
+	"md5":    gosnmp.MD5,
+	"sha":    gosnmp.SHA,
+	"sha224": gosnmp.SHA224,
+	"sha256": gosnmp.SHA256,
+	"sha384": gosnmp.SHA384,
+	"sha512": gosnmp.SHA512,
+}
+
+// privProtocolNames 加密协议名称，用于配置文件
+var privProtocolNames = map[string]gosnmp.SnmpV3PrivProtocol{
+	"":        gosnmp.NoPriv,
+	"none":    gosnmp.NoPriv,
+	"des":     gosnmp.DES,
+	"aes":     gosnmp.AES,
+	"aes192":  gosnmp.AES192,
+	"aes256":  gosnmp.AES256,
+	"aes192c": gosnmp.AES192C,
+	"aes256c": gosnmp.AES256C,
+}
+
+// ParseAuthProtocol 解析认证协议名称（不区分大小写），如 "SHA256"
+func ParseAuthProtocol(name string) (gosnmp.SnmpV3AuthProtocol, error) {
+	p, ok := authProtocolNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return 0, fmt.Errorf("unknown auth protocol: %s", name)
+	}
+	return p, nil
+}
+
+// ParsePrivProtocol 解析加密协议名称（不区分大小写），如 "AES"
+func ParsePrivProtocol(name string) (gosnmp.SnmpV3PrivProtocol, error) {
+	p, ok := privProtocolNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return 0, fmt.Errorf("unknown privacy protocol: %s", name)
+	}
+	return p, nil
+}
+
+// validate 检查用户配置并将未设置的协议规范为 NoAuth / NoPriv
+func (u *User) validate() error {
+	if u.Name == "" {
+		return fmt.Errorf("user name is required")
+	}
+	if u.AuthProtocol == 0 {
+		u.AuthProtocol = gosnmp.NoAuth
+	}
+	if u.PrivProtocol == 0 {
+		u.PrivProtocol = gosnmp.NoPriv
+	}
+	if u.AuthProtocol != gosnmp.NoAuth && len(u.AuthPassphrase) < minPassphraseLen {
+		return fmt.Errorf("user %s: auth passphrase must be at least %d characters", u.Name, minPassphraseLen)
+	}
+	if u.PrivProtocol != gosnmp.NoPriv {
+		if u.AuthProtocol == gosnmp.NoAuth {
+			return fmt.Errorf("user %s: privacy requires authentication", u.Name)
+		}
+		if len(u.PrivPassphrase) < minPassphraseLen {
+			return fmt.Errorf("user %s: privacy passphrase must be at least %d characters", u.Name, minPassphraseLen)
+		}
+	}
+	return nil
+}
+
+// usm 转换为 GoSNMPServer 使用的 USM 参数
+func (u User) usm() gosnmp.UsmSecurityParameters {
+	return gosnmp.UsmSecurityParameters{
+		UserName:                 u.Name,
+		AuthenticationProtocol:   u.AuthProtocol,
+		AuthenticationPassphrase: u.AuthPassphrase,
+		PrivacyProtocol:          u.PrivProtocol,
+		PrivacyPassphrase:        u.PrivPassphrase,
+	}
+}
@@ -0,0 +1,199 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/slayercat/GoSNMPServer"
+)
+
+// passwordToKeyLen RFC 3414 A.2 中口令扩展后参与哈希的字节数
+const passwordToKeyLen = 1048576
+
+// AddUser 在运行时添加 SNMPv3 用户，之后的请求即可使用，用户名已存在时返回错误
+func (a *Agent) AddUser(u User) error {
+	if err := u.validate(); err != nil {
+		return err
+	}
+	return a.updateUsers(func(users []User) ([]User, error) {
+		if slices.ContainsFunc(users, func(existing User) bool { return existing.Name == u.Name }) {
+			return nil, fmt.Errorf("duplicate user: %s", u.Name)
+		}
+		a.logger.Info("Added SNMPv3 user", "user", u.Name, "auth", u.AuthProtocol, "priv", u.PrivProtocol)
+		return append(users, u), nil
+	})
+}
+
+// RemoveUser 在运行时删除 SNMPv3 用户，之后该用户的请求被拒绝，用户不存在时返回错误
+func (a *Agent) RemoveUser(name string) error {
+	return a.updateUsers(func(users []User) ([]User, error) {
+		i := slices.IndexFunc(users, func(u User) bool { return u.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown user: %s", name)
+		}
+		a.logger.Info("Removed SNMPv3 user", "user", name)
+		return slices.Delete(users, i, i+1), nil
+	})
+}
+
+// ChangeUserKeys 在运行时更换 SNMPv3 用户的认证和加密口令，协议保持不变
+//
+// GoSNMPServer 按口令和 engineID 生成本地化密钥，因此新密钥以口令表示；用户不加密时 privPassphrase 被忽略。
+// 更换后使用旧口令的请求认证失败，管理端需要同时更新口令。
+func (a *Agent) ChangeUserKeys(name, authPassphrase, privPassphrase string) error {
+	return a.updateUsers(func(users []User) ([]User, error) {
+		i := slices.IndexFunc(users, func(u User) bool { return u.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown user: %s", name)
+		}
+		u := users[i]
+		u.AuthPassphrase = authPassphrase
+		if u.PrivProtocol != gosnmp.NoPriv {
+			u.PrivPassphrase = privPassphrase
+		}
+		if err := u.validate(); err != nil {
+			return nil, err
+		}
+		users[i] = u
+		a.logger.Info("Changed SNMPv3 user keys", "user", name)
+		return users, nil
+	})
+}
+
+// Users 返回当前的 SNMPv3 用户名
+func (a *Agent) Users() []string {
+	users := a.access.Load().users
+	names := make([]string, len(users))
+	for i, u := range users {
+		names[i] = u.Name
+	}
+	return names
+}
+
+// updateUsers 复制当前的用户列表交给 fn 修改，fn 返回 nil 错误时发布新的访问控制配置
+//
+// worker 在处理下一个请求前换入新配置，正在处理的请求仍使用原配置。
+func (a *Agent) updateUsers(fn func(users []User) ([]User, error)) error {
+	a.accessMu.Lock()
+	defer a.accessMu.Unlock()
+
+	current := a.access.Load()
+	users, err := fn(slices.Clone(current.users))
+	if err != nil {
+		return err
+	}
+	next := *current
+	next.users = users
+	a.access.Store(&next)
+	return nil
+}
+
+// EngineID 返回 Agent 完整的 snmpEngineID，用于在管理端配置本地化密钥或 trap 接收方的用户
+func (a *Agent) EngineID() []byte {
+	id, _, _ := a.engineParams()
+	return []byte(id)
+}
+
+// LocalizedKeys 返回用户在本 Agent 的 engineID 下的本地化认证密钥和加密密钥（RFC 3414 2.6）
+//
+// 与 Agent 校验请求时使用的密钥一致，包括 AES192/AES256 的密钥扩展；用户不认证或不加密时对应的密钥为 nil。
+func (a *Agent) LocalizedKeys(name string) (authKey, privKey []byte, err error) {
+	users := a.access.Load().users
+	i := slices.IndexFunc(users, func(u User) bool { return u.Name == name })
+	if i < 0 {
+		return nil, nil, fmt.Errorf("unknown user: %s", name)
+	}
+	u := users[i]
+	if u.AuthProtocol == gosnmp.NoAuth {
+		return nil, nil, nil
+	}
+
+	usm := u.usm()
+	usm.AuthoritativeEngineID = string(a.EngineID())
+	if err := usm.InitSecurityKeys(); err != nil {
+		return nil, nil, fmt.Errorf("user %s: %w", name, err)
+	}
+	if u.PrivProtocol == gosnmp.NoPriv {
+		return usm.SecretKey, nil, nil
+	}
+	return usm.SecretKey, usm.PrivacyKey, nil
+}
+
+// PasswordToKey 按 RFC 3414 A.2 将口令转换为与引擎无关的主密钥 Ku
+func PasswordToKey(proto gosnmp.SnmpV3AuthProtocol, passphrase string) ([]byte, error) {
+	if proto == gosnmp.NoAuth || proto == 0 {
+		return nil, fmt.Errorf("auth protocol is required")
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase is required")
+	}
+	h := proto.HashType().New()
+	buf := make([]byte, 64)
+	for i, n := 0, 0; n < passwordToKeyLen; n += len(buf) {
+		for j := range buf {
+			buf[j] = passphrase[i%len(passphrase)]
+			i++
+		}
+		h.Write(buf)
+	}
+	return h.Sum(nil), nil
+}
+
+// LocalizeKey 按 RFC 3414 2.6 将主密钥 Ku 本地化到 engineID：Kul = H(Ku || engineID || Ku)
+func LocalizeKey(proto gosnmp.SnmpV3AuthProtocol, ku, engineID []byte) ([]byte, error) {
+	if proto == gosnmp.NoAuth || proto == 0 {
+		return nil, fmt.Errorf("auth protocol is required")
+	}
+	h := proto.HashType().New()
+	h.Write(ku)
+	h.Write(engineID)
+	h.Write(ku)
+	return h.Sum(nil), nil
+}
+
+// decodeV3 按请求中的用户校验并解密 SNMPv3 请求，返回的报文带有用于响应的 USM 参数
+//
+// 发现报文（没有用户名或变量绑定）、未知用户、安全级别与用户配置不符和未知 context 的请求返回 nil，
+// 仍交给 GoSNMPServer（回复 Report 或不响应）；认证失败或无法解密时返回错误，不响应。
+func (a *Agent) decodeV3(w *worker, packet []byte, header *gosnmp.SnmpPacket) (*gosnmp.SnmpPacket, error) {
+	params, ok := header.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	if !ok || params.UserName == "" {
+		return nil, nil
+	}
+	sc := &w.server.SecurityConfig
+	user := sc.FindForUser(params.UserName)
+	if user == nil || header.MsgFlags&gosnmp.AuthPriv != usmFlags(user) {
+		return nil, nil
+	}
+
+	usm := user.Copy().(*gosnmp.UsmSecurityParameters)
+	usm.AuthoritativeEngineID = string(sc.AuthoritativeEngineID.Marshal())
+	usm.AuthoritativeEngineBoots = sc.AuthoritativeEngineBoots
+	usm.AuthoritativeEngineTime = sc.OnGetAuthoritativeEngineTime()
+	decoder := gosnmp.GoSNMP{Version: gosnmp.Version3, SecurityParameters: usm}
+	// UnmarshalTrap 与 SnmpDecodePacket 不同，会校验认证摘要
+	req, err := decoder.UnmarshalTrap(packet, true)
+	if err != nil {
+		return nil, fmt.Errorf("SNMPv3 request from user %s: %w", params.UserName, err)
+	}
+	if len(req.Variables) == 0 || (req.ContextName != "" && !a.knownCommunity(req.ContextName)) {
+		return nil, nil
+	}
+
+	GoSNMPServer.GenKeys(usm)
+	GoSNMPServer.GenSalt(usm)
+	req.SecurityParameters = usm
+	return req, nil
+}
+
+// usmFlags 返回用户配置要求的 msgFlags 安全级别
+func usmFlags(usm *gosnmp.UsmSecurityParameters) gosnmp.SnmpV3MsgFlags {
+	switch {
+	case usm.PrivacyProtocol > gosnmp.NoPriv:
+		return gosnmp.AuthPriv
+	case usm.AuthenticationProtocol > gosnmp.NoAuth:
+		return gosnmp.AuthNoPriv
+	}
+	return gosnmp.NoAuthNoPriv
+}
@@ -0,0 +1,244 @@
+package lzsnmp
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// asn1TypeNames 类型名称到 ASN.1 类型的映射，用于结构体标签等文本配置
+var asn1TypeNames = map[string]gosnmp.Asn1BER{
+	"integer":          gosnmp.Integer,
+	"int":              gosnmp.Integer,
+	"octetstring":      gosnmp.OctetString,
+	"string":           gosnmp.OctetString,
+	"oid":              gosnmp.ObjectIdentifier,
+	"objectidentifier": gosnmp.ObjectIdentifier,
+	"ipaddress":        gosnmp.IPAddress,
+	"counter32":        gosnmp.Counter32,
+	"gauge32":          gosnmp.Gauge32,
+	"timeticks":        gosnmp.TimeTicks,
+	"counter64":        gosnmp.Counter64,
+	"uinteger32":       gosnmp.Uinteger32,
+	"opaque":           gosnmp.Opaque,
+	"opaquefloat":      gosnmp.OpaqueFloat,
+	"opaquedouble":     gosnmp.OpaqueDouble,
+}
+
+// ParseType 解析类型名称，如 "gauge32"、"octetstring"（不区分大小写）
+func ParseType(name string) (gosnmp.Asn1BER, error) {
+	t, ok := asn1TypeNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return 0, fmt.Errorf("unknown SNMP type: %s", name)
+	}
+	return t, nil
+}
+
+// normalizeValue 将 Go 值转换为 gosnmp 编码 oidType 时接受的类型
+func normalizeValue(oidType gosnmp.Asn1BER, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, fmt.Errorf("nil value for type %v", oidType)
+	}
+
+	switch oidType {
+	case gosnmp.Integer:
+		n, err := toInt64(value)
+		if err != nil {
+			return nil, err
+		}
+		return int(n), nil
+	case gosnmp.Counter32, gosnmp.Gauge32, gosnmp.Uinteger32:
+		n, err := toUint64(value)
+		if err != nil {
+			return nil, err
+		}
+		if n > 0xFFFFFFFF {
+			return nil, fmt.Errorf("value %d overflows %v", n, oidType)
+		}
+		return uint(n), nil
+	case gosnmp.TimeTicks:
+		n, err := toUint64(value)
+		if err != nil {
+			return nil, err
+		}
+		return uint32(n), nil
+	case gosnmp.Counter64:
+		return toUint64(value)
+	case gosnmp.OctetString, gosnmp.Opaque:
+		switch v := value.(type) {
+		case string, []byte:
+			return v, nil
+		case float32:
+			if oidType == gosnmp.Opaque {
+				return EncodeOpaqueFloat(v), nil
+			}
+		case float64:
+			if oidType == gosnmp.Opaque {
+				return EncodeOpaqueDouble(v), nil
+			}
+		case fmt.Stringer:
+			return v.String(), nil
+		}
+		return fmt.Sprint(value), nil
+	case gosnmp.ObjectIdentifier:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("cannot use %T as ObjectIdentifier", value)
+	case gosnmp.IPAddress:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case net.IP:
+			return v.String(), nil
+		}
+		return nil, fmt.Errorf("cannot use %T as IPAddress", value)
+	case gosnmp.OpaqueFloat:
+		f, err := toFloat64(value)
+		return float32(f), err
+	case gosnmp.OpaqueDouble:
+		return toFloat64(value)
+	}
+
+	return value, nil
+}
+
+func toInt64(value interface{}) (int64, error) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return int64(rv.Uint()), nil
+	}
+	return 0, fmt.Errorf("cannot convert %T to integer", value)
+}
+
+func toUint64(value interface{}) (uint64, error) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if rv.Int() < 0 {
+			return 0, fmt.Errorf("negative value %d for unsigned type", rv.Int())
+		}
+		return uint64(rv.Int()), nil
+	}
+	return 0, fmt.Errorf("cannot convert %T to unsigned integer", value)
+}
+
+func toFloat64(value interface{}) (float64, error) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), nil
+	}
+	return 0, fmt.Errorf("cannot convert %T to float", value)
+}
+
+// ParseValue 将文本解析为 oidType 对应的值，hex 表示 OctetString 的文本为十六进制编码
+//
+// 与 ExportSubtree、配置文件和管理接口使用相同的文本格式。
+func ParseValue(oidType gosnmp.Asn1BER, text string, hex bool) (interface{}, error) {
+	return parseValueText(oidType, text, hex)
+}
+
+// FormatValue 将值格式化为 ParseValue 接受的文本，非 UTF-8 的 OctetString 以十六进制表示并返回 true
+func FormatValue(oidType gosnmp.Asn1BER, value interface{}) (string, bool, error) {
+	return formatValueText(oidType, value)
+}
+
+// formatValueText 将值格式化为文本，非 UTF-8 的 OctetString 以十六进制表示并返回 hex 标记
+func formatValueText(oidType gosnmp.Asn1BER, value interface{}) (string, bool, error) {
+	value, err := normalizeValue(oidType, value)
+	if err != nil {
+		return "", false, err
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		if !utf8.Valid(v) {
+			return hex.EncodeToString(v), true, nil
+		}
+		return string(v), false, nil
+	case string:
+		if !utf8.ValidString(v) {
+			return hex.EncodeToString([]byte(v)), true, nil
+		}
+		return v, false, nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), false, nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), false, nil
+	}
+	return fmt.Sprint(value), false, nil
+}
+
+// parseValueText 将文本解析为 oidType 对应的值，isHex 表示 OctetString 为十六进制
+func parseValueText(oidType gosnmp.Asn1BER, text string, isHex bool) (interface{}, error) {
+	switch oidType {
+	case gosnmp.Integer:
+		n, err := strconv.ParseInt(strings.TrimSpace(text), 10, 32)
+		return int(n), err
+	case gosnmp.Counter32, gosnmp.Gauge32, gosnmp.Uinteger32:
+		n, err := strconv.ParseUint(strings.TrimSpace(text), 10, 32)
+		return uint(n), err
+	case gosnmp.TimeTicks:
+		n, err := strconv.ParseUint(strings.TrimSpace(text), 10, 32)
+		return uint32(n), err
+	case gosnmp.Counter64:
+		return strconv.ParseUint(strings.TrimSpace(text), 10, 64)
+	case gosnmp.OctetString, gosnmp.Opaque:
+		if isHex {
+			return hex.DecodeString(text)
+		}
+		return text, nil
+	case gosnmp.ObjectIdentifier:
+		return strings.TrimSpace(text), nil
+	case gosnmp.IPAddress:
+		if net.ParseIP(strings.TrimSpace(text)) == nil {
+			return nil, fmt.Errorf("invalid IP address: %s", text)
+		}
+		return strings.TrimSpace(text), nil
+	case gosnmp.OpaqueFloat:
+		f, err := strconv.ParseFloat(strings.TrimSpace(text), 32)
+		return float32(f), err
+	case gosnmp.OpaqueDouble:
+		return strconv.ParseFloat(strings.TrimSpace(text), 64)
+	}
+	return nil, fmt.Errorf("unsupported SNMP type: %v", oidType)
+}
+
+// clampUint32 将浮点值截断到 uint32 范围并四舍五入，NaN 和负数为 0
+func clampUint32(v float64) uint32 {
+	switch {
+	case math.IsNaN(v) || v <= 0:
+		return 0
+	case v >= math.MaxUint32:
+		return math.MaxUint32
+	}
+	return uint32(math.Round(v))
+}
+
+// clampUint64 将浮点值截断到 uint64 范围，NaN 和负数为 0
+func clampUint64(v float64) uint64 {
+	switch {
+	case math.IsNaN(v) || v <= 0:
+		return 0
+	case v >= math.MaxUint64:
+		return math.MaxUint64
+	}
+	return uint64(v)
+}
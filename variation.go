@@ -0,0 +1,153 @@
+package lzsnmp
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// maxRandomWalkSteps 两次请求之间最多补算的随机游走步数，超过时只走这么多步
+const maxRandomWalkSteps = 10000
+
+// Variation 模拟值的变化规律，用于让模拟设备（如 LoadSimulationFile 加载的数据）产生变化的值
+//
+// 每个 OID 调用一次 Variation 创建生成器，base 为挂载时 OID 的静态值；生成器按自挂载起经过的时长返回当前值，
+// 可能被并发调用。RandomWalk、Sine 和 CounterRate 为内置实现，也可以自行编写。
+type Variation func(base float64) func(elapsed time.Duration) float64
+
+// RandomWalk 每隔 interval（默认 1 秒）在当前值上加减不超过 step 的随机量，结果限制在 [lo, hi]
+func RandomWalk(step, lo, hi float64, interval time.Duration) Variation {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return func(base float64) func(time.Duration) float64 {
+		var mu sync.Mutex
+		current := min(max(base, lo), hi)
+		steps := int64(0)
+		return func(elapsed time.Duration) float64 {
+			mu.Lock()
+			defer mu.Unlock()
+			target := int64(elapsed / interval)
+			for range min(target-steps, maxRandomWalkSteps) {
+				current = min(max(current+(rand.Float64()*2-1)*step, lo), hi)
+			}
+			steps = target
+			return current
+		}
+	}
+}
+
+// Sine 围绕挂载时的值按正弦波动，振幅为 amplitude，周期为 period（默认 1 小时）
+func Sine(amplitude float64, period time.Duration) Variation {
+	if period <= 0 {
+		period = time.Hour
+	}
+	return func(base float64) func(time.Duration) float64 {
+		return func(elapsed time.Duration) float64 {
+			return base + amplitude*math.Sin(2*math.Pi*elapsed.Seconds()/period.Seconds())
+		}
+	}
+}
+
+// CounterRate 从挂载时的值开始以每秒 perSecond 的速率递增，Counter32 在 2^32 处回绕
+func CounterRate(perSecond float64) Variation {
+	return func(base float64) func(time.Duration) float64 {
+		return func(elapsed time.Duration) float64 {
+			return base + perSecond*elapsed.Seconds()
+		}
+	}
+}
+
+// Vary 为相对 OID 挂载变化规律，见 VaryAbsolute
+func (a *Agent) Vary(relativeOID string, v Variation) (int, error) {
+	absoluteOID := fmt.Sprintf("%s.%s", a.oidPrefix, relativeOID)
+	return a.VaryAbsolute(absoluteOID, v)
+}
+
+// VaryAbsolute 将静态 OID 改为由 v 生成的动态值，返回挂载的 OID 数量
+//
+// oid 为数值类型的静态 OID 时只挂载它本身，否则挂载子树下所有数值类型（整数、计数器、Gauge32、TimeTicks、
+// OpaqueFloat/Double）的静态 OID，每个 OID 从自己的值开始独立变化。生成的值按类型取整和截断到取值范围，
+// Counter32 回绕。没有可挂载的 OID 时返回错误；再次注册静态值即可取消变化。
+func (a *Agent) VaryAbsolute(oid string, v Variation) (int, error) {
+	if v == nil {
+		return 0, fmt.Errorf("variation is required")
+	}
+	oid, err := normalizeOID(oid)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = a.updateStore(func(s *oidStore) error {
+		var targets []string
+		if _, ok := s.staticVals[oid]; ok {
+			targets = []string{oid}
+		} else {
+			for candidate := range s.staticVals {
+				if hasOIDPrefix(candidate, oid) && isNumericType(s.types[candidate]) {
+					targets = append(targets, candidate)
+				}
+			}
+			sort.Strings(targets)
+		}
+		if len(targets) == 0 {
+			return fmt.Errorf("no numeric static OID at or under %s", oid)
+		}
+
+		start := time.Now()
+		for _, target := range targets {
+			oidType := s.types[target]
+			base, err := toFloat64(s.staticVals[target])
+			if err != nil || !isNumericType(oidType) {
+				return fmt.Errorf("cannot vary %s OID %s", oidType, target)
+			}
+			generate := v(base)
+			s.putDynamic(target, oidType, func() (interface{}, error) {
+				return variedValue(oidType, generate(time.Since(start))), nil
+			}, nil)
+			a.persist.forget(target)
+		}
+		count = len(targets)
+		a.logger.Info("Attached value variation", "oid", oid, "count", count)
+		return nil
+	})
+	return count, err
+}
+
+// isNumericType 判断类型的值能否由 Variation 生成
+func isNumericType(oidType gosnmp.Asn1BER) bool {
+	switch oidType {
+	case gosnmp.Integer, gosnmp.Counter32, gosnmp.Gauge32, gosnmp.Uinteger32, gosnmp.TimeTicks,
+		gosnmp.Counter64, gosnmp.OpaqueFloat, gosnmp.OpaqueDouble:
+		return true
+	}
+	return false
+}
+
+// variedValue 将生成的值转换为 oidType 的取值，计数器回绕，其他类型截断到取值范围
+func variedValue(oidType gosnmp.Asn1BER, v float64) interface{} {
+	switch oidType {
+	case gosnmp.Integer:
+		if math.IsNaN(v) {
+			return 0
+		}
+		return int(math.Round(min(max(v, math.MinInt32), math.MaxInt32)))
+	case gosnmp.Counter32:
+		return uint(clampUint64(v) % (1 << 32))
+	case gosnmp.Gauge32, gosnmp.Uinteger32:
+		return uint(clampUint32(v))
+	case gosnmp.TimeTicks:
+		return clampUint32(v)
+	case gosnmp.Counter64:
+		return clampUint64(v)
+	case gosnmp.OpaqueFloat:
+		return float32(v)
+	}
+	return v
+}
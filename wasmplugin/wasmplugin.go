@@ -0,0 +1,470 @@
+// Package wasmplugin 使用 WebAssembly 模块（wazero 运行时）提供 OID 子树，
+// 插件可以用任何能编译为 wasm 的语言编写，在沙箱中运行，无需重新编译 Agent
+//
+// 单独成包，不使用插件时不引入 wazero 依赖。
+//
+// 插件需要导出 memory 和以下函数，字符串以 (指针, 长度) 传入，返回值为 指针<<32 | 长度 的 i64：
+//
+//	lzsnmp_alloc(size i32) i32                      分配 size 字节供宿主写入参数
+//	lzsnmp_list() i64                               子树中的实例，每行 "<相对 OID> <类型>"，类型名同 lzsnmp.ParseType
+//	lzsnmp_get(oid, oid_len i32) i64                实例的值（文本，同 lzsnmp.ParseValue）；以 "!" 开头时为错误信息
+//	lzsnmp_set(oid, oid_len, val, val_len i32) i64  可选，写入值（OctetString 为原始字节，其他类型为文本），返回空字符串表示成功，否则为错误信息
+//
+// 相对 OID 相对于插件的子树根。宿主不释放 lzsnmp_alloc 分配的内存，返回的字符串只需在下一次调用前有效，
+// 插件可以复用同一块缓冲区。插件以 WASI reactor 方式运行：实例化后调用导出的 _initialize（如果有），
+// 不提供文件系统、网络、环境变量和命令行参数，标准输出和标准错误被丢弃。
+package wasmplugin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	lzsnmp "github.com/liuzhen9320/snmp-go"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// DefaultTimeout 每次调用插件函数的默认超时
+const DefaultTimeout = time.Second
+
+// DefaultMaxMemoryPages 插件线性内存的默认上限（64 KiB 一页，共 64 MiB）
+const DefaultMaxMemoryPages = 1024
+
+// maxInstances lzsnmp_list 最多返回的实例数
+const maxInstances = 10000
+
+// Options 插件的加载选项
+type Options struct {
+	Timeout        time.Duration // 每次调用的超时，超时的实例被丢弃，下一次调用重新实例化；默认 DefaultTimeout
+	MaxMemoryPages uint32        // 线性内存上限（页），默认 DefaultMaxMemoryPages
+	// Refresh 大于 0 时按该间隔重新调用 lzsnmp_list，注册新增的实例、注销消失的实例；
+	// 为 0 时只在加载时和调用 Plugin.Refresh 时列出
+	Refresh time.Duration
+	// OnRefreshError 定时刷新失败时调用，已注册的实例保持不变，下一个周期重试
+	OnRefreshError func(err error)
+}
+
+// Plugin 已加载的插件和它注册的实例
+//
+// 插件函数在同一个模块实例中串行执行，插件可以用全局变量保存状态；调用超时后实例被重新创建，状态丢失。
+type Plugin struct {
+	agent   *lzsnmp.Agent
+	root    string
+	name    string
+	timeout time.Duration
+
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+
+	mu        sync.Mutex
+	mod       api.Module // 超时后为 nil，下一次调用时重新实例化
+	instances map[string]gosnmp.Asn1BER
+	closed    bool
+	stop      chan struct{}
+}
+
+// Load 编译并实例化 wasm 模块，将其 lzsnmp_list 列出的实例注册在绝对路径 root 下，name 用于日志和错误信息
+func Load(agent *lzsnmp.Agent, root, name string, wasm []byte, opts Options) (*Plugin, error) {
+	root = strings.Trim(root, ".")
+	if !validOID(root) {
+		return nil, fmt.Errorf("plugin %s: invalid root OID %q", name, root)
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.MaxMemoryPages == 0 {
+		opts.MaxMemoryPages = DefaultMaxMemoryPages
+	}
+
+	ctx := context.Background()
+	// 超时时终止正在执行的插件函数，防止死循环阻塞请求
+	cfg := wazero.NewRuntimeConfig().WithMemoryLimitPages(opts.MaxMemoryPages).WithCloseOnContextDone(true)
+	r := wazero.NewRuntimeWithConfig(ctx, cfg)
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, r); err != nil {
+		r.Close(ctx)
+		return nil, fmt.Errorf("plugin %s: %w", name, err)
+	}
+	compiled, err := r.CompileModule(ctx, wasm)
+	if err != nil {
+		r.Close(ctx)
+		return nil, fmt.Errorf("plugin %s: %w", name, err)
+	}
+	for _, export := range []string{"lzsnmp_alloc", "lzsnmp_list", "lzsnmp_get"} {
+		if _, ok := compiled.ExportedFunctions()[export]; !ok {
+			r.Close(ctx)
+			return nil, fmt.Errorf("plugin %s: function %s is not exported", name, export)
+		}
+	}
+
+	p := &Plugin{
+		agent:     agent,
+		root:      root,
+		name:      name,
+		timeout:   opts.Timeout,
+		runtime:   r,
+		compiled:  compiled,
+		instances: make(map[string]gosnmp.Asn1BER),
+		stop:      make(chan struct{}),
+	}
+	if err := p.Refresh(); err != nil {
+		p.Close()
+		return nil, err
+	}
+	if opts.Refresh > 0 {
+		go p.refreshLoop(opts.Refresh, opts.OnRefreshError)
+	}
+	return p, nil
+}
+
+// LoadFile 读取 .wasm 文件并加载，见 Load
+func LoadFile(agent *lzsnmp.Agent, root, path string, opts Options) (*Plugin, error) {
+	wasm, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", path, err)
+	}
+	return Load(agent, root, filepath.Base(path), wasm, opts)
+}
+
+// Writable 判断插件是否导出了 lzsnmp_set
+func (p *Plugin) Writable() bool {
+	_, ok := p.compiled.ExportedFunctions()["lzsnmp_set"]
+	return ok
+}
+
+// Instances 返回插件当前注册的实例数
+func (p *Plugin) Instances() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.instances)
+}
+
+// Refresh 调用 lzsnmp_list，注册新增或类型改变的实例，注销消失的实例
+func (p *Plugin) Refresh() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return fmt.Errorf("plugin %s is closed", p.name)
+	}
+
+	text, err := p.callLocked("lzsnmp_list")
+	if err != nil {
+		return err
+	}
+	listed, err := p.parseList(text)
+	if err != nil {
+		return err
+	}
+
+	for oid, oidType := range p.instances {
+		if listed[oid] != oidType {
+			p.agent.UnregisterAbsolute(oid)
+			delete(p.instances, oid)
+		}
+	}
+	var setter func(oid string) lzsnmp.SetHandler
+	if p.Writable() {
+		setter = p.setter
+	}
+	for oid, oidType := range listed {
+		if _, ok := p.instances[oid]; ok {
+			continue
+		}
+		opts := lzsnmp.RegisterOpts{Name: p.name}
+		if setter != nil {
+			opts.Setter = setter(oid)
+		}
+		if err := p.agent.RegisterAbsoluteWithOpts(oid, oidType, p.getter(oid), opts); err != nil {
+			return fmt.Errorf("plugin %s: %w", p.name, err)
+		}
+		p.instances[oid] = oidType
+	}
+	return nil
+}
+
+// Close 注销插件的所有实例并释放运行时
+func (p *Plugin) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	close(p.stop)
+
+	for oid := range p.instances {
+		p.agent.UnregisterAbsolute(oid)
+	}
+	p.instances = nil
+	return p.runtime.Close(context.Background())
+}
+
+func (p *Plugin) refreshLoop(interval time.Duration, onError func(err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+		if err := p.Refresh(); err != nil && onError != nil {
+			select {
+			case <-p.stop:
+			default:
+				onError(err)
+			}
+		}
+	}
+}
+
+// parseList 解析 lzsnmp_list 的输出，返回绝对 OID → 类型
+func (p *Plugin) parseList(text string) (map[string]gosnmp.Asn1BER, error) {
+	listed := make(map[string]gosnmp.Asn1BER)
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("plugin %s: list line %d: want \"<oid> <type>\", got %q", p.name, line, scanner.Text())
+		}
+		suffix := strings.Trim(fields[0], ".")
+		if !validOID(suffix) {
+			return nil, fmt.Errorf("plugin %s: list line %d: invalid OID %q", p.name, line, fields[0])
+		}
+		oid := p.root + "." + suffix
+		oidType, err := lzsnmp.ParseType(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: list line %d: %w", p.name, line, err)
+		}
+		if len(listed) >= maxInstances {
+			return nil, fmt.Errorf("plugin %s: more than %d instances", p.name, maxInstances)
+		}
+		listed[oid] = oidType
+	}
+	return listed, nil
+}
+
+// validOID 判断 oid 是否为点分的 32 位无符号整数
+func validOID(oid string) bool {
+	if oid == "" {
+		return false
+	}
+	for _, arc := range strings.Split(oid, ".") {
+		if _, err := strconv.ParseUint(arc, 10, 32); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Plugin) getter(oid string) lzsnmp.ValueHandler {
+	suffix := strings.TrimPrefix(oid, p.root+".")
+	return func() (interface{}, error) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		oidType, ok := p.instances[oid]
+		if !ok {
+			return nil, lzsnmp.ErrNoSuchInstance
+		}
+		text, err := p.callLocked("lzsnmp_get", suffix)
+		if err != nil {
+			return nil, err
+		}
+		if msg, failed := strings.CutPrefix(text, "!"); failed {
+			return nil, fmt.Errorf("plugin %s: get %s: %s", p.name, suffix, msg)
+		}
+		value, err := lzsnmp.ParseValue(oidType, text, false)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s returned invalid %s value %q for %s: %w", p.name, oidType, text, suffix, err)
+		}
+		return value, nil
+	}
+}
+
+func (p *Plugin) setter(oid string) lzsnmp.SetHandler {
+	suffix := strings.TrimPrefix(oid, p.root+".")
+	return func(value interface{}) error {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		oidType, ok := p.instances[oid]
+		if !ok {
+			return lzsnmp.ErrNoSuchInstance
+		}
+		text, err := setText(oidType, value)
+		if err != nil {
+			return err
+		}
+		msg, err := p.callLocked("lzsnmp_set", suffix, text)
+		if err != nil {
+			return err
+		}
+		if msg != "" {
+			return fmt.Errorf("plugin %s: set %s: %s", p.name, suffix, msg)
+		}
+		return nil
+	}
+}
+
+// setText 将 SET 写入的值转换为传给 lzsnmp_set 的文本：OctetString 为原始字节，其他类型同 lzsnmp.FormatValue
+func setText(oidType gosnmp.Asn1BER, value interface{}) (string, error) {
+	if oidType == gosnmp.OctetString {
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case []byte:
+			return string(v), nil
+		}
+	}
+	text, _, err := lzsnmp.FormatValue(oidType, value)
+	return text, err
+}
+
+// callLocked 在超时内调用导出函数 name，args 依次写入插件内存并以 (指针, 长度) 传入，返回插件返回的字符串
+func (p *Plugin) callLocked(name string, args ...string) (string, error) {
+	if p.closed {
+		return "", fmt.Errorf("plugin %s is closed", p.name)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	mod, err := p.moduleLocked(ctx)
+	if err != nil {
+		return "", err
+	}
+	params := make([]uint64, 0, 2*len(args))
+	for _, arg := range args {
+		ptr, err := p.writeLocked(ctx, mod, arg)
+		if err != nil {
+			return "", p.callError(name, err)
+		}
+		params = append(params, uint64(ptr), uint64(len(arg)))
+	}
+
+	results, err := mod.ExportedFunction(name).Call(ctx, params...)
+	if err != nil {
+		return "", p.callError(name, err)
+	}
+	ptr, size := uint32(results[0]>>32), uint32(results[0])
+	data, ok := mod.Memory().Read(ptr, size)
+	if !ok {
+		return "", fmt.Errorf("plugin %s: %s returned out of range memory %d+%d", p.name, name, ptr, size)
+	}
+	return string(data), nil
+}
+
+// moduleLocked 返回模块实例，没有时（首次调用或上次超时后）重新实例化
+func (p *Plugin) moduleLocked(ctx context.Context) (api.Module, error) {
+	if p.mod != nil && !p.mod.IsClosed() {
+		return p.mod, nil
+	}
+	cfg := wazero.NewModuleConfig().
+		WithName("").
+		WithStartFunctions("_initialize").
+		WithSysWalltime().
+		WithSysNanotime()
+	mod, err := p.runtime.InstantiateModule(ctx, p.compiled, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", p.name, err)
+	}
+	if mod.Memory() == nil {
+		mod.Close(ctx)
+		return nil, fmt.Errorf("plugin %s: memory is not exported", p.name)
+	}
+	p.mod = mod
+	return mod, nil
+}
+
+// writeLocked 通过 lzsnmp_alloc 在插件内存中分配空间并写入 s
+func (p *Plugin) writeLocked(ctx context.Context, mod api.Module, s string) (uint32, error) {
+	results, err := mod.ExportedFunction("lzsnmp_alloc").Call(ctx, uint64(len(s)))
+	if err != nil {
+		return 0, err
+	}
+	ptr := uint32(results[0])
+	if !mod.Memory().Write(ptr, []byte(s)) {
+		return 0, fmt.Errorf("lzsnmp_alloc returned out of range memory %d+%d", ptr, len(s))
+	}
+	return ptr, nil
+}
+
+// callError 包装调用错误，超时或 trap 后丢弃模块实例
+func (p *Plugin) callError(name string, err error) error {
+	if p.mod != nil && p.mod.IsClosed() {
+		p.mod = nil
+	}
+	var exit *sys.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == sys.ExitCodeDeadlineExceeded {
+		return fmt.Errorf("plugin %s: %s timed out after %s", p.name, name, p.timeout)
+	}
+	return fmt.Errorf("plugin %s: %s: %w", p.name, name, err)
+}
+
+// RegisterPlugins 加载配置文件中的插件，File 的相对路径相对于 baseDir（通常为配置文件所在目录），
+// onRefreshError 见 Options.OnRefreshError
+//
+// 返回加载的插件，重新加载配置文件前应先 Close 它们。任何一个插件加载失败时关闭已加载的插件并返回错误。
+func RegisterPlugins(a *lzsnmp.Agent, fc *lzsnmp.FileConfig, baseDir string, onRefreshError func(err error)) ([]*Plugin, error) {
+	var plugins []*Plugin
+	fail := func(err error) ([]*Plugin, error) {
+		for _, p := range plugins {
+			p.Close()
+		}
+		return nil, err
+	}
+	for _, pc := range fc.Plugins {
+		if pc.MaxMemoryMB < 0 || pc.MaxMemoryMB > 4096 {
+			return fail(fmt.Errorf("plugin %s: invalid max_memory_mb %d, want 0-4096", pc.OID, pc.MaxMemoryMB))
+		}
+		opts := Options{MaxMemoryPages: uint32(pc.MaxMemoryMB) * 16, OnRefreshError: onRefreshError}
+		for _, d := range []struct {
+			text string
+			dst  *time.Duration
+			name string
+		}{{pc.Timeout, &opts.Timeout, "timeout"}, {pc.Refresh, &opts.Refresh, "refresh"}} {
+			if d.text == "" {
+				continue
+			}
+			v, err := time.ParseDuration(d.text)
+			if err != nil {
+				return fail(fmt.Errorf("plugin %s: invalid %s: %w", pc.OID, d.name, err))
+			}
+			*d.dst = v
+		}
+
+		root := pc.OID
+		if !strings.HasPrefix(root, ".") {
+			var err error
+			if root, err = a.OIDIn(pc.Prefix, root); err != nil {
+				return fail(fmt.Errorf("plugin %s: %w", pc.OID, err))
+			}
+		}
+		if pc.File == "" {
+			return fail(fmt.Errorf("plugin %s: file is required", pc.OID))
+		}
+		path := pc.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		p, err := LoadFile(a, root, path, opts)
+		if err != nil {
+			return fail(err)
+		}
+		plugins = append(plugins, p)
+	}
+	return plugins, nil
+}
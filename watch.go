@@ -0,0 +1,111 @@
+package lzsnmp
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// watchBuffer Watch 返回的 channel 缓冲的事件数
+const watchBuffer = 64
+
+// ChangeEvent 管理端通过 SET 修改了一个 OID
+type ChangeEvent struct {
+	OID          string
+	Type         gosnmp.Asn1BER
+	OldValue     interface{} // 修改前的值，无法读取或创建表格行时为 nil
+	NewValue     interface{} // 修改后的值，删除表格行时为 nil
+	Source       net.Addr    // 请求来源地址
+	Version      gosnmp.SnmpVersion
+	SecurityName string // v1/v2c 为 community，v3 为用户名
+	RequestID    string // 与该请求的日志中 "request" 键的值相同
+	Time         time.Time
+}
+
+// watchHub Watch 的订阅方
+type watchHub struct {
+	mu     sync.Mutex
+	nextID int
+	subs   map[int]*watcher
+}
+
+type watcher struct {
+	prefix string
+	ch     chan ChangeEvent
+}
+
+// Watch 订阅 oidPrefix（绝对路径，为空时表示所有 OID）下的 SET 修改
+//
+// 事件在整个 SET 请求成功后发出，被撤销的修改不会发出。可创建行的表格按单元格发出事件，状态列也包括在内。
+// 返回的函数取消订阅并关闭 channel。订阅方处理过慢、缓冲已满时新的事件被丢弃，不阻塞请求。
+func (a *Agent) Watch(oidPrefix string) (<-chan ChangeEvent, func()) {
+	h := &a.watches
+	w := &watcher{prefix: trimOID(oidPrefix), ch: make(chan ChangeEvent, watchBuffer)}
+
+	h.mu.Lock()
+	if h.subs == nil {
+		h.subs = make(map[int]*watcher)
+	}
+	id := h.nextID
+	h.nextID++
+	h.subs[id] = w
+	h.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs, id)
+			h.mu.Unlock()
+			close(w.ch)
+		})
+	}
+	return w.ch, cancel
+}
+
+func (w *watcher) covers(oid string) bool {
+	return w.prefix == "" || hasOIDPrefix(oid, w.prefix)
+}
+
+// watched 判断是否有订阅方关注 oid，没有时不需要读取旧值
+func (h *watchHub) watched(oid string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, w := range h.subs {
+		if w.covers(oid) {
+			return true
+		}
+	}
+	return false
+}
+
+// publishChanges 补充请求方信息后将事件分发给关注的订阅方
+func (a *Agent) publishChanges(w *worker, events []ChangeEvent) {
+	if len(events) == 0 {
+		return
+	}
+	h := &a.watches
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.subs) == 0 {
+		return
+	}
+
+	req := w.requestInfo("", nil)
+	now := time.Now()
+	for _, e := range events {
+		e.Source, e.Version, e.SecurityName, e.RequestID, e.Time = req.Source, req.Version, req.SecurityName, req.RequestID, now
+		for _, sub := range h.subs {
+			if !sub.covers(e.OID) {
+				continue
+			}
+			select {
+			case sub.ch <- e:
+			default:
+				a.logger.Debug("Watch subscriber is full, dropping", "oid", e.OID)
+			}
+		}
+	}
+}
@@ -0,0 +1,210 @@
+//go:build !lzsnmp_nohttp
+
+package lzsnmp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// Webhook 将接收到的通知以 HTTP POST 转发到的地址
+type Webhook struct {
+	URL      string            // 接收地址（必需）
+	TrapOIDs []string          // 只转发 snmpTrapOID 位于其中任一子树下的通知，为空时转发所有通知
+	Template string            // 请求体的 text/template 模板，为空时为通知的 JSON（见 ReceivedTrap.MarshalJSON）
+	Headers  map[string]string // 额外的请求头，如 Authorization；默认 Content-Type 为 application/json
+	Timeout  time.Duration     // 单次请求超时，默认 5 秒
+	Retries  int               // 网络错误、429 和 5xx 时的重试次数，间隔从 1 秒开始翻倍
+	Queue    int               // 等待发送的通知数上限，默认 100，队列满时丢弃新通知
+}
+
+// webhookFuncs 模板可以使用的函数
+var webhookFuncs = template.FuncMap{
+	// json 将值编码为 JSON，用于在 JSON 模板中安全地嵌入字符串
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	// text 将变量的值格式化为文本，非 UTF-8 的 OctetString 为十六进制
+	"text": func(pdu gosnmp.SnmpPDU) string {
+		text, _, err := formatValueText(pdu.Type, pdu.Value)
+		if err != nil {
+			return fmt.Sprint(pdu.Value)
+		}
+		return text
+	},
+}
+
+// webhookSender 一个 Webhook 的发送队列
+type webhookSender struct {
+	receiver *Receiver
+	config   Webhook
+	filters  []string
+	tmpl     *template.Template
+	client   *http.Client
+	queue    chan []byte
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// AddWebhook 将之后接受的通知转发到 w.URL，可以在 Start 之后调用
+//
+// 每个 Webhook 有独立的队列和发送协程，慢速或不可用的地址不会阻塞接收和其他处理函数。
+// 模板的数据为 *ReceivedTrap，另外可以使用 json 和 text 函数，如 Slack 的 incoming webhook：
+//
+//	{"text": {{printf "%s from %s" .TrapOID .SourceIP | json}}}
+//
+// 网络错误、429 和 5xx 响应按 Retries 重试，其他 4xx 响应不重试。Receiver.Close 时丢弃队列中未发送的通知。
+func (r *Receiver) AddWebhook(w Webhook) error {
+	if !strings.HasPrefix(w.URL, "http://") && !strings.HasPrefix(w.URL, "https://") {
+		return fmt.Errorf("invalid webhook URL: %s", w.URL)
+	}
+	if w.Retries < 0 {
+		return fmt.Errorf("webhook retries must not be negative")
+	}
+	if w.Timeout <= 0 {
+		w.Timeout = 5 * time.Second
+	}
+	if w.Queue <= 0 {
+		w.Queue = 100
+	}
+
+	s := &webhookSender{
+		receiver: r,
+		config:   w,
+		client:   &http.Client{Timeout: w.Timeout},
+		queue:    make(chan []byte, w.Queue),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, oid := range w.TrapOIDs {
+		oid, err := normalizeOID(oid)
+		if err != nil {
+			return fmt.Errorf("webhook %s: %w", w.URL, err)
+		}
+		s.filters = append(s.filters, oid)
+	}
+	if w.Template != "" {
+		tmpl, err := template.New(w.URL).Funcs(webhookFuncs).Parse(w.Template)
+		if err != nil {
+			return fmt.Errorf("webhook %s: invalid template: %w", w.URL, err)
+		}
+		s.tmpl = tmpl
+	}
+
+	go s.run()
+	r.addForwarder(s.enqueue, s.close)
+	r.logger.Info("Added trap webhook", "url", w.URL, "filters", len(s.filters))
+	return nil
+}
+
+// enqueue 渲染通知并放入发送队列，不阻塞接收协程
+func (s *webhookSender) enqueue(t *ReceivedTrap) {
+	if len(s.filters) > 0 && !containsOIDPrefix(s.filters, t.TrapOID) {
+		return
+	}
+
+	var body []byte
+	if s.tmpl != nil {
+		var buf bytes.Buffer
+		if err := s.tmpl.Execute(&buf, t); err != nil {
+			s.receiver.logger.Error("Failed to render webhook template", "url", s.config.URL, "trap", t.TrapOID, "error", err)
+			return
+		}
+		body = buf.Bytes()
+	} else {
+		b, err := t.MarshalJSON()
+		if err != nil {
+			s.receiver.logger.Error("Failed to encode notification for webhook", "url", s.config.URL, "trap", t.TrapOID, "error", err)
+			return
+		}
+		body = b
+	}
+
+	select {
+	case s.queue <- body:
+	default:
+		s.receiver.logger.Warn("Webhook queue is full, dropping notification", "url", s.config.URL, "trap", t.TrapOID)
+	}
+}
+
+// containsOIDPrefix oid 是否位于 prefixes 中任一子树下
+func containsOIDPrefix(prefixes []string, oid string) bool {
+	for _, p := range prefixes {
+		if hasOIDPrefix(oid, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *webhookSender) run() {
+	defer close(s.done)
+	for {
+		select {
+		case <-s.stop:
+			return
+		case body := <-s.queue:
+			if err := s.deliver(body); err != nil {
+				s.receiver.logger.Error("Failed to deliver webhook", "url", s.config.URL, "error", err)
+			}
+		}
+	}
+}
+
+// deliver 发送一次通知，按配置重试
+func (s *webhookSender) deliver(body []byte) error {
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		retry, err := s.post(body)
+		if err == nil || !retry || attempt >= s.config.Retries {
+			return err
+		}
+		s.receiver.logger.Debug("Retrying webhook", "url", s.config.URL, "attempt", attempt+1, "error", err)
+		select {
+		case <-s.stop:
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post 发送一次请求，返回失败是否值得重试
+func (s *webhookSender) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, s.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("POST %s: %s", s.config.URL, resp.Status)
+}
+
+// close 停止发送协程
+func (s *webhookSender) close() {
+	close(s.stop)
+	<-s.done
+}
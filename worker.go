@@ -0,0 +1,89 @@
+package lzsnmp
+
+import (
+	"net"
+	"sync/atomic"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/slayercat/GoSNMPServer"
+)
+
+// worker 处理请求的工作协程
+//
+// 每个 worker 有独立的 MasterAgent 和请求上下文，worker 内的请求顺序处理，
+// 因此处理函数可以安全地从所属 worker 读取正在处理的请求。
+type worker struct {
+	server  *GoSNMPServer.MasterAgent
+	current requestContext
+	oids    atomic.Pointer[oidViews] // 待换入的 OID 列表
+	views   *oidViews                // 当前使用的 OID 列表，按请求的子 Agent 和安全级别选择
+	access  *accessConfig            // MasterAgent 当前使用的访问控制配置
+	decoder gosnmp.GoSNMP            // 解码请求以更新计数器，worker 内复用
+}
+
+// packetJob 排队等待处理的请求报文
+type packetJob struct {
+	conn   transport // 请求到达的连接，响应从该连接发出
+	local  net.IP
+	addr   net.Addr
+	packet []byte
+	pooled *[]byte // packet 所在的 packetPool 缓冲区，为 nil 时不归还
+}
+
+// release 将报文缓冲区归还 packetPool，之后不能再使用 packet
+func (j packetJob) release() {
+	if j.pooled != nil {
+		packetPool.Put(j.pooled)
+	}
+}
+
+// newWorker 创建 worker 及其 MasterAgent
+func (a *Agent) newWorker() (*worker, error) {
+	access := a.access.Load()
+	master := &GoSNMPServer.MasterAgent{
+		SecurityConfig: GoSNMPServer.SecurityConfig{
+			Users: access.usm(),
+		},
+		SubAgents: []*GoSNMPServer.SubAgent{
+			{
+				CommunityIDs: access.communityIDs(),
+				OIDs:         []*GoSNMPServer.PDUValueControlItem{},
+			},
+		},
+	}
+	a.engine.Load().applySecurity(&master.SecurityConfig)
+	if err := master.ReadyForWork(); err != nil {
+		return nil, err
+	}
+	w := &worker{server: master, access: access}
+	w.decoder.SecurityParameters = &gosnmp.UsmSecurityParameters{}
+	return w, nil
+}
+
+// markGenErr 设置 SubAgent 是否将处理函数错误报告为 genErr
+func (w *worker) markGenErr(on bool) {
+	for _, subAgent := range w.server.SubAgents {
+		subAgent.UserErrorMarkPacket = on
+	}
+}
+
+// syncOIDs 换入注册后重建的 OID 列表，并按请求的 community 和安全级别选择可见的列表，
+// 只在 worker 处理请求的协程中、syncAccess 之后调用
+func (w *worker) syncOIDs(pkt *gosnmp.SnmpPacket) {
+	if views := w.oids.Swap(nil); views != nil {
+		w.views = views
+	}
+	if w.views != nil {
+		w.server.SubAgents[0].OIDs = w.views.viewOf(w.access, pkt)[securityLevelOf(pkt)]
+	}
+}
+
+// runWorker 处理 jobs 中的请求，jobs 关闭后返回
+func (a *Agent) runWorker(w *worker, jobs <-chan packetJob) {
+	for job := range jobs {
+		a.stats.queued.Add(-1)
+		a.stats.inFlight.Add(1)
+		a.handlePacket(w, job)
+		a.stats.inFlight.Add(-1)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !lzsnmp_noyaml
+
+package lzsnmp
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+)
+
+// decodeYAML 解析 YAML（含 JSON）文档到 v，未知字段视为错误，空文档返回 io.EOF
+func decodeYAML(data []byte, v interface{}) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	return dec.Decode(v)
+}
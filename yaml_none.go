@@ -0,0 +1,22 @@
+//go:build lzsnmp_noyaml
+
+package lzsnmp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// decodeYAML 排除 YAML 解析时只接受 JSON 格式（YAML 的子集）的文档，未知字段视为错误，空文档返回 io.EOF
+func decodeYAML(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		if _, ok := err.(*json.SyntaxError); ok {
+			return fmt.Errorf("YAML support is excluded by the lzsnmp_noyaml build tag, use JSON: %w", err)
+		}
+		return err
+	}
+	return nil
+}